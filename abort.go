@@ -0,0 +1,95 @@
+package gwu
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+)
+
+// Abort is a panic value that aborts the handling of a request with a status code, for code that cannot return
+// an error up the call stack. Prefer returning errors, Abort is for existing code that panics on purpose.
+//
+// Handle recovers from a panic with an Abort, or with an error wrapping a StatusError, and responds with the error
+// and the status code like for an Exec's error, if the response has not been written yet. If it has, like by a
+// Stream, Handle aborts the connection with http.ErrAbortHandler, so the client sees a cut response. It logs the
+// stack at debug level. The error must be safe to display to the client, a nil Err responds with the status text.
+// Handle does not recover from other panics, see ErrorLog.
+//
+// Example usage:
+//
+//	func (s *Store) mustLoad(ctx context.Context, id string) Poem {
+//		poem, ok := s.load(ctx, id)
+//		if !ok {
+//			panic(gwu.Abort{Status: http.StatusNotFound, Err: ErrPoemNotFound})
+//		}
+//
+//		return poem
+//	}
+type Abort struct {
+	Status int
+	Err    error
+}
+
+func (a Abort) Error() string {
+	if a.Err == nil {
+		return http.StatusText(a.status())
+	}
+
+	return a.Err.Error()
+}
+
+func (a Abort) Unwrap() error {
+	return a.Err
+}
+
+// status returns the status code of the Abort, http.StatusInternalServerError if it is no error status code.
+func (a Abort) status() int {
+	if !validStatus(a.Status) {
+		return http.StatusInternalServerError
+	}
+
+	return a.Status
+}
+
+// abortOf returns the status code and error of a panic value that is an Abort or an error wrapping a StatusError.
+func abortOf(v any) (int, error, bool) {
+	err, ok := v.(error)
+	if !ok {
+		return 0, nil, false
+	}
+
+	var abort Abort
+	if errors.As(err, &abort) {
+		return abort.status(), abort, true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status, err, true
+	}
+
+	return 0, nil, false
+}
+
+// recoverAbort responds to a panic with an Abort with its error, see Abort. If the response was already written, it
+// aborts the connection with http.ErrAbortHandler instead. Other panics continue. Call it deferred.
+func (o HandleOpts) recoverAbort(w http.ResponseWriter, r *http.Request) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	code, err, ok := abortOf(v)
+	if !ok {
+		panic(v)
+	}
+
+	o = o.enrichedLog()
+	o.logClientError(r, code, "request aborted", "method", r.Method, "path", FullPath(r), "status", code,
+		"error", err, "stack", string(debug.Stack()))
+	if o.req.vals.responseWritten() {
+		panic(http.ErrAbortHandler)
+	}
+
+	o.writeError(w, r, err, code)
+}
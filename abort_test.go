@@ -0,0 +1,83 @@
+package gwu_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+var errPoemNotFound = errors.New("poem not found")
+
+func TestAbortRespondsThroughWrappers(t *testing.T) {
+	log := gwutest.Logger()
+	clock := gwu.NewManualClock(time.Unix(0, 0))
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (any, int, error) {
+		clock.Advance(time.Second)
+		panic(gwu.Abort{Status: http.StatusNotFound, Err: errPoemNotFound})
+	}, gwu.Log(log), gwu.WithClock(clock), gwu.WarnSlow(time.Millisecond), gwu.LogBodies(1024, nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	gwutest.AssertError(t, rec, http.StatusNotFound, errPoemNotFound.Error())
+	log.AssertLogged(t, slog.LevelWarn, "slow request", "status", "404")
+	log.AssertLogged(t, slog.LevelDebug, "request and response bodies", "response_body", errPoemNotFound.Error()+"\n")
+}
+
+func TestAbortAfterResponseWritten(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.Raw, int, error) {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("partial"))
+			panic(gwu.Abort{Status: http.StatusConflict, Err: errors.New("too late")})
+		}, 0, nil
+	})
+
+	rec := httptest.NewRecorder()
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Fatalf("panic = %v, want http.ErrAbortHandler", v)
+		}
+
+		if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+			t.Errorf("got %d %q, want the untouched partial response", rec.Code, rec.Body)
+		}
+	}()
+
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestAbortAfterStreamStarted(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.Stream[int], int, error) {
+		return func(_ context.Context, send func(int) error) error {
+			_ = send(1)
+			panic(gwu.Abort{Status: http.StatusConflict, Err: errors.New("too late")})
+		}, http.StatusOK, nil
+	})
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err == nil {
+		t.Errorf("read the whole body %q, want the connection aborted", body.String())
+	}
+
+	if strings.Contains(body.String(), "too late") {
+		t.Errorf("body %q contains the error of the Abort", body.String())
+	}
+}
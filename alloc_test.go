@@ -0,0 +1,63 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// maxHandleAllocs is the target of allocations of a GET returning a small struct, excluding user code, see serve.
+const maxHandleAllocs = 4
+
+type smallPoem struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+func getSmallPoem(context.Context, any, gwu.HandleOpts) (smallPoem, int, error) {
+	return smallPoem{ID: 7, Title: "Ode"}, http.StatusOK, nil
+}
+
+// allocsPerRequest returns the average allocations of serving the request with h.
+func allocsPerRequest(h http.Handler, r *http.Request) float64 {
+	w := &discardWriter{header: make(http.Header)}
+	return testing.AllocsPerRun(100, func() {
+		clear(w.header)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// skipAllocsWithRace skips a test of allocations when the race detector is on, it allocates on its own.
+func skipAllocsWithRace(t *testing.T) {
+	t.Helper()
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+}
+
+func TestHandleAllocs(t *testing.T) {
+	skipAllocsWithRace(t)
+	rt := gwu.NewRouter()
+	// A wildcard adds the allocation of the path values by http.ServeMux, see TestRouterMatchesOnce.
+	gwu.HandleRoute(rt, "GET /poems/latest", gwu.Empty(), getSmallPoem)
+
+	tests := []struct {
+		name string
+		h    http.Handler
+		r    *http.Request
+	}{
+		{"Handle", gwu.Handle(gwu.Empty(), getSmallPoem), httptest.NewRequest(http.MethodGet, "/", nil)},
+		{"HandleRoute", rt, httptest.NewRequest(http.MethodGet, "/poems/latest", nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allocsPerRequest(tt.h, tt.r); got > maxHandleAllocs {
+				t.Errorf("%v allocs per request, want at most %d", got, maxHandleAllocs)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// The baselines of these benchmarks are in testdata/bench.txt, cite them in changes to the hot path.
+
+type benchPoem struct {
+	ID    int64    `json:"id"`
+	Title string   `json:"title"`
+	Lines []string `json:"lines"`
+}
+
+func echoPoem(_ context.Context, in benchPoem, _ gwu.HandleOpts) (benchPoem, int, error) {
+	return in, http.StatusOK, nil
+}
+
+func BenchmarkHandleSmallJSON(b *testing.B) {
+	h := gwu.Handle(gwu.JSON[benchPoem](), echoPoem)
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":7,"title":"Ode","lines":["a","b"]}`))
+	r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+
+	gwutest.Bench(b, h, r)
+}
+
+func BenchmarkHandleLargeSlice(b *testing.B) {
+	poems := make([]benchPoem, 1000)
+	for i := range poems {
+		poems[i] = benchPoem{ID: int64(i), Title: "Ode", Lines: []string{"a", "b"}}
+	}
+
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) ([]benchPoem, int, error) {
+		return poems, http.StatusOK, nil
+	})
+
+	gwutest.Bench(b, h, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func BenchmarkHandleCnInFailure(b *testing.B) {
+	h := gwu.Handle(gwu.JSON[benchPoem](), echoPoem)
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":"7"`))
+	r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+
+	gwutest.Bench(b, h, r)
+}
+
+func BenchmarkHandleError(b *testing.B) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (benchPoem, int, error) {
+		return benchPoem{}, http.StatusNotFound, errPoemNotFound
+	})
+
+	gwutest.Bench(b, h, httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,406 @@
+package gwu
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMissingParam is the error of a request without a parameter required by Bind.
+	// Is safe to display to the client.
+	ErrMissingParam = errors.New("missing parameter")
+	// ErrInvalidParam is the error of a request with a parameter Bind cannot convert to the field's type.
+	// Is safe to display to the client.
+	ErrInvalidParam = errors.New("invalid parameter")
+	// ErrBinding is the error of requests to a Bind CnIn of a type with invalid tags, see Precompile.
+	// Is safe to display to the client.
+	ErrBinding = errors.New("failed to bind request")
+)
+
+// Bind CnIn binds the path values, query parameters, headers, and form fields of the request to the fields of the
+// struct In by their path, query, header, and form tags. The tag option required rejects requests without the
+// parameter. Form fields are read from bodies of the Content-Type application/x-www-form-urlencoded or
+// multipart/form-data, the values of a multipart form, files are not bound. Bind responds to bodies that cannot be
+// parsed with ErrDecodeRequest.
+//
+// Fields are strings, bools, integers, floats, time.Duration, or implement encoding.TextUnmarshaler, like
+// time.Time. Pointers to these and Opt of them are only set if the parameter is present, slices of them bind every
+// value of a repeated query parameter or header. Other fields are left untouched.
+//
+// Bind responds to missing parameters with ErrMissingParam and to unconvertible ones with ErrInvalidParam, both
+// with the DecodeErrorStatus. Bind derives how to bind a type once, on its first request, and responds to types
+// with invalid tags with ErrBinding and http.StatusInternalServerError, call Precompile to panic at startup instead.
+//
+// Example usage:
+//
+//	type PoemQuery struct {
+//		AuthorID int64          `path:"id"`
+//		Limit    int            `query:"limit"`
+//		Tags     []string       `query:"tag"`
+//		Lang     string         `header:"Accept-Language"`
+//		Cursor   *string        `query:"cursor"`
+//		Since    gwu.Opt[int64] `query:"since"`
+//	}
+//
+//	mux.Handle("GET /author/{id}/poems", gwu.Handle(gwu.Bind[PoemQuery](), ctrl.ByAuthor))
+func Bind[In any]() CnIn[In] {
+	return func(r *http.Request, opts HandleOpts) (In, error) {
+		var in In
+		plan, err := bindPlanOf(reflect.TypeFor[In]())
+		if err != nil {
+			opts.logFailure("invalid binding", "error", err)
+			return in, WithStatus(http.StatusInternalServerError, ErrBinding)
+		}
+
+		return in, plan.bind(r, opts, reflect.ValueOf(&in).Elem())
+	}
+}
+
+// Precompile derives how Bind binds the struct T and panics if its tags are invalid. Call it at startup for the
+// types of Bind CnIns, to surface tag errors before the first request instead of responding with ErrBinding.
+func Precompile[T any]() {
+	if _, err := bindPlanOf(reflect.TypeFor[T]()); err != nil {
+		panic(err)
+	}
+}
+
+// bindSource is where a bindField reads its values from.
+type bindSource int
+
+const (
+	fromPath bindSource = iota
+	fromQuery
+	fromHeader
+	fromForm
+)
+
+// bindTags are the struct tags of the sources.
+var bindTags = [...]string{fromPath: "path", fromQuery: "query", fromHeader: "header", fromForm: "form"}
+
+// maxFormMemory is the memory a multipart form may take, larger parts are stored in temporary files like by
+// http.Request.ParseMultipartForm.
+const maxFormMemory = 10 << 20
+
+// bindField is a field bound by Bind.
+type bindField struct {
+	index    int
+	source   bindSource
+	name     string
+	required bool
+	set      func(f reflect.Value, vals []string) error
+	missing  *paramError
+	invalid  *paramError
+}
+
+// bindPlan is how Bind binds a struct type, derived once per type and shared by all requests.
+type bindPlan struct {
+	fields []bindField
+	query  bool
+	form   bool
+}
+
+// bindEntry is a cached bindPlan, or the problem of the type's tags.
+type bindEntry struct {
+	plan *bindPlan
+	err  error
+}
+
+// bindPlans caches the bindEntry of every type, keyed by reflect.Type.
+var bindPlans sync.Map
+
+// bindPlanOf returns the cached bindPlan of the type, deriving it on first use. Concurrent first uses may derive
+// the plan more than once, but all of them return the one that is stored.
+func bindPlanOf(t reflect.Type) (*bindPlan, error) {
+	if v, ok := bindPlans.Load(t); ok {
+		e := v.(*bindEntry)
+		return e.plan, e.err
+	}
+
+	plan, err := newBindPlan(t)
+	v, _ := bindPlans.LoadOrStore(t, &bindEntry{plan: plan, err: err})
+	e := v.(*bindEntry)
+
+	return e.plan, e.err
+}
+
+// newBindPlan derives the bindPlan of the struct type and reports all problems of its tags.
+func newBindPlan(t reflect.Type) (*bindPlan, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("gwu: bind %s: not a struct", t)
+	}
+
+	plan := &bindPlan{}
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		var tags []bindSource
+		for src, key := range bindTags {
+			if _, ok := sf.Tag.Lookup(key); ok {
+				tags = append(tags, bindSource(src))
+			}
+		}
+
+		if len(tags) == 0 {
+			continue
+		}
+
+		if len(tags) > 1 {
+			errs = append(errs, fmt.Errorf("field %s: more than one of the path, query, header, and form tags", sf.Name))
+			continue
+		}
+
+		if !sf.IsExported() {
+			errs = append(errs, fmt.Errorf("field %s: unexported", sf.Name))
+			continue
+		}
+
+		src := tags[0]
+		name, opts, _ := strings.Cut(sf.Tag.Get(bindTags[src]), ",")
+		if name == "" {
+			errs = append(errs, fmt.Errorf("field %s: %s tag without name", sf.Name, bindTags[src]))
+			continue
+		}
+
+		set, err := fieldSetter(sf.Type, src != fromPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field %s: %w", sf.Name, err))
+			continue
+		}
+
+		if src == fromHeader {
+			name = http.CanonicalHeaderKey(name)
+		}
+
+		plan.fields = append(plan.fields, bindField{
+			index:    i,
+			source:   src,
+			name:     name,
+			required: hasOpt(opts, "required"),
+			set:      set,
+			missing:  &paramError{err: ErrMissingParam, name: name},
+			invalid:  &paramError{err: ErrInvalidParam, name: name},
+		})
+		plan.query = plan.query || src == fromQuery
+		plan.form = plan.form || src == fromForm
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("gwu: bind %s: %w", t, errors.Join(errs...))
+	}
+
+	return plan, nil
+}
+
+// bind sets the fields of the struct v from the request.
+func (p *bindPlan) bind(r *http.Request, opts HandleOpts, v reflect.Value) error {
+	var query url.Values
+	if p.query {
+		query = r.URL.Query()
+	}
+
+	var form url.Values
+	if p.form {
+		var err error
+		if form, err = formValues(r); err != nil {
+			return ErrDecodeRequest
+		}
+	}
+
+	var path [1]string
+	for _, f := range p.fields {
+		var vals []string
+		switch f.source {
+		case fromPath:
+			if path[0] = r.PathValue(f.name); path[0] != "" {
+				vals = path[:]
+			} else {
+				opts.noWildcard(f.name)
+			}
+		case fromQuery:
+			vals = query[f.name]
+		case fromHeader:
+			vals = r.Header[f.name]
+		case fromForm:
+			vals = form[f.name]
+		}
+
+		if len(vals) == 0 {
+			if f.required {
+				return f.missing
+			}
+
+			continue
+		}
+
+		if err := f.set(v.Field(f.index), vals); err != nil {
+			return f.invalid
+		}
+	}
+
+	return nil
+}
+
+// formValues returns the fields of the form in the request body, none if the body is no form.
+func formValues(r *http.Request) (url.Values, error) {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mt {
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+
+		return r.PostForm, nil
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(maxFormMemory); err != nil {
+			return nil, err
+		}
+
+		return r.MultipartForm.Value, nil
+	default:
+		return nil, nil
+	}
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// fieldSetter returns the function setting a field of the type from the values of its parameter, the last value
+// for single values. Slices are only supported for sources with repeated values.
+func fieldSetter(t reflect.Type, repeated bool) (func(f reflect.Value, vals []string) error, error) {
+	if elem := optElem(t); elem != nil {
+		set, err := fieldSetter(elem, repeated)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(f reflect.Value, vals []string) error {
+			v := reflect.New(elem).Elem()
+			if err := set(v, vals); err != nil {
+				return err
+			}
+
+			f.Addr().Interface().(optional).setAny(v.Interface())
+			return nil
+		}, nil
+	}
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return lastValue(scalarSetter(t)), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		set, err := fieldSetter(t.Elem(), repeated)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(f reflect.Value, vals []string) error {
+			v := reflect.New(t.Elem())
+			if err := set(v.Elem(), vals); err != nil {
+				return err
+			}
+
+			f.Set(v)
+			return nil
+		}, nil
+	case reflect.Slice:
+		if !repeated {
+			return nil, fmt.Errorf("unsupported type %s for a path value", t)
+		}
+
+		set := scalarSetter(t.Elem())
+		if set == nil {
+			return nil, fmt.Errorf("unsupported type %s", t)
+		}
+
+		return func(f reflect.Value, vals []string) error {
+			s := reflect.MakeSlice(t, len(vals), len(vals))
+			for i, val := range vals {
+				if err := set(s.Index(i), val); err != nil {
+					return err
+				}
+			}
+
+			f.Set(s)
+			return nil
+		}, nil
+	}
+
+	set := scalarSetter(t)
+	if set == nil {
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+
+	return lastValue(set), nil
+}
+
+// lastValue adapts the scalar setter to the values of a parameter, it sets the last value.
+func lastValue(set func(f reflect.Value, val string) error) func(f reflect.Value, vals []string) error {
+	return func(f reflect.Value, vals []string) error {
+		return set(f, vals[len(vals)-1])
+	}
+}
+
+// scalarSetter returns the function converting and setting a single value of the type, or nil if the type is not
+// supported.
+func scalarSetter(t reflect.Type) func(f reflect.Value, val string) error {
+	switch {
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return func(f reflect.Value, val string) error {
+			return f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+		}
+	case t == durationType:
+		return func(f reflect.Value, val string) error {
+			d, err := time.ParseDuration(val)
+			f.SetInt(int64(d))
+			return err
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(f reflect.Value, val string) error {
+			f.SetString(val)
+			return nil
+		}
+	case reflect.Bool:
+		return func(f reflect.Value, val string) error {
+			b, err := strconv.ParseBool(val)
+			f.SetBool(b)
+			return err
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(f reflect.Value, val string) error {
+			n, err := strconv.ParseInt(val, 10, t.Bits())
+			f.SetInt(n)
+			return err
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(f reflect.Value, val string) error {
+			n, err := strconv.ParseUint(val, 10, t.Bits())
+			f.SetUint(n)
+			return err
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(f reflect.Value, val string) error {
+			n, err := strconv.ParseFloat(val, t.Bits())
+			f.SetFloat(n)
+			return err
+		}
+	default:
+		return nil
+	}
+}
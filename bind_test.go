@@ -0,0 +1,223 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+type poemQuery struct {
+	AuthorID int64              `path:"id"`
+	Limit    int                `query:"limit,required"`
+	Tags     []string           `query:"tag"`
+	Lang     string             `header:"accept-language"`
+	Cursor   *string            `query:"cursor"`
+	Since    gwu.Opt[time.Time] `query:"since"`
+	Timeout  time.Duration      `header:"X-Timeout"`
+	Draft    bool               `form:"draft"`
+	Ignored  string
+}
+
+// bindQuery serves the request to the route /author/{id}/poems with Bind[poemQuery] and returns the bound input.
+func bindQuery(t *testing.T, r *http.Request) (poemQuery, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	var got poemQuery
+	exec := func(_ context.Context, q poemQuery, _ gwu.HandleOpts) (gwu.NoBody, int, error) {
+		got = q
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	}
+
+	mux := http.NewServeMux()
+	gwu.HandleRoute(mux, "POST /author/{id}/poems", gwu.Bind[poemQuery](), exec)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, r)
+
+	return got, rec
+}
+
+func TestBind(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost,
+		"/author/7/poems?limit=10&tag=ode&tag=autumn&cursor=abc&since=2026-10-14T12:00:00Z&ignored=x",
+		strings.NewReader(url.Values{"draft": {"true"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept-Language", "en")
+	r.Header.Set("X-Timeout", "1.5s")
+
+	got, rec := bindQuery(t, r)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("%d %s", rec.Code, rec.Body)
+	}
+
+	cursor := "abc"
+	want := poemQuery{
+		AuthorID: 7,
+		Limit:    10,
+		Tags:     []string{"ode", "autumn"},
+		Lang:     "en",
+		Cursor:   &cursor,
+		Since:    gwu.Some(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)),
+		Timeout:  1500 * time.Millisecond,
+		Draft:    true,
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bound %+v, want %+v", got, want)
+	}
+}
+
+// TestBindAbsent leaves the fields of absent optional parameters untouched.
+func TestBindAbsent(t *testing.T) {
+	got, rec := bindQuery(t, httptest.NewRequest(http.MethodPost, "/author/7/poems?limit=1", nil))
+	if rec.Code != http.StatusNoContent || !reflect.DeepEqual(got, poemQuery{AuthorID: 7, Limit: 1}) {
+		t.Errorf("%d with %+v, want only the path value and limit", rec.Code, got)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		name, target string
+		err          error
+	}{
+		{"missing required", "/author/7/poems", gwu.ErrMissingParam},
+		{"invalid path value", "/author/keats/poems?limit=1", gwu.ErrInvalidParam},
+		{"invalid query", "/author/7/poems?limit=ten", gwu.ErrInvalidParam},
+		{"invalid time", "/author/7/poems?limit=1&since=yesterday", gwu.ErrInvalidParam},
+	}
+
+	for _, tt := range tests {
+		_, rec := bindQuery(t, httptest.NewRequest(http.MethodPost, tt.target, nil))
+		gwutest.AssertError(t, rec, http.StatusBadRequest, tt.err.Error())
+	}
+}
+
+type invalidBinding struct {
+	Both     string      `query:"a" header:"A"`
+	hidden   string      `query:"hidden"`
+	Nameless string      `query:""`
+	Map      map[int]int `query:"map"`
+	Path     []string    `path:"rest"`
+}
+
+func TestPrecompile(t *testing.T) {
+	gwu.Precompile[poemQuery]()
+
+	defer func() {
+		err, _ := recover().(error)
+		if err == nil {
+			t.Fatal("Precompile did not panic")
+		}
+
+		// All problems are reported at once.
+		for _, field := range []string{"Both", "hidden", "Nameless", "Map", "Path"} {
+			if !strings.Contains(err.Error(), "field "+field+":") {
+				t.Errorf("panic %q, want the problem of %s", err, field)
+			}
+		}
+	}()
+
+	gwu.Precompile[invalidBinding]()
+}
+
+// TestBindInvalidTags responds with ErrBinding and 500 to requests of a type with invalid tags, and logs the problem.
+func TestBindInvalidTags(t *testing.T) {
+	log := gwutest.Logger()
+	h := gwu.Handle(gwu.Bind[invalidBinding](), noContent[invalidBinding], gwu.Log(gwutest.Logger()),
+		gwu.ErrorLog(log))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	gwutest.AssertError(t, rec, http.StatusInternalServerError, gwu.ErrBinding.Error())
+
+	if entries := log.Filter(slog.LevelError); len(entries) != 1 || entries[0].Msg != "invalid binding" {
+		t.Errorf("logged %v, want the invalid binding", entries)
+	}
+}
+
+// racedQuery is bound for the first time by the goroutines of TestBindConcurrentFirstUse.
+type racedQuery struct {
+	ID    int64    `query:"id,required"`
+	Name  string   `query:"name"`
+	Tags  []string `query:"tag"`
+	Trace string   `header:"X-Trace"`
+}
+
+// TestBindConcurrentFirstUse binds a type for the first time from concurrent requests, run it with -race.
+func TestBindConcurrentFirstUse(t *testing.T) {
+	h := gwu.Handle(gwu.Bind[racedQuery](), func(_ context.Context, q racedQuery, _ gwu.HandleOpts) (racedQuery, int,
+		error) {
+		return q, http.StatusOK, nil
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			id := strconv.Itoa(i)
+			r := httptest.NewRequest(http.MethodGet, "/?id="+id+"&name=n"+id+"&tag=a&tag="+id, nil)
+			r.Header.Set("X-Trace", id)
+			if i%2 == 0 {
+				gwu.Precompile[racedQuery]()
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			want := `{"ID":` + id + `,"Name":"n` + id + `","Tags":["a","` + id + `"],"Trace":"` + id + `"}`
+			if got := strings.TrimSpace(rec.Body.String()); rec.Code != http.StatusOK || got != want {
+				errs <- errors.New(strconv.Itoa(rec.Code) + " " + got + ", want " + want)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// manualQuery reads the parameters of racedQuery without reflection, the baseline of BenchmarkBind.
+func manualQuery(r *http.Request, _ gwu.HandleOpts) (racedQuery, error) {
+	q := r.URL.Query()
+	id, err := strconv.ParseInt(q.Get("id"), 10, 64)
+	if err != nil {
+		return racedQuery{}, gwu.ErrInvalidParam
+	}
+
+	return racedQuery{ID: id, Name: q.Get("name"), Tags: q["tag"], Trace: r.Header.Get("X-Trace")}, nil
+}
+
+// BenchmarkBind compares Bind with reading the parameters by hand, the difference is the cost of the reflection.
+func BenchmarkBind(b *testing.B) {
+	r := httptest.NewRequest(http.MethodGet, "/?id=7&name=ode&tag=a&tag=b", nil)
+	r.Header.Set("X-Trace", "abc")
+	opts := gwutest.Opts()
+
+	for name, in := range map[string]gwu.CnIn[racedQuery]{"Bind": gwu.Bind[racedQuery](), "manual": manualQuery} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := in(r, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package gwu
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// LogBodies logs up to maxBytes of the request body and of the response body at the debug level after each
+// request. Use it to debug a single route temporarily, it is off by default.
+//
+// If redact is not nil, LogBodies calls it with the field name and value of every field in the JSON bodies and
+// logs the returned value instead, see RedactFields. Strings are passed unquoted, numbers, bools, null, objects, and
+// arrays as their JSON. A value that redact changes is logged as the returned string, the fields of objects and the
+// elements of arrays it leaves unchanged are redacted in turn, elements under the field name of their array. Bodies
+// that are not valid JSON, including bodies cut off at maxBytes, cannot be redacted and are omitted from the log in
+// that case.
+//
+// LogBodies does not capture responses written by a Raw and does nothing for streams, it conflicts with LongLived.
+// A maxBytes <= 0 disables body logging.
+func LogBodies(maxBytes int, redact func(field string, value string) string) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.bodyLogMax = maxBytes
+		opt.bodyLogRedact = redact
+	}
+}
+
+// RedactFields returns a redact function for LogBodies that replaces the values of the given JSON fields with
+// "[REDACTED]". It matches field names case-insensitively.
+func RedactFields(fields ...string) func(field string, value string) string {
+	return func(field string, value string) string {
+		for _, f := range fields {
+			if strings.EqualFold(f, field) {
+				return "[REDACTED]"
+			}
+		}
+
+		return value
+	}
+}
+
+// logsBodies reports whether the handler logs request and response bodies.
+func (o HandleOpts) logsBodies() bool {
+	return o.bodyLogMax > 0 && !o.longLived && !o.streams
+}
+
+// capBuffer keeps the first max bytes written to it and discards the rest.
+type capBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *capBuffer) Write(p []byte) (int, error) {
+	if rest := b.max - b.buf.Len(); len(p) > rest {
+		b.buf.Write(p[:rest])
+		b.truncated = true
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
+
+// teeBody copies everything read from the request body into a capBuffer.
+type teeBody struct {
+	io.Reader
+	io.Closer
+}
+
+// captureRequestBody replaces the request body with one that captures up to max bytes of what the CnIn reads.
+func captureRequestBody(r *http.Request, max int) *capBuffer {
+	c := &capBuffer{max: max}
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = teeBody{Reader: io.TeeReader(r.Body, c), Closer: r.Body}
+	}
+
+	return c
+}
+
+// logBodies logs the captured request and response bodies.
+func logBodies(opts HandleOpts, req, resp *capBuffer) {
+	opts = opts.enrichedLog()
+	opts.Log.Debug("request and response bodies",
+		"request_body", redactBody(req, opts.bodyLogRedact),
+		"request_body_truncated", req.truncated,
+		"response_body", redactBody(resp, opts.bodyLogRedact),
+		"response_body_truncated", resp.truncated,
+	)
+}
+
+// redactBody returns the captured body with all JSON string values passed through redact.
+func redactBody(c *capBuffer, redact func(field string, value string) string) string {
+	if redact == nil || c.buf.Len() == 0 {
+		return c.buf.String()
+	}
+
+	dec := json.NewDecoder(&c.buf)
+	dec.UseNumber()
+	var v any
+	if dec.Decode(&v) != nil {
+		return "[omitted: body is not redactable]"
+	}
+
+	b, err := json.Marshal(redactValue("", v, redact))
+	if err != nil {
+		return "[omitted: body is not redactable]"
+	}
+
+	return string(b)
+}
+
+// redactValue walks a decoded JSON value and redacts all strings and all other values with a field name, array
+// elements inherit the field name. Objects and arrays that redact leaves unchanged are walked.
+func redactValue(field string, v any, redact func(field string, value string) string) any {
+	if s, ok := v.(string); ok {
+		return redact(field, s)
+	}
+
+	if field != "" {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+
+		if r := redact(field, string(b)); r != string(b) {
+			return r
+		}
+	}
+
+	switch v := v.(type) {
+	case []any:
+		for i, e := range v {
+			v[i] = redactValue(field, e, redact)
+		}
+	case map[string]any:
+		for k, e := range v {
+			v[k] = redactValue(k, e, redact)
+		}
+	}
+
+	return v
+}
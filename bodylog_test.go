@@ -0,0 +1,74 @@
+package gwu_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func echoFields(_ context.Context, in map[string]any, _ gwu.HandleOpts) (map[string]any, int, error) {
+	return in, http.StatusOK, nil
+}
+
+// postBody serves a POST of the JSON body.
+func postBody(h http.Handler, body string) {
+	r := httptest.NewRequest(http.MethodPost, "/poems", strings.NewReader(body))
+	r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestLogBodiesRedact(t *testing.T) {
+	log := gwutest.Logger()
+	redact := gwu.RedactFields("password", "PIN", "card", "tokens", "secret")
+	h := gwu.Handle(gwu.JSON[map[string]any](), echoFields, gwu.Log(log), gwu.LogBodies(1024, redact))
+
+	postBody(h, `{"id": 1, "title": "Ode", "password": "hunter2", "pin": 1234, "card": {"number": "4111"},
+		"tokens": ["a", "b"], "lines": [{"text": "Thou", "secret": true}], "draft": null}`)
+
+	want := `{"card":"[REDACTED]","draft":null,"id":1,"lines":[{"secret":"[REDACTED]","text":"Thou"}],` +
+		`"password":"[REDACTED]","pin":"[REDACTED]","title":"Ode","tokens":"[REDACTED]"}`
+	log.AssertLogged(t, slog.LevelDebug, "request and response bodies", "request_body", want,
+		"request_body_truncated", false, "response_body", want, "response_body_truncated", false)
+}
+
+func TestLogBodiesCap(t *testing.T) {
+	body := `{"id": 1, "title": "Ode"}`
+
+	log := gwutest.Logger()
+	postBody(gwu.Handle(gwu.JSON[map[string]any](), echoFields, gwu.Log(log), gwu.LogBodies(8, nil)), body)
+	log.AssertLogged(t, slog.LevelDebug, "request and response bodies", "request_body", body[:8],
+		"request_body_truncated", true, "response_body", `{"id":1,`, "response_body_truncated", true)
+
+	// A body cut off at the cap is not valid JSON, and omitted if it would be redacted.
+	log = gwutest.Logger()
+	postBody(gwu.Handle(gwu.JSON[map[string]any](), echoFields, gwu.Log(log),
+		gwu.LogBodies(8, gwu.RedactFields("title"))), body)
+	log.AssertLogged(t, slog.LevelDebug, "request and response bodies",
+		"request_body", "[omitted: body is not redactable]", "request_body_truncated", true)
+}
+
+// TestLogBodiesLongLived does not log the bodies of streams, and rejects LogBodies with LongLived.
+func TestLogBodiesLongLived(t *testing.T) {
+	numbers := func(context.Context, map[string]any, gwu.HandleOpts) (gwu.Stream[int], int, error) {
+		return func(_ context.Context, send func(int) error) error {
+			return send(1)
+		}, http.StatusOK, nil
+	}
+
+	log := gwutest.Logger()
+	postBody(gwu.Handle(gwu.JSON[map[string]any](), numbers, gwu.Log(log), gwu.LogBodies(1024, nil)), `{"id": 1}`)
+	if log.Contains("request and response bodies") {
+		t.Errorf("entries %v, want the bodies of the stream not logged", log.Entries())
+	}
+
+	_, err := gwu.TryHandle(gwu.JSON[map[string]any](), echoFields, gwu.LogBodies(1024, nil), gwu.LongLived())
+	if err == nil || !strings.Contains(err.Error(), "LogBodies conflicts with LongLived") {
+		t.Errorf("error %v, want the conflict of LogBodies and LongLived", err)
+	}
+}
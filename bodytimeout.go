@@ -0,0 +1,112 @@
+package gwu
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBodyTimeout is the error of responses to requests whose body stalled longer than the BodyReadTimeout.
+// Is safe to display to the client.
+var ErrBodyTimeout = errors.New("request body read timed out")
+
+// BodyReadTimeout limits the time a read of the request body may wait for the client, so stalled uploads do not tie
+// up the handler. The timeout is reset by every read, a large but steady upload takes as long as it needs.
+// Handle responds to requests whose body stalled while the CnIn read it with ErrBodyTimeout,
+// http.StatusRequestTimeout, and `Connection: close`, the CnIn's error is discarded. Reads of a stalled body fail
+// with ErrBodyTimeout. The timeout elapses on the handler's Clock.
+//
+// Set it on a Router or Group for all of its routes, and override it per route. A timeout <= 0 removes it.
+//
+// Example usage:
+//
+//	api := rt.Group("/api", gwu.BodyReadTimeout(10*time.Second))
+func BodyReadTimeout(d time.Duration) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.bodyTimeout = d
+	}
+}
+
+// timedBody is a request body whose reads time out after the BodyReadTimeout. With the RealClock, it sets the read
+// deadline of the connection if the http.ResponseWriter supports it. Otherwise, it closes the body once the timeout
+// elapsed on the handler's Clock.
+type timedBody struct {
+	io.ReadCloser
+	timeout  time.Duration
+	clock    Clock
+	rc       *http.ResponseController
+	deadline bool
+	timer    timer
+	expired  atomic.Bool
+}
+
+// timeBody applies the handler's BodyReadTimeout to the request body, it returns nil if there is none.
+// Call stop on the returned body when the request is done.
+func (o HandleOpts) timeBody(w http.ResponseWriter, r *http.Request) *timedBody {
+	if o.bodyTimeout <= 0 || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	b := &timedBody{ReadCloser: r.Body, timeout: o.bodyTimeout, clock: o.Clock(), rc: http.NewResponseController(w)}
+	// The read deadline of the connection is in real time, other clocks time the reads themselves.
+	if _, ok := b.clock.(realClock); ok {
+		b.deadline = b.rc.SetReadDeadline(b.clock.Now().Add(b.timeout)) == nil
+	}
+
+	r.Body = b
+
+	return b
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	switch {
+	case b.expired.Load():
+		return 0, ErrBodyTimeout
+	case b.deadline:
+		_ = b.rc.SetReadDeadline(b.clock.Now().Add(b.timeout))
+	case b.timer == nil:
+		b.timer = afterFunc(b.clock, b.timeout, b.expire)
+	default:
+		b.timer.Reset(b.timeout)
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+
+	if err != nil && err != io.EOF && (b.expired.Load() || errors.Is(err, os.ErrDeadlineExceeded)) {
+		b.expired.Store(true)
+		return n, ErrBodyTimeout
+	}
+
+	return n, err
+}
+
+// expire closes the body of a stalled read, which makes the read fail.
+func (b *timedBody) expire() {
+	b.expired.Store(true)
+	_ = b.ReadCloser.Close()
+}
+
+// stop stops the timeout of the body.
+func (b *timedBody) stop() {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+
+	if b.deadline && !b.expired.Load() {
+		// The deadline outlives the request on a kept-alive connection, reset it for the next request. The connection
+		// of a stalled body is closed after the response, the expired deadline keeps net/http from waiting for the
+		// rest of the body.
+		_ = b.rc.SetReadDeadline(time.Time{})
+	}
+}
+
+// timedOut reports whether a read of the body timed out.
+func (b *timedBody) timedOut() bool {
+	return b != nil && b.expired.Load()
+}
@@ -0,0 +1,163 @@
+package gwu_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// bodyTimeout is the BodyReadTimeout of the tests, steady bodies send a chunk every tenth of it.
+const bodyTimeout = 100 * time.Millisecond
+
+// pipeRequest returns a POST whose body is written by write, the pipe is closed when write returns.
+func pipeRequest(write func(w io.Writer)) *http.Request {
+	pr, pw := io.Pipe()
+	go func() {
+		write(pw)
+		pw.Close()
+	}()
+
+	r := httptest.NewRequest(http.MethodPost, "/poems", pr)
+	r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+
+	return r
+}
+
+// TestBodyReadTimeoutClock times out a stalled body on the handler's Clock, time stands still for a steady body.
+func TestBodyReadTimeoutClock(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	h := gwu.Handle(gwu.JSON[benchPoem](), echoPoem, gwu.WithClock(clock), gwu.BodyReadTimeout(time.Second))
+
+	stalled := make(chan struct{})
+	t.Cleanup(func() { close(stalled) })
+
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(rec, pipeRequest(func(w io.Writer) {
+			_, _ = io.WriteString(w, `{"id":7,"title":`)
+			<-stalled
+		}))
+	}()
+
+	for wait := true; wait; {
+		select {
+		case <-done:
+			wait = false
+		case <-time.After(time.Millisecond):
+			clock.Advance(100 * time.Millisecond)
+		}
+	}
+
+	gwutest.AssertError(t, rec, http.StatusRequestTimeout, gwu.ErrBodyTimeout.Error())
+
+	// Without advancing the clock, a slow body does not time out.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, pipeRequest(func(w io.Writer) {
+		_, _ = io.WriteString(w, `{"id":7,`)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = io.WriteString(w, `"title":"Ode"}`)
+	}))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestBodyReadTimeoutPipe(t *testing.T) {
+	h := gwu.Handle(gwu.JSON[benchPoem](), echoPoem, gwu.BodyReadTimeout(bodyTimeout))
+	stalled := make(chan struct{})
+	t.Cleanup(func() { close(stalled) })
+
+	tests := []struct {
+		name  string
+		write func(w io.Writer)
+		want  int
+	}{
+		{"stalled", func(w io.Writer) {
+			_, _ = io.WriteString(w, `{"id":7,"title":`)
+			<-stalled
+		}, http.StatusRequestTimeout},
+		{"steady", func(w io.Writer) {
+			_, _ = io.WriteString(w, `{"id":7,"lines":[`)
+			for i := range 30 {
+				time.Sleep(bodyTimeout / 10)
+				_, _ = fmt.Fprintf(w, `"line %d",`, i)
+			}
+
+			_, _ = io.WriteString(w, `"end"]}`)
+		}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, pipeRequest(tt.write))
+
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+
+			if tt.want != http.StatusRequestTimeout {
+				return
+			}
+
+			if got := rec.Header().Get("Connection"); got != "close" {
+				t.Errorf("Connection %q, want close", got)
+			}
+
+			gwutest.AssertError(t, rec, http.StatusRequestTimeout, gwu.ErrBodyTimeout.Error())
+		})
+	}
+}
+
+// TestBodyReadTimeoutConn stalls a body on a real connection, where the read deadline of the connection times out.
+func TestBodyReadTimeoutConn(t *testing.T) {
+	srv := httptest.NewServer(gwu.Handle(gwu.JSON[benchPoem](), echoPoem, gwu.BodyReadTimeout(bodyTimeout)))
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	_, _ = io.WriteString(conn, "POST /poems HTTP/1.1\r\nHost: gwu\r\nContent-Type: application/json\r\n"+
+		"Content-Length: 100\r\n\r\n"+`{"id":7,"title":`)
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestTimeout {
+		t.Errorf("status %d, want 408", resp.StatusCode)
+	}
+
+	if !resp.Close {
+		t.Error("connection kept alive, want Connection: close")
+	}
+
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+
+	if _, err := br.ReadByte(); err == nil || strings.Contains(err.Error(), "timeout") {
+		t.Errorf("connection not closed after the response: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package gwu
+
+import (
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrClientCertRequired is the error of requests without a verified client certificate to a handler with
+	// RequireClientCert. Is safe to display to the client.
+	ErrClientCertRequired = errors.New("verified client certificate required")
+	// ErrIdentityNotAllowed is the error of the matchers of RequireClientCert for certificates without an allowed
+	// identity. Is safe to display to the client.
+	ErrIdentityNotAllowed = errors.New("client certificate identity not allowed")
+)
+
+// PeerInfo is the verified client certificate of a request, see PeerFrom.
+type PeerInfo struct {
+	// Certificate is the leaf certificate of the first verified chain.
+	Certificate *x509.Certificate
+	// URIs are the URI SANs of the certificate, like the SPIFFE ID "spiffe://example.org/ns/billing/sa/api".
+	URIs []string
+	// DNSNames are the DNS SANs of the certificate.
+	DNSNames []string
+	// CommonName is the common name of the certificate's subject.
+	CommonName string
+}
+
+// Identity returns the first URI SAN of the certificate, or its common name if it has none, e.g. to log it.
+func (p PeerInfo) Identity() string {
+	if len(p.URIs) > 0 {
+		return p.URIs[0]
+	}
+
+	return p.CommonName
+}
+
+// PeerFrom returns the verified client certificate of the request. It reports false if the request was not made over
+// TLS or the server did not verify a client certificate, configure the tls.Config of the server with ClientCAs and
+// tls.RequireAndVerifyClientCert or tls.VerifyClientCertIfGiven.
+func PeerFrom(r *http.Request) (PeerInfo, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return PeerInfo{}, false
+	}
+
+	return newPeerInfo(r.TLS.VerifiedChains[0][0]), true
+}
+
+// newPeerInfo returns the PeerInfo of the certificate.
+func newPeerInfo(cert *x509.Certificate) PeerInfo {
+	p := PeerInfo{Certificate: cert, DNSNames: cert.DNSNames, CommonName: cert.Subject.CommonName}
+	for _, u := range cert.URIs {
+		p.URIs = append(p.URIs, u.String())
+	}
+
+	return p
+}
+
+// RequireClientCert authorizes requests by their verified client certificate before the CnIn runs, see PeerFrom.
+// It rejects requests without one with ErrClientCertRequired and http.StatusUnauthorized, and requests whose
+// certificate the match function rejects with its error and http.StatusForbidden. Both are logged at warn level with
+// the presented identity. Like Before, RequireClientCert accumulates.
+//
+// The match function's error must be safe to display to the client, MatchURI, MatchURISuffix, and MatchURIRegexp
+// match the URI SANs of the certificate.
+//
+// Example usage:
+//
+//	billing := rt.Group("/billing", gwu.RequireClientCert(
+//		gwu.MatchURIRegexp(regexp.MustCompile(`^spiffe://example\.org/ns/billing/sa/[a-z-]+$`)),
+//	))
+func RequireClientCert(match func(PeerInfo) error) HandleOptsFunc {
+	return Before(func(r *http.Request, opts HandleOpts) error {
+		peer, ok := PeerFrom(r)
+		if !ok {
+			// An unverified certificate is only presented if the server does not verify them, log it nonetheless.
+			identity := ""
+			if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+				identity = newPeerInfo(r.TLS.PeerCertificates[0]).Identity()
+			}
+
+			logWarn(opts.Log, "client certificate required", "identity", identity, "method", r.Method,
+				"path", FullPath(r))
+			return WithStatus(http.StatusUnauthorized, ErrClientCertRequired)
+		}
+
+		if err := match(peer); err != nil {
+			logWarn(opts.Log, "client certificate not allowed", "identity", peer.Identity(), "method", r.Method,
+				"path", FullPath(r), "error", err)
+			return WithStatus(http.StatusForbidden, err)
+		}
+
+		return nil
+	})
+}
+
+// MatchURI returns a match function for RequireClientCert allowing certificates with one of the URI SANs.
+func MatchURI(uris ...string) func(PeerInfo) error {
+	return matchURIs(func(uri string) bool {
+		for _, u := range uris {
+			if uri == u {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// MatchURISuffix returns a match function for RequireClientCert allowing certificates with a URI SAN with the suffix,
+// like "/sa/billing-api" for the billing-api service account of any namespace. It matches the URIs of every trust
+// domain the server's ClientCAs verify, anchor the match with MatchURIRegexp otherwise.
+func MatchURISuffix(suffix string) func(PeerInfo) error {
+	return matchURIs(func(uri string) bool {
+		return strings.HasSuffix(uri, suffix)
+	})
+}
+
+// MatchURIRegexp returns a match function for RequireClientCert allowing certificates with a URI SAN matching the
+// regular expression. Anchor it with ^ and $ to match whole URIs.
+func MatchURIRegexp(re *regexp.Regexp) func(PeerInfo) error {
+	return matchURIs(re.MatchString)
+}
+
+// matchURIs returns a match function allowing certificates with a URI SAN that ok reports true for.
+func matchURIs(ok func(uri string) bool) func(PeerInfo) error {
+	return func(p PeerInfo) error {
+		for _, uri := range p.URIs {
+			if ok(uri) {
+				return nil
+			}
+		}
+
+		return ErrIdentityNotAllowed
+	}
+}
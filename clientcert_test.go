@@ -0,0 +1,216 @@
+package gwu_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// certAuthority issues client certificates.
+type certAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newCertAuthority(t *testing.T) *certAuthority {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gwu test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &certAuthority{cert: cert, key: key}
+}
+
+// issue returns a client certificate with the common name and URI SAN.
+func (ca *certAuthority) issue(t *testing.T, cn, uri string) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: cn},
+		URIs:         []*url.URL{u},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// tlsServer serves h over TLS with the client authentication, and returns a client presenting the certificates.
+func tlsServer(t *testing.T, h http.Handler, auth tls.ClientAuthType, ca *certAuthority) (*httptest.Server,
+	func(certs ...tls.Certificate) *http.Client) {
+	t.Helper()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	srv := httptest.NewUnstartedServer(h)
+	srv.TLS = &tls.Config{ClientCAs: pool, ClientAuth: auth}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return srv, func(certs ...tls.Certificate) *http.Client {
+		tr := srv.Client().Transport.(*http.Transport).Clone()
+		tr.TLSClientConfig.Certificates = certs
+		t.Cleanup(tr.CloseIdleConnections)
+
+		return &http.Client{Transport: tr}
+	}
+}
+
+func TestRequireClientCert(t *testing.T) {
+	ca := newCertAuthority(t)
+	log := gwutest.Logger()
+
+	var peer gwu.PeerInfo
+	in := func(r *http.Request, _ gwu.HandleOpts) (any, error) {
+		peer, _ = gwu.PeerFrom(r)
+		return nil, nil
+	}
+
+	h := gwu.Handle(in, noContent, gwu.Log(log),
+		gwu.RequireClientCert(gwu.MatchURI("spiffe://example.org/ns/billing/sa/api")))
+	srv, client := tlsServer(t, h, tls.VerifyClientCertIfGiven, ca)
+
+	tests := []struct {
+		name   string
+		certs  []tls.Certificate
+		status int
+		log    string
+	}{
+		{"allowed", []tls.Certificate{ca.issue(t, "api", "spiffe://example.org/ns/billing/sa/api")},
+			http.StatusNoContent, ""},
+		{"without cert", nil, http.StatusUnauthorized, "client certificate required"},
+		{"not allowed", []tls.Certificate{ca.issue(t, "web", "spiffe://example.org/ns/shop/sa/web")},
+			http.StatusForbidden, "client certificate not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log.Reset()
+			peer = gwu.PeerInfo{}
+
+			resp, err := client(tt.certs...).Get(srv.URL + "/billing")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.status)
+			}
+
+			if tt.log != "" {
+				log.AssertLogged(t, slog.LevelWarn, tt.log, "path", "/billing")
+				return
+			}
+
+			if got := peer.Identity(); got != "spiffe://example.org/ns/billing/sa/api" || peer.CommonName != "api" {
+				t.Errorf("peer %q %q, want the identity of the certificate", got, peer.CommonName)
+			}
+		})
+	}
+}
+
+// TestRequireClientCertUnverified rejects certificates the server did not verify, and logs their identity.
+func TestRequireClientCertUnverified(t *testing.T) {
+	ca := newCertAuthority(t)
+	log := gwutest.Logger()
+	h := gwu.Handle(gwu.Empty(), noContent, gwu.Log(log), gwu.RequireClientCert(gwu.MatchURISuffix("/sa/api")))
+	srv, client := tlsServer(t, h, tls.RequestClientCert, ca)
+
+	resp, err := client(newCertAuthority(t).issue(t, "api", "spiffe://evil.example/sa/api")).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status %d, want 401", resp.StatusCode)
+	}
+
+	log.AssertLogged(t, slog.LevelWarn, "client certificate required", "identity", "spiffe://evil.example/sa/api")
+}
+
+// TestPeerFromPlainHTTP reports no peer for requests without TLS.
+func TestPeerFromPlainHTTP(t *testing.T) {
+	if _, ok := gwu.PeerFrom(httptest.NewRequest(http.MethodGet, "/", nil)); ok {
+		t.Error("PeerFrom reported a peer without TLS")
+	}
+}
+
+func TestMatchURI(t *testing.T) {
+	peer := gwu.PeerInfo{URIs: []string{"https://example.org/web", "spiffe://example.org/ns/billing/sa/api"}}
+	tests := []struct {
+		name  string
+		match func(gwu.PeerInfo) error
+		ok    bool
+	}{
+		{"uri", gwu.MatchURI("spiffe://example.org/ns/billing/sa/api"), true},
+		{"other uri", gwu.MatchURI("spiffe://example.org/ns/billing/sa/web"), false},
+		{"suffix", gwu.MatchURISuffix("/sa/api"), true},
+		{"other suffix", gwu.MatchURISuffix("/sa/admin"), false},
+		{"regexp", gwu.MatchURIRegexp(regexp.MustCompile(`^spiffe://example\.org/ns/billing/sa/[a-z-]+$`)), true},
+		{"unanchored regexp", gwu.MatchURIRegexp(regexp.MustCompile(`evil\.example`)), false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.match(peer); (err == nil) != tt.ok || err != nil && !errors.Is(err, gwu.ErrIdentityNotAllowed) {
+			t.Errorf("%s: error %v, want allowed %v", tt.name, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package gwu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrClientTimeout is the error of responses to requests that exceeded the time budget of the caller, see
+// HonorClientTimeout. Handle wraps it with the budget.
+// Is safe to display to the client.
+var ErrClientTimeout = errors.New("time budget of the caller exceeded")
+
+// clientTimeout is the configuration of HonorClientTimeout.
+type clientTimeout struct {
+	header string
+	max    time.Duration
+}
+
+// HonorClientTimeout honors the time budget callers send in the header, in milliseconds, like
+// "X-Request-Timeout: 250", so the handler does not keep working after the caller gave up. The budget starts when
+// the handler receives the request, is capped at max, a max <= 0 does not cap it, and elapses on the handler's Clock.
+// It covers the Before hooks, the CnIn, and the Exec: the request's context is canceled when the budget elapsed, with
+// the RealClock it has the deadline of the budget.
+//
+// If the budget is exceeded after the Before hooks, the CnIn, or the Exec, Handle responds with ErrClientTimeout,
+// wrapped with the budget, and http.StatusGatewayTimeout, whatever they returned. The failure is the caller's, so
+// Handle logs it at debug level, not as failure. Requests without the header, or with a value that is no positive
+// number of milliseconds, have no budget.
+//
+// Example usage:
+//
+//	api := rt.Group("/api", gwu.HonorClientTimeout("X-Request-Timeout", 30*time.Second))
+func HonorClientTimeout(header string, max time.Duration) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.clientTimeout = &clientTimeout{header: http.CanonicalHeaderKey(header), max: max}
+	}
+}
+
+// clientBudget is the time budget of a request, see HonorClientTimeout. The zero clientBudget is no budget.
+type clientBudget struct {
+	d     time.Duration
+	clock Clock
+	err   error
+}
+
+// clientBudget returns the time budget of the request, starting now.
+func (o HandleOpts) clientBudget(r *http.Request) clientBudget {
+	if o.clientTimeout == nil {
+		return clientBudget{}
+	}
+
+	v, err := strconv.ParseInt(strings.TrimSpace(r.Header.Get(o.clientTimeout.header)), 10, 64)
+	if err != nil || v <= 0 || v > int64(time.Duration(1<<63-1)/time.Millisecond) {
+		return clientBudget{}
+	}
+
+	d := time.Duration(v) * time.Millisecond
+	if o.clientTimeout.max > 0 {
+		d = min(d, o.clientTimeout.max)
+	}
+
+	return clientBudget{d: d, clock: o.Clock(), err: fmt.Errorf("%w: %s", ErrClientTimeout, d)}
+}
+
+// context returns ctx canceled with the budget's error once the budget elapsed on the Clock, call cancel if it is
+// not nil. It returns ctx if there is no budget.
+func (b clientBudget) context(ctx context.Context) (_ context.Context, cancel context.CancelFunc) {
+	if b.err == nil {
+		return ctx, nil
+	}
+
+	if _, ok := b.clock.(realClock); ok {
+		return context.WithDeadlineCause(ctx, b.clock.Now().Add(b.d), b.err)
+	}
+
+	// Other clocks cannot set a deadline, cancel the context when the Clock says so.
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	expired := b.clock.After(b.d)
+	go func() {
+		select {
+		case <-expired:
+			cancelCause(b.err)
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() { cancelCause(context.Canceled) }
+}
+
+// exceeded reports whether the budget of the request elapsed.
+func (b clientBudget) exceeded(r *http.Request) bool {
+	return b.err != nil && context.Cause(r.Context()) == b.err
+}
+
+// rejectOverBudget responds to a request whose budget elapsed before the Exec ran.
+func (o HandleOpts) rejectOverBudget(w http.ResponseWriter, r *http.Request, b clientBudget) {
+	o.logClientError(r, http.StatusGatewayTimeout, "request failed", "method", r.Method, "path", FullPath(r),
+		"status", http.StatusGatewayTimeout, "error", b.err)
+	o.writeError(w, r, b.err, http.StatusGatewayTimeout)
+}
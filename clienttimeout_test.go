@@ -0,0 +1,102 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// budgetRequest returns a request with the time budget header.
+func budgetRequest(budget string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Request-Timeout", budget)
+	return r
+}
+
+// serveAdvancing serves the request, advancing the clock until the handler returned.
+func serveAdvancing(h http.Handler, r *http.Request, clock *gwu.ManualClock) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(rec, r)
+	}()
+
+	for {
+		select {
+		case <-done:
+			return rec
+		case <-time.After(time.Millisecond):
+			clock.Advance(50 * time.Millisecond)
+		}
+	}
+}
+
+func TestHonorClientTimeout(t *testing.T) {
+	slow := func(ctx context.Context, _ any, _ gwu.HandleOpts) (any, int, error) {
+		<-ctx.Done()
+		return nil, http.StatusInternalServerError, ctx.Err()
+	}
+
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	h := gwu.Handle(gwu.Empty(), slow, gwu.WithClock(clock), gwu.HonorClientTimeout("X-Request-Timeout", time.Second))
+
+	rec := serveAdvancing(h, budgetRequest("250"), clock)
+	gwutest.AssertError(t, rec, http.StatusGatewayTimeout, "time budget of the caller exceeded: 250ms")
+
+	// The budget is capped at max.
+	rec = serveAdvancing(h, budgetRequest("60000"), clock)
+	gwutest.AssertError(t, rec, http.StatusGatewayTimeout, "time budget of the caller exceeded: 1s")
+}
+
+// TestHonorClientTimeoutBefore spends the budget in a Before hook, neither the CnIn nor the Exec run.
+func TestHonorClientTimeoutBefore(t *testing.T) {
+	hook := func(r *http.Request, _ gwu.HandleOpts) error {
+		<-r.Context().Done()
+		return nil
+	}
+
+	ran := false
+	in := func(*http.Request, gwu.HandleOpts) (any, error) {
+		ran = true
+		return nil, nil
+	}
+
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	h := gwu.Handle(in, noContent, gwu.WithClock(clock), gwu.Before(hook),
+		gwu.HonorClientTimeout("X-Request-Timeout", 0))
+
+	rec := serveAdvancing(h, budgetRequest("100"), clock)
+	gwutest.AssertError(t, rec, http.StatusGatewayTimeout, "time budget of the caller exceeded: 100ms")
+	if ran {
+		t.Error("the CnIn ran after the budget elapsed")
+	}
+}
+
+func TestHonorClientTimeoutIgnored(t *testing.T) {
+	var deadline bool
+	exec := func(ctx context.Context, _ any, _ gwu.HandleOpts) (any, int, error) {
+		_, deadline = ctx.Deadline()
+		return nil, http.StatusNoContent, nil
+	}
+
+	h := gwu.Handle(gwu.Empty(), exec, gwu.HonorClientTimeout("X-Request-Timeout", time.Second))
+	for _, budget := range []string{"", "soon", "-5", "0", "1.5"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, budgetRequest(budget))
+		if rec.Code != http.StatusNoContent || deadline {
+			t.Errorf("%q: status %d, deadline %v, want 204 without deadline", budget, rec.Code, deadline)
+		}
+	}
+
+	// With the RealClock, the context has the deadline of the budget.
+	h.ServeHTTP(httptest.NewRecorder(), budgetRequest("5000"))
+	if !deadline {
+		t.Error("context without the deadline of the budget")
+	}
+}
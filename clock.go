@@ -0,0 +1,171 @@
+package gwu
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time, gwu uses it for all time-dependent features. Set it with WithClock.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// RealClock returns the Clock based on the time package, it is the default.
+func RealClock() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithClock sets the Clock for the handler, use a ManualClock in tests.
+func WithClock(c Clock) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.clock = c
+	}
+}
+
+// Clock returns the handler's Clock, it returns RealClock if none is set.
+func (o HandleOpts) Clock() Clock {
+	if o.clock == nil {
+		return realClock{}
+	}
+
+	return o.clock
+}
+
+// ManualClock is a Clock for tests, its time only moves when calling Advance or Set.
+// ManualClock is safe for concurrent use.
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []manualWaiter
+}
+
+type manualWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewManualClock returns a ManualClock set to the given time.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Since returns the time elapsed since t according to the clock.
+func (c *ManualClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// After returns a channel that receives the clock's time once the clock advanced by d.
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, manualWaiter{at: c.now.Add(d), ch: ch})
+
+	return ch
+}
+
+// Advance moves the clock forward by d and fires all After channels that are due.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(c.now.Add(d))
+}
+
+// Set sets the clock to t and fires all After channels that are due.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(t)
+}
+
+func (c *ManualClock) set(t time.Time) {
+	c.now = t
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(t) {
+			waiters = append(waiters, w)
+			continue
+		}
+
+		w.ch <- t
+	}
+
+	c.waiters = waiters
+}
+
+// timer calls a function once its duration elapsed, see afterFunc. Unlike a time.Timer, it is not safe for
+// concurrent use.
+type timer interface {
+	Reset(d time.Duration) bool
+	Stop() bool
+}
+
+// afterFunc calls f in its own goroutine once d elapsed on the Clock, like time.AfterFunc.
+func afterFunc(c Clock, d time.Duration, f func()) timer {
+	if _, ok := c.(realClock); ok {
+		return time.AfterFunc(d, f)
+	}
+
+	t := &clockTimer{clock: c, f: f}
+	t.Reset(d)
+
+	return t
+}
+
+// clockTimer is the timer of a Clock other than the RealClock, it waits for the Clock's After channel.
+type clockTimer struct {
+	clock Clock
+	f     func()
+	stop  chan struct{}
+}
+
+func (t *clockTimer) Reset(d time.Duration) bool {
+	active := t.Stop()
+	fire, stop := t.clock.After(d), make(chan struct{})
+	t.stop = stop
+	go func() {
+		select {
+		case <-fire:
+			t.f()
+		case <-stop:
+		}
+	}()
+
+	return active
+}
+
+func (t *clockTimer) Stop() bool {
+	if t.stop == nil {
+		return false
+	}
+
+	close(t.stop)
+	t.stop = nil
+
+	return true
+}
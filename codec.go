@@ -0,0 +1,91 @@
+package gwu
+
+import (
+	"encoding/json"
+	"io"
+	"sync/atomic"
+)
+
+// JSONCodec is the JSON implementation gwu encodes and decodes request and response bodies with, it defaults to
+// encoding/json. Set it for all handlers with SetJSONCodec, or per handler with WithJSONCodec.
+//
+// The JSON CnIn, IntoJSON, and the encoding of responses, RPC params and results, and WebSocket messages use the
+// JSONCodec. Error bodies, the item envelopes of a MultiStatus, the Spec, and logged bodies always use encoding/json.
+//
+// Third-party implementations can differ from encoding/json: in the order of encoded map keys, the escaping of
+// HTML characters and invalid UTF-8, the handling of duplicate keys and case-insensitive field matching, and
+// whether they call MarshalJSON and UnmarshalJSON methods. Compare the output of your endpoints before switching.
+type JSONCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	NewEncoder(w io.Writer) JSONEncoder
+	NewDecoder(r io.Reader) JSONDecoder
+}
+
+// JSONEncoder writes JSON values to a stream, like json.Encoder. Encode terminates every value with a newline.
+type JSONEncoder interface {
+	Encode(v any) error
+}
+
+// JSONDecoder reads JSON values from a stream, like json.Decoder.
+type JSONDecoder interface {
+	Decode(v any) error
+}
+
+// StdJSON returns the JSONCodec based on encoding/json, it is the default.
+func StdJSON() JSONCodec {
+	return stdJSON{}
+}
+
+type stdJSON struct{}
+
+func (stdJSON) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (stdJSON) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (stdJSON) NewEncoder(w io.Writer) JSONEncoder { return json.NewEncoder(w) }
+func (stdJSON) NewDecoder(r io.Reader) JSONDecoder { return json.NewDecoder(r) }
+
+// defaultCodec holds the codec set by SetJSONCodec.
+var defaultCodec atomic.Pointer[JSONCodec]
+
+// SetJSONCodec sets the JSONCodec of all handlers without a WithJSONCodec option, nil restores encoding/json.
+// Like Defaults, Handle reads it when it is called, handlers created before a call keep their codec.
+// SetJSONCodec is safe for concurrent use, but is meant to be called once at startup before registering handlers.
+func SetJSONCodec(c JSONCodec) {
+	if c == nil {
+		defaultCodec.Store(nil)
+		return
+	}
+
+	defaultCodec.Store(&c)
+}
+
+// WithJSONCodec sets the JSONCodec of the handler, overriding SetJSONCodec.
+func WithJSONCodec(c JSONCodec) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.jsonCodec = c
+	}
+}
+
+// JSONCodec returns the handler's JSONCodec, see WithJSONCodec and SetJSONCodec. With FieldNaming, the codec
+// renames the fields.
+func (o HandleOpts) JSONCodec() JSONCodec {
+	c := o.jsonCodec
+	if c == nil {
+		c = packageCodec()
+	}
+
+	if _, ok := c.(namingCodec); !ok && o.fieldNaming != nil {
+		return namingCodec{codec: c, naming: o.fieldNaming}
+	}
+
+	return c
+}
+
+// packageCodec returns the codec set by SetJSONCodec, or encoding/json.
+func packageCodec() JSONCodec {
+	if c := defaultCodec.Load(); c != nil {
+		return *c
+	}
+
+	return stdJSON{}
+}
@@ -0,0 +1,194 @@
+package gwu_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// recordingCodec is a JSONCodec recording its calls, it encodes and decodes with encoding/json.
+type recordingCodec struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (c *recordingCodec) record(call string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, call)
+}
+
+// take returns the recorded calls and resets them.
+func (c *recordingCodec) take() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	calls := c.calls
+	c.calls = nil
+
+	return calls
+}
+
+func (c *recordingCodec) Marshal(v any) ([]byte, error) {
+	c.record("Marshal")
+	return gwu.StdJSON().Marshal(v)
+}
+
+func (c *recordingCodec) Unmarshal(data []byte, v any) error {
+	c.record("Unmarshal")
+	return gwu.StdJSON().Unmarshal(data, v)
+}
+
+func (c *recordingCodec) NewEncoder(w io.Writer) gwu.JSONEncoder {
+	c.record("NewEncoder")
+	return gwu.StdJSON().NewEncoder(w)
+}
+
+func (c *recordingCodec) NewDecoder(r io.Reader) gwu.JSONDecoder {
+	c.record("NewDecoder")
+	return gwu.StdJSON().NewDecoder(r)
+}
+
+// postSmallPoem serves a POST of the poem as JSON with h.
+func postSmallPoem(h http.Handler) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/poems", strings.NewReader(`{"id": 7, "title": "Ode"}`))
+	r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+
+	return rec
+}
+
+// TestJSONCodec decodes the request and encodes the response with the JSONCodec of the handler.
+func TestJSONCodec(t *testing.T) {
+	codec := &recordingCodec{}
+	rec := postSmallPoem(gwu.Handle(gwu.JSON[smallPoem](), createSmallPoem, gwu.WithJSONCodec(codec)))
+
+	if rec.Code != http.StatusCreated || rec.Body.String() != `{"id":7,"title":"Ode"}`+"\n" {
+		t.Errorf("%d %q, want 201 with the poem", rec.Code, rec.Body)
+	}
+
+	if got, want := codec.take(), []string{"NewDecoder", "NewEncoder"}; !slices.Equal(got, want) {
+		t.Errorf("calls %q, want %q", got, want)
+	}
+}
+
+// TestSetJSONCodec applies the package codec to the handlers created afterward, unless they set their own.
+func TestSetJSONCodec(t *testing.T) {
+	t.Cleanup(func() { gwu.SetJSONCodec(nil) })
+
+	before := gwu.Handle(gwu.JSON[smallPoem](), createSmallPoem)
+	pkg, own := &recordingCodec{}, &recordingCodec{}
+	gwu.SetJSONCodec(pkg)
+	after := gwu.Handle(gwu.JSON[smallPoem](), createSmallPoem)
+	overridden := gwu.Handle(gwu.JSON[smallPoem](), createSmallPoem, gwu.WithJSONCodec(own))
+	gwu.SetJSONCodec(nil)
+	restored := gwu.Handle(gwu.JSON[smallPoem](), createSmallPoem)
+
+	for _, tt := range []struct {
+		name     string
+		h        http.Handler
+		pkg, own int
+	}{
+		{"created before", before, 0, 0},
+		{"created after", after, 2, 0},
+		{"WithJSONCodec", overridden, 0, 2},
+		{"restored", restored, 0, 0},
+	} {
+		if rec := postSmallPoem(tt.h); rec.Code != http.StatusCreated {
+			t.Errorf("%s: status %d, want 201", tt.name, rec.Code)
+		}
+
+		if p, o := len(pkg.take()), len(own.take()); p != tt.pkg || o != tt.own {
+			t.Errorf("%s: %d calls of the package codec and %d of its own, want %d and %d", tt.name, p, o, tt.pkg,
+				tt.own)
+		}
+	}
+}
+
+// TestStdJSON encodes the responses exactly like encoding/json without a codec.
+func TestStdJSON(t *testing.T) {
+	out := map[string]any{"title": "<b>Ode</b> & Elegy", "lines": []string{"Season of mists"}, "id": 7, "z": nil,
+		"a": " "}
+	exec := func(context.Context, any, gwu.HandleOpts) (map[string]any, int, error) {
+		return out, http.StatusOK, nil
+	}
+
+	var want bytes.Buffer
+	if err := json.NewEncoder(&want).Encode(out); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, h := range map[string]http.Handler{
+		"default": gwu.Handle(gwu.Empty(), exec),
+		"StdJSON": gwu.Handle(gwu.Empty(), exec, gwu.WithJSONCodec(gwu.StdJSON())),
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Body.String() != want.String() {
+			t.Errorf("%s: %q, want the encoding/json output %q", name, rec.Body, want.String())
+		}
+	}
+}
+
+// marshalCodec is a JSONCodec encoding every value with a single Marshal into a buffer, the way codecs of
+// third-party libraries without a streaming encoder are wired. It stands in for them in BenchmarkJSONCodec, gwu does
+// not depend on one.
+type marshalCodec struct{}
+
+func (marshalCodec) Marshal(v any) ([]byte, error)          { return json.Marshal(v) }
+func (marshalCodec) Unmarshal(data []byte, v any) error     { return json.Unmarshal(data, v) }
+func (marshalCodec) NewEncoder(w io.Writer) gwu.JSONEncoder { return marshalEncoder{w} }
+func (marshalCodec) NewDecoder(r io.Reader) gwu.JSONDecoder { return marshalDecoder{r} }
+
+type marshalEncoder struct{ w io.Writer }
+
+func (e marshalEncoder) Encode(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(append(b, '\n'))
+	return err
+}
+
+type marshalDecoder struct{ r io.Reader }
+
+func (d marshalDecoder) Decode(v any) error {
+	b, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func BenchmarkJSONCodec(b *testing.B) {
+	for name, codec := range map[string]gwu.JSONCodec{"std": gwu.StdJSON(), "marshal": marshalCodec{}} {
+		h := gwu.Handle(gwu.JSON[smallPoem](), createSmallPoem, gwu.WithJSONCodec(codec))
+		w := &discardWriter{header: make(http.Header)}
+		body := []byte(`{"id": 7, "title": "Ode"}`)
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r := httptest.NewRequest(http.MethodPost, "/poems", bytes.NewReader(body))
+				r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+				clear(w.header)
+				h.ServeHTTP(w, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package gwu
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// SecureCompare reports whether a and b are equal in a time independent of their contents, compare secrets like API
+// keys, signatures, and CSRF tokens with it, never with ==. It compares the SHA-256 digests of a and b, so the time
+// does not reveal the length of the secret either, unlike with subtle.ConstantTimeCompare.
+//
+// Example usage:
+//
+//	func APIKey(key []byte) gwu.CnIn[any] {
+//		return func(r *http.Request, _ gwu.HandleOpts) (any, error) {
+//			if !gwu.SecureCompare([]byte(r.Header.Get("X-API-Key")), key) {
+//				return nil, gwu.WithStatus(http.StatusUnauthorized, ErrInvalidKey)
+//			}
+//
+//			return nil, nil
+//		}
+//	}
+func SecureCompare(a, b []byte) bool {
+	da, db := sha256.Sum256(a), sha256.Sum256(b)
+	return subtle.ConstantTimeCompare(da[:], db[:]) == 1
+}
@@ -0,0 +1,159 @@
+package gwu_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// secretName matches the names of identifiers, fields, and functions that hold or compute secrets.
+var secretName = regexp.MustCompile(
+	`(?i)secret|signature|\bsig|password|passwd|api_?key|token|csrf|digest|hmac|mac\b|credential`)
+
+// naiveCompares returns the positions of == and != comparisons, and bytes.Equal calls, on secrets in the file.
+// Comparisons with a constant, like token == "-" or digest != "", reveal nothing and are allowed.
+func naiveCompares(fset *token.FileSet, f *ast.File) []string {
+	var found []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		var operands []ast.Expr
+		switch n := n.(type) {
+		case *ast.BinaryExpr:
+			if n.Op == token.EQL || n.Op == token.NEQ {
+				operands = []ast.Expr{n.X, n.Y}
+			}
+		case *ast.CallExpr:
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Equal" {
+				if pkg, ok := sel.X.(*ast.Ident); ok && (pkg.Name == "bytes" || pkg.Name == "hmac") {
+					operands = n.Args
+				}
+			}
+		}
+
+		if len(operands) != 2 || isConstant(operands[0]) || isConstant(operands[1]) {
+			return true
+		}
+
+		if mentionsSecret(operands[0]) || mentionsSecret(operands[1]) {
+			found = append(found, fset.Position(n.Pos()).String())
+		}
+
+		return true
+	})
+
+	return found
+}
+
+// isConstant reports whether the expression is a literal, nil, or a bool.
+func isConstant(e ast.Expr) bool {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.Ident:
+		return e.Name == "nil" || e.Name == "true" || e.Name == "false"
+	}
+
+	return false
+}
+
+// mentionsSecret reports whether a name in the expression matches secretName.
+func mentionsSecret(e ast.Expr) bool {
+	found := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && secretName.MatchString(id.Name) {
+			found = true
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+// TestNoNaiveSecretCompare greps the package for secrets compared with == or bytes.Equal, compare them with
+// SecureCompare.
+func TestNoNaiveSecretCompare(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, pos := range naiveCompares(fset, f) {
+			t.Errorf("%s: secret compared with == or bytes.Equal, use SecureCompare", pos)
+		}
+	}
+}
+
+// TestNaiveComparesDetects checks that the lint of TestNoNaiveSecretCompare finds naive comparisons.
+func TestNaiveComparesDetects(t *testing.T) {
+	src := `package p
+
+func check(r *http.Request, apiKey []byte, b []byte, want string) bool {
+	if r.Header.Get("X-API-Key") != string(apiKey) {
+		return false
+	}
+
+	if digestOf(sha256.New, b) != want {
+		return false
+	}
+
+	if token == "-" || signature != "" {
+		return true
+	}
+
+	return bytes.Equal(hmacOf(b), []byte(want))
+}
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := naiveCompares(fset, f); len(got) != 3 {
+		t.Errorf("found %v, want the comparisons of lines 4, 8, and 16", got)
+	}
+}
+
+func TestSecureCompare(t *testing.T) {
+	key := []byte("s3cr3t-api-key")
+	tests := []struct {
+		name string
+		b    []byte
+		want bool
+	}{
+		{"equal", []byte("s3cr3t-api-key"), true},
+		{"last byte", []byte("s3cr3t-api-kez"), false},
+		{"first byte", []byte("t3cr3t-api-key"), false},
+		{"prefix", []byte("s3cr3t-api-ke"), false},
+		{"longer", []byte("s3cr3t-api-keys"), false},
+		{"empty", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := gwu.SecureCompare(key, tt.b); got != tt.want {
+			t.Errorf("%s: SecureCompare %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if !gwu.SecureCompare(nil, []byte{}) {
+		t.Error("SecureCompare(nil, empty) false, want true")
+	}
+}
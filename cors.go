@@ -0,0 +1,178 @@
+package gwu
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy configures Cross-Origin Resource Sharing for a handler, see CORS.
+type CORSPolicy struct {
+	// AllowedOrigins lists the allowed origins, like "https://example.com". An origin "*" allows all origins, and
+	// a wildcard subdomain like "https://*.example.com" allows all subdomains of example.com.
+	AllowedOrigins []string
+	// AllowedMethods lists the methods allowed in preflight requests, defaults to GET, HEAD, and POST.
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers allowed in preflight requests, "*" allows all headers.
+	AllowedHeaders []string
+	// ExposeHeaders lists the response headers the browser exposes to the client.
+	ExposeHeaders []string
+	// MaxAge is how long the browser may cache the result of a preflight request, zero omits the header.
+	MaxAge time.Duration
+	// AllowCredentials allows requests with credentials, like cookies. It cannot be combined with the origin "*".
+	AllowCredentials bool
+}
+
+// CORS applies the CORS policy to the handler. The handler answers preflight requests directly, without running
+// the CnIn and Exec, and adds the CORS headers to all other responses, including error responses.
+//
+// Preflight requests use the OPTIONS method, so a handler registered for a method only does not receive them.
+// Register the handler for OPTIONS too, or use HandleRoute with a Router, which registers OPTIONS for the path
+// automatically.
+//
+// CORS rejects a policy allowing credentials for the origin "*", browsers refuse this combination.
+func CORS(policy CORSPolicy) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		if policy.AllowCredentials && slices.Contains(policy.AllowedOrigins, "*") {
+			opt.invalid("CORS: AllowCredentials cannot be combined with the origin \"*\"")
+		}
+
+		opt.cors = &policy
+	}
+}
+
+// apply adds the CORS headers to the response. If r is a preflight request, apply answers it and reports true.
+func (p *CORSPolicy) apply(w http.ResponseWriter, r *http.Request) bool {
+	h := w.Header()
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+	h.Add("Vary", "Origin")
+	if preflight {
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if origin == "" {
+		return false
+	}
+
+	if !preflight {
+		if p.allowsOrigin(origin) {
+			p.setOrigin(h, origin)
+			if len(p.ExposeHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(p.ExposeHeaders, ", "))
+			}
+		}
+
+		return false
+	}
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	headers := requestedHeaders(r)
+	if p.allowsOrigin(origin) && p.allowsMethod(method) && p.allowsHeaders(headers) {
+		p.setOrigin(h, origin)
+		h.Set("Access-Control-Allow-Methods", strings.Join(p.methods(), ", "))
+		if len(headers) > 0 {
+			h.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		}
+
+		if p.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return true
+}
+
+// setOrigin sets the Access-Control-Allow-Origin and Access-Control-Allow-Credentials headers.
+func (p *CORSPolicy) setOrigin(h http.Header, origin string) {
+	if slices.Contains(p.AllowedOrigins, "*") {
+		h.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	h.Set("Access-Control-Allow-Origin", origin)
+	if p.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (p *CORSPolicy) allowsOrigin(origin string) bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+
+		scheme, domain, ok := strings.Cut(allowed, "*.")
+		if ok && len(origin) > len(scheme)+len(domain)+1 && strings.HasPrefix(origin, scheme) &&
+			strings.HasSuffix(strings.ToLower(origin), "."+strings.ToLower(domain)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *CORSPolicy) methods() []string {
+	if len(p.AllowedMethods) == 0 {
+		return []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+
+	return p.AllowedMethods
+}
+
+func (p *CORSPolicy) allowsMethod(method string) bool {
+	return slices.Contains(p.methods(), method)
+}
+
+func (p *CORSPolicy) allowsHeaders(headers []string) bool {
+	if slices.Contains(p.AllowedHeaders, "*") {
+		return true
+	}
+
+	for _, header := range headers {
+		if !slices.ContainsFunc(p.AllowedHeaders, func(allowed string) bool {
+			return strings.EqualFold(allowed, header)
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// requestedHeaders returns the headers of the Access-Control-Request-Headers header.
+func requestedHeaders(r *http.Request) []string {
+	var headers []string
+	for _, v := range r.Header.Values("Access-Control-Request-Headers") {
+		for _, header := range strings.Split(v, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				headers = append(headers, header)
+			}
+		}
+	}
+
+	return headers
+}
+
+// preflight is the handler a Router registers for OPTIONS requests to a path whose routes use CORS.
+// It answers preflight requests with the policy of the route for the requested method.
+type preflight struct {
+	policies map[string]*CORSPolicy
+}
+
+func (p *preflight) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	policy, ok := p.policies[r.Header.Get("Access-Control-Request-Method")]
+	if !ok {
+		w.Header().Add("Vary", "Origin")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	policy.apply(w, r)
+}
@@ -0,0 +1,165 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+)
+
+var poemsPolicy = gwu.CORSPolicy{
+	AllowedOrigins:   []string{"https://poems.example", "https://*.poems.example"},
+	AllowedMethods:   []string{http.MethodGet, http.MethodPut},
+	AllowedHeaders:   []string{"Content-Type", "X-Request-ID"},
+	ExposeHeaders:    []string{"ETag"},
+	MaxAge:           10 * time.Minute,
+	AllowCredentials: true,
+}
+
+// corsRequest returns a request from the origin, a preflight request if method is set.
+func corsRequest(origin, method, headers string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/poems", nil)
+	if method != "" {
+		r.Method = http.MethodOptions
+		r.Header.Set("Access-Control-Request-Method", method)
+	}
+
+	if headers != "" {
+		r.Header.Set("Access-Control-Request-Headers", headers)
+	}
+
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+
+	return r
+}
+
+func TestCORS(t *testing.T) {
+	ran := 0
+	exec := func(context.Context, any, gwu.HandleOpts) (string, int, error) {
+		ran++
+		return "Ode", http.StatusOK, nil
+	}
+
+	h := gwu.Handle(gwu.Empty(), exec, gwu.CORS(poemsPolicy))
+
+	tests := []struct {
+		name    string
+		r       *http.Request
+		status  int
+		headers map[string]string
+		ran     bool
+	}{
+		{"simple", corsRequest("https://poems.example", "", ""), http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin":      "https://poems.example",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Expose-Headers":    "ETag",
+			"Access-Control-Allow-Methods":     "",
+		}, true},
+		{"simple from a subdomain", corsRequest("https://odes.poems.example", "", ""), http.StatusOK,
+			map[string]string{"Access-Control-Allow-Origin": "https://odes.poems.example"}, true},
+		{"simple without origin", corsRequest("", "", ""), http.StatusOK,
+			map[string]string{"Access-Control-Allow-Origin": ""}, true},
+		// The response is served, the browser withholds it from the disallowed origin.
+		{"simple disallowed", corsRequest("https://evil.example", "", ""), http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin":      "",
+			"Access-Control-Allow-Credentials": "",
+			"Access-Control-Expose-Headers":    "",
+		}, true},
+		{"not a subdomain", corsRequest("https://evilpoems.example", "", ""), http.StatusOK,
+			map[string]string{"Access-Control-Allow-Origin": ""}, true},
+		{"preflight", corsRequest("https://poems.example", http.MethodPut, "content-type, x-request-id"),
+			http.StatusNoContent, map[string]string{
+				"Access-Control-Allow-Origin":      "https://poems.example",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "GET, PUT",
+				"Access-Control-Allow-Headers":     "content-type, x-request-id",
+				"Access-Control-Max-Age":           "600",
+			}, false},
+		{"preflight disallowed origin", corsRequest("https://evil.example", http.MethodPut, ""), http.StatusNoContent,
+			map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""}, false},
+		{"preflight disallowed method", corsRequest("https://poems.example", http.MethodDelete, ""),
+			http.StatusNoContent, map[string]string{"Access-Control-Allow-Origin": ""}, false},
+		{"preflight disallowed header", corsRequest("https://poems.example", http.MethodPut, "Authorization"),
+			http.StatusNoContent, map[string]string{"Access-Control-Allow-Origin": ""}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = 0
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, tt.r)
+
+			if rec.Code != tt.status || (ran == 1) != tt.ran {
+				t.Errorf("status %d, exec ran %d times, want %d and ran %v", rec.Code, ran, tt.status, tt.ran)
+			}
+
+			for k, want := range tt.headers {
+				if got := rec.Header().Get(k); got != want {
+					t.Errorf("%s %q, want %q", k, got, want)
+				}
+			}
+
+			if vary := rec.Header().Values("Vary"); len(vary) == 0 || vary[0] != "Origin" {
+				t.Errorf("Vary %q, want Origin", vary)
+			}
+		})
+	}
+}
+
+// TestCORSAnyOrigin allows every origin with "*", without credentials.
+func TestCORSAnyOrigin(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), noContent, gwu.CORS(gwu.CORSPolicy{AllowedOrigins: []string{"*"}}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, corsRequest("https://any.example", "", ""))
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin %q, want *", got)
+	}
+
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials %q, want none", got)
+	}
+}
+
+// TestCORSAnyOriginWithCredentials rejects the policy, browsers refuse it.
+func TestCORSAnyOriginWithCredentials(t *testing.T) {
+	policy := gwu.CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+
+	_, err := gwu.TryHandle(gwu.Empty(), noContent, gwu.CORS(policy))
+	if err == nil || !strings.Contains(err.Error(), `AllowCredentials cannot be combined with the origin "*"`) {
+		t.Errorf("error %v, want the rejected policy", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Handle did not panic")
+		}
+	}()
+
+	gwu.Handle(gwu.Empty(), noContent, gwu.CORS(policy))
+}
+
+// TestCORSRouterPreflight answers the preflight of a route registered for another method.
+func TestCORSRouterPreflight(t *testing.T) {
+	rt := gwu.NewRouter()
+	gwu.HandleRoute(rt, "PUT /poems", gwu.Empty(), noContent, gwu.CORS(poemsPolicy))
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, corsRequest("https://poems.example", http.MethodPut, "Content-Type"))
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "https://poems.example" {
+		t.Errorf("status %d, headers %v, want the allowed preflight", rec.Code, rec.Header())
+	}
+
+	// No route of the path has a policy for DELETE.
+	rec = httptest.NewRecorder()
+	rt.ServeHTTP(rec, corsRequest("https://poems.example", http.MethodDelete, ""))
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("status %d, headers %v, want the preflight without CORS headers", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,71 @@
+package gwu_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func validTitle(p smallPoem) error {
+	if p.Title == "" {
+		return errors.New("title required")
+	}
+
+	return nil
+}
+
+// TestDecodeErrorStatus responds to malformed and to invalid input of the same route with their own status codes.
+func TestDecodeErrorStatus(t *testing.T) {
+	tooLarge := func(*http.Request, gwu.HandleOpts) (smallPoem, error) {
+		return smallPoem{}, gwu.WithStatus(http.StatusRequestEntityTooLarge, errors.New("poem too long"))
+	}
+
+	tests := []struct {
+		name   string
+		inFn   gwu.CnIn[smallPoem]
+		optFns []gwu.HandleOptsFunc
+		body   string
+		status int
+		msg    string
+	}{
+		{"malformed", gwu.ValCnIn(gwu.JSON[smallPoem](), validTitle), nil, `{"title":`, http.StatusBadRequest, ""},
+		{"invalid", gwu.ValCnIn(gwu.JSON[smallPoem](), validTitle), nil, `{"id": 1}`, http.StatusBadRequest,
+			"title required"},
+		{"configured malformed", gwu.ValCnIn(gwu.JSON[smallPoem](), validTitle),
+			[]gwu.HandleOptsFunc{gwu.ValidationErrorStatus(http.StatusUnprocessableEntity)}, `{"title":`,
+			http.StatusBadRequest, ""},
+		{"configured invalid", gwu.ValCnIn(gwu.JSON[smallPoem](), validTitle),
+			[]gwu.HandleOptsFunc{gwu.ValidationErrorStatus(http.StatusUnprocessableEntity)}, `{"id": 1}`,
+			http.StatusUnprocessableEntity, "title required"},
+		{"decode status", gwu.ValCnIn(gwu.JSON[smallPoem](), validTitle),
+			[]gwu.HandleOptsFunc{gwu.DecodeErrorStatus(http.StatusNotAcceptable)}, `{"title":`,
+			http.StatusNotAcceptable, ""},
+		{"status of the error", tooLarge, []gwu.HandleOptsFunc{gwu.DecodeErrorStatus(http.StatusNotAcceptable)},
+			`{}`, http.StatusRequestEntityTooLarge, "poem too long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := gwu.Handle(tt.inFn, echoSmallPoem, tt.optFns...)
+			r := httptest.NewRequest(http.MethodPost, "/poems", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			gwutest.AssertError(t, rec, tt.status, tt.msg)
+		})
+	}
+}
+
+func TestDecodeErrorStatusInvalid(t *testing.T) {
+	for _, opt := range []gwu.HandleOptsFunc{gwu.DecodeErrorStatus(http.StatusOK), gwu.ValidationErrorStatus(302)} {
+		if _, err := gwu.TryHandle(gwu.JSON[smallPoem](), echoSmallPoem, opt); err == nil {
+			t.Error("TryHandle accepted a status code that is no error status code")
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// logsExec logs a line with the request's logger.
+func logsExec(_ context.Context, _ any, opts gwu.HandleOpts) (gwu.NoBody, int, error) {
+	opts.Log.Info("served")
+	return gwu.NoBody{}, http.StatusNoContent, nil
+}
+
+func TestDefaults(t *testing.T) {
+	t.Cleanup(func() { gwu.Defaults() })
+
+	before := gwu.Handle(gwu.JSON[smallPoem](), echoSmallPoem, gwu.Log(gwu.NoopLogger()))
+
+	def, route := gwutest.Logger(), gwutest.Logger()
+	gwu.Defaults(gwu.Log(def), gwu.DecodeErrorStatus(http.StatusUnprocessableEntity))
+	tests := []struct {
+		name string
+		h    http.Handler
+		want *gwutest.CaptureLogger
+	}{
+		{"default", gwu.Handle(gwu.Empty(), logsExec), def},
+		{"overridden per route", gwu.Handle(gwu.Empty(), logsExec, gwu.Log(route)), route},
+	}
+
+	for _, tt := range tests {
+		def.Reset()
+		route.Reset()
+		tt.h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		for _, log := range []*gwutest.CaptureLogger{def, route} {
+			if got := log.Contains("served"); got != (log == tt.want) {
+				t.Errorf("%s: logged to the default %v, the route's logger %v", tt.name, def.Contains("served"),
+					route.Contains("served"))
+			}
+		}
+	}
+
+	// The other defaults apply alongside a route's own options, handlers created before keep theirs.
+	decodes := []struct {
+		h    http.Handler
+		want int
+	}{
+		{gwu.Handle(gwu.JSON[smallPoem](), echoSmallPoem, gwu.Log(route)), http.StatusUnprocessableEntity},
+		{before, http.StatusBadRequest},
+	}
+
+	for _, d := range decodes {
+		rec := httptest.NewRecorder()
+		d.h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		if rec.Code != d.want {
+			t.Errorf("status %d, want %d", rec.Code, d.want)
+		}
+	}
+
+	// Defaults without options clears them.
+	gwu.Defaults()
+	rec := httptest.NewRecorder()
+	gwu.Handle(gwu.JSON[smallPoem](), echoSmallPoem, gwu.Log(route)).ServeHTTP(rec,
+		httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400 without defaults", rec.Code)
+	}
+}
@@ -0,0 +1,140 @@
+package gwu
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrMissingDigest is the error of requests without a Digest header to a handler with VerifyDigest.
+	// Is safe to display to the client.
+	ErrMissingDigest = errors.New("missing digest")
+	// ErrUnsupportedDigest is the error of requests whose Digest header has no digest of an accepted algorithm.
+	// Is safe to display to the client.
+	ErrUnsupportedDigest = errors.New("no digest of a supported algorithm")
+	// ErrDigestMismatch is the error of requests whose body matches none of the accepted digests of the Digest header,
+	// the error of VerifyDigest wraps it and names the algorithms. Is safe to display to the client.
+	ErrDigestMismatch = errors.New("digest mismatch")
+)
+
+// digestAlgorithms are the supported algorithms of the Digest header, see RFC 3230 and RFC 5843.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+// VerifyDigest verifies the body of the request against its Digest header, like
+// "Digest: sha-256=X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=", before the CnIn constructs the input from it. It
+// accepts the algorithms sha-256 and sha-512, or only the given ones. Of a header listing several digests, the body
+// must match any digest of an accepted algorithm, digests of other algorithms are ignored. An unsupported algorithm
+// is an invalid option.
+//
+// VerifyDigest reads the whole body, limit its size with MaxRequestBytes. It responds with
+// http.StatusBadRequest and ErrMissingDigest, ErrUnsupportedDigest, or an error wrapping ErrDigestMismatch that names
+// the algorithms.
+//
+// Example usage:
+//
+//	gwu.Post(rt, "/partner/orders", gwu.JSON[Order](), ctrl.CreateOrder, gwu.VerifyDigest("sha-256"),
+//		gwu.ResponseDigest("sha-256"))
+func VerifyDigest(algorithms ...string) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		accepted := make(map[string]func() hash.Hash, len(digestAlgorithms))
+		for _, alg := range algorithms {
+			hashFn, ok := digestAlgorithms[strings.ToLower(alg)]
+			if !ok {
+				opt.invalid("VerifyDigest: unsupported algorithm %q", alg)
+				return
+			}
+
+			accepted[strings.ToLower(alg)] = hashFn
+		}
+
+		if len(accepted) == 0 {
+			accepted = digestAlgorithms
+		}
+
+		opt.reqDigests = accepted
+	}
+}
+
+// verifyDigest verifies the body of the request against its Digest header with the handler's VerifyDigest
+// algorithms, and replaces the body with the verified one for the CnIn.
+func (o HandleOpts) verifyDigest(r *http.Request) error {
+	if o.reqDigests == nil {
+		return nil
+	}
+
+	values := r.Header.Values("Digest")
+	if len(values) == 0 {
+		return WithStatus(http.StatusBadRequest, ErrMissingDigest)
+	}
+
+	b, err := readBody(r.Body)
+	if err != nil {
+		return ErrDecodeRequest
+	}
+
+	var mismatched []string
+	for _, v := range values {
+		for _, d := range strings.Split(v, ",") {
+			alg, want, _ := strings.Cut(strings.TrimSpace(d), "=")
+			alg = strings.ToLower(alg)
+			hashFn, ok := o.reqDigests[alg]
+			if !ok {
+				continue
+			}
+
+			if SecureCompare([]byte(digestOf(hashFn, b)), []byte(want)) {
+				r.Body = io.NopCloser(bytes.NewReader(b))
+				return nil
+			}
+
+			if !slices.Contains(mismatched, alg) {
+				mismatched = append(mismatched, alg)
+			}
+		}
+	}
+
+	if len(mismatched) == 0 {
+		return WithStatus(http.StatusBadRequest, ErrUnsupportedDigest)
+	}
+
+	return WithStatus(http.StatusBadRequest, fmt.Errorf("%w: %s", ErrDigestMismatch, strings.Join(mismatched, ", ")))
+}
+
+// ResponseDigest sets the Digest header with the digest of the algorithm, sha-256 or sha-512, on the handler's JSON
+// responses. Handle encodes JSON responses into a buffer before writing them, so the digest costs no extra copy.
+// Error responses, Raw, File, and Stream outputs have no Digest header.
+func ResponseDigest(algorithm string) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		alg := strings.ToLower(algorithm)
+		if _, ok := digestAlgorithms[alg]; !ok {
+			opt.invalid("ResponseDigest: unsupported algorithm %q", algorithm)
+			return
+		}
+
+		opt.respDigest = alg
+	}
+}
+
+// digestHeader returns the Digest header value of the body with the algorithm.
+func digestHeader(alg string, body []byte) string {
+	return alg + "=" + digestOf(digestAlgorithms[alg], body)
+}
+
+// digestOf returns the base64-encoded digest of the body.
+func digestOf(hashFn func() hash.Hash, body []byte) string {
+	h := hashFn()
+	h.Write(body)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,94 @@
+package gwu_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// The digests of "abc", the test vectors of FIPS 180-4.
+const (
+	abcSHA256 = "sha-256=ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0="
+	abcSHA512 = "sha-512=3a81oZNherrMQXNJriBBMRLm+k6JqX6iCp7u5ktV05ohkpkqJ0/BqDa6PCOj/uu9RU1EI2Q86A4qmslPpUyknw=="
+)
+
+func echoBody(_ context.Context, b []byte, _ gwu.HandleOpts) (string, int, error) {
+	return string(b), http.StatusOK, nil
+}
+
+func TestVerifyDigest(t *testing.T) {
+	tests := []struct {
+		name       string
+		algorithms []string
+		body       string
+		digests    []string
+		status     int
+		msg        string
+	}{
+		{"sha-256", nil, "abc", []string{abcSHA256}, http.StatusOK, ""},
+		{"sha-512", nil, "abc", []string{abcSHA512}, http.StatusOK, ""},
+		{"case insensitive", []string{"SHA-256"}, "abc", []string{"SHA-256=" + abcSHA256[8:]}, http.StatusOK, ""},
+		{"tampered", nil, "abd", []string{abcSHA256}, http.StatusBadRequest, "digest mismatch: sha-256"},
+		{"any listed", nil, "abc", []string{"sha-256=AAAA, " + abcSHA512}, http.StatusOK, ""},
+		{"any header", nil, "abc", []string{"md5=kAFQmDzST7DWlj99KOF/cg==", abcSHA256}, http.StatusOK, ""},
+		{"none matches", nil, "abd", []string{abcSHA256 + "," + abcSHA512}, http.StatusBadRequest,
+			"digest mismatch: sha-256, sha-512"},
+		{"not accepted", []string{"sha-512"}, "abc", []string{abcSHA256}, http.StatusBadRequest,
+			"no digest of a supported algorithm"},
+		{"missing", nil, "abc", nil, http.StatusBadRequest, "missing digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := gwu.Handle(gwu.RawBody(), echoBody, gwu.VerifyDigest(tt.algorithms...))
+			r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tt.body))
+			for _, d := range tt.digests {
+				r.Header.Add("Digest", d)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if tt.status != http.StatusOK {
+				gwutest.AssertError(t, rec, tt.status, tt.msg)
+				return
+			}
+
+			if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("status %d body %s, want 200 and the verified body", rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+func TestVerifyDigestUnsupported(t *testing.T) {
+	if _, err := gwu.TryHandle(gwu.RawBody(), echoBody, gwu.VerifyDigest("md5")); err == nil {
+		t.Error("VerifyDigest accepted md5")
+	}
+}
+
+func TestResponseDigest(t *testing.T) {
+	for alg, hashFn := range map[string]func() hash.Hash{"sha-256": sha256.New, "sha-512": sha512.New} {
+		t.Run(alg, func(t *testing.T) {
+			h := gwu.Handle(gwu.Empty(), getSmallPoem, gwu.ResponseDigest(alg))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			sum := hashFn()
+			sum.Write(rec.Body.Bytes())
+			want := alg + "=" + base64.StdEncoding.EncodeToString(sum.Sum(nil))
+			if got := rec.Header().Get("Digest"); got != want {
+				t.Errorf("Digest %q, want %q", got, want)
+			}
+		})
+	}
+}
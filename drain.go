@@ -0,0 +1,183 @@
+package gwu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrShuttingDown is the error of streams and WebSocket connections rejected while their server drains them, see
+// DrainStreams.
+// Is safe to display to the client.
+var ErrShuttingDown = errors.New("server shutting down, retry later")
+
+// drains are the streamRegistry of every http.Server serving streams, see DrainStreams.
+var drains sync.Map
+
+// streamRegistry tracks the long-lived responses of a server, so DrainStreams can end them. A nil streamRegistry,
+// of a request without http.Server, tracks nothing.
+type streamRegistry struct {
+	mu       sync.Mutex
+	draining bool
+	active   map[*streamEntry]struct{}
+	wg       sync.WaitGroup
+}
+
+// streamEntry is an active stream, stop asks it to end.
+type streamEntry struct {
+	stop func()
+}
+
+// registryOf returns the streamRegistry of the server.
+func registryOf(srv *http.Server) *streamRegistry {
+	if v, ok := drains.Load(srv); ok {
+		return v.(*streamRegistry)
+	}
+
+	v, _ := drains.LoadOrStore(srv, &streamRegistry{active: make(map[*streamEntry]struct{})})
+	return v.(*streamRegistry)
+}
+
+// streamsOf returns the streamRegistry of the server serving the request, nil if it is served without http.Server,
+// like with an httptest.ResponseRecorder.
+func streamsOf(r *http.Request) *streamRegistry {
+	srv, _ := r.Context().Value(http.ServerContextKey).(*http.Server)
+	if srv == nil {
+		return nil
+	}
+
+	return registryOf(srv)
+}
+
+// DrainStreams ends the Streams, EventStreams, and HandleWS connections served by srv gracefully, call it on
+// shutdown before http.Server.Shutdown, which waits for idle connections but does not end responses in progress.
+// Shutdown calls both.
+//
+// DrainStreams asks every active stream to end after the value it is sending: a stream's context is canceled, and
+// once the producer returned, Handle writes a terminal event as its last value, the line {"event":"shutdown"} for a
+// Stream, and an event of type shutdown for an EventStream. A WebSocket connection is closed with the close code
+// 1001, going away, after the message it is handling. DrainStreams waits until all of them ended, or returns the
+// error of ctx if it is done before, the drain timeout. Streams that did not end by then are cut when the server
+// closes their connections.
+//
+// From the first call on, new streams and WebSocket connections of srv are rejected with ErrShuttingDown,
+// http.StatusServiceUnavailable, and "Retry-After: 1", before their Exec or accept runs. Other servers of the process
+// are not affected. Drained streams are not logged as failures.
+//
+// Example usage:
+//
+//	<-shutdown
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := gwu.DrainStreams(ctx, server); err != nil {
+//		log.Warn("streams not drained", "error", err)
+//	}
+//
+//	_ = server.Shutdown(ctx)
+func DrainStreams(ctx context.Context, srv *http.Server) error {
+	s := registryOf(srv)
+	s.mu.Lock()
+	s.draining = true
+	for e := range s.active {
+		e.stop()
+		delete(s.active, e)
+	}
+
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown shuts srv down gracefully within the drain timeout of ctx: it ends its streams with DrainStreams, then
+// calls srv.Shutdown. If ctx is done before, Shutdown closes srv, cutting the connections left, and returns the
+// error of ctx. The server's drain state is discarded afterward.
+//
+// Example usage:
+//
+//	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer cancel()
+//	go func() { _ = server.ListenAndServe() }()
+//
+//	<-ctx.Done()
+//	drainCtx, drainCancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer drainCancel()
+//	if err := gwu.Shutdown(drainCtx, server); err != nil {
+//		log.Warn("shutdown not graceful", "error", err)
+//	}
+func Shutdown(ctx context.Context, srv *http.Server) error {
+	defer drains.Delete(srv)
+
+	err := DrainStreams(ctx, srv)
+	if shutdownErr := srv.Shutdown(ctx); shutdownErr != nil {
+		_ = srv.Close()
+		if err == nil {
+			err = shutdownErr
+		}
+	}
+
+	return err
+}
+
+// isDraining reports whether DrainStreams was called.
+func (s *streamRegistry) isDraining() bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.draining
+}
+
+// enter registers an active stream with the function ending it, it reports false if the server is draining.
+// Call leave with the returned entry when the stream ended.
+func (s *streamRegistry) enter(stop func()) (*streamEntry, bool) {
+	if s == nil {
+		return nil, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.draining {
+		return nil, false
+	}
+
+	e := &streamEntry{stop: stop}
+	s.active[e] = struct{}{}
+	s.wg.Add(1)
+
+	return e, true
+}
+
+// leave unregisters the stream of the entry.
+func (s *streamRegistry) leave(e *streamEntry) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.active, e)
+	s.mu.Unlock()
+
+	s.wg.Done()
+}
+
+// rejectDraining writes the response of a stream rejected while draining.
+func (o HandleOpts) rejectDraining(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Retry-After", "1")
+	o.writeError(w, r, ErrShuttingDown, http.StatusServiceUnavailable)
+}
@@ -0,0 +1,248 @@
+package gwu_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// tick is a producer sending numbers every millisecond until its context is done.
+func tick(ctx context.Context, send func(int) error) error {
+	for i := 0; ; i++ {
+		if err := send(i); err != nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func streamServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	rt := gwu.NewRouter()
+	gwu.Get(rt, "/ndjson", gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.Stream[int], int, error) {
+		return tick, http.StatusOK, nil
+	})
+	gwu.Get(rt, "/sse", gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.EventStream[int], int, error) {
+		return tick, http.StatusOK, nil
+	})
+	gwu.Get(rt, "/plain", gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (string, int, error) {
+		return gwu.OK("plain")
+	})
+	rt.Handle("GET /ws", gwu.HandleWS(nil, echoWS))
+
+	srv := httptest.NewServer(rt)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// shutdownAfterFirstLine reads the response until the first line, shuts the server down within the budget, and
+// returns the rest of the body.
+func shutdownAfterFirstLine(t *testing.T, srv *httptest.Server, resp *http.Response, budget time.Duration) string {
+	t.Helper()
+
+	br := bufio.NewReader(resp.Body)
+	first, err := br.ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading the first line: %v", err)
+	}
+
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), budget)
+		defer cancel()
+
+		done <- gwu.Shutdown(ctx, srv.Config)
+	}()
+
+	var rest bytes.Buffer
+	_, _ = rest.ReadFrom(br)
+
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+
+	if d := time.Since(start); d > budget {
+		t.Errorf("Shutdown took %s, want at most the drain budget %s", d, budget)
+	}
+
+	return first + rest.String()
+}
+
+func TestShutdownEndsNDJSONStream(t *testing.T) {
+	srv := streamServer(t)
+	resp, err := http.Get(srv.URL + "/ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body := shutdownAfterFirstLine(t, srv, resp, 2*time.Second)
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	if last := lines[len(lines)-1]; last != `{"event":"shutdown"}` {
+		t.Errorf("last line = %q, want the shutdown line", last)
+	}
+
+	for _, line := range lines[:len(lines)-1] {
+		if strings.TrimLeft(line, "0123456789") != "" {
+			t.Errorf("line %q is no complete value", line)
+		}
+	}
+}
+
+func TestShutdownEndsEventStream(t *testing.T) {
+	srv := streamServer(t)
+	resp, err := http.Get(srv.URL + "/sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != gwu.ContentTypeEventStream {
+		t.Errorf("Content-Type = %q, want %q", ct, gwu.ContentTypeEventStream)
+	}
+
+	body := shutdownAfterFirstLine(t, srv, resp, 2*time.Second)
+	events := strings.Split(strings.TrimSuffix(body, "\n\n"), "\n\n")
+	if last := events[len(events)-1]; last != "event: shutdown\ndata: {}" {
+		t.Errorf("last event = %q, want the shutdown event", last)
+	}
+
+	for _, event := range events[:len(events)-1] {
+		if n, ok := strings.CutPrefix(event, "data: "); !ok || strings.TrimLeft(n, "0123456789") != "" {
+			t.Errorf("event %q is no complete data event", event)
+		}
+	}
+}
+
+func TestShutdownClosesWebSocket(t *testing.T) {
+	srv := streamServer(t)
+	c := gwutest.DialWS(t, srv.URL+"/ws", nil)
+	c.Send(wsMsg{Text: "hi"})
+	var got wsMsg
+	c.Receive(&got)
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		done <- gwu.Shutdown(ctx, srv.Config)
+	}()
+
+	if code, _ := c.ReadClose(); code != 1001 {
+		t.Errorf("close code = %d, want 1001", code)
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
+
+func TestDrainStreamsRejectsNewStreams(t *testing.T) {
+	srv := streamServer(t)
+	other := streamServer(t)
+
+	if err := gwu.DrainStreams(context.Background(), srv.Config); err != nil {
+		t.Fatalf("DrainStreams: %v", err)
+	}
+
+	for _, path := range []string{"/ndjson", "/sse", "/ws"} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") != "1" {
+			t.Errorf("%s: status %d, Retry-After %q, want 503 and 1", path, resp.StatusCode, resp.Header.Get("Retry-After"))
+		}
+	}
+
+	// Other routes of the server, and the streams of other servers, are not affected.
+	for _, url := range []string{srv.URL + "/plain", other.URL + "/plain"} {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status %d, want 200", url, resp.StatusCode)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, other.URL+"/ndjson", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("stream of another server: status %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestShutdownDrainBudgetExceeded(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.Stream[int], int, error) {
+		return func(_ context.Context, send func(int) error) error {
+			_ = send(1)
+			close(started)
+			// Ignores the shutdown.
+			<-release
+			return nil
+		}, http.StatusOK, nil
+	})
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	// Released before the server waits for its handlers to return.
+	defer close(release)
+
+	go func() {
+		if resp, err := http.Get(srv.URL); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := gwu.Shutdown(ctx, srv.Config); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want context.DeadlineExceeded", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Shutdown took %s, want it bounded by the drain budget", d)
+	}
+}
@@ -0,0 +1,38 @@
+package gwu
+
+// EnrichLog returns the HandleOpts with the key-value pairs added to the request's logger and ErrorLog, like the
+// identifiers of the decoded input. Call it in a CnIn, a Before hook, or the Exec: Handle logs everything after it
+// returns with the attributes, including its own log lines, like failed or slow requests, and the logger in the
+// context of the Exec. Use the returned HandleOpts to log with the attributes within the same function.
+//
+// The attributes only apply to the request, other requests of the handler log without them.
+//
+// Example usage:
+//
+//	func poemIn(r *http.Request, opts gwu.HandleOpts) (PoemUpdate, error) {
+//		in, err := gwu.JSON[PoemUpdate]()(r, opts)
+//		gwu.EnrichLog(opts, "poem_id", in.ID)
+//		return in, err
+//	}
+func EnrichLog(opts HandleOpts, args ...any) HandleOpts {
+	opts = opts.enrichedLog()
+	opts.Log = withAttrs(opts.Log, args...)
+	if opts.errLog != nil {
+		opts.errLog = withAttrs(opts.errLog, args...)
+	}
+
+	if vals := opts.req.vals; vals != nil {
+		vals.log, vals.errLog = opts.Log, opts.errLog
+	}
+
+	return opts
+}
+
+// enrichedLog returns the HandleOpts with the loggers enriched by EnrichLog during the request.
+func (o HandleOpts) enrichedLog() HandleOpts {
+	if vals := o.req.vals; vals != nil && vals.log != nil {
+		o.Log, o.errLog = vals.log, vals.errLog
+	}
+
+	return o
+}
@@ -0,0 +1,80 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// TestEnrichLogConcurrent enriches the logs of concurrent requests of a handler with different IDs, run it with
+// -race. Every line of a request, of the Exec and of Handle to both loggers, must carry its own ID only.
+func TestEnrichLogConcurrent(t *testing.T) {
+	const n = 32
+
+	// The CnIns wait for each other, so all requests enrich at the same time.
+	var enriched sync.WaitGroup
+	enriched.Add(n)
+	in := func(r *http.Request, opts gwu.HandleOpts) (string, error) {
+		id := r.URL.Query().Get("id")
+		gwu.EnrichLog(opts, "poem_id", id)
+		enriched.Done()
+		enriched.Wait()
+
+		return id, nil
+	}
+
+	exec := func(ctx context.Context, id string, opts gwu.HandleOpts) (any, int, error) {
+		opts.Log.Info("exec", "id", id)
+		gwu.LoggerFrom(ctx).Info("exec context", "id", id)
+
+		return nil, http.StatusInternalServerError, errors.New("failed " + id)
+	}
+
+	log, errLog := gwutest.Logger(), gwutest.Logger()
+	h := gwu.Handle(in, exec, gwu.Log(log), gwu.ErrorLog(errLog))
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?id="+strconv.Itoa(i), nil))
+		}()
+	}
+
+	wg.Wait()
+
+	for _, e := range append(log.Entries(), errLog.Entries()...) {
+		id, ok := e.Attrs["poem_id"]
+		if !ok {
+			t.Errorf("entry without poem_id: %s", e)
+			continue
+		}
+
+		if want, ok := e.Attrs["id"]; ok && id != want {
+			t.Errorf("entry of %v with poem_id %v: %s", want, id, e)
+		}
+
+		if err, ok := e.Attrs["error"]; ok && !strings.HasSuffix(fmt.Sprint(err), fmt.Sprintf(" %v", id)) {
+			t.Errorf("entry of %v with poem_id %v: %s", err, id, e)
+		}
+	}
+
+	for i := range n {
+		id := strconv.Itoa(i)
+		log.AssertLogged(t, slog.LevelInfo, "exec", "poem_id", id, "id", id)
+		log.AssertLogged(t, slog.LevelInfo, "exec context", "poem_id", id, "id", id)
+		log.AssertLogged(t, slog.LevelError, "request failed", "poem_id", id)
+		errLog.AssertLogged(t, slog.LevelError, "request failed", "poem_id", id)
+	}
+}
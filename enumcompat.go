@@ -0,0 +1,184 @@
+package gwu
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// EnumMapping is the compatibility mapping of an enum field for EnumCompat.
+type EnumMapping struct {
+	// Known are the values of the enum each client version knows, keyed by the version.
+	Known map[string][]string
+	// Fallback replaces the values a client version does not know.
+	Fallback string
+}
+
+// enumCompat is the configuration of EnumCompat.
+type enumCompat struct {
+	header   string
+	mappings map[string]EnumMapping
+}
+
+// EnumCompat rewrites the enum values of responses that older clients do not know to a fallback, for clients that
+// fail on unknown enum values. Clients request the compatibility mode with their version in the header, responses
+// to requests without the header, or with a version no mapping knows, are not rewritten.
+//
+// The keys of the mappings are the JSON paths of the enum fields, like $.status, with [*] for the elements of an
+// array, like $.poems[*].lines[*].status. String values at a path that are not known to the client's version are
+// replaced with the mapping's Fallback. EnumCompat encodes the response before rewriting it, the order of the
+// fields is kept. It sets the Vary header. EnumCompat panics if a path does not start with $.
+//
+// Example usage:
+//
+//	gwu.Get(rt, "/poems", gwu.Empty(), ctrl.All, gwu.EnumCompat("X-Client-Version", map[string]gwu.EnumMapping{
+//		"$[*].status": {
+//			Known:    map[string][]string{"1.0": {"draft", "published"}, "2.0": {"draft", "published", "archived"}},
+//			Fallback: "published",
+//		},
+//	}))
+func EnumCompat(header string, mappings map[string]EnumMapping) HandleOptsFunc {
+	c := &enumCompat{header: http.CanonicalHeaderKey(header), mappings: make(map[string]EnumMapping, len(mappings))}
+	for path, m := range mappings {
+		if !strings.HasPrefix(path, "$") {
+			panic(fmt.Sprintf("gwu: EnumCompat: path %q does not start with $", path))
+		}
+
+		c.mappings[path] = m
+	}
+
+	return func(opt *HandleOpts) {
+		opt.enumCompat = c
+	}
+}
+
+// compatEnums returns the output with the enum values rewritten for the client's version, see EnumCompat.
+func (o HandleOpts) compatEnums(w http.ResponseWriter, r *http.Request, v any) (any, error) {
+	w.Header().Add("Vary", o.enumCompat.header)
+	data, ok, err := o.enumCompat.rewrite(r, o.JSONCodec(), v)
+	if err != nil || !ok {
+		return v, err
+	}
+
+	return data, nil
+}
+
+// rewrite returns the JSON of the response with the enum values rewritten for the client's version of the request,
+// it reports false if the response does not need rewriting.
+func (c *enumCompat) rewrite(r *http.Request, codec JSONCodec, v any) (json.RawMessage, bool, error) {
+	version := r.Header.Get(c.header)
+	if version == "" {
+		return nil, false, nil
+	}
+
+	known := make(map[string][]string, len(c.mappings))
+	for path, m := range c.mappings {
+		if values, ok := m.Known[version]; ok {
+			known[path] = values
+		}
+	}
+
+	if len(known) == 0 {
+		return nil, false, nil
+	}
+
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err = c.rewriteJSON(data, known)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// enumFrame is an object or array rewriteJSON is in.
+type enumFrame struct {
+	path   string
+	object bool
+	// key is the key of the value an object frame reads next, expectKey whether it reads a key next.
+	key       string
+	expectKey bool
+}
+
+// rewriteJSON replaces the string values at the paths of known that are not known with the fallback of the path's
+// mapping. It rewrites the bytes of the values only, so the rest of the JSON is kept as is.
+func (c *enumCompat) rewriteJSON(data []byte, known map[string][]string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var out []byte
+	var stack []enumFrame
+	last := 0
+
+	// valuePath returns the path of the next value, and marks the key of an object frame as read.
+	valuePath := func() string {
+		if len(stack) == 0 {
+			return "$"
+		}
+
+		top := &stack[len(stack)-1]
+		if !top.object {
+			return top.path + "[*]"
+		}
+
+		top.expectKey = true
+		return top.path + "." + top.key
+	}
+
+	for {
+		start := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			if len(stack) == 0 && errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				stack = append(stack, enumFrame{path: valuePath(), object: t == '{', expectKey: t == '{'})
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if n := len(stack); n > 0 && stack[n-1].object && stack[n-1].expectKey {
+				stack[n-1].key, stack[n-1].expectKey = t, false
+				continue
+			}
+
+			path := valuePath()
+			values, ok := known[path]
+			if !ok || slices.Contains(values, t) {
+				continue
+			}
+
+			end := int(dec.InputOffset())
+			quote := bytes.IndexByte(data[start:end], '"')
+			fallback, _ := json.Marshal(c.mappings[path].Fallback)
+			out = append(out, data[last:int(start)+quote]...)
+			out = append(out, fallback...)
+			last = end
+		default:
+			valuePath()
+		}
+	}
+
+	if out == nil {
+		return data, nil
+	}
+
+	return append(out, data[last:]...), nil
+}
@@ -0,0 +1,124 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+type compatLine struct {
+	Text   string `json:"text"`
+	Status string `json:"status"`
+}
+
+type compatPoem struct {
+	Status string       `json:"status"`
+	Lines  []compatLine `json:"lines"`
+	Kind   any          `json:"kind"`
+}
+
+type compatPage struct {
+	Status string       `json:"status"`
+	Poems  []compatPoem `json:"poems"`
+}
+
+// compatPoems serves the page with EnumCompat and returns the response to a client of the version.
+func compatPoems(t *testing.T, version string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	page := compatPage{Status: "beta", Poems: []compatPoem{
+		{Status: "archived", Kind: 7, Lines: []compatLine{
+			{Text: "Season of mists", Status: "published"},
+			{Text: "Close bosom-friend", Status: "reviewed"},
+		}},
+		{Status: "draft", Kind: "archived", Lines: []compatLine{{Text: "inherited \"archived\"", Status: "reviewed"}}},
+	}}
+
+	known := map[string][]string{
+		"1.0": {"draft", "published"},
+		"2.0": {"draft", "published", "archived", "reviewed"},
+	}
+
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (compatPage, int, error) {
+		return page, http.StatusOK, nil
+	}, gwu.EnumCompat("x-client-version", map[string]gwu.EnumMapping{
+		"$.status":                   {Known: map[string][]string{"1.0": {"stable"}}, Fallback: "stable"},
+		"$.poems[*].status":          {Known: known, Fallback: "published"},
+		"$.poems[*].kind":            {Known: known, Fallback: "draft"},
+		"$.poems[*].lines[*].status": {Known: known, Fallback: "draft"},
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/poems", nil)
+	if version != "" {
+		r.Header.Set("X-Client-Version", version)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+
+	return rec
+}
+
+const compatOriginal = `{"status":"beta","poems":[` +
+	`{"status":"archived","lines":[{"text":"Season of mists","status":"published"},` +
+	`{"text":"Close bosom-friend","status":"reviewed"}],"kind":7},` +
+	`{"status":"draft","lines":[{"text":"inherited \"archived\"","status":"reviewed"}],"kind":"archived"}]}`
+
+func TestEnumCompat(t *testing.T) {
+	tests := []struct {
+		name, version, want string
+	}{
+		// Non-string values at a path and strings at other paths are kept.
+		{"old client", "1.0", `{"status":"stable","poems":[` +
+			`{"status":"published","lines":[{"text":"Season of mists","status":"published"},` +
+			`{"text":"Close bosom-friend","status":"draft"}],"kind":7},` +
+			`{"status":"draft","lines":[{"text":"inherited \"archived\"","status":"draft"}],"kind":"draft"}]}`},
+		{"client knowing everything", "2.0", compatOriginal},
+		{"without header", "", compatOriginal},
+		{"unknown version", "3.0", compatOriginal},
+	}
+
+	for _, tt := range tests {
+		rec := compatPoems(t, tt.version)
+		if got := strings.TrimSpace(rec.Body.String()); rec.Code != http.StatusOK || got != tt.want {
+			t.Errorf("%s: %d\n%s\nwant\n%s", tt.name, rec.Code, got, tt.want)
+		}
+
+		if vary := rec.Header().Get("Vary"); vary != "X-Client-Version" {
+			t.Errorf("%s: Vary %q, want the compat header", tt.name, vary)
+		}
+	}
+}
+
+func TestEnumCompatInvalidPath(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("EnumCompat did not panic")
+		}
+	}()
+
+	gwu.EnumCompat("X-Client-Version", map[string]gwu.EnumMapping{"poems[*].status": {Fallback: "draft"}})
+}
+
+// TestEnumCompatArray rewrites the elements of a response that is an array.
+func TestEnumCompatArray(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) ([]compatLine, int, error) {
+		return []compatLine{{Text: "Ode", Status: "archived"}, {Text: "Elegy", Status: "draft"}}, http.StatusOK, nil
+	}, gwu.EnumCompat("X-Client-Version", map[string]gwu.EnumMapping{
+		"$[*].status": {Known: map[string][]string{"1.0": {"draft", "published"}}, Fallback: "published"},
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/poems", nil)
+	r.Header.Set("X-Client-Version", "1.0")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+
+	want := `[{"text":"Ode","status":"published"},{"text":"Elegy","status":"draft"}]`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Errorf("%s, want %s", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package gwu
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// ErrorLog sets a separate logger for failures: panics, Exec errors with a 5xx status code, and response encoding
+// failures. Handle logs failures to the ErrorLog with the Error level, and additionally to the request logger,
+// unless ErrorLogOnly is set.
+//
+// Without ErrorLog, Handle logs encoding failures to the request logger and does not log panics and 5xx errors.
+func ErrorLog(log Logger) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.errLog = log
+	}
+}
+
+// ErrorLogOnly logs failures only to the ErrorLog, not to the request logger.
+func ErrorLogOnly() HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.errLogOnly = true
+	}
+}
+
+// logFailure logs a failure if the handler has an ErrorLog.
+func (o HandleOpts) logFailure(msg string, args ...any) {
+	if o.errLog == nil {
+		return
+	}
+
+	logError(o.errLog, msg, args...)
+	if !o.errLogOnly {
+		logError(o.Log, msg, args...)
+	}
+}
+
+// reportFailure logs a failure like logFailure, and with the handler's logger if there is no ErrorLog.
+func (o HandleOpts) reportFailure(msg string, args ...any) {
+	if o.errLog == nil {
+		logError(o.Log, msg, args...)
+		return
+	}
+
+	o.logFailure(msg, args...)
+}
+
+// logEncodeFailure logs a failure to encode the response.
+func (o HandleOpts) logEncodeFailure(err error) {
+	msg := fmt.Errorf("%w: %w", ErrEncodeResponse, err).Error()
+	if o.errLog == nil || !o.errLogOnly {
+		o.Log.Info(msg)
+	}
+
+	if o.errLog != nil {
+		logError(o.errLog, msg)
+	}
+}
+
+// logPanic logs a panic that occurred while handling r and continues panicking, net/http handles the panic.
+// Call it deferred.
+func (o HandleOpts) logPanic(r *http.Request) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	if v != http.ErrAbortHandler {
+		o.logFailure("panic while handling request",
+			"method", r.Method, "path", FullPath(r), "panic", v, "stack", string(debug.Stack()))
+	}
+
+	panic(v)
+}
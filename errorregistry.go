@@ -0,0 +1,112 @@
+package gwu
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// registeredError is an error type registered with RegisterError.
+type registeredError struct {
+	typ   reflect.Type
+	match func(err error) (status int, msg string, ok bool)
+}
+
+// errRegistry holds the error types registered with RegisterError, in the order of registration.
+var errRegistry struct {
+	mu    sync.RWMutex
+	types []registeredError
+}
+
+// RegisterError registers the error type E with the status code and the message Handle responds with. Handle
+// consults the registered types for errors an Exec returns without status code, that is with status code 0 and no
+// StatusError: the first registered type that matches the error with errors.As determines the status code, and the
+// message of the matching error is written instead of the error, so it must be safe to display to the client.
+// A nil message responds with the status text. Errors of no registered type respond with
+// http.StatusInternalServerError, like before.
+//
+// The full error is passed to the After hooks and logged, errors with a 5xx status code with the ErrorLog, others
+// at debug level. RegisterError is safe for concurrent use, call it at package init, e.g. in a var declaration or
+// init function. It panics if E is already registered or the status code is no 4xx or 5xx status code.
+//
+// Example usage:
+//
+//	func init() {
+//		gwu.RegisterError(http.StatusConflict, func(err *store.ConflictError) string {
+//			return "poem " + err.Key + " already exists"
+//		})
+//	}
+//
+//	func (c *Ctrl) Create(ctx context.Context, poem Poem, _ gwu.HandleOpts) (Poem, int, error) {
+//		if err := c.store.Insert(ctx, poem); err != nil {
+//			return Poem{}, 0, err // responds with http.StatusConflict for a *store.ConflictError
+//		}
+//
+//		return poem, http.StatusCreated, nil
+//	}
+func RegisterError[E error](status int, message func(E) string) {
+	typ := reflect.TypeFor[E]()
+	if !validStatus(status) {
+		panic(fmt.Sprintf("gwu: RegisterError %s: %d is no error status code", typ, status))
+	}
+
+	match := func(err error) (int, string, bool) {
+		var target E
+		if !errors.As(err, &target) {
+			return 0, "", false
+		}
+
+		if message == nil {
+			return status, http.StatusText(status), true
+		}
+
+		return status, message(target), true
+	}
+
+	errRegistry.mu.Lock()
+	defer errRegistry.mu.Unlock()
+
+	for _, t := range errRegistry.types {
+		if t.typ == typ {
+			panic(fmt.Sprintf("gwu: RegisterError %s: already registered", typ))
+		}
+	}
+
+	errRegistry.types = append(errRegistry.types, registeredError{typ: typ, match: match})
+}
+
+// execErrStatus returns the status code and the client-safe error for an error an Exec returned without status code,
+// see RegisterError.
+func execErrStatus(err error) (int, error) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status, err
+	}
+
+	errRegistry.mu.RLock()
+	defer errRegistry.mu.RUnlock()
+
+	for _, t := range errRegistry.types {
+		if status, msg, ok := t.match(err); ok {
+			return status, &safeError{msg: msg, err: err}
+		}
+	}
+
+	return http.StatusInternalServerError, err
+}
+
+// safeError is the client-safe message of a registered error type, it wraps the full error.
+type safeError struct {
+	msg string
+	err error
+}
+
+func (e *safeError) Error() string {
+	return e.msg
+}
+
+func (e *safeError) Unwrap() error {
+	return e.err
+}
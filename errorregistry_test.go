@@ -0,0 +1,166 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// conflictError is a store error registered with 409 and a message naming the key.
+type conflictError struct {
+	Key string
+}
+
+func (e *conflictError) Error() string {
+	return "duplicate key " + e.Key + " in table poems"
+}
+
+// unavailableError is a store error registered with 503 and the status text.
+type unavailableError struct {
+	Replica string
+}
+
+func (e unavailableError) Error() string {
+	return "replica " + e.Replica + " unreachable"
+}
+
+func init() {
+	gwu.RegisterError(http.StatusConflict, func(err *conflictError) string {
+		return "poem " + err.Key + " already exists"
+	})
+	gwu.RegisterError[unavailableError](http.StatusServiceUnavailable, nil)
+}
+
+// failWith serves a request to a handler whose Exec returns the error and the status code.
+func failWith(err error, code int, opts ...gwu.HandleOptsFunc) *httptest.ResponseRecorder {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		return gwu.NoBody{}, code, err
+	}, opts...)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/poems", nil))
+
+	return rec
+}
+
+func TestRegisterError(t *testing.T) {
+	conflict := &conflictError{Key: "ode"}
+	tests := []struct {
+		name   string
+		err    error
+		code   int
+		status int
+		msg    string
+	}{
+		{"registered", conflict, 0, http.StatusConflict, "poem ode already exists"},
+		{"second type", unavailableError{Replica: "db-2"}, 0, http.StatusServiceUnavailable,
+			http.StatusText(http.StatusServiceUnavailable)},
+		{"deep in the chain", fmt.Errorf("create: %w", fmt.Errorf("insert: %w", conflict)), 0, http.StatusConflict,
+			"poem ode already exists"},
+		{"joined", errors.Join(errors.New("rollback failed"), conflict), 0, http.StatusConflict,
+			"poem ode already exists"},
+		{"not registered", errors.New("database down"), 0, http.StatusInternalServerError, "database down"},
+		{"status error wins", gwu.WithStatus(http.StatusBadRequest, fmt.Errorf("invalid: %w", conflict)), 0,
+			http.StatusBadRequest, "invalid: duplicate key ode in table poems"},
+		{"explicit status wins", conflict, http.StatusUnprocessableEntity, http.StatusUnprocessableEntity,
+			conflict.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gwutest.AssertError(t, failWith(tt.err, tt.code, gwu.Log(gwutest.Logger())), tt.status, tt.msg)
+		})
+	}
+}
+
+// TestRegisterErrorLogged logs and passes the full error, not the message written to the client.
+func TestRegisterErrorLogged(t *testing.T) {
+	log, errLog := gwutest.Logger(), gwutest.Logger()
+	var hooked error
+	after := gwu.After(func(_ *http.Request, _ gwu.HandleOpts, _ int, err error) { hooked = err })
+
+	err := fmt.Errorf("create: %w", &conflictError{Key: "ode"})
+	failWith(err, 0, gwu.Log(log), after)
+	log.AssertLogged(t, slog.LevelDebug, "request failed", "status", int64(http.StatusConflict),
+		"error", "create: duplicate key ode in table poems")
+	if hooked != err {
+		t.Errorf("After hook got %v, want the full error", hooked)
+	}
+
+	failWith(unavailableError{Replica: "db-2"}, 0, gwu.Log(log), gwu.ErrorLog(errLog))
+	errLog.AssertLogged(t, slog.LevelError, "request failed", "status", int64(http.StatusServiceUnavailable),
+		"error", "replica db-2 unreachable")
+}
+
+func TestRegisterErrorPanics(t *testing.T) {
+	type quotaError struct{ error }
+
+	for name, fn := range map[string]func(){
+		"duplicate": func() { gwu.RegisterError[*conflictError](http.StatusConflict, nil) },
+		"no error status": func() {
+			gwu.RegisterError[quotaError](http.StatusOK, nil)
+		},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: RegisterError did not panic", name)
+				}
+			}()
+
+			fn()
+		}()
+	}
+}
+
+// lateError is registered by TestRegisterErrorConcurrent, one instantiation per goroutine.
+type lateError[T any] struct{}
+
+func (lateError[T]) Error() string {
+	return "late"
+}
+
+// lateErrors registers the lateError types once, the registry cannot be reset for a repeated test run.
+var lateErrors sync.Once
+
+// TestRegisterErrorConcurrent registers types while requests consult the registry, run it with -race.
+func TestRegisterErrorConcurrent(t *testing.T) {
+	lateErrors.Do(func() { registerLateErrors(t) })
+	if rec := failWith(lateError[bool]{}, 0); rec.Code != http.StatusTeapot {
+		t.Errorf("status %d, want the registered 418", rec.Code)
+	}
+}
+
+func registerLateErrors(t *testing.T) {
+	var wg sync.WaitGroup
+	register := []func(){
+		func() { gwu.RegisterError[lateError[int]](http.StatusTeapot, nil) },
+		func() { gwu.RegisterError[lateError[string]](http.StatusTeapot, nil) },
+		func() { gwu.RegisterError[lateError[bool]](http.StatusTeapot, nil) },
+		func() { gwu.RegisterError[lateError[float64]](http.StatusTeapot, nil) },
+	}
+
+	for _, fn := range register {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+		go func() {
+			defer wg.Done()
+			if rec := failWith(&conflictError{Key: "ode"}, 0); rec.Code != http.StatusConflict {
+				t.Errorf("status %d, want 409", rec.Code)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,163 @@
+package gwu
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// StatusError is an error that carries the HTTP status code to respond with.
+// Handle responds with the status code of a StatusError returned by a CnIn, instead of the default status.
+//
+// Like any error returned by a CnIn or Exec, the wrapped error must be safe to display to the client.
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+// WithStatus wraps err in a StatusError with the given status code.
+func WithStatus(status int, err error) error {
+	return &StatusError{Status: status, Err: err}
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is an error returned by the validation function of ValCnIn.
+// Handle responds to a ValidationError with the ValidationErrorStatus, and to other CnIn errors with the
+// DecodeErrorStatus.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorFunc writes an error response with the status code, set it with Errors.
+// The error is safe to display to the client.
+type ErrorFunc func(w http.ResponseWriter, r *http.Request, err error, code int)
+
+// Errors sets the ErrorFunc writing the handler's error responses, defaults to TextError. The handlers of the package
+// taking options, like Static, NotFoundHandler, and the Router's 404 and 405 responses, use it with the same default.
+// A Spec documents the error responses as ErrorBody if fn is a JSONErrorFunc, like JSONError, and as plain text
+// otherwise.
+func Errors[F ~func(w http.ResponseWriter, r *http.Request, err error, code int)](fn F) HandleOptsFunc {
+	_, jsonErrors := any(fn).(JSONErrorFunc)
+	return func(opt *HandleOpts) {
+		opt.errFn = ErrorFunc(fn)
+		opt.jsonErrors = jsonErrors
+	}
+}
+
+// TextError writes the error message as plain text, like http.Error.
+func TextError(w http.ResponseWriter, _ *http.Request, err error, code int) {
+	http.Error(w, err.Error(), code)
+}
+
+// ErrorBody is the JSON body of error responses written by JSONError.
+type ErrorBody struct {
+	Error string `json:"error"`
+	// Fields are the field errors of the error, see FieldError.
+	Fields []*FieldError `json:"fields,omitempty"`
+}
+
+// JSONErrorFunc is an ErrorFunc writing ErrorBody responses. Convert an ErrorFunc writing them to a JSONErrorFunc
+// before setting it with Errors, so a Spec documents the error responses as ErrorBody.
+type JSONErrorFunc func(w http.ResponseWriter, r *http.Request, err error, code int)
+
+// JSONError writes the error message as ErrorBody with Content-Type `application/json`.
+var JSONError = JSONErrorFunc(jsonError)
+
+// jsonError is the function of JSONError.
+func jsonError(w http.ResponseWriter, _ *http.Request, err error, code int) {
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", ContentTypeJSON)
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+
+	// The field errors may be shared between requests, the body lists copies with their messages.
+	var fields []*FieldError
+	for _, fe := range fieldErrors(err) {
+		c := *fe
+		c.Message = c.message()
+		fields = append(fields, &c)
+	}
+
+	_ = json.NewEncoder(w).Encode(ErrorBody{Error: err.Error(), Fields: fields})
+}
+
+// errFnOr returns the handler's ErrorFunc, or fn if none is set.
+func (o HandleOpts) errFnOr(fn ErrorFunc) ErrorFunc {
+	if o.errFn == nil {
+		return fn
+	}
+
+	return o.errFn
+}
+
+// writeError writes an error response with the handler's ErrorFunc.
+func (o HandleOpts) writeError(w http.ResponseWriter, r *http.Request, err error, code int) {
+	if o.translate != nil {
+		err = o.translateFields(r, err)
+	}
+
+	o.errFnOr(TextError)(w, r, err, code)
+}
+
+// DecodeErrorStatus sets the status code Handle responds with when a CnIn fails, defaults to http.StatusBadRequest.
+// A StatusError returned by the CnIn overrides the status code. The code must be a 4xx or 5xx status code.
+func DecodeErrorStatus(code int) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		if !validStatus(code) {
+			opt.invalid("DecodeErrorStatus: %d is no error status code", code)
+		}
+
+		opt.decodeErrStatus = code
+	}
+}
+
+// ValidationErrorStatus sets the status code Handle responds with when a CnIn fails with a ValidationError,
+// defaults to http.StatusBadRequest. A StatusError returned by the CnIn overrides the status code.
+//
+// Use it together with DecodeErrorStatus to distinguish semantically invalid input, e.g.
+// http.StatusUnprocessableEntity, from malformed input. The code must be a 4xx or 5xx status code.
+func ValidationErrorStatus(code int) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		if !validStatus(code) {
+			opt.invalid("ValidationErrorStatus: %d is no error status code", code)
+		}
+
+		opt.valErrStatus = code
+	}
+}
+
+// inErrStatus returns the status code for an error returned by a CnIn.
+func inErrStatus(err error, opts HandleOpts) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status
+	}
+
+	code := opts.decodeErrStatus
+	var valErr *ValidationError
+	if errors.As(err, &valErr) {
+		code = opts.valErrStatus
+	}
+
+	if code == 0 {
+		return http.StatusBadRequest
+	}
+
+	return code
+}
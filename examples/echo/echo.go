@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/jensilo/gwu"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// ErrEmpty for external use, safe to display to the client.
+var ErrEmpty = errors.New("message must not be empty")
+
+func main() {
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	gwu.Defaults(gwu.Log(log))
+
+	rt := gwu.NewRouter()
+	rt.Handle("GET /echo", gwu.HandleWS(AcceptOrigin, Echo, gwu.MaxMessageBytes(4<<10), gwu.PingInterval(time.Minute)))
+
+	server := &http.Server{Addr: ":8080", Handler: rt}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	go func() {
+		log.Info("start server...")
+		log.Info("server stopped", "error", server.ListenAndServe())
+	}()
+
+	<-ctx.Done()
+
+	// Close the open connections with the close code 1001 before shutting down.
+	drainCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := gwu.Shutdown(drainCtx, server); err != nil {
+		log.Warn("shutdown not graceful", "error", err)
+	}
+}
+
+type Message struct {
+	Text string    `json:"text"`
+	At   time.Time `json:"at"`
+}
+
+// AcceptOrigin accepts connections from pages served by localhost only.
+func AcceptOrigin(r *http.Request, _ gwu.HandleOpts) error {
+	if origin := r.Header.Get("Origin"); origin != "" && !strings.HasPrefix(origin, "http://localhost") {
+		return gwu.WithStatus(http.StatusForbidden, errors.New("origin not allowed"))
+	}
+
+	return nil
+}
+
+// Echo sends every message back to the client with the time it was received.
+func Echo(_ context.Context, in Message, send func(Message) error, opts gwu.HandleOpts) error {
+	if in.Text == "" {
+		return ErrEmpty
+	}
+
+	opts.Log.Debug("echo message", "text", in.Text)
+
+	return send(Message{Text: in.Text, At: time.Now()})
+}
@@ -36,29 +36,26 @@ func main() {
 	store := NewStore()
 	ctrl := PoemController{store: store}
 
-	mux := http.NewServeMux()
-	mux.Handle("GET /poem/{id}", gwu.Handle(IDIn("id"), ctrl.ByID,
-		gwu.Log(log.With("method", "GET", "route", "/poem/{id}"))),
-	)
-	mux.Handle("GET /poems", gwu.Handle(gwu.Empty(), ctrl.All,
-		gwu.Log(log.With("method", "GET", "route", "/poems"))),
-	)
-	mux.Handle("POST /poem", gwu.Handle(gwu.JSON[Poem](), gwu.ValIn(ctrl.Create, ValidateToCreate),
-		gwu.Log(log.With("method", "POST", "route", "/poem"))),
-	)
-	mux.Handle("GET /poems/author/{author}", gwu.Handle(gwu.PathVal("author"), ctrl.ByAuthor,
-		gwu.Log(log.With("method", "GET", "route", "/poems/author/{author}"))),
-	)
-	mux.Handle("DELETE /poem/{id}", gwu.Handle(IDIn("id"), ctrl.Delete,
-		gwu.Log(log.With("method", "DELETE", "route", "/poem/{id}"))),
-	)
-
-	server := http.Server{Addr: ":8080", Handler: mux}
+	gwu.Defaults(gwu.Log(log))
+
+	server := http.Server{Addr: ":8080", Handler: Routes(&ctrl)}
 
 	log.Info("start server...")
 	log.Info("server killed", "error", server.ListenAndServe())
 }
 
+// Routes returns the mux serving the poem API of the controller, the options apply to all routes.
+func Routes(ctrl *PoemController, optFns ...gwu.HandleOptsFunc) *http.ServeMux {
+	mux := http.NewServeMux()
+	gwu.Get(mux, "/poem/{id}", IDIn("id"), ctrl.ByID, optFns...)
+	gwu.Get(mux, "/poems", gwu.Empty(), ctrl.All, optFns...)
+	gwu.Post(mux, "/poem", gwu.JSON[Poem](), gwu.ValIn(ctrl.Create, ValidateToCreate), optFns...)
+	gwu.Get(mux, "/poems/author/{author}", gwu.PathVal("author"), ctrl.ByAuthor, optFns...)
+	mux.Handle("DELETE /poem/{id}", gwu.HandleNoOut(IDIn("id"), ctrl.Delete, optFns...))
+
+	return mux
+}
+
 type ID string
 
 func NewID() ID {
@@ -191,7 +188,8 @@ func (c *PoemController) Create(_ context.Context, poem Poem, opts gwu.HandleOpt
 		return poem, http.StatusInternalServerError, ErrCouldNotCreate
 	}
 
-	return poem, http.StatusCreated, nil
+	opts.Header().Set("Location", "/poem/"+string(poem.ID))
+	return gwu.Created(poem)
 }
 
 func (c *PoemController) ByID(_ context.Context, id ID, opts gwu.HandleOpts) (Poem, int, error) {
@@ -201,12 +199,11 @@ func (c *PoemController) ByID(_ context.Context, id ID, opts gwu.HandleOpts) (Po
 		return poem, http.StatusNotFound, ErrNotFound
 	}
 
-	return poem, http.StatusOK, nil
+	return gwu.OK(poem)
 }
 
 func (c *PoemController) All(_ context.Context, _ any, opts gwu.HandleOpts) ([]Poem, int, error) {
-	poems := c.store.All()
-	return poems, http.StatusOK, nil
+	return gwu.OK(c.store.All())
 }
 
 func (c *PoemController) ByAuthor(_ context.Context, author string, opts gwu.HandleOpts) ([]Poem, int, error) {
@@ -216,17 +213,17 @@ func (c *PoemController) ByAuthor(_ context.Context, author string, opts gwu.Han
 		return nil, http.StatusNotFound, ErrAuthorNotFound
 	}
 
-	return poems, http.StatusOK, nil
+	return gwu.OK(poems)
 }
 
-func (c *PoemController) Delete(_ context.Context, id ID, opts gwu.HandleOpts) (string, int, error) {
+func (c *PoemController) Delete(_ context.Context, id ID, opts gwu.HandleOpts) (int, error) {
 	err := c.store.Delete(id)
 	if err != nil {
 		opts.Log.Debug("could not delete poem", "id", id, "error", err)
-		return string(id), http.StatusNotFound, ErrNotFound
+		return http.StatusNotFound, ErrNotFound
 	}
 
-	return string(id), http.StatusOK, nil
+	return http.StatusNoContent, nil
 }
 
 func (s *Store) mock() {
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestByID(t *testing.T) {
+	ctrl := PoemController{store: NewStore()}
+
+	poem, code, err := gwutest.CallExec(context.Background(), ctrl.ByID, ID("1234567890"))
+	if err != nil || code != http.StatusOK || poem.Name != "The Raven" {
+		t.Errorf("ByID %q, %d, %v, want The Raven", poem.Name, code, err)
+	}
+}
+
+func TestByIDNotFound(t *testing.T) {
+	ctrl := PoemController{store: NewStore()}
+	log := gwutest.Logger()
+	ctx := gwu.ContextWithLogger(context.Background(), log)
+
+	_, code, err := gwutest.CallExec(ctx, ctrl.ByID, ID("unknown"))
+	if code != http.StatusNotFound || !errors.Is(err, ErrNotFound) {
+		t.Errorf("ByID %d, %v, want 404 and ErrNotFound", code, err)
+	}
+
+	// The internal error is logged, not returned to the client.
+	log.AssertLogged(t, slog.LevelDebug, "non-existent poem", "id", "unknown")
+	if errors.Is(err, errNotFound) {
+		t.Error("ByID returned the internal error")
+	}
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	ctrl := PoemController{store: NewStore()}
+	opts := gwutest.Opts()
+
+	code, err := ctrl.Delete(context.Background(), "unknown", opts)
+	if code != http.StatusNotFound || !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete %d, %v, want 404 and ErrNotFound", code, err)
+	}
+
+	gwutest.Captured(opts).AssertLogged(t, slog.LevelDebug, "could not delete poem", "id", "unknown",
+		"error", errNotFound)
+}
+
+func TestPoemAPI(t *testing.T) {
+	c := gwutest.Server(t, Routes(&PoemController{store: NewStore()}))
+
+	created, resp, err := gwutest.Post[Poem, Poem](c, "/poem", Poem{Name: "Ode", Author: "John Keats", Text: "Thou"})
+	if err != nil || resp.StatusCode != http.StatusCreated || created.ID == "" {
+		t.Fatalf("Post %+v, %v, want the created poem", created, err)
+	}
+
+	if loc := resp.Header.Get("Location"); loc != "/poem/"+string(created.ID) {
+		t.Errorf("Location %q, want the path of the poem", loc)
+	}
+
+	got, _, err := gwutest.Get[Poem](c, "/poem/"+string(created.ID))
+	if err != nil || got != created {
+		t.Errorf("Get %+v, %v, want %+v", got, err, created)
+	}
+
+	poems, _, err := gwutest.Get[[]Poem](c, "/poems/author/Goethe")
+	if err != nil || len(poems) != 2 {
+		t.Errorf("Get %d poems of Goethe, %v, want 2", len(poems), err)
+	}
+
+	if _, _, err := gwutest.Delete[gwu.NoBody](c, "/poem/"+string(created.ID)); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+
+	var httpErr *gwutest.HTTPError
+	_, _, err = gwutest.Get[Poem](c, "/poem/"+string(created.ID))
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusNotFound || httpErr.Message != ErrNotFound.Error() {
+		t.Errorf("Get of the deleted poem: %v, want 404 and the message of ErrNotFound", err)
+	}
+
+	_, _, err = gwutest.Post[Poem, Poem](c, "/poem", Poem{Name: "Ode", Author: "John Keats"})
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusBadRequest ||
+		!strings.Contains(httpErr.Message, "text required") {
+		t.Errorf("Post without text: %v, want 400 and the missing text", err)
+	}
+}
+
+func TestPoemRoutes(t *testing.T) {
+	store := NewStore()
+	log := gwutest.Logger()
+	rt := Routes(&PoemController{store: store}, gwu.Log(log))
+
+	seed := func(t *testing.T) {
+		if err := store.Add(Poem{ID: "seeded0001", Name: "Ode", Author: "John Keats", Text: "Thou"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gwutest.Run(t, rt, []gwutest.Case{
+		{Name: "by id", Method: http.MethodGet, Path: "/poem/1234567890", WantStatus: http.StatusOK,
+			WantBody: gwutest.BodyContains(`"name":"The Raven"`)},
+		{Name: "unknown id", Method: http.MethodGet, Path: "/poem/unknown", WantStatus: http.StatusNotFound,
+			WantBody: gwutest.BodyContains(ErrNotFound.Error()), Log: log,
+			WantLog: []gwutest.WantLog{{Level: slog.LevelDebug, Msg: "requested non-existent poem"}}},
+		{Name: "by author", Method: http.MethodGet, Path: "/poems/author/Robert%20Frost", WantStatus: http.StatusOK,
+			WantBody: []Poem{store.poems["abc123defx"]}},
+		{Name: "unknown author", Method: http.MethodGet, Path: "/poems/author/Homer", WantStatus: http.StatusNotFound,
+			WantBody: gwutest.BodyContains(ErrAuthorNotFound.Error()), Log: log,
+			WantLog: []gwutest.WantLog{{Level: slog.LevelDebug, Msg: "no poems found for author"}}},
+		{Name: "create without author", Method: http.MethodPost, Path: "/poem", Body: Poem{Name: "Ode", Text: "Thou"},
+			WantStatus: http.StatusBadRequest, WantBody: gwutest.BodyContains("author required")},
+		{Name: "create", Method: http.MethodPost, Path: "/poem",
+			Body: Poem{Name: "Ode", Author: "John Keats", Text: "Thou"}, WantStatus: http.StatusCreated,
+			WantBody: func(b []byte) error {
+				if !strings.Contains(string(b), `"author":"John Keats"`) {
+					return errors.New("missing the author")
+				}
+
+				return nil
+			}},
+		{Name: "delete", Method: http.MethodDelete, Path: "/poem/seeded0001", Setup: seed,
+			WantStatus: http.StatusNoContent},
+		{Name: "delete unknown", Method: http.MethodDelete, Path: "/poem/unknown", WantStatus: http.StatusNotFound,
+			Log: log, WantLog: []gwutest.WantLog{{Level: slog.LevelDebug, Msg: "could not delete poem"}}},
+	})
+}
@@ -0,0 +1,84 @@
+package gwu
+
+import (
+	"context"
+	"net/http"
+)
+
+// ExecE executes the endpoint logic like an Exec, but without status code, see HandleE.
+//
+// Important: Return only safe to display errors, Handle writes an ExecE function's error to the response.
+type ExecE[In, Out any] func(context.Context, In, HandleOpts) (Out, error)
+
+// Exec adapts the ExecE to an Exec deriving the status code, see HandleE. It returns errors with status code 0, so
+// Handle derives their status code. Use it to register an ExecE with the method helpers, like Post. A Spec cannot
+// tell the adapted ExecE from an Exec and documents no http.StatusCreated for CreatedOnPost, register the ExecE with
+// HandleRouteE instead.
+//
+// Example usage:
+//
+//	gwu.Put(rt, "/poems/{id}", gwu.JSON[Poem](), gwu.ExecE[Poem, Poem](ctrl.Replace).Exec())
+func (fn ExecE[In, Out]) Exec() Exec[In, Out] {
+	return func(ctx context.Context, in In, opts HandleOpts) (Out, int, error) {
+		out, err := fn(ctx, in, opts)
+		if err != nil {
+			return out, 0, err
+		}
+
+		if opts.createdOnPost && opts.req.r != nil && opts.req.r.Method == http.MethodPost {
+			return out, http.StatusCreated, nil
+		}
+
+		return out, http.StatusOK, nil
+	}
+}
+
+// HandleE works like Handle with an ExecE, which returns no status code. The status code is derived:
+//   - the status code of a StatusError the ExecE returns, see WithStatus,
+//   - the status code of a registered error type, see RegisterError,
+//   - http.StatusInternalServerError for other errors,
+//   - http.StatusCreated for POST requests with CreatedOnPost,
+//   - http.StatusOK otherwise.
+//
+// HandleE adapts the ExecE to an Exec, so all options and CnIns, like ValCnIn, compose with it like with Handle.
+//
+// Example usage:
+//
+//	h := gwu.HandleE(gwu.PathVal("id"), func(ctx context.Context, id string, _ gwu.HandleOpts) (Poem, error) {
+//		poem, ok := repo.Poem(ctx, id)
+//		if !ok {
+//			return Poem{}, gwu.WithStatus(http.StatusNotFound, ErrPoemNotFound)
+//		}
+//
+//		return poem, nil
+//	})
+func HandleE[In, Out any](inFn CnIn[In], fn ExecE[In, Out], optFns ...HandleOptsFunc) http.Handler {
+	return Handle(inFn, fn.Exec(), withDerivedStatus(optFns)...)
+}
+
+// HandleRouteE works like HandleRoute with an ExecE, see HandleE. A Spec documents http.StatusCreated for its POST
+// routes with CreatedOnPost.
+//
+// Example usage:
+//
+//	gwu.HandleRouteE(rt, "POST /poems", gwu.JSON[NewPoem](), ctrl.Create, gwu.CreatedOnPost())
+func HandleRouteE[In, Out any](mux Mux, pattern string, inFn CnIn[In], fn ExecE[In, Out],
+	optFns ...HandleOptsFunc) {
+	HandleRoute(mux, pattern, inFn, fn.Exec(), withDerivedStatus(optFns)...)
+}
+
+// withDerivedStatus appends the option marking the handler's Exec as an adapted ExecE to the options.
+func withDerivedStatus(opts []HandleOptsFunc) []HandleOptsFunc {
+	return append(opts[:len(opts):len(opts)], func(opt *HandleOpts) {
+		opt.derivedStatus = true
+	})
+}
+
+// CreatedOnPost makes an ExecE respond to successful POST requests with http.StatusCreated instead of
+// http.StatusOK, see HandleE. It does not affect an Exec, which returns its status code itself, and a Spec documents
+// http.StatusCreated only for the routes of HandleRouteE.
+func CreatedOnPost() HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.createdOnPost = true
+	}
+}
@@ -0,0 +1,69 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func createPoem(_ context.Context, in smallPoem, _ gwu.HandleOpts) (smallPoem, error) {
+	return in, nil
+}
+
+// TestCreatedOnPostSpec documents http.StatusCreated only for the routes CreatedOnPost responds to with it.
+func TestCreatedOnPostSpec(t *testing.T) {
+	spec := gwu.NewSpec("poems", "1.0.0")
+	rt := gwu.NewRouter(gwu.Collect(spec), gwu.CreatedOnPost())
+	gwu.HandleRouteE(rt, "POST /poems", gwu.JSON[smallPoem](), createPoem)
+	gwu.HandleRouteE(rt, "PUT /poems/{id}", gwu.JSON[smallPoem](), createPoem)
+	gwu.HandleRoute(rt, "POST /drafts", gwu.JSON[smallPoem](), echoSmallPoem)
+
+	b, err := spec.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Responses map[string]any `json:"responses"`
+		} `json:"paths"`
+	}
+
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path, method, want string
+	}{
+		{"/poems", "post", "201"},
+		{"/poems/{id}", "put", "200"},
+		{"/drafts", "post", "200"},
+	}
+
+	for _, tt := range tests {
+		responses := doc.Paths[tt.path][tt.method].Responses
+		if _, ok := responses[tt.want]; !ok {
+			t.Errorf("%s %s: responses %v, want %s", tt.method, tt.path, responses, tt.want)
+		}
+	}
+
+	// The routes respond like they are documented.
+	for _, tt := range tests {
+		_, resp := gwutest.Do[smallPoem, smallPoem](t, rt, strings.ToUpper(tt.method), tt.path,
+			smallPoem{ID: 1, Title: "Ode"}, gwutest.PathValue("id", "1"))
+		if got := strconv.Itoa(resp.StatusCode); got != tt.want {
+			t.Errorf("%s %s: status %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func echoSmallPoem(_ context.Context, in smallPoem, _ gwu.HandleOpts) (smallPoem, int, error) {
+	return in, http.StatusOK, nil
+}
@@ -0,0 +1,195 @@
+package gwu
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// FieldError is the validation error of a field of the input, for validation functions of ValCnIn returning an
+// error per field, joined with errors.Join. The MessageKey and Params identify the message independent of the
+// language, like "too_long" with {"max": 80}, see TranslateFields to translate it.
+//
+// JSONError lists the field errors of an error in the fields of the ErrorBody, with their keys and params for
+// programmatic clients.
+//
+// Example usage:
+//
+//	func validatePoem(p Poem) error {
+//		var errs []error
+//		if len(p.Title) > 80 {
+//			errs = append(errs, &gwu.FieldError{Field: "title", MessageKey: "too_long", Params: map[string]any{"max": 80}})
+//		}
+//
+//		return errors.Join(errs...)
+//	}
+type FieldError struct {
+	Field      string         `json:"field"`
+	MessageKey string         `json:"key,omitempty"`
+	Params     map[string]any `json:"params,omitempty"`
+	// Message is the message for the client, TranslateFields sets it. Without a message, the key is the message.
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.message()
+}
+
+// message returns the Message, or the MessageKey if there is none.
+func (e *FieldError) message() string {
+	if e.Message == "" {
+		return e.MessageKey
+	}
+
+	return e.Message
+}
+
+// Translator translates the message with the key and params to the language, see TranslateFields. It returns ""
+// if there is no translation.
+type Translator func(lang, key string, params map[string]any) string
+
+// TranslateFields translates the messages of the field errors of error responses before they are written, see
+// FieldError. The language is the preferred language of the request's Accept-Language header, "" if there is none.
+// Field errors without key or translation keep their Message, or the key if there is none.
+//
+// Example usage:
+//
+//	api := rt.Group("/api", gwu.TranslateFields(func(lang, key string, params map[string]any) string {
+//		return catalog.Sprintf(lang, key, params)
+//	}))
+func TranslateFields(fn Translator) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.translate = fn
+	}
+}
+
+// translateFields returns err with translated copies of its field errors, the field errors of err are not changed.
+// It returns err if it has no field errors.
+func (o HandleOpts) translateFields(r *http.Request, err error) error {
+	fields := fieldErrors(err)
+	if len(fields) == 0 {
+		return err
+	}
+
+	lang := preferredLanguage(r)
+	copies := make(map[*FieldError]*FieldError, len(fields))
+	t := &translatedError{err: err, fields: make([]*FieldError, 0, len(fields))}
+	for _, fe := range fields {
+		c := *fe
+		if c.MessageKey != "" {
+			if msg := o.translate(lang, c.MessageKey, c.Params); msg != "" {
+				c.Message = msg
+			}
+		}
+
+		copies[fe] = &c
+		t.fields = append(t.fields, &c)
+	}
+
+	t.msg = translatedText(err, copies)
+
+	return t
+}
+
+// translatedError is an error with translated copies of the field errors of err, see TranslateFields. The field
+// errors of err may be shared between requests, so they are never changed.
+type translatedError struct {
+	err    error
+	fields []*FieldError
+	msg    string
+}
+
+func (e *translatedError) Error() string {
+	return e.msg
+}
+
+func (e *translatedError) Unwrap() error {
+	return e.err
+}
+
+// As sets a *FieldError target to the first translated field error, other targets are matched against err.
+func (e *translatedError) As(target any) bool {
+	if fe, ok := target.(**FieldError); ok {
+		*fe = e.fields[0]
+		return true
+	}
+
+	return false
+}
+
+// translatedText returns the message of err with the messages of the copies of its field errors. Wrapping errors
+// with the message of the error they wrap, and errors joined like by errors.Join, are rebuilt, other errors keep
+// their message.
+func translatedText(err error, copies map[*FieldError]*FieldError) string {
+	switch e := err.(type) {
+	case *FieldError:
+		return copies[e].Error()
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil && err.Error() == inner.Error() {
+			return translatedText(inner, copies)
+		}
+	case interface{ Unwrap() []error }:
+		errs := e.Unwrap()
+		msgs := make([]string, len(errs))
+		texts := make([]string, len(errs))
+		for i, inner := range errs {
+			msgs[i] = inner.Error()
+			texts[i] = translatedText(inner, copies)
+		}
+
+		if err.Error() == strings.Join(msgs, "\n") {
+			return strings.Join(texts, "\n")
+		}
+	}
+
+	return err.Error()
+}
+
+// fieldErrors returns the field errors in the tree of err.
+func fieldErrors(err error) []*FieldError {
+	var fields []*FieldError
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *translatedError:
+			fields = append(fields, e.fields...)
+		case *FieldError:
+			fields = append(fields, e)
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		}
+	}
+
+	walk(err)
+
+	return fields
+}
+
+// preferredLanguage returns the language tag with the highest quality in the Accept-Language header, the first of
+// equal ones.
+func preferredLanguage(r *http.Request) string {
+	lang, best := "", 0.0
+	for _, accept := range r.Header.Values("Accept-Language") {
+		for _, s := range strings.Split(accept, ",") {
+			tag, params, _ := strings.Cut(strings.TrimSpace(s), ";")
+			q := 1.0
+			if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				var err error
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+
+			if tag != "" && tag != "*" && q > best {
+				lang, best = tag, q
+			}
+		}
+	}
+
+	return lang
+}
@@ -0,0 +1,63 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+var errTitleTooLong = &gwu.FieldError{Field: "title", MessageKey: "too_long", Params: map[string]any{"max": 80}}
+
+func TestTranslateFieldsSharedFieldError(t *testing.T) {
+	translations := map[string]string{"en": "is too long", "de": "ist zu lang"}
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		return gwu.NoBody{}, http.StatusBadRequest, &gwu.ValidationError{Err: errors.Join(errTitleTooLong)}
+	}, gwu.Errors(gwu.JSONError), gwu.TranslateFields(func(lang, key string, _ map[string]any) string {
+		return translations[lang]
+	}))
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		lang := []string{"en", "de"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			body, _ := gwutest.Do[any, gwu.ErrorBody](t, h, http.MethodGet, "/", nil,
+				gwutest.Header("Accept-Language", lang), gwutest.Status(http.StatusBadRequest))
+			if len(body.Fields) != 1 || body.Fields[0].Message != translations[lang] {
+				t.Errorf("lang %s: fields = %+v, want message %q", lang, body.Fields, translations[lang])
+			}
+
+			if want := "title: " + translations[lang]; body.Error != want {
+				t.Errorf("lang %s: error = %q, want %q", lang, body.Error, want)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if errTitleTooLong.Message != "" {
+		t.Errorf("shared FieldError changed, message = %q", errTitleTooLong.Message)
+	}
+}
+
+func TestJSONErrorSharedFieldError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gwu.JSONError(rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.Join(errTitleTooLong), http.StatusBadRequest)
+
+	if !strings.Contains(rec.Body.String(), `"message":"too_long"`) {
+		t.Errorf("body = %s, want the key as message", rec.Body)
+	}
+
+	if errTitleTooLong.Message != "" {
+		t.Errorf("shared FieldError changed, message = %q", errTitleTooLong.Message)
+	}
+}
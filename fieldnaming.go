@@ -0,0 +1,468 @@
+package gwu
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// fieldNaming is the configuration of FieldNaming, it caches the names of the fields of every struct type.
+type fieldNaming struct {
+	convert func(goName string) string
+	plans   sync.Map
+	// renamed caches whether the JSON of a type has renamed keys, see renames.
+	renamed sync.Map
+}
+
+// FieldNaming names the JSON fields of struct fields without json tag name with convert, instead of their Go
+// names, like with SnakeCase for snake_case JSON. Fields with a json tag name keep it, only untagged fields and
+// fields with only tag options, like `json:",omitempty"`, are renamed.
+//
+// FieldNaming applies to everything the handler's JSONCodec encodes and decodes: the JSON CnIn, responses, Streams,
+// WebSocket messages, and RPC params and results. The codec encodes and decodes the values as usual, FieldNaming
+// renames the keys of the JSON of struct types by the names derived once per type. Keys of maps, and of values of
+// types with their own MarshalJSON or UnmarshalJSON, are kept. The Spec documents the fields with their names, a
+// type of routes with different FieldNaming is documented as a component per naming, like Poem and Poem2.
+//
+// Set it on a Router for all of its routes, FieldNaming(nil) restores the Go names for a route.
+//
+// Example usage:
+//
+//	type Poem struct {
+//		PoemID   string
+//		Title    string `json:"headline"`
+//		LineTags []string
+//	}
+//
+//	// Encodes {"poem_id":"1","headline":"The Raven","line_tags":["gothic"]}
+//	rt := gwu.NewRouter(gwu.FieldNaming(gwu.SnakeCase))
+func FieldNaming(convert func(goName string) string) HandleOptsFunc {
+	var n *fieldNaming
+	if convert != nil {
+		n = &fieldNaming{convert: convert}
+	}
+
+	return func(opt *HandleOpts) {
+		opt.fieldNaming = n
+	}
+}
+
+// SnakeCase converts a Go name to snake_case, like PoemID to poem_id, HTTPServer to http_server, and PoemIDs to
+// poem_ids, see FieldNaming.
+func SnakeCase(goName string) string {
+	runes := []rune(goName)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			acronymEnd := i > 0 && unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// The plural of an acronym, like IDs, ends it.
+			if acronymEnd && runes[i+1] == 's' && (i+2 == len(runes) || unicode.IsUpper(runes[i+2])) {
+				acronymEnd = false
+			}
+
+			if prevLower || acronymEnd {
+				b.WriteByte('_')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// name returns the JSON name of the field, see FieldNaming. A nil fieldNaming keeps the names of encoding/json.
+func (n *fieldNaming) name(f jsonField) string {
+	if n == nil || f.tagged {
+		return f.name
+	}
+
+	return n.convert(f.goName)
+}
+
+// namedField is a field of a namingPlan, with the quoted JSON name to rename its key to, nil if the key is kept, and
+// its type.
+type namedField struct {
+	key []byte
+	typ reflect.Type
+}
+
+// namingPlan are the fields of a struct type keyed by the names encoding/json encodes them with, enc, and by the
+// names FieldNaming names them, dec.
+type namingPlan struct {
+	enc, dec map[string]namedField
+}
+
+// plan returns the namingPlan of the struct type.
+func (n *fieldNaming) plan(t reflect.Type) *namingPlan {
+	if v, ok := n.plans.Load(t); ok {
+		return v.(*namingPlan)
+	}
+
+	p := &namingPlan{enc: make(map[string]namedField), dec: make(map[string]namedField)}
+	for _, f := range jsonFields(t) {
+		name := n.name(f)
+		if name == f.name {
+			p.enc[f.name] = namedField{typ: f.typ}
+			p.dec[name] = namedField{typ: f.typ}
+			continue
+		}
+
+		encKey, _ := json.Marshal(name)
+		decKey, _ := json.Marshal(f.name)
+		p.enc[f.name] = namedField{key: encKey, typ: f.typ}
+		p.dec[name] = namedField{key: decKey, typ: f.typ}
+	}
+
+	v, _ := n.plans.LoadOrStore(t, p)
+	return v.(*namingPlan)
+}
+
+// renames reports whether FieldNaming renames a key of the JSON of a value of type t, so the JSON of other types is
+// not scanned. The result is cached per type.
+func (n *fieldNaming) renames(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	if v, ok := n.renamed.Load(t); ok {
+		return v.(bool)
+	}
+
+	seen := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type) bool
+	walk = func(t reflect.Type) bool {
+		t = namingType(t)
+		if t == nil || seen[t] {
+			return false
+		}
+
+		seen[t] = true
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return walk(t.Elem())
+		case reflect.Struct:
+			for _, f := range n.plan(t).enc {
+				if f.key != nil || walk(f.typ) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	v, _ := n.renamed.LoadOrStore(t, walk(t))
+	return v.(bool)
+}
+
+// signature returns the names of the fields of t and of the types of its fields, so the Spec documents a type once
+// per naming of its fields. It is empty if FieldNaming renames no key of the JSON of t.
+func (n *fieldNaming) signature(t reflect.Type) string {
+	if n == nil || !n.renames(t) {
+		return ""
+	}
+
+	var b strings.Builder
+	seen := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		t = namingType(t)
+		if t == nil || seen[t] {
+			return
+		}
+
+		seen[t] = true
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			walk(t.Elem())
+		case reflect.Struct:
+			for _, f := range jsonFields(t) {
+				b.WriteString(n.name(f) + ",")
+				walk(f.typ)
+			}
+		}
+	}
+
+	walk(t)
+	return b.String()
+}
+
+// jsonMethodTypes are the interfaces of types that encode or decode themselves.
+var jsonMethodTypes = []reflect.Type{
+	jsonMarshalerType,
+	reflect.TypeFor[json.Unmarshaler](),
+	textMarshalerType,
+	reflect.TypeFor[encoding.TextUnmarshaler](),
+}
+
+// namingType returns the type whose fields or elements make up the JSON of t, or nil if FieldNaming keeps the keys of
+// its JSON, like for interfaces and types with their own JSON methods.
+func namingType(t reflect.Type) reflect.Type {
+	for t != nil {
+		switch {
+		case t.Kind() == reflect.Pointer:
+			t = t.Elem()
+		case optElem(t) != nil:
+			t = optElem(t)
+		case t.Kind() == reflect.Interface:
+			return nil
+		default:
+			p := reflect.PointerTo(t)
+			for _, m := range jsonMethodTypes {
+				if t.Implements(m) || p.Implements(m) {
+					return nil
+				}
+			}
+
+			return t
+		}
+	}
+
+	return nil
+}
+
+// maxNamingDepth is the nesting depth of JSON above which FieldNaming keeps the keys, like the limit of
+// encoding/json, which rejects such JSON.
+const maxNamingDepth = 10000
+
+// rename renames the keys of the JSON encoded from or decoded into a value of type t, from the names of
+// encoding/json to the FieldNaming names if encode is true, and back otherwise. It returns malformed JSON as is, for
+// the codec to report.
+func (n *fieldNaming) rename(data []byte, t reflect.Type, encode bool) []byte {
+	if !n.renames(t) {
+		return data
+	}
+
+	r := renamer{naming: n, data: data, encode: encode}
+	if !r.value(t, 0) || r.out == nil {
+		return data
+	}
+
+	return append(r.out, data[r.last:]...)
+}
+
+// renamer scans JSON along a Go type and copies it to out with the keys of struct fields renamed. The data from last
+// on is not copied yet.
+type renamer struct {
+	naming  *fieldNaming
+	data    []byte
+	out     []byte
+	i, last int
+	encode  bool
+}
+
+// value scans the JSON value at the offset, of type t, and reports whether it is well-formed.
+func (r *renamer) value(t reflect.Type, depth int) bool {
+	r.space()
+	if r.i >= len(r.data) || depth > maxNamingDepth {
+		return false
+	}
+
+	switch r.data[r.i] {
+	case '{':
+		return r.object(namingType(t), depth+1)
+	case '[':
+		return r.array(namingType(t), depth+1)
+	case '"':
+		_, ok := r.str()
+		return ok
+	default:
+		start := r.i
+		for r.i < len(r.data) && !strings.ContainsRune(",:]} \t\r\n", rune(r.data[r.i])) {
+			r.i++
+		}
+
+		return r.i > start
+	}
+}
+
+// object scans the JSON object at the offset, of type t from namingType, and renames the keys of struct fields.
+func (r *renamer) object(t reflect.Type, depth int) bool {
+	var fields map[string]namedField
+	if t != nil && t.Kind() == reflect.Struct {
+		fields = r.naming.plan(t).dec
+		if r.encode {
+			fields = r.naming.plan(t).enc
+		}
+	}
+
+	r.i++
+	if r.space(); r.next('}') {
+		return true
+	}
+
+	for {
+		r.space()
+		start := r.i
+		key, ok := r.str()
+		if !ok {
+			return false
+		}
+
+		var next reflect.Type
+		switch {
+		case t == nil:
+		case t.Kind() == reflect.Map:
+			next = t.Elem()
+		case fields != nil:
+			f := fields[string(key)]
+			if bytes.IndexByte(key, '\\') >= 0 {
+				var name string
+				_ = json.Unmarshal(r.data[start:r.i], &name)
+				f = fields[name]
+			}
+
+			next = f.typ
+			if f.key != nil {
+				r.out = append(r.out, r.data[r.last:start]...)
+				r.out = append(r.out, f.key...)
+				r.last = r.i
+			}
+		}
+
+		if r.space(); !r.next(':') || !r.value(next, depth) {
+			return false
+		}
+
+		if r.space(); r.next('}') {
+			return true
+		}
+
+		if !r.next(',') {
+			return false
+		}
+	}
+}
+
+// array scans the JSON array at the offset, of type t from namingType.
+func (r *renamer) array(t reflect.Type, depth int) bool {
+	var elem reflect.Type
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		elem = t.Elem()
+	}
+
+	r.i++
+	if r.space(); r.next(']') {
+		return true
+	}
+
+	for {
+		if !r.value(elem, depth) {
+			return false
+		}
+
+		if r.space(); r.next(']') {
+			return true
+		}
+
+		if !r.next(',') {
+			return false
+		}
+	}
+}
+
+// str scans the JSON string at the offset and returns its content, still escaped.
+func (r *renamer) str() ([]byte, bool) {
+	if !r.next('"') {
+		return nil, false
+	}
+
+	start := r.i
+	for r.i < len(r.data) {
+		switch r.data[r.i] {
+		case '\\':
+			r.i += 2
+		case '"':
+			r.i++
+			return r.data[start : r.i-1], true
+		default:
+			r.i++
+		}
+	}
+
+	return nil, false
+}
+
+// next skips the byte c at the offset, it reports false if there is another byte.
+func (r *renamer) next(c byte) bool {
+	if r.i < len(r.data) && r.data[r.i] == c {
+		r.i++
+		return true
+	}
+
+	return false
+}
+
+// space skips the whitespace at the offset.
+func (r *renamer) space() {
+	for r.i < len(r.data) && strings.IndexByte(" \t\r\n", r.data[r.i]) >= 0 {
+		r.i++
+	}
+}
+
+// namingCodec is the JSONCodec of a handler with FieldNaming, it renames the keys of the JSON of its codec.
+type namingCodec struct {
+	codec  JSONCodec
+	naming *fieldNaming
+}
+
+func (c namingCodec) Marshal(v any) ([]byte, error) {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.naming.rename(data, reflect.TypeOf(v), true), nil
+}
+
+func (c namingCodec) Unmarshal(data []byte, v any) error {
+	return c.codec.Unmarshal(c.naming.rename(data, reflect.TypeOf(v), false), v)
+}
+
+func (c namingCodec) NewEncoder(w io.Writer) JSONEncoder {
+	return namingEncoder{c: c, w: w}
+}
+
+func (c namingCodec) NewDecoder(r io.Reader) JSONDecoder {
+	return namingDecoder{c: c, dec: c.codec.NewDecoder(r)}
+}
+
+// namingEncoder is the JSONEncoder of a namingCodec.
+type namingEncoder struct {
+	c namingCodec
+	w io.Writer
+}
+
+func (e namingEncoder) Encode(v any) error {
+	data, err := e.c.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(append(data, '\n'))
+	return err
+}
+
+// namingDecoder is the JSONDecoder of a namingCodec, it reads every value before renaming its keys.
+type namingDecoder struct {
+	c   namingCodec
+	dec JSONDecoder
+}
+
+func (d namingDecoder) Decode(v any) error {
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	return d.c.Unmarshal(raw, v)
+}
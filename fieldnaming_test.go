@@ -0,0 +1,168 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+)
+
+type namedMeta struct {
+	CreatedBy string
+}
+
+type namedLine struct {
+	LineNo int
+	Text   string `json:"text"`
+	Tags   []string
+}
+
+// namedPoem mixes tagged and untagged fields, FieldNaming renames the untagged ones.
+type namedPoem struct {
+	namedMeta
+	PoemID    string
+	Title     string `json:"headline"`
+	Lines     []namedLine
+	Stanzas   [][]namedLine
+	ByAuthor  map[string]namedLine
+	Note      *string `json:",omitempty"`
+	Extra     any
+	Published time.Time
+}
+
+func echoNamedPoem(_ context.Context, in namedPoem, _ gwu.HandleOpts) (namedPoem, int, error) {
+	return in, http.StatusOK, nil
+}
+
+func TestFieldNamingRoundTrip(t *testing.T) {
+	// Map keys, values of an any, and the JSON of a time.Time are kept.
+	body := `{
+		"created_by": "poe",
+		"poem_id": "1",
+		"headline": "The Raven",
+		"lines": [{"line_no": 1, "text": "Once upon a midnight dreary", "tags": ["gothic"]}],
+		"stanzas": [[{"line_no": 2, "text": "while I pondered, weak and weary", "tags": null}]],
+		"by_author": {"EdgarPoe": {"line_no": 3, "text": "Nevermore", "tags": []}},
+		"note": "first \"stanza\"",
+		"extra": {"KeepMe": [1, 2]},
+		"published": "1845-01-29T00:00:00Z"
+	}`
+
+	var got namedPoem
+	exec := func(ctx context.Context, in namedPoem, opts gwu.HandleOpts) (namedPoem, int, error) {
+		got = in
+		return echoNamedPoem(ctx, in, opts)
+	}
+
+	h := gwu.Handle(gwu.JSON[namedPoem](), exec, gwu.FieldNaming(gwu.SnakeCase))
+	r := httptest.NewRequest(http.MethodPost, "/poems", strings.NewReader(body))
+	r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	if got.CreatedBy != "poe" || got.PoemID != "1" || got.Stanzas[0][0].LineNo != 2 ||
+		got.ByAuthor["EdgarPoe"].LineNo != 3 {
+		t.Errorf("decoded %+v, want the fields of the snake_case JSON", got)
+	}
+
+	assertJSON(t, json.RawMessage(rec.Body.Bytes()), body)
+}
+
+func TestFieldNamingTagsWin(t *testing.T) {
+	type tagged struct {
+		PoemID string `json:"PoemID"`
+		Title  string `json:",omitempty"`
+	}
+
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (tagged, int, error) {
+		return tagged{PoemID: "1", Title: "Ode"}, http.StatusOK, nil
+	}, gwu.FieldNaming(gwu.SnakeCase))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertJSON(t, json.RawMessage(rec.Body.Bytes()), `{"PoemID": "1", "title": "Ode"}`)
+}
+
+func TestFieldNamingMalformed(t *testing.T) {
+	h := gwu.Handle(gwu.JSON[namedPoem](), echoNamedPoem, gwu.FieldNaming(gwu.SnakeCase))
+	for _, body := range []string{`{"poem_id": "1"`, `{"poem_id" "1"}`, `[{"lines": [}]`} {
+		r := httptest.NewRequest(http.MethodPost, "/poems", strings.NewReader(body))
+		r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", body, rec.Code)
+		}
+	}
+}
+
+// TestFieldNamingAllocs checks that FieldNaming costs nothing for types without renamed fields, like smallPoem with
+// its json tags.
+func TestFieldNamingAllocs(t *testing.T) {
+	skipAllocsWithRace(t)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	want := allocsPerRequest(gwu.Handle(gwu.Empty(), getSmallPoem), r)
+	if got := allocsPerRequest(gwu.Handle(gwu.Empty(), getSmallPoem, gwu.FieldNaming(gwu.SnakeCase)), r); got != want {
+		t.Errorf("%v allocs per request with FieldNaming, want %v like without", got, want)
+	}
+}
+
+func TestFieldNamingSpec(t *testing.T) {
+	spec := gwu.NewSpec("poems", "1.0.0")
+	rt := gwu.NewRouter(gwu.Collect(spec))
+	gwu.HandleRoute(rt, "POST /v2/poems", gwu.JSON[namedPoem](), echoNamedPoem, gwu.FieldNaming(gwu.SnakeCase))
+	gwu.HandleRoute(rt, "POST /v1/poems", gwu.JSON[namedPoem](), echoNamedPoem)
+	gwu.HandleRoute(rt, "POST /v3/poems", gwu.JSON[namedPoem](), echoNamedPoem, gwu.FieldNaming(gwu.SnakeCase))
+
+	b, err := spec.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			RequestBody struct {
+				Content map[string]struct {
+					Schema gwu.Schema `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]gwu.Schema `json:"schemas"`
+		} `json:"components"`
+	}
+
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// The snake_case routes share a component, the route with Go names has its own.
+	names := map[string]string{}
+	for path, field := range map[string]string{"/v1/poems": "PoemID", "/v2/poems": "poem_id", "/v3/poems": "poem_id"} {
+		ref := doc.Paths[path]["post"].RequestBody.Content[gwu.ContentTypeJSON].Schema.Ref
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		if _, ok := doc.Components.Schemas[name].Properties[field]; !ok {
+			t.Errorf("%s: component %q without property %s", path, name, field)
+		}
+
+		names[path] = name
+	}
+
+	if names["/v2/poems"] != names["/v3/poems"] || names["/v1/poems"] == names["/v2/poems"] {
+		t.Errorf("components %v, want one per naming", names)
+	}
+
+	if len(doc.Components.Schemas) != 4 {
+		t.Errorf("%d components, want namedPoem and namedLine per naming", len(doc.Components.Schemas))
+	}
+}
@@ -0,0 +1,66 @@
+package gwu
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// File is an Out value served with http.ServeContent instead of as JSON. Handle answers range requests with
+// http.StatusPartialContent and the Content-Range of the byte range, invalid ranges with
+// http.StatusRequestedRangeNotSatisfiable, and sets `Accept-Ranges: bytes` on full responses.
+//
+// The If-Range, If-Match, If-None-Match, and If-Modified-Since headers are evaluated against the ETag and ModTime,
+// a range request with an If-Range that does not match gets the full content. Handle ignores the status code the
+// Exec returns with a File, unless it returns an error, and closes the Content if it implements io.Closer. A File
+// without Content is a server error, Handle logs it and responds with ErrEncodeResponse and
+// http.StatusInternalServerError.
+//
+// Example usage:
+//
+//	func (c *Controller) Download(_ context.Context, id string, _ gwu.HandleOpts) (gwu.File, int, error) {
+//		f, info, err := c.store.Open(id)
+//		if err != nil {
+//			return gwu.File{}, http.StatusNotFound, ErrNotFound
+//		}
+//
+//		return gwu.File{Name: info.Name, ModTime: info.Updated, ETag: `"` + info.Hash + `"`, Content: f}, 0, nil
+//	}
+type File struct {
+	// Name is the file name, Handle derives the Content-Type from its extension if ContentType is empty.
+	Name string
+	// ContentType sets the Content-Type, if empty, Handle derives it from the Name or the content.
+	ContentType string
+	// ModTime sets the Last-Modified header, the zero time sets none.
+	ModTime time.Time
+	// ETag sets the ETag header, it must be quoted, like `"v1"`. A strong ETag enables If-Range with entity tags.
+	ETag    string
+	Content io.ReadSeeker
+}
+
+// errNoFileContent is the error logged for a File without Content.
+var errNoFileContent = errors.New("file without content")
+
+// serve writes the file to the response.
+func (f File) serve(w http.ResponseWriter, r *http.Request, opts HandleOpts) {
+	if f.Content == nil {
+		opts.logEncodeFailure(errNoFileContent)
+		opts.writeError(w, r, ErrEncodeResponse, http.StatusInternalServerError)
+		return
+	}
+
+	if c, ok := f.Content.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	if f.ContentType != "" {
+		w.Header().Set("Content-Type", f.ContentType)
+	}
+
+	if f.ETag != "" {
+		w.Header().Set("ETag", f.ETag)
+	}
+
+	http.ServeContent(w, r, f.Name, f.ModTime, f.Content)
+}
@@ -0,0 +1,42 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestFileWithoutContent(t *testing.T) {
+	log := gwutest.Logger()
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.File, int, error) {
+		return gwu.File{Name: "poem.txt"}, http.StatusOK, nil
+	}, gwu.Log(log))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/poem.txt", nil))
+
+	gwutest.AssertError(t, rec, http.StatusInternalServerError, gwu.ErrEncodeResponse.Error())
+	if !log.Contains("failed to encode response: file without content") {
+		t.Errorf("entries %v, want the missing content logged", log.Entries())
+	}
+}
+
+func TestFileRange(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.File, int, error) {
+		return gwu.File{Name: "poem.txt", Content: strings.NewReader("Once upon a midnight dreary")}, 0, nil
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/poem.txt", nil)
+	r.Header.Set("Range", "bytes=0-3")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "Once" {
+		t.Errorf("status %d body %q, want 206 and Once", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,127 @@
+package gwu_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+type fuzzPoem struct {
+	ID      string         `json:"id"`
+	Title   string         `json:"title"`
+	Lines   []string       `json:"lines"`
+	Rating  float64        `json:"rating"`
+	Year    gwu.Opt[int]   `json:"year"`
+	Authors map[string]int `json:"authors"`
+}
+
+type fuzzForm struct {
+	Title  string        `form:"title,required"`
+	Lines  []string      `form:"line"`
+	Rating float64       `form:"rating"`
+	Year   gwu.Opt[int]  `form:"year"`
+	Draft  *bool         `form:"draft"`
+	Limit  uint8         `form:"limit"`
+	Retry  gwu.Opt[bool] `form:"retry"`
+}
+
+// multipartBoundary is the boundary of the multipart bodies of FuzzMultipart.
+const multipartBoundary = "gwufuzz"
+
+// fuzzBodies fuzzes the CnIn with bodies of the content type, like gwutest.FuzzCnIn does for JSON bodies.
+func fuzzBodies[In any](f *testing.F, inFn gwu.CnIn[In], contentType string, seeds []string) {
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+
+		defer func() {
+			if v := recover(); v != nil {
+				t.Fatalf("CnIn panicked with body %q: %v", body, v)
+			}
+		}()
+
+		_, err := inFn(r, gwutest.Opts())
+		if err != nil && !fuzzSafe(err) {
+			t.Fatalf("CnIn returned an unsafe error with body %q: %v", body, err)
+		}
+	})
+}
+
+// fuzzSafe reports whether err is one of the errors Bind is expected to return for malformed requests.
+func fuzzSafe(err error) bool {
+	var valErr *gwu.ValidationError
+	var statusErr *gwu.StatusError
+	if errors.As(err, &valErr) || errors.As(err, &statusErr) {
+		return true
+	}
+
+	for _, safe := range []error{gwu.ErrDecodeRequest, gwu.ErrRequestTooLarge, gwu.ErrMissingParam, gwu.ErrInvalidParam} {
+		if errors.Is(err, safe) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func FuzzJSON(f *testing.F) {
+	gwutest.FuzzCnIn(f, gwu.JSON[fuzzPoem](), append(gwutest.JSONSeeds,
+		[]byte(`{"year":null,"authors":{"a":1,"b":1e400}}`),
+		[]byte(`{"lines":"not a list","rating":"NaN"}`),
+	))
+}
+
+func FuzzForm(f *testing.F) {
+	fuzzBodies(f, gwu.Bind[fuzzForm](), "application/x-www-form-urlencoded", []string{
+		"title=Ode&line=a&line=b&rating=4.5&year=1819&draft=true&limit=10",
+		"",
+		"title",
+		"title=&&&==",
+		"title=%",
+		"title=%zz",
+		"title=%c3%28",
+		"title=\xff\xfe",
+		"title=Ode&rating=1e999999&year=99999999999999999999",
+		"title=Ode&limit=256&draft=maybe",
+		"title=Ode;line=a",
+		"title=" + strings.Repeat("a", 1<<16),
+		strings.Repeat("line=a&", 10000) + "title=Ode",
+	})
+}
+
+func FuzzMultipart(f *testing.F) {
+	part := func(name, value string) string {
+		return "--" + multipartBoundary + "\r\nContent-Disposition: form-data; name=\"" + name + "\"\r\n\r\n" + value +
+			"\r\n"
+	}
+	end := "--" + multipartBoundary + "--\r\n"
+
+	fuzzBodies(f, gwu.Bind[fuzzForm](), "multipart/form-data; boundary="+multipartBoundary, []string{
+		part("title", "Ode") + part("line", "a") + part("line", "b") + part("year", "1819") + end,
+		end,
+		"",
+		"--" + multipartBoundary,
+		"--" + multipartBoundary + "\r\n",
+		part("title", "Ode"),
+		part("title", "Ode") + "--" + multipartBoundary[:3],
+		part("title", "Ode") + "--" + multipartBoundary + "-",
+		"--" + multipartBoundary + "\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nOde",
+		"--" + multipartBoundary + "\r\nContent-Disposition: form-data\r\n\r\nno name\r\n" + end,
+		"--" + multipartBoundary + "\r\nContent-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\r\n\r\n" +
+			"content\r\n" + part("title", "Ode") + end,
+		"--" + multipartBoundary + "\r\nContent-Disposition: form-data; name=\"title\r\n\r\nOde\r\n" + end,
+		part("title", "\xff\xfe") + end,
+		part("title", "Ode") + part("rating", "1e999999") + part("limit", "-1") + end,
+		strings.Repeat(part("line", "a"), 1000) + part("title", "Ode") + end,
+	})
+}
@@ -2,12 +2,13 @@ package gwu
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"log/slog"
+	"hash"
 	"net/http"
-	"os"
+	"reflect"
+	"sync/atomic"
+	"time"
 )
 
 var (
@@ -25,25 +26,181 @@ type Logger interface {
 
 // IntoJSON writes the data as JSON with Content-Type `application/json` and given status code to the response.
 // If the JSON encoding fails, it logs the error and writes ErrEncodeResponse to the response.
+// A nil log logs to the fallback logger, see Handle.
 //
 // Example usage:
 //
 //	web.IntoJSON(w, log, data, http.StatusOK)
 func IntoJSON(w http.ResponseWriter, log Logger, data any, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+	err := writeJSON(w, data, statusCode)
+	if err != nil {
+		orFallback(log).Info(fmt.Errorf("%w: %w", ErrEncodeResponse, err).Error())
+	}
+}
+
+// writeJSON writes the data as JSON with the status code to the response, see IntoJSON.
+// It encodes the data before writing the response, if the encoding fails, it writes ErrEncodeResponse with
+// http.StatusInternalServerError and returns the encoding error.
+// Responses with status codes that do not allow a body, like http.StatusNoContent, are written without body.
+func writeJSON(w http.ResponseWriter, data any, statusCode int) error {
+	return writeJSONWith(w, packageCodec(), data, statusCode, "", nil)
+}
 
-	err := json.NewEncoder(w).Encode(data)
+// writeJSONWith writes the data like writeJSON, encoded with the codec. If digest is a Digest algorithm, it sets the
+// Digest header of the encoded data, see ResponseDigest. The phaseTimer t, if not nil, measures encoding and writing.
+func writeJSONWith(w http.ResponseWriter, c JSONCodec, data any, statusCode int, digest string, t *phaseTimer) error {
+	if !bodyAllowed(statusCode) {
+		w.WriteHeader(statusCode)
+		return nil
+	}
+
+	b := getJSONBuffer()
+	defer putJSONBuffer(b)
+
+	// A FieldNaming codec renames the keys of the JSON of its codec, so encoding/json keeps the pooled encoder.
+	naming, renames := c.(namingCodec)
+	if renames {
+		c = naming.codec
+	}
+
+	var enc JSONEncoder = b.enc
+	if _, ok := c.(stdJSON); !ok {
+		enc = c.NewEncoder(&b.buf)
+	}
+
+	err := enc.Encode(data)
+	t.enter(phaseWrite)
 	if err != nil {
-		log.Info(fmt.Errorf("%w: %w", ErrEncodeResponse, err).Error())
 		http.Error(w, ErrEncodeResponse.Error(), http.StatusInternalServerError)
+		return err
+	}
+
+	body := b.buf.Bytes()
+	if renames {
+		body = naming.naming.rename(body, reflect.TypeOf(data), true)
 	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	if digest != "" {
+		w.Header().Set("Digest", digestHeader(digest, body))
+	}
+
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+
+	return nil
+}
+
+// bodyAllowed reports whether a response with the status code may have a body, see RFC 9110 section 6.4.1.
+func bodyAllowed(code int) bool {
+	return code >= 200 && code != http.StatusNoContent && code != http.StatusNotModified
 }
 
 // HandleOpts are options for the Handle, CnIn, and Exec functions, use HandleOptsFunc to set the options.
 // Use the HandleOpts to retrieve a contextual logger.
+//
+// The options set on Handle are a template, Handle derives a copy with request-scoped state for every request and
+// passes it to the CnIn and Exec. Changes to a request's HandleOpts never affect other requests.
 type HandleOpts struct {
 	Log Logger
+
+	exposeRequest    bool
+	decodeErrStatus  int
+	valErrStatus     int
+	slowThreshold    time.Duration
+	longLived        bool
+	bodyLogMax       int
+	bodyLogRedact    func(field string, value string) string
+	sampler          *sampler
+	clock            Clock
+	errLog           Logger
+	errLogOnly       bool
+	traceContext     bool
+	route            *pattern
+	routeLog         Logger
+	cors             *CORSPolicy
+	errFn            ErrorFunc
+	jsonErrors       bool
+	before           []BeforeFunc
+	after            []AfterFunc
+	spa              string
+	slash            slashMode
+	headers          http.Header
+	securityHeaders  bool
+	bodyMax          int64
+	bodyTimeout      time.Duration
+	collectRouteErrs bool
+	jsonCodec        JSONCodec
+	writeTimeout     time.Duration
+	observer         Observer
+	shedAbove        int
+	respDigest       string
+	reqDigests       map[string]func() hash.Hash
+	goneAfterSunset  bool
+	createdOnPost    bool
+	derivedStatus    bool
+	inFlight         *inFlight
+	example          any
+	tenantFn         func(r *http.Request) string
+	tenants          map[string][]HandleOptsFunc
+	outTransform     *outTransform
+	translate        Translator
+	enumCompat       *enumCompat
+	streams          bool
+	names            []string
+	clientTimeout    *clientTimeout
+	fieldNaming      *fieldNaming
+	logThrottle      *logThrottle
+	wsMaxMsg         int64
+	wsPing           time.Duration
+	spec             *Spec
+	doc              Operation
+
+	errs []error
+	req  request
+}
+
+// request is the request-scoped state of a HandleOpts, it is embedded by value to save an allocation per request.
+type request struct {
+	w      http.ResponseWriter
+	r      *http.Request
+	trace  *Trace
+	vals   *values
+	tenant string
+}
+
+// forRequest derives the HandleOpts for a single request from the handler's options.
+func (o HandleOpts) forRequest(w http.ResponseWriter, r *http.Request) HandleOpts {
+	o.req = request{w: w, r: r}
+
+	if o.route != nil {
+		if prefix := mountPrefix(r.Context()); prefix != "" || o.routeLog == nil {
+			p := *o.route
+			p.path = prefix + p.path
+			o.Log = withAttrs(o.Log, p.logAttrs()...)
+		} else {
+			o.Log = o.routeLog
+		}
+	}
+
+	o.Log = o.sampler.sample(o.Log)
+
+	if v, ok := VersionFrom(r.Context()); ok {
+		o.Log = withAttrs(o.Log, "api_version", v)
+	}
+
+	if m, ok := OriginalMethod(r.Context()); ok {
+		o.Log = withAttrs(o.Log, "original_method", m)
+	}
+
+	if o.traceContext {
+		if t, ok := parseTrace(r); ok {
+			o.req.trace = &t
+			o.Log = withAttrs(o.Log, "trace_id", t.TraceID, "span_id", t.SpanID)
+		}
+	}
+
+	return o
 }
 
 // HandleOptsFunc sets a HandleOpts option.
@@ -56,11 +213,56 @@ func Log(log Logger) HandleOptsFunc {
 	}
 }
 
+// defaultOpts holds the options set by Defaults.
+var defaultOpts atomic.Pointer[[]HandleOptsFunc]
+
+// Defaults sets options that Handle applies to every handler it creates, use it to configure a common logger.
+// Defaults replaces the options of a previous call, call it without options to clear them.
+//
+// Handle applies the default options first, in the given order, followed by the handler's own options.
+// Options applied later override earlier ones, so a handler's options take precedence over the defaults.
+// Handle reads the defaults when it is called, handlers created before a call to Defaults keep their options.
+//
+// Defaults is safe for concurrent use, but is meant to be called once at startup before registering handlers.
+func Defaults(opts ...HandleOptsFunc) {
+	opts = append([]HandleOptsFunc(nil), opts...)
+	defaultOpts.Store(&opts)
+}
+
+// newHandleOpts applies the default options and the given options.
+func newHandleOpts(optFns []HandleOptsFunc) HandleOpts {
+	var opts HandleOpts
+	if defaults := defaultOpts.Load(); defaults != nil {
+		for _, fn := range *defaults {
+			fn(&opts)
+		}
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return opts
+}
+
+// prepare derives what does not change between requests when the handler is created, so forRequest and serve do
+// not have to: the fallback logger, the logger with the route's attributes, the JSONCodec, and the gauge of in-flight
+// requests.
+func (o *HandleOpts) prepare() {
+	o.Log = orFallback(o.Log)
+	if o.route != nil {
+		o.routeLog = withAttrs(o.Log, o.route.logAttrs()...)
+	}
+
+	o.jsonCodec = o.JSONCodec()
+	o.inFlight = newInFlight(*o)
+}
+
 // CnIn constructs the input of an Exec function.
 // Commonly used are JSON, PathVal, and Empty.
 //
 // Important: Return only safe to display errors, Handle writes a CnIn function's error to the response with
-// http.StatusBadRequest.
+// http.StatusBadRequest, see DecodeErrorStatus and StatusError to change the status code.
 type CnIn[In any] func(*http.Request, HandleOpts) (In, error)
 
 // Exec executes the endpoint logic. Pass it to Handle to retrieve an http.Handler.
@@ -70,14 +272,17 @@ type CnIn[In any] func(*http.Request, HandleOpts) (In, error)
 // intermediate. An Exec is aware of its HTTP context and should only return client-safe error messages.
 // Services contain business logic and may leak internal information.
 //
+// An error with status code 0 responds with the status code of its StatusError or registered error type, see
+// RegisterError, or http.StatusInternalServerError.
+//
 // Important: Return only safe to display errors, Handle writes an Exec function's error to the response.
 type Exec[In, Out any] func(context.Context, In, HandleOpts) (Out, int, error)
 
-// JSON CnIn decodes the request body into the given data type In.
+// JSON CnIn decodes the request body into the given data type In with the handler's JSONCodec.
 func JSON[In any]() CnIn[In] {
-	return func(r *http.Request, _ HandleOpts) (In, error) {
+	return func(r *http.Request, opts HandleOpts) (In, error) {
 		var in In
-		err := json.NewDecoder(r.Body).Decode(&in)
+		err := opts.JSONCodec().NewDecoder(r.Body).Decode(&in)
 		if err != nil {
 			return in, ErrDecodeRequest
 		}
@@ -88,8 +293,13 @@ func JSON[In any]() CnIn[In] {
 
 // PathVal CnIn reads a path value with the given key.
 func PathVal(key string) CnIn[string] {
-	return func(r *http.Request, _ HandleOpts) (string, error) {
-		return r.PathValue(key), nil
+	return func(r *http.Request, opts HandleOpts) (string, error) {
+		v := r.PathValue(key)
+		if v == "" {
+			opts.noWildcard(key)
+		}
+
+		return v, nil
 	}
 }
 
@@ -101,6 +311,41 @@ func Empty() CnIn[any] {
 	}
 }
 
+// RawBody CnIn reads the whole request body, e.g. to verify the signature of a webhook. Limit the size of bodies
+// with MaxRequestBytes. RawBody reads into buffers shared across requests and copies the body into a slice of its
+// exact size, the slice is owned by the Exec and may be retained.
+func RawBody() CnIn[[]byte] {
+	return func(r *http.Request, _ HandleOpts) ([]byte, error) {
+		b, err := readBody(r.Body)
+		if err != nil {
+			return nil, ErrDecodeRequest
+		}
+
+		return b, nil
+	}
+}
+
+// ValCnIn CnIn validates the input constructed by the given CnIn with the given validation function.
+// If the validation fails, it returns the validation error wrapped in a ValidationError, Handle responds to it
+// with the ValidationErrorStatus.
+//
+// ValCnIn expects the validation function to return an error that is safe to display to the client.
+func ValCnIn[In any](inFn CnIn[In], fnVal func(in In) error) CnIn[In] {
+	return func(r *http.Request, opts HandleOpts) (In, error) {
+		in, err := inFn(r, opts)
+		if err != nil {
+			return in, err
+		}
+
+		err = fnVal(in)
+		if err != nil {
+			return in, &ValidationError{Err: err}
+		}
+
+		return in, nil
+	}
+}
+
 // ValIn Exec validates the input with the given validation function.
 // If the validation fails, it returns an http.StatusBadRequest and the validation error.
 // Afterward, it calls the given Exec function.
@@ -123,30 +368,278 @@ func ValIn[In, Out any](fn Exec[In, Out], fnVal func(in In) error) Exec[In, Out]
 // Handle returns an http.Handler that executes the endpoint's logic with the given CnIn and Exec functions.
 // Handle abstracts the HTTP boilerplate.
 //
-// If no Log option provides a logger, Handle instantiates a new slog.Logger with slog.TextHandler.
+// Handle applies the options set by Defaults before the given options.
+// If no Log option provides a non-nil logger, Handle uses a fallback slog.Logger with slog.TextHandler writing to
+// os.Stderr. All handlers share the same fallback logger.
+// The logger is also stored in the context passed to the Exec, retrieve it with LoggerFrom.
+//
+// An Exec returning a non-nil Raw writes the response itself, see Raw, a File is served with range support, see
+// File, a Stream is written as newline-delimited JSON, see Stream, and an EventStream as Server-Sent Events.
+//
+// Handle panics if the options are invalid or conflict with each other, use TryHandle to get an error instead.
 func Handle[In, Out any](inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) http.Handler {
-	var opts HandleOpts
-	for _, fn := range optFns {
-		fn(&opts)
+	h, err := TryHandle(inFn, fn, optFns...)
+	if err != nil {
+		panic(err)
+	}
+
+	return h
+}
+
+// TryHandle works like Handle, but returns an error instead of panicking if the options are invalid or conflict
+// with each other.
+func TryHandle[In, Out any](inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) (http.Handler, error) {
+	h, _, err := handle(inFn, fn, optFns)
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// HandleFunc works like Handle, but returns an http.HandlerFunc, for APIs that require one. Wrap the handler with
+// standard middleware with Wrap.
+func HandleFunc[In, Out any](inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) http.HandlerFunc {
+	h, _, err := handle(inFn, fn, optFns)
+	if err != nil {
+		panic(err)
 	}
 
-	if opts.Log == nil {
-		opts.Log = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	return h
+}
+
+// handle creates the handler like TryHandle and also returns the handler's options.
+func handle[In, Out any](
+	inFn CnIn[In], fn Exec[In, Out], optFns []HandleOptsFunc,
+) (http.HandlerFunc, HandleOpts, error) {
+	opts := newHandleOpts(optFns)
+	checkExample[In](&opts)
+	checkOutTransform[Out](&opts)
+	if err := opts.validate(); err != nil {
+		return nil, opts, err
 	}
 
+	opts.streams = isStream[Out]()
+
+	// The names are taken before prepare, which sets the fallback logger and the JSONCodec the user did not set.
+	opts.names = opts.optNames()
+	opts.prepare()
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		in, err := inFn(r, opts)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		serve(w, r, opts.forRequest(w, r), inFn, fn)
+	}), opts, nil
+}
+
+// serve handles a single request with the request's HandleOpts. It is the hot path of every handler: besides the
+// CnIn, the Exec, and the encoder, a small JSON response costs 4 allocations, keep it that way.
+func serve[In, Out any](rw http.ResponseWriter, r *http.Request, opts HandleOpts, inFn CnIn[In], fn Exec[In, Out]) {
+	if opts.tenantFn != nil {
+		opts = opts.forTenant(r)
+	}
+
+	if opts.inFlight != nil {
+		if !opts.inFlight.enter(opts.req.tenant) {
+			opts.shed(rw, r)
 			return
 		}
 
-		out, code, err := fn(r.Context(), in, opts)
-		if err != nil {
-			http.Error(w, err.Error(), code)
+		defer opts.inFlight.leave(opts.req.tenant)
+	}
+
+	opts.req.vals = getValues()
+	defer putValues(opts.req.vals)
+
+	phases := opts.startPhases()
+	if phases != nil {
+		defer opts.finishPhases(phases)
+	}
+
+	budget := opts.clientBudget(r)
+	if ctx, cancel := budget.context(r.Context()); cancel != nil {
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	if opts.errLog != nil {
+		defer opts.logPanic(r)
+	}
+
+	opts.setHeaders(rw)
+
+	var w http.ResponseWriter = opts.req.vals.trackResponse(rw)
+	if opts.cors != nil && opts.cors.apply(w, r) {
+		return
+	}
+
+	if opts.streams && streamsOf(r).isDraining() {
+		opts.rejectDraining(w, r)
+		return
+	}
+
+	if opts.warnsSlow() {
+		sw := &statusWriter{ResponseWriter: w}
+		defer warnIfSlow(opts, r, sw, opts.Clock().Now())
+		w = sw
+	}
+
+	body, ok := opts.limitBody(w, r)
+	if !ok {
+		opts.writeError(w, r, ErrRequestTooLarge, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	timed := opts.timeBody(w, r)
+	if timed != nil {
+		defer timed.stop()
+	}
+
+	if opts.logsBodies() {
+		resp := &capBuffer{max: opts.bodyLogMax}
+		defer logBodies(opts, captureRequestBody(r, opts.bodyLogMax), resp)
+		w = &statusWriter{ResponseWriter: w, capture: resp}
+	}
+
+	// Deferred after the writers above, so they see the response of an Abort.
+	defer opts.recoverAbort(w, r)
+
+	code, err := opts.runBefore(r)
+	opts = opts.enrichedLog()
+	if budget.exceeded(r) {
+		opts.rejectOverBudget(w, r, budget)
+		return
+	}
+
+	if err != nil {
+		if code >= http.StatusInternalServerError {
+			opts.logFailure("request failed", "method", r.Method, "path", FullPath(r), "status", code, "error", err)
+		} else {
+			opts.logClientError(r, code, "request rejected", "method", r.Method, "path", FullPath(r), "status", code,
+				"error", err)
+		}
+
+		opts.writeError(w, r, err, code)
+		return
+	}
+
+	phases.enter(phaseDecode)
+	var in In
+	err = opts.verifyDigest(r)
+	if err == nil {
+		in, err = inFn(r, opts)
+	}
+
+	phases.enter(phaseWrite)
+	opts = opts.enrichedLog()
+	if body.tooLarge() {
+		opts.writeError(w, r, ErrRequestTooLarge, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if timed.timedOut() {
+		w.Header().Set("Connection", "close")
+		opts.writeError(w, r, ErrBodyTimeout, http.StatusRequestTimeout)
+		return
+	}
+
+	if budget.exceeded(r) {
+		opts.rejectOverBudget(w, r, budget)
+		return
+	}
+
+	if err != nil {
+		code := inErrStatus(err, opts)
+		opts.logClientError(r, code, "decoding request failed", "method", r.Method, "path", FullPath(r),
+			"status", code, "error", err)
+		opts.writeError(w, r, err, code)
+		return
+	}
+
+	ctx := ContextWithLogger(r.Context(), opts.Log)
+	if opts.exposeRequest {
+		ctx = context.WithValue(ctx, requestCtxKey{}, r)
+	}
+
+	if opts.req.trace != nil {
+		ctx = ContextWithTrace(ctx, *opts.req.trace)
+	}
+
+	phases.enter(phaseExec)
+	out, code, err := fn(ctx, in, opts)
+	phases.enter(phaseNone)
+	opts = opts.enrichedLog()
+	if budget.exceeded(r) {
+		code, err = http.StatusGatewayTimeout, budget.err
+	}
+
+	respErr := err
+	if err != nil && code == 0 {
+		code, respErr = execErrStatus(err)
+	}
+
+	opts.runAfter(r, code, err)
+	phases.enter(phaseWrite)
+
+	// Converting the output to an interface allocates, convert it once.
+	v := any(out)
+	if raw, ok := v.(Raw); ok && raw != nil {
+		opts.req.vals.raw = true
+		raw(rw, r)
+		return
+	}
+
+	if err != nil {
+		if code >= http.StatusInternalServerError && err != budget.err {
+			opts.logFailure("request failed", "method", r.Method, "path", FullPath(r), "status", code, "error", err)
+		} else if respErr != err || err == budget.err {
+			opts.logClientError(r, code, "request failed", "method", r.Method, "path", FullPath(r), "status", code,
+				"error", err)
+		}
+
+		opts.writeError(w, r, respErr, code)
+		return
+	}
+
+	if _, ok := v.(NoBody); ok || opts.req.vals.noBody {
+		w.WriteHeader(code)
+		return
+	}
+
+	if f, ok := v.(File); ok {
+		f.serve(w, r, opts)
+		return
+	}
+
+	if s, ok := v.(streamer); ok {
+		s.stream(w, r, opts, code)
+		return
+	}
+
+	if ms, ok := v.(multiStatus); ok {
+		code = multiStatusCode(w, ms, code)
+	}
+
+	if u, ok := v.(upserted); ok {
+		v, code = opts.upsertCode(w, r, u)
+	}
+
+	phases.enter(phaseEncode)
+	if opts.outTransform != nil {
+		if v, ok = opts.transformOut(r, v); !ok {
+			opts.writeError(w, r, ErrEncodeResponse, http.StatusInternalServerError)
 			return
 		}
+	}
 
-		IntoJSON(w, opts.Log, out, code)
-	})
+	if opts.enumCompat != nil {
+		if v, err = opts.compatEnums(w, r, v); err != nil {
+			opts.logEncodeFailure(err)
+			opts.writeError(w, r, ErrEncodeResponse, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	err = writeJSONWith(w, opts.JSONCodec(), v, code, opts.respDigest, phases)
+	if err != nil {
+		opts.logEncodeFailure(err)
+	}
 }
@@ -0,0 +1,278 @@
+// Package gwuclient provides a typed client for services built with gwu, mirroring the In and Out types of
+// the server's handlers.
+package gwuclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jensilo/gwu"
+)
+
+// Client sends requests to a service, call it with Call.
+type Client struct {
+	// BaseURL is prefixed to the path of every call, e.g. "https://poems.internal/api".
+	BaseURL string
+	// Header is sent with every request, the headers set by Call take precedence.
+	Header http.Header
+	// HTTP is the underlying client, it defaults to http.DefaultClient.
+	HTTP *http.Client
+	// Retry retries failed requests, nil sends every request once. Override it for a call with WithRetry.
+	Retry *RetryPolicy
+	// Codec encodes the input and decodes the output of calls, and sets the Accept header. Defaults to JSON.
+	Codec Codec
+	// Clock waits between retries and measures the duration of requests, it defaults to gwu.RealClock.
+	Clock gwu.Clock
+
+	// Log logs every attempt at debug level with the method, URL, status, duration, attempt, and the request headers.
+	// The values of sensitive headers like Authorization and Cookie are redacted. Nil logs nothing.
+	Log gwu.Logger
+	// PropagateTrace sets the traceparent and tracestate headers from the gwu.Trace of the call's context, see
+	// gwu.TraceFrom.
+	PropagateTrace bool
+	// OnRequest is called before every attempt with its request, e.g. to add instrumentation headers.
+	OnRequest func(r *http.Request)
+	// OnResponse is called after every attempt with its request and either its response or error. The response body is
+	// already read and closed.
+	OnResponse func(r *http.Request, resp *http.Response, err error)
+}
+
+// New returns a Client for the base URL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, Header: make(http.Header)}
+}
+
+// Call sends a request with the encoded input to the path and decodes the response into Out, see Client.Codec.
+// A nil input, or an input of an empty struct type like struct{}, sends no body. A response without body, or an
+// Out of an empty struct type, skips decoding.
+//
+// A response with a status code that is no 2xx status code returns an *APIError. Call retries failed requests
+// by the client's RetryPolicy, the options change a single call.
+//
+// Example usage:
+//
+//	c := gwuclient.New("https://poems.internal")
+//	poem, err := gwuclient.Call[NewPoem, Poem](ctx, c, http.MethodPost, "/poem", NewPoem{Title: "Ode"})
+func Call[In, Out any](ctx context.Context, c *Client, method, path string, in In, opts ...CallOption) (Out, error) {
+	var out Out
+
+	var body []byte
+	if !isEmpty(in) {
+		var err error
+		body, err = c.codec().Marshal(in)
+		if err != nil {
+			return out, fmt.Errorf("gwuclient: encode request: %w", err)
+		}
+	}
+
+	resp, b, err := c.do(ctx, method, path, body, c.callOpts(opts))
+	failed := resp != nil && (resp.StatusCode < 200 || resp.StatusCode > 299)
+	if err != nil && !failed {
+		return out, err
+	}
+
+	// An error response whose body was cut off keeps its status and the part of the body read.
+	if failed {
+		return out, newAPIError(resp, b)
+	}
+
+	if len(b) == 0 || isEmptyStruct(reflect.TypeFor[Out]()) {
+		return out, nil
+	}
+
+	dec, err := c.decoder(resp.Header.Get("Content-Type"), resp.StatusCode)
+	if err != nil {
+		return out, err
+	}
+
+	if err := dec.Unmarshal(b, &out); err != nil {
+		return out, fmt.Errorf("gwuclient: decode response into %T: %w", out, err)
+	}
+
+	return out, nil
+}
+
+// CallOption changes a single Call.
+type CallOption func(*callOpts)
+
+type callOpts struct {
+	header http.Header
+	retry  *RetryPolicy
+}
+
+// Header sets a header of the request, e.g. an Idempotency-Key.
+func Header(key, value string) CallOption {
+	return func(o *callOpts) {
+		o.header.Set(key, value)
+	}
+}
+
+// callOpts returns the options of a call, the client's options overridden by the given options.
+func (c *Client) callOpts(opts []CallOption) callOpts {
+	o := callOpts{header: make(http.Header), retry: c.Retry}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// request returns the request of an attempt.
+func (c *Client) request(ctx context.Context, method, path string, body []byte, o callOpts) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+path, r)
+	if err != nil {
+		return nil, fmt.Errorf("gwuclient: %w", err)
+	}
+
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Accept", c.codec().ContentType())
+	if body != nil {
+		req.Header.Set("Content-Type", c.codec().ContentType())
+	}
+
+	for k, v := range o.header {
+		req.Header[k] = v
+	}
+
+	if t, ok := gwu.TraceFrom(ctx); ok && c.PropagateTrace {
+		req.Header.Set("Traceparent", t.Traceparent())
+		if t.State != "" {
+			req.Header.Set("Tracestate", t.State)
+		}
+	}
+
+	return req, nil
+}
+
+// do sends the request, retrying it by the retry policy, and returns the last response with its read body.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, o callOpts) (*http.Response, []byte, error) {
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := c.request(ctx, method, path, body, o)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if c.OnRequest != nil {
+			c.OnRequest(req)
+		}
+
+		start := c.clock().Now()
+		resp, b, err := send(hc, req)
+		c.logAttempt(req, resp, err, attempt, c.clock().Since(start))
+		if c.OnResponse != nil {
+			c.OnResponse(req, resp, err)
+		}
+
+		if !o.retry.retries(attempt, req, resp, err) {
+			return resp, b, err
+		}
+
+		wait, ok := o.retry.wait(attempt, resp, c.clock().Now())
+		if !ok {
+			return resp, b, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("gwuclient: %w", ctx.Err())
+		case <-c.clock().After(wait):
+		}
+	}
+}
+
+// logAttempt logs an attempt at debug level.
+func (c *Client) logAttempt(r *http.Request, resp *http.Response, err error, attempt int, d time.Duration) {
+	if c.Log == nil {
+		return
+	}
+
+	args := []any{"method", r.Method, "url", r.URL.Redacted(), "attempt", attempt, "duration", d}
+	if resp != nil {
+		args = append(args, "status", resp.StatusCode)
+	}
+
+	if err != nil {
+		args = append(args, "error", err)
+	}
+
+	c.Log.Debug("client request", append(args, "header", redactHeader(r.Header))...)
+}
+
+// sensitiveHeaders are the headers whose values are never logged.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// redactHeader returns a copy of the header with the values of sensitive headers redacted.
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range sensitiveHeaders {
+		if _, ok := h[k]; ok {
+			h[k] = []string{"REDACTED"}
+		}
+	}
+
+	return h
+}
+
+// send sends the request once and reads the response body. If reading the body fails, it returns the part read.
+func send(hc *http.Client, req *http.Request) (*http.Response, []byte, error) {
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gwuclient: %w", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, b, fmt.Errorf("gwuclient: read response: %w", err)
+	}
+
+	return resp, b, nil
+}
+
+// clock returns the client's Clock, it returns gwu.RealClock if none is set.
+func (c *Client) clock() gwu.Clock {
+	if c.Clock == nil {
+		return gwu.RealClock()
+	}
+
+	return c.Clock
+}
+
+// isEmpty reports whether the input sends no body.
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return isEmptyStruct(rv.Type())
+	}
+}
+
+// isEmptyStruct reports whether the type is a struct without fields, like struct{}.
+func isEmptyStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 0
+}
@@ -0,0 +1,146 @@
+package gwuclient_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwuclient"
+)
+
+type poem struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+var errNotFound = errors.New("poem not found")
+
+// seen are the request details a poemServer handler saw.
+type seen struct {
+	contentType, accept, token string
+	body                       []byte
+}
+
+// poemServer serves the poem routes under /api and records the last request.
+func poemServer(t *testing.T) (*httptest.Server, *atomic.Pointer[seen]) {
+	var last atomic.Pointer[seen]
+	record := func(r *http.Request, _ gwu.HandleOpts) error {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		last.Store(&seen{
+			contentType: r.Header.Get("Content-Type"),
+			accept:      r.Header.Get("Accept"),
+			token:       r.Header.Get("X-Token"),
+			body:        body,
+		})
+
+		return nil
+	}
+
+	rt := gwu.NewRouter(gwu.Before(record))
+	api := rt.Group("/api")
+	gwu.Post(api, "/poems", gwu.JSON[poem](), func(_ context.Context, p poem, _ gwu.HandleOpts) (poem, int, error) {
+		p.ID = "7"
+		return gwu.Created(p)
+	})
+	gwu.Get(api, "/poems/{id}", gwu.PathVal("id"), poemByID)
+	gwu.Delete(api, "/poems/{id}", gwu.PathVal("id"), gwu.ExecNoOut[string](deletePoem).Exec())
+
+	srv := httptest.NewServer(rt)
+	t.Cleanup(srv.Close)
+
+	return srv, &last
+}
+
+func poemByID(_ context.Context, id string, _ gwu.HandleOpts) (poem, int, error) {
+	if id != "7" {
+		return poem{}, http.StatusNotFound, errNotFound
+	}
+
+	return gwu.OK(poem{ID: id, Title: "Ode"})
+}
+
+func deletePoem(context.Context, string, gwu.HandleOpts) (int, error) {
+	return http.StatusNoContent, nil
+}
+
+func TestCallRoundTrip(t *testing.T) {
+	srv, last := poemServer(t)
+	c := gwuclient.New(srv.URL + "/api/")
+	c.Header.Set("X-Token", "secret")
+	ctx := context.Background()
+
+	created, err := gwuclient.Call[poem, poem](ctx, c, http.MethodPost, "/poems", poem{Title: "Ode"})
+	if err != nil || created != (poem{ID: "7", Title: "Ode"}) {
+		t.Fatalf("POST: %+v, %v, want the created poem", created, err)
+	}
+
+	s := last.Load()
+	if s.contentType != gwu.ContentTypeJSON || s.accept != gwu.ContentTypeJSON || s.token != "secret" {
+		t.Errorf("POST headers: %+v, want JSON Content-Type and Accept, and the default header", s)
+	}
+
+	got, err := gwuclient.Call[any, poem](ctx, c, http.MethodGet, "/poems/7", nil)
+	if err != nil || got != (poem{ID: "7", Title: "Ode"}) {
+		t.Fatalf("GET: %+v, %v, want the poem", got, err)
+	}
+
+	if s := last.Load(); len(s.body) != 0 || s.contentType != "" {
+		t.Errorf("GET with nil input: body %q, Content-Type %q, want none", s.body, s.contentType)
+	}
+
+	if _, err := gwuclient.Call[struct{}, gwu.NoBody](ctx, c, http.MethodDelete, "/poems/7", struct{}{}); err != nil {
+		t.Fatalf("DELETE: %v", err)
+	}
+
+	if s := last.Load(); len(s.body) != 0 {
+		t.Errorf("DELETE with struct{} input: body %q, want none", s.body)
+	}
+}
+
+func TestCallAPIError(t *testing.T) {
+	srv, _ := poemServer(t)
+
+	_, err := gwuclient.Call[any, poem](context.Background(), gwuclient.New(srv.URL+"/api"), http.MethodGet,
+		"/poems/8", nil)
+
+	var apiErr *gwuclient.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an APIError", err)
+	}
+
+	if apiErr.Status != http.StatusNotFound || apiErr.Message != errNotFound.Error() {
+		t.Errorf("APIError %d %q, want 404 %q", apiErr.Status, apiErr.Message, errNotFound)
+	}
+}
+
+// countingTransport counts the requests it sends.
+type countingTransport struct {
+	n atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.n.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestCallHTTPClient(t *testing.T) {
+	srv, _ := poemServer(t)
+	transport := &countingTransport{}
+	c := gwuclient.New(srv.URL + "/api")
+	c.HTTP = &http.Client{Transport: transport}
+
+	if _, err := gwuclient.Call[any, poem](context.Background(), c, http.MethodGet, "/poems/7", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := transport.n.Load(); n != 1 {
+		t.Errorf("%d requests sent with the client's http.Client, want 1", n)
+	}
+}
@@ -0,0 +1,81 @@
+package gwuclient
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+
+	"github.com/jensilo/gwu"
+)
+
+// Codec encodes requests and decodes responses of a media type, set it with Client.Codec.
+//
+// gwu handlers do not negotiate the media type, they read and write JSON. Use another Codec for servers speaking it,
+// like a gwu handler with a CnIn decoding XML and a Raw writing it.
+type Codec interface {
+	// ContentType is the media type of the encoded values, e.g. "application/json".
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSON returns the Codec for application/json, it is the default.
+func JSON() Codec {
+	return jsonCodec{}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                { return gwu.ContentTypeJSON }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// XML returns the Codec for application/xml, based on encoding/xml.
+func XML() Codec {
+	return xmlCodec{}
+}
+
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string                { return "application/xml" }
+func (xmlCodec) Marshal(v any) ([]byte, error)      { return xml.Marshal(v) }
+func (xmlCodec) Unmarshal(data []byte, v any) error { return xml.Unmarshal(data, v) }
+
+// ContentTypeError is the error of a response with a Content-Type no Codec of the Client decodes.
+type ContentTypeError struct {
+	Status      int
+	ContentType string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("gwuclient: status %d: unsupported response content type %q", e.Status, e.ContentType)
+}
+
+// codec returns the client's Codec, it returns JSON if none is set.
+func (c *Client) codec() Codec {
+	if c.Codec == nil {
+		return jsonCodec{}
+	}
+
+	return c.Codec
+}
+
+// decoder returns the Codec decoding a response with the Content-Type. It decodes the client's media type, and
+// JSON. A response without Content-Type is decoded with the client's Codec.
+func (c *Client) decoder(contentType string, status int) (Codec, error) {
+	if contentType == "" {
+		return c.codec(), nil
+	}
+
+	mt, _, err := mime.ParseMediaType(contentType)
+	switch {
+	case err != nil:
+	case mt == c.codec().ContentType():
+		return c.codec(), nil
+	case mt == gwu.ContentTypeJSON:
+		return jsonCodec{}, nil
+	}
+
+	return nil, &ContentTypeError{Status: status, ContentType: contentType}
+}
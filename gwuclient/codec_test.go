@@ -0,0 +1,124 @@
+package gwuclient_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwuclient"
+)
+
+// xmlServer serves POST /poems as XML, the poem is created with the ID 7. It requires the XML Content-Type and
+// Accept headers, errors are gwu's JSON error bodies.
+func xmlServer(t *testing.T) *httptest.Server {
+	in := func(r *http.Request, _ gwu.HandleOpts) (poem, error) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/xml" {
+			return poem{}, gwu.WithStatus(http.StatusUnsupportedMediaType, errors.New("want XML, got "+ct))
+		}
+
+		if accept := r.Header.Get("Accept"); accept != "application/xml" {
+			return poem{}, gwu.WithStatus(http.StatusNotAcceptable, errors.New("want to send XML, accepted "+accept))
+		}
+
+		var p poem
+		if err := xml.NewDecoder(r.Body).Decode(&p); err != nil {
+			return p, gwu.WithStatus(http.StatusBadRequest, err)
+		}
+
+		return p, nil
+	}
+
+	create := func(_ context.Context, p poem, _ gwu.HandleOpts) (gwu.Raw, int, error) {
+		if p.Title == "" {
+			return nil, http.StatusUnprocessableEntity, errors.New("title required")
+		}
+
+		p.ID = "7"
+		return func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			w.WriteHeader(http.StatusCreated)
+			_ = xml.NewEncoder(w).Encode(p)
+		}, 0, nil
+	}
+
+	mux := http.NewServeMux()
+	gwu.HandleRoute(mux, "POST /poems", in, create, gwu.Errors(gwu.JSONError))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	jsonSrv, last := poemServer(t)
+	tests := []struct {
+		name  string
+		url   string
+		codec gwuclient.Codec
+	}{
+		{"JSON", jsonSrv.URL + "/api", gwuclient.JSON()},
+		{"default", jsonSrv.URL + "/api", nil},
+		{"XML", xmlServer(t).URL, gwuclient.XML()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gwuclient.New(tt.url)
+			c.Codec = tt.codec
+
+			got, err := gwuclient.Call[poem, poem](context.Background(), c, http.MethodPost, "/poems", poem{Title: "Ode"})
+			if err != nil || got != (poem{ID: "7", Title: "Ode"}) {
+				t.Errorf("%+v, %v, want the created poem", got, err)
+			}
+		})
+	}
+
+	if s := last.Load(); s.accept != gwu.ContentTypeJSON || s.contentType != gwu.ContentTypeJSON {
+		t.Errorf("Accept %q and Content-Type %q, want JSON", s.accept, s.contentType)
+	}
+}
+
+// TestCodecJSONErrors decodes the JSON error bodies of a server speaking XML.
+func TestCodecJSONErrors(t *testing.T) {
+	c := gwuclient.New(xmlServer(t).URL)
+	c.Codec = gwuclient.XML()
+
+	_, err := gwuclient.Call[poem, poem](context.Background(), c, http.MethodPost, "/poems", poem{})
+
+	var apiErr *gwuclient.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnprocessableEntity || apiErr.Message != "title required" {
+		t.Errorf("error %v, want the APIError with the message of the JSON body", err)
+	}
+
+	// The server rejects JSON.
+	_, err = gwuclient.Call[poem, poem](context.Background(), gwuclient.New(c.BaseURL), http.MethodPost, "/poems",
+		poem{Title: "Ode"})
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnsupportedMediaType {
+		t.Errorf("error %v, want the 415 of the JSON request", err)
+	}
+}
+
+func TestContentTypeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte("<h1>Poems</h1>"))
+	}))
+	defer srv.Close()
+
+	for _, codec := range []gwuclient.Codec{gwuclient.JSON(), gwuclient.XML()} {
+		c := gwuclient.New(srv.URL)
+		c.Codec = codec
+
+		_, err := gwuclient.Call[any, poem](context.Background(), c, http.MethodGet, "/poems/7", nil)
+
+		var ctErr *gwuclient.ContentTypeError
+		if !errors.As(err, &ctErr) || ctErr.Status != http.StatusOK || ctErr.ContentType != "text/html; charset=utf-8" {
+			t.Errorf("%s: error %v, want a ContentTypeError with the content type", codec.ContentType(), err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package gwuclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jensilo/gwu"
+)
+
+// maxExcerpt is the maximum length of the body excerpt of an APIError.
+const maxExcerpt = 512
+
+// APIError is the error of a response with a status code that is no 2xx status code, retrieve it with errors.As.
+//
+// Call parses the error body by its Content-Type: plain text, gwu's JSON error bodies, and
+// application/problem+json. A body that cannot be parsed, or that was cut off while reading it, keeps the status, and
+// an excerpt of the body as Message.
+//
+// Example usage:
+//
+//	var apiErr *gwuclient.APIError
+//	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+//		// ...
+//	}
+type APIError struct {
+	Status int
+	// Code is the machine-readable error code, the type of a problem.
+	Code    string
+	Message string
+	// Fields are the errors of the input fields, with the message keys and params of gwu.FieldError.
+	Fields []gwu.FieldError
+	// RequestID is the ID of the failed request, from the body or the X-Request-ID header.
+	RequestID string
+	// Body is an excerpt of the response body.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+
+	if e.Code != "" {
+		return fmt.Sprintf("gwuclient: status %d: %s: %s", e.Status, e.Code, msg)
+	}
+
+	return fmt.Sprintf("gwuclient: status %d: %s", e.Status, msg)
+}
+
+// errorBody is the union of gwu's JSON error bodies and problem details, see RFC 9457.
+type errorBody struct {
+	Error     string           `json:"error"`
+	Message   string           `json:"message"`
+	Code      string           `json:"code"`
+	ErrorID   string           `json:"error_id"`
+	RequestID string           `json:"request_id"`
+	Fields    []gwu.FieldError `json:"fields"`
+
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+// newAPIError returns the APIError of an error response.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		Status:    resp.StatusCode,
+		Message:   excerpt(body),
+		RequestID: resp.Header.Get("X-Request-ID"),
+		Body:      excerpt(body),
+	}
+
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mt != gwu.ContentTypeJSON && mt != "application/problem+json" {
+		return e
+	}
+
+	var eb errorBody
+	if json.Unmarshal(body, &eb) != nil {
+		return e
+	}
+
+	e.Message = first(eb.Detail, eb.Message, eb.Error, eb.Title, e.Message)
+	e.Code = eb.Code
+	if eb.Type != "about:blank" {
+		// about:blank is the type of problems without further semantics.
+		e.Code = first(eb.Code, eb.Type)
+	}
+	e.Fields = eb.Fields
+	e.RequestID = first(eb.RequestID, eb.ErrorID, e.RequestID)
+
+	return e
+}
+
+// excerpt returns the trimmed body, cut to maxExcerpt bytes without splitting a rune.
+func excerpt(body []byte) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) <= maxExcerpt {
+		return s
+	}
+
+	i := maxExcerpt
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+
+	return s[:i] + "…"
+}
+
+// first returns the first non-empty string.
+func first(s ...string) string {
+	for _, v := range s {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,162 @@
+package gwuclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwuclient"
+)
+
+func TestAPIErrorFields(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (any, int, error) {
+		fe := &gwu.FieldError{Field: "title", MessageKey: "too_long", Params: map[string]any{"max": 80}}
+		return nil, http.StatusBadRequest, &gwu.ValidationError{Err: errors.Join(fe)}
+	}, gwu.Errors(gwu.JSONError))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	_, err := gwuclient.Call[any, any](context.Background(), gwuclient.New(srv.URL), http.MethodPost, "/", nil)
+
+	var apiErr *gwuclient.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an APIError", err)
+	}
+
+	if apiErr.Message != "title: too_long" {
+		t.Errorf("message = %q, want %q", apiErr.Message, "title: too_long")
+	}
+
+	if len(apiErr.Fields) != 1 {
+		t.Fatalf("fields = %+v, want one field error", apiErr.Fields)
+	}
+
+	fe := apiErr.Fields[0]
+	if fe.Field != "title" || fe.MessageKey != "too_long" || fe.Message != "too_long" || fe.Params["max"] != 80.0 {
+		t.Errorf("field error = %+v, want title too_long with max 80", fe)
+	}
+}
+
+// respond returns a server responding with the status, Content-Type, and body.
+func respond(t *testing.T, status int, contentType, body string, header http.Header) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestAPIError(t *testing.T) {
+	long := strings.Repeat("ä", 300)
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		header      http.Header
+		want        gwuclient.APIError
+	}{
+		{"plain text", http.StatusNotFound, gwu.ContentTypeText, "poem not found\n", nil,
+			gwuclient.APIError{Message: "poem not found", Body: "poem not found"}},
+		{"gwu JSON", http.StatusConflict, gwu.ContentTypeJSON,
+			`{"error": "poem exists", "code": "duplicate", "error_id": "e-1"}`, nil,
+			gwuclient.APIError{Code: "duplicate", Message: "poem exists", RequestID: "e-1"}},
+		{"gwu JSON message", http.StatusBadRequest, gwu.ContentTypeJSON + "; charset=utf-8",
+			`{"message": "invalid poem", "request_id": "r-1"}`, http.Header{"X-Request-Id": {"r-2"}},
+			gwuclient.APIError{Message: "invalid poem", RequestID: "r-1"}},
+		{"problem", http.StatusForbidden, "application/problem+json",
+			`{"type": "https://poems.example/problems/locked", "title": "Locked", "detail": "poem 7 is locked"}`, nil,
+			gwuclient.APIError{Code: "https://poems.example/problems/locked", Message: "poem 7 is locked"}},
+		{"problem about:blank", http.StatusTooManyRequests, "application/problem+json",
+			`{"type": "about:blank", "title": "Too Many Requests"}`, nil,
+			gwuclient.APIError{Message: "Too Many Requests"}},
+		{"truncated JSON", http.StatusBadGateway, gwu.ContentTypeJSON, `{"error": "upstream`,
+			http.Header{"X-Request-Id": {"r-3"}},
+			gwuclient.APIError{Message: `{"error": "upstream`, RequestID: "r-3", Body: `{"error": "upstream`}},
+		{"HTML", http.StatusServiceUnavailable, "text/html", "<h1>Maintenance</h1>", nil,
+			gwuclient.APIError{Message: "<h1>Maintenance</h1>", Body: "<h1>Maintenance</h1>"}},
+		{"empty", http.StatusInternalServerError, "", "", nil, gwuclient.APIError{}},
+		// The excerpt does not split the two bytes of an ä.
+		{"long", http.StatusInternalServerError, gwu.ContentTypeText, long, nil,
+			gwuclient.APIError{Message: long[:512] + "…", Body: long[:512] + "…"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := respond(t, tt.status, tt.contentType, tt.body, tt.header)
+			_, err := gwuclient.Call[any, any](context.Background(), gwuclient.New(srv.URL), http.MethodGet, "/", nil)
+
+			var apiErr *gwuclient.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error %v, want an APIError", err)
+			}
+
+			want := tt.want
+			want.Status = tt.status
+			if want.Body == "" {
+				want.Body = strings.TrimSpace(tt.body)
+			}
+
+			if !reflect.DeepEqual(*apiErr, want) {
+				t.Errorf("APIError %+v, want %+v", *apiErr, want)
+			}
+		})
+	}
+}
+
+// TestAPIErrorCutOff keeps the status and the part read of an error body the connection cut off.
+func TestAPIErrorCutOff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_, _ = buf.WriteString("HTTP/1.1 500 Internal Server Error\r\nContent-Type: text/plain\r\n" +
+			"Content-Length: 100\r\n\r\ndatabase unavail")
+		_ = buf.Flush()
+	}))
+	defer srv.Close()
+
+	_, err := gwuclient.Call[any, any](context.Background(), gwuclient.New(srv.URL), http.MethodGet, "/", nil)
+
+	var apiErr *gwuclient.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusInternalServerError ||
+		apiErr.Message != "database unavail" {
+		t.Errorf("error %v, want the 500 with the part of the body read", err)
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	for _, tt := range []struct {
+		err  gwuclient.APIError
+		want string
+	}{
+		{gwuclient.APIError{Status: http.StatusNotFound}, "gwuclient: status 404: Not Found"},
+		{gwuclient.APIError{Status: http.StatusConflict, Message: "poem exists"}, "gwuclient: status 409: poem exists"},
+		{gwuclient.APIError{Status: http.StatusConflict, Code: "duplicate", Message: "poem exists"},
+			"gwuclient: status 409: duplicate: poem exists"},
+	} {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("%q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package gwuclient_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwuclient"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestClientLog(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	srv, _ := flakyServer(t, clock, scripted{status: http.StatusServiceUnavailable})
+
+	log := gwutest.Logger()
+	c := gwuclient.New(srv.URL)
+	c.Clock, c.Log = clock, log
+	c.Retry = &gwuclient.RetryPolicy{MaxAttempts: 2}
+	c.Header.Set("Authorization", "Bearer secret")
+	c.Header.Set("Cookie", "session=secret")
+	c.Header.Set("X-Client", "poems")
+
+	if err := callAdvancing(c, clock, http.MethodGet); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := log.Filter(slog.LevelDebug)
+	if len(entries) != 2 {
+		t.Fatalf("entries %v, want one per attempt", entries)
+	}
+
+	for i, status := range []int{http.StatusServiceUnavailable, http.StatusNoContent} {
+		e := entries[i]
+		if e.Msg != "client request" || e.Attrs["method"] != http.MethodGet || e.Attrs["url"] != srv.URL+"/poems" ||
+			e.Attrs["attempt"] != int64(i+1) || e.Attrs["status"] != int64(status) {
+			t.Errorf("attempt %d: %v, want the method, URL, attempt, and status %d", i+1, e, status)
+		}
+
+		if _, ok := e.Attrs["duration"].(time.Duration); !ok {
+			t.Errorf("attempt %d: %v, want the duration", i+1, e)
+		}
+
+		h, _ := e.Attrs["header"].(http.Header)
+		if h.Get("Authorization") != "REDACTED" || h.Get("Cookie") != "REDACTED" || h.Get("X-Client") != "poems" {
+			t.Errorf("attempt %d: header %v, want the sensitive headers redacted", i+1, h)
+		}
+	}
+
+	// The redaction does not change the sent headers.
+	if c.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Authorization %q, want the header of the client unchanged", c.Header.Get("Authorization"))
+	}
+}
+
+// TestClientLogURL redacts the password of the URL.
+func TestClientLogURL(t *testing.T) {
+	srv, _ := poemServer(t)
+	log := gwutest.Logger()
+	c := gwuclient.New("http://poet:secret@" + srv.Listener.Addr().String() + "/api")
+	c.Log = log
+
+	if _, err := gwuclient.Call[any, poem](context.Background(), c, http.MethodGet, "/poems/7", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	log.AssertLogged(t, slog.LevelDebug, "client request", "url",
+		"http://poet:xxxxx@"+srv.Listener.Addr().String()+"/api/poems/7", "status", int64(http.StatusOK))
+}
+
+func TestClientPropagateTrace(t *testing.T) {
+	var traceparent, tracestate string
+	srv, _ := poemServer(t)
+	c := gwuclient.New(srv.URL + "/api")
+	c.OnRequest = func(r *http.Request) {
+		traceparent, tracestate = r.Header.Get("Traceparent"), r.Header.Get("Tracestate")
+	}
+
+	tr := gwu.Trace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Flags: 1, State: "rojo=1"}
+	ctx := gwu.ContextWithTrace(context.Background(), tr)
+
+	// Without PropagateTrace, the trace stays in the service.
+	if _, err := gwuclient.Call[any, poem](ctx, c, http.MethodGet, "/poems/7", nil); err != nil || traceparent != "" {
+		t.Errorf("traceparent %q, %v, want none", traceparent, err)
+	}
+
+	c.PropagateTrace = true
+	if _, err := gwuclient.Call[any, poem](ctx, c, http.MethodGet, "/poems/7", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := regexp.MustCompile(`^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$`)
+	if !want.MatchString(traceparent) || traceparent == tr.Traceparent() || tracestate != "rojo=1" {
+		t.Errorf("traceparent %q and tracestate %q, want the trace with a new span and the state", traceparent,
+			tracestate)
+	}
+
+	// A context without trace sends none.
+	traceparent = ""
+	if _, err := gwuclient.Call[any, poem](context.Background(), c, http.MethodGet, "/poems/7", nil); err != nil ||
+		traceparent != "" {
+		t.Errorf("traceparent %q, %v, want none", traceparent, err)
+	}
+}
+
+func TestClientHooks(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	srv, _ := flakyServer(t, clock, scripted{status: http.StatusBadGateway})
+
+	var calls []string
+	c := gwuclient.New(srv.URL)
+	c.Clock = clock
+	c.Retry = &gwuclient.RetryPolicy{MaxAttempts: 2}
+	c.OnRequest = func(r *http.Request) {
+		calls = append(calls, "request "+r.URL.Path)
+		r.Header.Set("X-Attempt", "set by the hook")
+	}
+	c.OnResponse = func(r *http.Request, resp *http.Response, err error) {
+		if err != nil || r.Header.Get("X-Attempt") != "set by the hook" {
+			t.Errorf("OnResponse: request header %q, error %v", r.Header.Get("X-Attempt"), err)
+		}
+
+		calls = append(calls, "response "+resp.Status)
+	}
+
+	if err := callAdvancing(c, clock, http.MethodGet); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"request /poems", "response 502 Bad Gateway", "request /poems", "response 204 No Content"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls %q, want %q", calls, want)
+	}
+
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d: %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
+
+// TestClientOnResponseError calls OnResponse with the error of a failed attempt.
+func TestClientOnResponseError(t *testing.T) {
+	srv, _ := poemServer(t)
+	srv.Close()
+
+	var got error
+	c := gwuclient.New(srv.URL)
+	c.OnResponse = func(_ *http.Request, resp *http.Response, err error) {
+		if resp != nil {
+			t.Errorf("OnResponse with response %v, want none", resp)
+		}
+
+		got = err
+	}
+
+	_, err := gwuclient.Call[any, poem](context.Background(), c, http.MethodGet, "/poems/7", nil)
+	if err == nil || got == nil {
+		t.Errorf("Call error %v, OnResponse error %v, want the connection error", err, got)
+	}
+}
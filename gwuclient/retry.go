@@ -0,0 +1,130 @@
+package gwuclient
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy retries requests that failed with a connection error or a temporary server error, set it with
+// Client.Retry or WithRetry.
+//
+// Call buffers the request body, so every attempt sends the same body.
+//
+// Example usage:
+//
+//	c := gwuclient.New("https://poems.internal")
+//	c.Retry = &gwuclient.RetryPolicy{MaxAttempts: 3}
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first, below 2 sends a request once.
+	MaxAttempts int
+	// Backoff is the wait before the first retry, it doubles for every further retry. Defaults to 100ms.
+	Backoff time.Duration
+	// MaxBackoff caps the backoff, defaults to 10s. A Retry-After header of the response is honored, but Call does
+	// not retry if it asks to wait longer than MaxBackoff and returns the response's error instead.
+	MaxBackoff time.Duration
+	// Retry reports whether to retry the request after the response or error, defaults to DefaultRetry.
+	Retry func(r *http.Request, resp *http.Response, err error) bool
+}
+
+// WithRetry overrides the client's retry policy for a call, nil sends the request once.
+func WithRetry(p *RetryPolicy) CallOption {
+	return func(o *callOpts) {
+		o.retry = p
+	}
+}
+
+// DefaultRetry retries idempotent requests failing with a connection error or a 502, 503, or 504 status code.
+// Requests with the methods GET, HEAD, PUT, and DELETE are idempotent, and any request with an Idempotency-Key
+// header. Requests canceled by their context are never retried.
+func DefaultRetry(r *http.Request, resp *http.Response, err error) bool {
+	if !idempotent(r) {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// idempotent reports whether the request can be sent more than once.
+func idempotent(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return r.Header.Get("Idempotency-Key") != ""
+	}
+}
+
+// retries reports whether to retry after the attempt. A nil policy never retries.
+func (p *RetryPolicy) retries(attempt int, r *http.Request, resp *http.Response, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if p.Retry == nil {
+		return DefaultRetry(r, resp, err)
+	}
+
+	return p.Retry(r, resp, err)
+}
+
+// wait returns the wait after the attempt: the response's Retry-After, or the exponential backoff with jitter capped
+// at the MaxBackoff. It reports false if the Retry-After is longer than the MaxBackoff, the request must not be
+// retried before.
+func (p *RetryPolicy) wait(attempt int, resp *http.Response, now time.Time) (time.Duration, bool) {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * time.Second
+	}
+
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After"), now); ok {
+			return d, d <= maxBackoff
+		}
+	}
+
+	d := p.Backoff
+	if d <= 0 {
+		d = 100 * time.Millisecond
+	}
+
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+
+	d = min(d, maxBackoff)
+
+	// Jitter between half and the full backoff spreads the retries of concurrent clients.
+	return d/2 + rand.N(d/2+1), true
+}
+
+// retryAfter parses a Retry-After header in seconds or as HTTP date.
+func retryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if s, err := strconv.ParseInt(v, 10, 64); err == nil && s >= 0 {
+		// Large values would overflow the duration.
+		return time.Duration(min(s, int64(math.MaxInt64/time.Second))) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,192 @@
+package gwuclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwuclient"
+)
+
+// scripted is a response of a flakyServer.
+type scripted struct {
+	status     int
+	retryAfter string
+}
+
+// flakyServer responds with the scripted responses in order, and with 204 once they are used up. It returns the
+// times of the attempts on the clock.
+func flakyServer(t *testing.T, clock gwu.Clock, script ...scripted) (*httptest.Server, func() []time.Time) {
+	var mu sync.Mutex
+	var attempts []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		n := len(attempts)
+		attempts = append(attempts, clock.Now())
+		mu.Unlock()
+
+		if n >= len(script) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if script[n].retryAfter != "" {
+			w.Header().Set("Retry-After", script[n].retryAfter)
+		}
+
+		w.WriteHeader(script[n].status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]time.Time(nil), attempts...)
+	}
+}
+
+// callAdvancing calls the server, advancing the clock until the call returned.
+func callAdvancing(c *gwuclient.Client, clock *gwu.ManualClock, method string, opts ...gwuclient.CallOption) error {
+	errc := make(chan error, 1)
+	go func() {
+		_, err := gwuclient.Call[any, gwu.NoBody](context.Background(), c, method, "/poems", nil, opts...)
+		errc <- err
+	}()
+
+	for {
+		select {
+		case err := <-errc:
+			return err
+		case <-time.After(time.Millisecond):
+			clock.Advance(100 * time.Millisecond)
+		}
+	}
+}
+
+func TestRetryAttempts(t *testing.T) {
+	unavailable, badGateway := scripted{status: http.StatusServiceUnavailable}, scripted{status: http.StatusBadGateway}
+	tests := []struct {
+		name     string
+		method   string
+		opts     []gwuclient.CallOption
+		script   []scripted
+		attempts int
+		status   int
+	}{
+		{"recovers", http.MethodGet, nil, []scripted{unavailable, badGateway}, 3, 0},
+		{"exhausted", http.MethodDelete, nil, []scripted{unavailable, unavailable, unavailable}, 3,
+			http.StatusServiceUnavailable},
+		{"not idempotent", http.MethodPost, nil, []scripted{unavailable}, 1, http.StatusServiceUnavailable},
+		{"idempotency key", http.MethodPost, []gwuclient.CallOption{gwuclient.Header("Idempotency-Key", "k1")},
+			[]scripted{unavailable}, 2, 0},
+		{"client error", http.MethodGet, nil, []scripted{{status: http.StatusNotFound}}, 1, http.StatusNotFound},
+		{"per call", http.MethodGet, []gwuclient.CallOption{gwuclient.WithRetry(nil)}, []scripted{unavailable}, 1,
+			http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+			srv, attempts := flakyServer(t, clock, tt.script...)
+			c := gwuclient.New(srv.URL)
+			c.Clock = clock
+			c.Retry = &gwuclient.RetryPolicy{MaxAttempts: 3}
+
+			err := callAdvancing(c, clock, tt.method, tt.opts...)
+			if got := len(attempts()); got != tt.attempts {
+				t.Errorf("%d attempts, want %d", got, tt.attempts)
+			}
+
+			var apiErr *gwuclient.APIError
+			switch {
+			case tt.status == 0 && err != nil:
+				t.Errorf("error %v, want none", err)
+			case tt.status != 0 && (!errors.As(err, &apiErr) || apiErr.Status != tt.status):
+				t.Errorf("error %v, want an APIError with status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+// TestRetryAfter waits for the Retry-After of the response before the retry, seconds and HTTP dates.
+func TestRetryAfter(t *testing.T) {
+	start := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		retryAfter string
+		// earliest returns the earliest time of the retry after the first attempt.
+		earliest func(first time.Time) time.Time
+	}{
+		{"3", func(first time.Time) time.Time { return first.Add(3 * time.Second) }},
+		{start.Add(3 * time.Second).Format(http.TimeFormat), func(time.Time) time.Time { return start.Add(3 * time.Second) }},
+	}
+
+	for _, tt := range tests {
+		clock := gwu.NewManualClock(start)
+		srv, attempts := flakyServer(t, clock, scripted{http.StatusServiceUnavailable, tt.retryAfter})
+		c := gwuclient.New(srv.URL)
+		c.Clock = clock
+		c.Retry = &gwuclient.RetryPolicy{MaxAttempts: 2}
+
+		if err := callAdvancing(c, clock, http.MethodGet); err != nil {
+			t.Fatal(err)
+		}
+
+		// The clock may advance before the first attempt, an HTTP date is a point in time.
+		at := attempts()
+		if len(at) != 2 {
+			t.Fatalf("Retry-After %s: %d attempts, want 2", tt.retryAfter, len(at))
+		}
+
+		if earliest := tt.earliest(at[0]); at[1].Before(earliest) {
+			t.Errorf("Retry-After %s: attempts at %v, want the retry at %v or later", tt.retryAfter, at, earliest)
+		}
+	}
+}
+
+// TestRetryAfterBeyondMaxBackoff does not retry before a Retry-After longer than the MaxBackoff.
+func TestRetryAfterBeyondMaxBackoff(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	srv, attempts := flakyServer(t, clock, scripted{http.StatusServiceUnavailable, "3600"})
+	c := gwuclient.New(srv.URL)
+	c.Clock = clock
+	c.Retry = &gwuclient.RetryPolicy{MaxAttempts: 3, MaxBackoff: 2 * time.Second}
+
+	// The clock does not advance, Call returns without waiting.
+	_, err := gwuclient.Call[any, gwu.NoBody](context.Background(), c, http.MethodGet, "/poems", nil)
+
+	var apiErr *gwuclient.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusServiceUnavailable {
+		t.Errorf("error %v, want the APIError of the 503", err)
+	}
+
+	if got := len(attempts()); got != 1 {
+		t.Errorf("%d attempts, want 1", got)
+	}
+}
+
+// TestRetryCanceled aborts the wait for the retry when the context is canceled.
+func TestRetryCanceled(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	srv, attempts := flakyServer(t, clock, scripted{status: http.StatusServiceUnavailable})
+	c := gwuclient.New(srv.URL)
+	c.Clock = clock
+	c.Retry = &gwuclient.RetryPolicy{MaxAttempts: 3}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.OnResponse = func(*http.Request, *http.Response, error) { cancel() }
+
+	_, err := gwuclient.Call[any, gwu.NoBody](ctx, c, http.MethodGet, "/poems", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error %v, want context.Canceled", err)
+	}
+
+	if got := len(attempts()); got != 1 {
+		t.Errorf("%d attempts, want 1", got)
+	}
+}
@@ -0,0 +1,416 @@
+// Package gwugen generates a typed client package from the routes of a gwu.Router, see Generate.
+package gwugen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jensilo/gwu"
+)
+
+// Generate returns the source of the Go package pkg with a function calling each route with gwuclient.Call, like
+// GetPoemByID(ctx context.Context, c *gwuclient.Client, id string) (poem.Poem, error). The path wildcards are
+// arguments, and the input of POST, PUT, and PATCH routes is the request body. Wildcards named like a package the
+// function uses get the suffix Param. Routes registered with Handle have no types and are skipped.
+//
+// The output is gofmt-ed and sorted by path and method, so it is stable across runs. The In and Out types must be
+// importable, types of package main are not supported, and the generated package must not be the package of the
+// types. Routes with generic or unnamed types, like gwu.Opt[Poem] as Out, are skipped with a comment in place of
+// their function.
+//
+// Generate is meant to be run by a small program building the Router, called with go:generate.
+//
+// Example usage:
+//
+//	//go:generate go run ./internal/gen
+//	func main() {
+//		rt := poem.Routes()
+//		src, err := gwugen.Generate("poemclient", rt.Routes())
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		err = os.WriteFile("poemclient/client.go", src, 0o644)
+//		// ...
+//	}
+func Generate(pkg string, routes []gwu.RouteInfo) ([]byte, error) {
+	g := &generator{imports: map[string]string{
+		"context":                          "context",
+		"github.com/jensilo/gwu/gwuclient": "gwuclient",
+	}}
+
+	var rs []route
+	for _, info := range routes {
+		if info.InType == nil || info.OutType == nil {
+			continue
+		}
+
+		rs = append(rs, newRoute(info))
+	}
+
+	sort.SliceStable(rs, func(i, j int) bool {
+		if rs[i].path != rs[j].path {
+			return rs[i].path < rs[j].path
+		}
+
+		return rs[i].method < rs[j].method
+	})
+
+	var funcs bytes.Buffer
+	names := make(map[string]bool)
+	for _, r := range rs {
+		name := r.name()
+		for i := 2; names[name]; i++ {
+			name = r.name() + strconv.Itoa(i)
+		}
+
+		names[name] = true
+		err := g.function(&funcs, name, r)
+		if errors.Is(err, errUnsupported) {
+			fmt.Fprintf(&funcs, "\n// %s is not generated for %s %s: %v.\n", name, r.method, r.path, err)
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("gwugen: %s: %w", r.pattern, err)
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gwugen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+
+	// Standard library imports come first, separated from the others like goimports does.
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+
+	slices.SortFunc(paths, func(a, b string) int {
+		if std(a) != std(b) {
+			if std(a) {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	for i, path := range paths {
+		if i > 0 && std(paths[i-1]) != std(path) {
+			b.WriteString("\n")
+		}
+
+		if name := g.imports[path]; name != path[strings.LastIndex(path, "/")+1:] {
+			fmt.Fprintf(&b, "\t%s %q\n", name, path)
+			continue
+		}
+
+		fmt.Fprintf(&b, "\t%q\n", path)
+	}
+
+	b.WriteString(")\n")
+	b.Write(funcs.Bytes())
+
+	if g.rest {
+		b.WriteString(escapeRestSrc)
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("gwugen: format: %w", err)
+	}
+
+	return src, nil
+}
+
+// std reports whether the import path is of a standard library package.
+func std(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// escapeRestSrc is the source of the helper escaping the segments of {name...} wildcards.
+const escapeRestSrc = `
+// escapeRest escapes the segments of a path, keeping the slashes between them.
+func escapeRest(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return strings.Join(segments, "/")
+}
+`
+
+// route is a route to generate a function for.
+type route struct {
+	pattern  string
+	method   string
+	path     string
+	segments []segment
+	in, out  reflect.Type
+}
+
+// segment is a segment of a route's path, a wildcard if name is set.
+type segment struct {
+	literal string
+	name    string
+	rest    bool
+}
+
+// newRoute returns the route of a RouteInfo.
+func newRoute(info gwu.RouteInfo) route {
+	method := info.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	path := info.Pattern
+	if i := strings.Index(path, "/"); i >= 0 {
+		path = path[i:]
+	}
+
+	r := route{pattern: info.Pattern, method: method, path: path, in: info.InType, out: info.OutType}
+	for _, s := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		switch {
+		case s == "{$}":
+		case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "...}"):
+			r.segments = append(r.segments, segment{name: s[1 : len(s)-4], rest: true})
+		case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+			r.segments = append(r.segments, segment{name: s[1 : len(s)-1]})
+		default:
+			r.segments = append(r.segments, segment{literal: s})
+		}
+	}
+
+	return r
+}
+
+// hasBody reports whether the route's input is sent as request body.
+func (r route) hasBody() bool {
+	switch r.method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return false
+	}
+
+	switch r.in.Kind() {
+	case reflect.String, reflect.Interface:
+		return false
+	default:
+		return true
+	}
+}
+
+// name returns the function name of the route, like GetPoemByID for GET /poem/{id}.
+func (r route) name() string {
+	var b strings.Builder
+	b.WriteString(camel(strings.ToLower(r.method)))
+
+	var params []string
+	for _, s := range r.segments {
+		if s.name != "" {
+			params = append(params, camel(s.name))
+			continue
+		}
+
+		b.WriteString(camel(s.literal))
+	}
+
+	if len(r.segments) == 0 || len(r.segments) == len(params) {
+		b.WriteString("Root")
+	}
+
+	if len(params) > 0 {
+		b.WriteString("By" + strings.Join(params, "And"))
+	}
+
+	return b.String()
+}
+
+// initialisms are written in upper case in function names.
+var initialisms = map[string]bool{"api": true, "http": true, "id": true, "json": true, "url": true, "uuid": true}
+
+// camel returns the words of s in camel case, words are separated by characters that are no letters or digits.
+func camel(s string) string {
+	var b strings.Builder
+	for _, w := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if initialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+
+	return b.String()
+}
+
+// generator generates the functions of the routes and collects their imports.
+type generator struct {
+	// imports are the names of the imported packages, keyed by path.
+	imports map[string]string
+	rest    bool
+}
+
+// errUnsupported is the error of types Generate cannot express, it skips their routes.
+var errUnsupported = errors.New("not supported")
+
+// function writes the function calling the route. It fails with errUnsupported if a type of the route is not
+// supported, and then adds no imports.
+func (g *generator) function(b *bytes.Buffer, name string, r route) error {
+	imports := maps.Clone(g.imports)
+	in, out, err := g.types(r)
+	if err != nil {
+		g.imports = imports
+		return err
+	}
+
+	params := []string{"ctx context.Context", "c *gwuclient.Client"}
+	path := `"`
+	for _, s := range r.segments {
+		if s.name == "" {
+			path += "/" + s.literal
+			continue
+		}
+
+		arg := s.name
+		if token.IsKeyword(arg) || reserved[arg] || arg == "ctx" || arg == "c" || arg == "in" || arg == "escapeRest" ||
+			slices.Contains(g.importNames(), arg) {
+			arg += "Param"
+		}
+
+		params = append(params, arg+" string")
+		g.imports["net/url"] = "url"
+		if s.rest {
+			g.imports["strings"] = "strings"
+			g.rest = true
+			path += `/" + escapeRest(` + arg + `) + "`
+			continue
+		}
+
+		path += `/" + url.PathEscape(` + arg + `) + "`
+	}
+
+	path = strings.TrimSuffix(path+`"`, ` + ""`)
+	if path == `""` {
+		path = `"/"`
+	}
+
+	body, bodyType := "nil", "any"
+	if r.hasBody() {
+		params = append(params, "in "+in)
+		body, bodyType = "in", in
+	}
+
+	fmt.Fprintf(b, "\n// %s calls %s %s.\n", name, r.method, r.path)
+	fmt.Fprintf(b, "func %s(%s) (%s, error) {\n", name, strings.Join(params, ", "), out)
+	fmt.Fprintf(b, "\treturn gwuclient.Call[%s, %s](ctx, c, %q, %s, %s)\n}\n", bodyType, out, r.method, path, body)
+
+	return nil
+}
+
+// types returns the Go expressions of the route's In, if it is sent as body, and Out type.
+func (g *generator) types(r route) (in, out string, err error) {
+	if r.hasBody() {
+		if in, err = g.typeExpr(r.in); err != nil {
+			return "", "", err
+		}
+	}
+
+	out, err = g.typeExpr(r.out)
+	return in, out, err
+}
+
+// typeExpr returns the Go expression of the type, and adds the imports it needs.
+func (g *generator) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+
+		if t.PkgPath() == "main" {
+			return "", fmt.Errorf("type %s of package main cannot be imported", t)
+		}
+
+		if strings.Contains(t.Name(), "[") {
+			return "", fmt.Errorf("generic type %s is %w", t, errUnsupported)
+		}
+
+		return g.importName(t) + "." + t.Name(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := g.typeExpr(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeExpr(t.Elem())
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := g.typeExpr(t.Elem())
+		return "[" + strconv.Itoa(t.Len()) + "]" + elem, err
+	case reflect.Map:
+		key, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+
+		elem, err := g.typeExpr(t.Elem())
+		return "map[" + key + "]" + elem, err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any", nil
+		}
+	case reflect.Struct:
+		if t.NumField() == 0 {
+			return "struct{}", nil
+		}
+	}
+
+	return "", fmt.Errorf("unnamed type %s is %w", t, errUnsupported)
+}
+
+// importName returns the name to qualify the named type with, it imports the type's package under a unique name.
+func (g *generator) importName(t reflect.Type) string {
+	if name, ok := g.imports[t.PkgPath()]; ok {
+		return name
+	}
+
+	base, _, _ := strings.Cut(t.String(), ".")
+
+	name := base
+	for i := 2; slices.Contains(g.importNames(), name) || reserved[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	g.imports[t.PkgPath()] = name
+
+	return name
+}
+
+// reserved are the names of packages the generated code may import, neither imports nor arguments use them.
+var reserved = map[string]bool{"context": true, "gwuclient": true, "strings": true, "url": true}
+
+// importNames returns the names of the imported packages.
+func (g *generator) importNames() []string {
+	names := make([]string, 0, len(g.imports))
+	for _, name := range g.imports {
+		names = append(names, name)
+	}
+
+	return names
+}
@@ -0,0 +1,56 @@
+package gwugen_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/jensilo/gwu/gwugen"
+	"github.com/jensilo/gwu/gwugen/internal/poem"
+)
+
+// poemClient is the golden file of the client of the poem routes, it is compiled with the module.
+const poemClient = "internal/poemclient/client.go"
+
+func TestGeneratePoemClient(t *testing.T) {
+	src, err := gwugen.Generate("poemclient", poem.Routes().Routes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if os.Getenv("GWU_UPDATE_GOLDEN") != "" {
+		if err := os.WriteFile(poemClient, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(poemClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(want, src) {
+		t.Errorf("generated client differs from %s, run the test with GWU_UPDATE_GOLDEN=1 to accept it:\n%s",
+			poemClient, src)
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	first, err := gwugen.Generate("poemclient", poem.Routes().Routes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 10 {
+		src, err := gwugen.Generate("poemclient", poem.Routes().Routes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(first, src) {
+			t.Fatalf("output differs between runs:\n%s\n\n%s", first, src)
+		}
+	}
+}
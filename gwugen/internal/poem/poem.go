@@ -0,0 +1,67 @@
+// Package poem has the routes of the poem example with importable types, the golden test of gwugen generates
+// poemclient from them.
+package poem
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jensilo/gwu"
+)
+
+type ID string
+
+type Poem struct {
+	ID     ID     `json:"id"`
+	Name   string `json:"name"`
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+// Routes returns the Router of the poem routes.
+func Routes() *gwu.Router {
+	rt := gwu.NewRouter()
+	gwu.Get(rt, "/poem/{id}", IDIn("id"), ByID)
+	gwu.Get(rt, "/poems", gwu.Empty(), All)
+	gwu.Post(rt, "/poem", gwu.JSON[Poem](), Create)
+	gwu.Get(rt, "/poems/author/{author}", gwu.PathVal("author"), ByAuthor)
+	gwu.Get(rt, "/poems/author/{author}/{poem}", gwu.Empty(), ByAuthorAndName)
+	gwu.Get(rt, "/poems/latest", gwu.Empty(), Latest)
+	gwu.Delete(rt, "/poem/{id}", IDIn("id"), gwu.ExecNoOut[ID](Delete).Exec())
+
+	return rt
+}
+
+func IDIn(key string) gwu.CnIn[ID] {
+	return func(r *http.Request, _ gwu.HandleOpts) (ID, error) {
+		return ID(r.PathValue(key)), nil
+	}
+}
+
+func ByID(_ context.Context, id ID, _ gwu.HandleOpts) (Poem, int, error) {
+	return Poem{ID: id}, http.StatusOK, nil
+}
+
+func All(context.Context, any, gwu.HandleOpts) ([]Poem, int, error) {
+	return nil, http.StatusOK, nil
+}
+
+func Create(_ context.Context, p Poem, _ gwu.HandleOpts) (Poem, int, error) {
+	return p, http.StatusCreated, nil
+}
+
+func ByAuthor(_ context.Context, author string, _ gwu.HandleOpts) ([]Poem, int, error) {
+	return []Poem{{Author: author}}, http.StatusOK, nil
+}
+
+func ByAuthorAndName(context.Context, any, gwu.HandleOpts) (Poem, int, error) {
+	return Poem{}, http.StatusOK, nil
+}
+
+func Latest(context.Context, any, gwu.HandleOpts) (gwu.Opt[Poem], int, error) {
+	return gwu.Opt[Poem]{}, http.StatusOK, nil
+}
+
+func Delete(context.Context, ID, gwu.HandleOpts) (int, error) {
+	return http.StatusNoContent, nil
+}
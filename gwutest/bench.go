@@ -0,0 +1,73 @@
+package gwutest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// Bench benchmarks the handler with the request, it reports the allocations per request.
+//
+// Bench reuses the request with a rewound copy of its body and a reset response writer for every iteration, so only
+// the handler is measured, not the construction of requests and recorders.
+//
+// Example usage:
+//
+//	func BenchmarkPoem(b *testing.B) {
+//		gwutest.Bench(b, rt, httptest.NewRequest(http.MethodGet, "/poem/7", nil))
+//	}
+func Bench(b *testing.B, h http.Handler, r *http.Request) {
+	b.Helper()
+
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			b.Fatalf("gwutest: read request body of %s %s: %v", r.Method, r.URL, err)
+		}
+
+		r.Body.Close()
+	}
+
+	br := bytes.NewReader(body)
+	rb := io.NopCloser(br)
+	w := &benchWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		br.Reset(body)
+		r.Body = rb
+		w.reset()
+		h.ServeHTTP(w, r)
+	}
+}
+
+// benchWriter is a reusable http.ResponseWriter discarding the written body.
+type benchWriter struct {
+	header http.Header
+	code   int
+}
+
+func (w *benchWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *benchWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *benchWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return len(p), nil
+}
+
+func (w *benchWriter) reset() {
+	clear(w.header)
+	w.code = 0
+}
@@ -0,0 +1,119 @@
+package gwutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// ContractCase is a request to an operation of a Spec, see Contract.
+type ContractCase struct {
+	Name string
+	// Operation is the operation ID or the method and path of the operation in the Spec, like "GET /poem/{id}".
+	Operation string
+	// Path is the path of the request, like /poem/7, it defaults to the path of the operation.
+	Path   string
+	Header http.Header
+	// Body is JSON-encoded, a nil body sends none.
+	Body any
+}
+
+// Contract runs every case as a subtest against the handler and fails it if the response does not match the Spec:
+// the status code must be declared for the operation, and a JSON body must validate against the response schema,
+// see gwu.Schema.Validate. Mismatches report the JSON path of the offending field. The default response of an
+// operation only declares error status codes.
+//
+// Example usage:
+//
+//	gwutest.Contract(t, spec, rt, []gwutest.ContractCase{
+//		{Name: "existing poem", Operation: "GET /poem/{id}", Path: "/poem/1"},
+//		{Name: "create poem", Operation: "POST /poem", Body: Poem{Title: "Ode"}},
+//	})
+func Contract(t *testing.T, spec *gwu.Spec, h http.Handler, cases []ContractCase) {
+	t.Helper()
+
+	doc := spec.Document()
+	components := map[string]*gwu.Schema{}
+	if c, ok := doc["components"].(map[string]any); ok {
+		components, _ = c["schemas"].(map[string]*gwu.Schema)
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			method, path, op := findOperation(doc, c.Operation)
+			if op == nil {
+				t.Fatalf("gwutest: operation %q is not in the spec", c.Operation)
+			}
+
+			if c.Path != "" {
+				path = c.Path
+			}
+
+			b, isJSON := requestBody(c.Body)
+			r := httptest.NewRequest(method, path, b)
+			if isJSON {
+				r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+			}
+
+			for k, v := range c.Header {
+				r.Header[k] = v
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			responses, _ := op["responses"].(map[string]any)
+			resp, ok := responses[strconv.Itoa(rec.Code)].(map[string]any)
+			if !ok && rec.Code >= http.StatusBadRequest {
+				resp, ok = responses["default"].(map[string]any)
+			}
+
+			if !ok {
+				t.Fatalf("gwutest: %s %s: status %d is not declared for %s\nbody:\n%s",
+					method, path, rec.Code, c.Operation, rec.Body)
+			}
+
+			content, _ := resp["content"].(map[string]any)
+			media, _ := content[gwu.ContentTypeJSON].(map[string]any)
+			schema, _ := media["schema"].(*gwu.Schema)
+			if schema == nil || rec.Body.Len() == 0 {
+				return
+			}
+
+			var v any
+			dec := json.NewDecoder(bytes.NewReader(rec.Body.Bytes()))
+			dec.UseNumber()
+			if err := dec.Decode(&v); err != nil {
+				t.Fatalf("gwutest: %s %s: decode response: %v\nbody:\n%s", method, path, err, rec.Body)
+			}
+
+			if err := schema.Validate(v, components); err != nil {
+				t.Errorf("gwutest: %s %s: response does not match the schema of status %d:\n%v\nbody:\n%s",
+					method, path, rec.Code, err, rec.Body)
+			}
+		})
+	}
+}
+
+// findOperation returns the method, path, and operation object of the operation with the ID, or the method and path.
+func findOperation(doc map[string]any, name string) (string, string, map[string]any) {
+	paths, _ := doc["paths"].(map[string]any)
+	for path, ops := range paths {
+		ops, _ := ops.(map[string]any)
+		for method, op := range ops {
+			op, _ := op.(map[string]any)
+			method = strings.ToUpper(method)
+			if id, _ := op["operationId"].(string); id == name || method+" "+path == name {
+				return method, path, op
+			}
+		}
+	}
+
+	return "", "", nil
+}
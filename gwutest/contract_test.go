@@ -0,0 +1,32 @@
+package gwutest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestContract(t *testing.T) {
+	spec := gwu.NewSpec("poems", "1.0.0")
+	rt := gwu.NewRouter(gwu.Collect(spec), gwu.Errors(gwu.JSONError))
+	gwu.HandleRoute(rt, "POST /authors/{author}/poems", poemIn, createPoem,
+		gwu.Doc(gwu.Operation{OperationID: "createPoem", Status: http.StatusCreated}))
+	gwu.HandleRoute(rt, "GET /poems/{id}", gwu.PathInt("id"),
+		func(_ context.Context, id int64, _ gwu.HandleOpts) (poem, int, error) {
+			if id != 7 {
+				return poem{}, http.StatusNotFound, gwu.ErrNotFound
+			}
+
+			return poem{ID: id, Title: "Ode"}, http.StatusOK, nil
+		})
+
+	gwutest.Contract(t, spec, rt, []gwutest.ContractCase{
+		{Name: "by operation ID", Operation: "createPoem", Path: "/authors/keats/poems", Body: poem{Title: "Ode"}},
+		{Name: "by pattern", Operation: "GET /poems/{id}", Path: "/poems/7"},
+		{Name: "default error response", Operation: "GET /poems/{id}", Path: "/poems/8"},
+		{Name: "decode error", Operation: "createPoem", Path: "/authors/keats/poems", Body: "{"},
+	})
+}
@@ -0,0 +1,50 @@
+package gwutest
+
+import (
+	"context"
+
+	"github.com/jensilo/gwu"
+)
+
+// Opts returns HandleOpts with the options applied and a new CaptureLogger as logger, retrieve it with Captured.
+// Use it to call an Exec or CnIn directly in tests.
+func Opts(optFns ...gwu.HandleOptsFunc) gwu.HandleOpts {
+	var opts gwu.HandleOpts
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	opts.Log = Logger()
+
+	return opts
+}
+
+// Captured returns the CaptureLogger of HandleOpts created with Opts, or nil.
+func Captured(opts gwu.HandleOpts) *CaptureLogger {
+	log, _ := opts.Log.(*CaptureLogger)
+	return log
+}
+
+// CallExec calls the Exec with the input, like Handle calls it, and returns its output, status code, and error.
+// The Exec logs to the CaptureLogger stored in ctx with gwu.ContextWithLogger, or to a new one if ctx carries none.
+//
+// Example usage:
+//
+//	log := gwutest.Logger()
+//	ctx := gwu.ContextWithLogger(context.Background(), log)
+//	_, code, err := gwutest.CallExec(ctx, ctrl.ByID, ID("unknown"))
+//	if code != http.StatusNotFound || err == nil {
+//		t.Fatalf("got %d, %v", code, err)
+//	}
+//
+//	log.AssertLogged(t, slog.LevelDebug, "non-existent poem", "id", "unknown")
+func CallExec[In, Out any](
+	ctx context.Context, fn gwu.Exec[In, Out], in In, optFns ...gwu.HandleOptsFunc,
+) (Out, int, error) {
+	opts := Opts(optFns...)
+	if log, ok := gwu.LoggerFrom(ctx).(*CaptureLogger); ok {
+		opts.Log = log
+	}
+
+	return fn(gwu.ContextWithLogger(ctx, opts.Log), in, opts)
+}
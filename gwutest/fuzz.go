@@ -0,0 +1,89 @@
+package gwutest
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// JSONSeeds are seed bodies for FuzzCnIn with valid and pathological JSON: deep nesting, huge numbers, invalid
+// UTF-8, and truncated documents.
+var JSONSeeds = [][]byte{
+	[]byte(`{}`),
+	[]byte(`{"id":"1","title":"Ode","lines":["a","b"]}`),
+	[]byte(`[]`),
+	[]byte(`null`),
+	[]byte(``),
+	[]byte(`{"id":`),
+	[]byte(`{"id":"1"`),
+	[]byte(`{"id":1e999999}`),
+	[]byte(`{"id":-0.000000000000000000000000000000000000000001}`),
+	[]byte(`{"id":123456789012345678901234567890}`),
+	[]byte("{\"id\":\"\xff\xfe\"}"),
+	[]byte("{\"\xc3\x28\":1}"),
+	[]byte(`{"id":"\ud800"}`),
+	[]byte(strings.Repeat(`[`, 100000) + strings.Repeat(`]`, 100000)),
+	[]byte(strings.Repeat(`{"a":`, 20000) + `1` + strings.Repeat(`}`, 20000)),
+	[]byte(`{"a":1}{"b":2}`),
+	[]byte(`{"a":1,"a":2}`),
+}
+
+// FuzzCnIn fuzzes the CnIn with request bodies, seeded with the seeds, or JSONSeeds if none are given. Call it in a
+// fuzz test. The requests are POST requests with Content-Type `application/json`.
+//
+// FuzzCnIn fails if the CnIn panics, or returns an error that is not one of the safe errors, see errors.Is.
+// gwu.ErrDecodeRequest, gwu.ErrRequestTooLarge, and the errors of gwu.ValidationError and gwu.StatusError are
+// always safe.
+//
+// Example usage:
+//
+//	func FuzzPoemIn(f *testing.F) {
+//		gwutest.FuzzCnIn(f, gwu.JSON[Poem](), nil)
+//	}
+func FuzzCnIn[In any](f *testing.F, inFn gwu.CnIn[In], seeds [][]byte, safe ...error) {
+	if len(seeds) == 0 {
+		seeds = JSONSeeds
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+
+		defer func() {
+			if v := recover(); v != nil {
+				t.Fatalf("gwutest: CnIn panicked with body %q: %v", body, v)
+			}
+		}()
+
+		_, err := inFn(r, Opts())
+		if err != nil && !safeError(err, safe) {
+			t.Fatalf("gwutest: CnIn returned an unsafe error with body %q: %v", body, err)
+		}
+	})
+}
+
+// safeError reports whether err is one of the safe errors, see FuzzCnIn.
+func safeError(err error, safe []error) bool {
+	var valErr *gwu.ValidationError
+	var statusErr *gwu.StatusError
+	if errors.As(err, &valErr) || errors.As(err, &statusErr) {
+		return true
+	}
+
+	for _, s := range append(safe, gwu.ErrDecodeRequest, gwu.ErrRequestTooLarge) {
+		if errors.Is(err, s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package gwutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// update makes Golden rewrite the golden files. Test binaries importing gwutest cannot define an update flag of
+// their own.
+var update = flag.Bool("update", false, "rewrite the golden files of gwutest.Golden")
+
+// GoldenOption normalizes a response body before Golden compares it.
+type GoldenOption func(v any) any
+
+// Scrub replaces the values at the JSON paths with "<scrubbed>", use it for volatile fields. A path consists of
+// dot-separated keys, where "*" matches all keys of an object and all elements of an array, and a number matches an
+// array element.
+//
+// Example usage:
+//
+//	gwutest.Golden(t, rec, "testdata/poems.json", gwutest.Scrub("*.id", "*.author.id"))
+func Scrub(paths ...string) GoldenOption {
+	return func(v any) any {
+		for _, path := range paths {
+			v = scrub(v, splitPath(path), func(any) any { return "<scrubbed>" })
+		}
+
+		return v
+	}
+}
+
+// ScrubTimestamps replaces all strings that are RFC 3339 timestamps, like encoding/json writes time.Time, with
+// "<timestamp>".
+func ScrubTimestamps() GoldenOption {
+	return scrubStrings("<timestamp>", func(s string) bool {
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	})
+}
+
+// uuid matches the canonical text form of a UUID.
+var uuid = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ScrubUUIDs replaces all strings that are UUIDs with "<uuid>", use it for generated IDs.
+func ScrubUUIDs() GoldenOption {
+	return scrubStrings("<uuid>", uuid.MatchString)
+}
+
+// ScrubMatching replaces all strings matching the regular expression with the replacement, use it for generated
+// IDs of other formats.
+func ScrubMatching(re *regexp.Regexp, replacement string) GoldenOption {
+	return scrubStrings(replacement, re.MatchString)
+}
+
+// scrubStrings returns a GoldenOption replacing all strings for which match reports true.
+func scrubStrings(replacement string, match func(string) bool) GoldenOption {
+	var walk func(v any) any
+	walk = func(v any) any {
+		switch v := v.(type) {
+		case string:
+			if match(v) {
+				return replacement
+			}
+		case map[string]any:
+			for k, e := range v {
+				v[k] = walk(e)
+			}
+		case []any:
+			for i, e := range v {
+				v[i] = walk(e)
+			}
+		}
+
+		return v
+	}
+
+	return walk
+}
+
+// Golden fails the test if the normalized response body differs from the golden file. Run the test with the -update
+// flag to write the golden file instead, e.g. go test -run TestSpec -update.
+//
+// Golden normalizes JSON bodies by sorting the keys of objects, indenting, and applying the options, in order.
+// Other bodies are compared as they are.
+//
+// Example usage:
+//
+//	gwutest.Golden(t, rec, "testdata/openapi.json")
+func Golden(t testing.TB, rec *httptest.ResponseRecorder, file string, opts ...GoldenOption) {
+	t.Helper()
+
+	got := normalizeBody(rec.Body.Bytes(), opts)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("gwutest: update golden file: %v", err)
+		}
+
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatalf("gwutest: update golden file: %v", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("gwutest: read golden file, run the test with -update to create it: %v", err)
+	}
+
+	if !bytes.Equal(want, got) {
+		t.Errorf("gwutest: response body differs from %s (-want +got), run the test with -update to accept it:\n%s",
+			file, diff(string(want), string(got)))
+	}
+}
+
+// normalizeBody returns the normalized body, see Golden.
+func normalizeBody(body []byte, opts []GoldenOption) []byte {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return body
+	}
+
+	for _, opt := range opts {
+		v = opt(v)
+	}
+
+	return []byte(indent(v) + "\n")
+}
@@ -0,0 +1,43 @@
+package gwutest_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestGolden(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "poem.json")
+	want := "{\n  \"created\": \"<timestamp>\",\n  \"id\": \"<uuid>\",\n  \"title\": \"Ode\"\n}\n"
+	if err := os.WriteFile(file, []byte(want), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The keys are sorted and the volatile fields scrubbed.
+	rec := jsonRecorder(http.StatusOK, `{"title": "Ode", "id": "5f0c6b1e-3a59-4e0b-9d2c-2a7c6f1e8b4d",
+		"created": "2026-10-14T12:00:00Z"}`)
+	gwutest.Golden(t, rec, file, gwutest.ScrubTimestamps(), gwutest.ScrubUUIDs())
+
+	got := failures(func(t testing.TB) {
+		gwutest.Golden(t, jsonRecorder(http.StatusOK, `{"title": "Ode"}`), file)
+	})
+
+	if len(got) != 1 || !strings.Contains(got[0], `-   "created": "<timestamp>",`) ||
+		!strings.Contains(got[0], "run the test with -update") {
+		t.Errorf("failures %q, want a diff of the removed fields", got)
+	}
+}
+
+func TestGoldenScrub(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "poems.json")
+	want := "[\n  {\n    \"id\": \"<scrubbed>\",\n    \"title\": \"Ode\"\n  }\n]\n"
+	if err := os.WriteFile(file, []byte(want), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	gwutest.Golden(t, jsonRecorder(http.StatusOK, `[{"id": 7, "title": "Ode"}]`), file, gwutest.Scrub("*.id"))
+}
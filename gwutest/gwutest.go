@@ -0,0 +1,143 @@
+// Package gwutest provides helpers to test gwu handlers, Exec, and CnIn functions.
+package gwutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// Option configures a request of Do.
+type Option func(*config)
+
+type config struct {
+	header     http.Header
+	pathValues []string
+	status     int
+}
+
+// Header sets a header of the request.
+func Header(key, value string) Option {
+	return func(c *config) {
+		c.header.Set(key, value)
+	}
+}
+
+// PathValue replaces the wildcard {key} or {key...} of the target with the escaped value, so the target can be
+// written like the handler's pattern.
+//
+// Example usage:
+//
+//	gwutest.Do[any, Poem](t, h, http.MethodGet, "/poem/{id}", nil, gwutest.PathValue("id", "7"))
+func PathValue(key, value string) Option {
+	return func(c *config) {
+		c.pathValues = append(c.pathValues, key, value)
+	}
+}
+
+// Status makes Do fail the test if the response has another status code.
+func Status(code int) Option {
+	return func(c *config) {
+		c.status = code
+	}
+}
+
+// Do sends a request with the JSON-encoded input to the handler, and decodes the JSON response into Out.
+// A nil input, like the input of a handler with gwu.Empty, sends no body. A response without body decodes to the
+// zero Out. Do fails the test with the raw body if the response cannot be decoded.
+//
+// The returned response's body can be read again.
+//
+// Example usage:
+//
+//	poem, resp := gwutest.Do[NewPoem, Poem](t, h, http.MethodPost, "/poem", NewPoem{Title: "Ode"},
+//		gwutest.Status(http.StatusCreated))
+func Do[In, Out any](t testing.TB, h http.Handler, method, target string, in In, opts ...Option) (Out, *http.Response) {
+	t.Helper()
+
+	c := config{header: make(http.Header)}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	for i := 0; i < len(c.pathValues); i += 2 {
+		key, value := c.pathValues[i], c.pathValues[i+1]
+		esc := pathEscape(value, strings.Contains(target, "{"+key+"...}"))
+		target = strings.NewReplacer("{"+key+"}", esc, "{"+key+"...}", esc).Replace(target)
+	}
+
+	var body io.Reader
+	if !isNil(in) {
+		b, err := json.Marshal(in)
+		if err != nil {
+			t.Fatalf("gwutest: encode request: %v", err)
+		}
+
+		body = bytes.NewReader(b)
+	}
+
+	r := httptest.NewRequest(method, target, body)
+	if body != nil {
+		r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+	}
+
+	for k, v := range c.header {
+		r.Header[k] = v
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	resp := rec.Result()
+
+	if c.status != 0 && rec.Code != c.status {
+		t.Errorf("gwutest: %s %s: status %d, want %d\nbody:\n%s", method, target, rec.Code, c.status, rec.Body)
+	}
+
+	var out Out
+	if rec.Body.Len() == 0 {
+		return out, resp
+	}
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("gwutest: %s %s: decode response into %T: %v\nstatus: %d\nbody:\n%s",
+			method, target, out, err, rec.Code, rec.Body)
+	}
+
+	return out, resp
+}
+
+// isNil reports whether v is nil or a nil pointer, map, or slice.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// pathEscape escapes a path value, the segments of a remainder separately.
+func pathEscape(v string, rest bool) string {
+	if !rest {
+		return url.PathEscape(v)
+	}
+
+	segs := strings.Split(v, "/")
+	for i, seg := range segs {
+		segs[i] = url.PathEscape(seg)
+	}
+
+	return strings.Join(segs, "/")
+}
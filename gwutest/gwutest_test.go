@@ -0,0 +1,139 @@
+package gwutest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+type poem struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author,omitempty"`
+	Lang   string `json:"lang,omitempty"`
+}
+
+// fakeT records the failures of a helper, Fatalf ends the goroutine like testing.T does.
+type fakeT struct {
+	testing.TB
+	mu       sync.Mutex
+	failures []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Fatalf(format string, args ...any) {
+	f.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// failures runs fn with a fakeT and returns the failures it reported.
+func failures(fn func(t testing.TB)) []string {
+	f := &fakeT{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(f)
+	}()
+
+	<-done
+
+	return f.failures
+}
+
+// poemIn decodes the poem, and sets its author from the path and its language from the Accept-Language header.
+func poemIn(r *http.Request, _ gwu.HandleOpts) (poem, error) {
+	var p poem
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		return p, gwu.WithStatus(http.StatusBadRequest, err)
+	}
+
+	p.Author, p.Lang = r.PathValue("author"), r.Header.Get("Accept-Language")
+
+	return p, nil
+}
+
+func createPoem(_ context.Context, p poem, _ gwu.HandleOpts) (poem, int, error) {
+	p.ID = 7
+	return p, http.StatusCreated, nil
+}
+
+func TestDo(t *testing.T) {
+	h := gwu.Handle(poemIn, createPoem)
+	rt := http.NewServeMux()
+	rt.Handle("POST /authors/{author}/poems", h)
+
+	got, resp := gwutest.Do[poem, poem](t, rt, http.MethodPost, "/authors/{author}/poems", poem{Title: "Ode"},
+		gwutest.PathValue("author", "John Keats"), gwutest.Header("Accept-Language", "en"),
+		gwutest.Status(http.StatusCreated))
+
+	if want := (poem{ID: 7, Title: "Ode", Author: "John Keats", Lang: "en"}); got != want {
+		t.Errorf("Do %+v, want %+v", got, want)
+	}
+
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Content-Type") != gwu.ContentTypeJSON {
+		t.Errorf("response %d %s, want 201 with JSON", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestDoRestPathValue(t *testing.T) {
+	rt := http.NewServeMux()
+	gwu.HandleRoute(rt, "GET /files/{path...}", gwu.PathVal("path"),
+		func(_ context.Context, path string, _ gwu.HandleOpts) (string, int, error) {
+			return path, http.StatusOK, nil
+		})
+
+	// The segments of a remainder are escaped separately, the slashes stay.
+	got, _ := gwutest.Do[any, string](t, rt, http.MethodGet, "/files/{path...}", nil,
+		gwutest.PathValue("path", "odes/to a nightingale.txt"), gwutest.Status(http.StatusOK))
+	if got != "odes/to a nightingale.txt" {
+		t.Errorf("path %q, want odes/to a nightingale.txt", got)
+	}
+}
+
+func TestDoWithoutBody(t *testing.T) {
+	var body bool
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	}, gwu.Before(func(r *http.Request, _ gwu.HandleOpts) error {
+		body = r.ContentLength != 0 || r.Header.Get("Content-Type") != ""
+		return nil
+	}))
+
+	// A nil input sends no body, and a response without body decodes to the zero Out.
+	got, resp := gwutest.Do[*poem, poem](t, h, http.MethodDelete, "/poems/7", nil)
+	if body || got != (poem{}) || resp.StatusCode != http.StatusNoContent {
+		t.Errorf("body sent %v, Do %+v and status %d, want no body, the zero poem, and 204", body, got,
+			resp.StatusCode)
+	}
+}
+
+func TestDoFailures(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (string, int, error) {
+		return "not a poem", http.StatusOK, nil
+	})
+
+	got := failures(func(t testing.TB) {
+		gwutest.Do[any, poem](t, h, http.MethodGet, "/poems/7", nil, gwutest.Status(http.StatusNotFound))
+	})
+
+	if len(got) != 2 || !strings.Contains(got[0], "status 200, want 404") ||
+		!strings.Contains(got[1], "decode response into gwutest_test.poem") || !strings.Contains(got[1], "not a poem") {
+		t.Errorf("failures %q, want the status mismatch and the decode error with the raw body", got)
+	}
+}
@@ -0,0 +1,126 @@
+package gwutest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// maxFailures is the number of failed responses Hammer reports in detail.
+const maxFailures = 10
+
+// Hammer sends every request iterations times to the handler, from parallelism goroutines at once, and fails the
+// test for every response with a status code that is no 2xx status code, or another status code than the first
+// response to the same request. After all requests, it runs the invariants, e.g. to check the length of a store.
+//
+// Hammer sends a clone of the request with a copy of its body every time, the requests are never sent themselves.
+// Run it with the race detector, go test -race, to catch state shared between requests.
+//
+// Example usage:
+//
+//	gwutest.Hammer(t, rt, []*http.Request{
+//		httptest.NewRequest(http.MethodPost, "/poem", strings.NewReader(`{"title":"Ode"}`)),
+//		httptest.NewRequest(http.MethodGet, "/poems", nil),
+//	}, 8, 100, func(t testing.TB) {
+//		if n := store.Len(); n != 800 {
+//			t.Errorf("store has %d poems, want 800", n)
+//		}
+//	})
+func Hammer(
+	t testing.TB, h http.Handler, reqs []*http.Request, parallelism, iterations int, invariants ...func(t testing.TB),
+) {
+	t.Helper()
+
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	bodies := make([][]byte, len(reqs))
+	for i, r := range reqs {
+		if r.Body == nil {
+			continue
+		}
+
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("gwutest: read request body of %s %s: %v", r.Method, r.URL, err)
+		}
+
+		r.Body.Close()
+		bodies[i] = b
+	}
+
+	type job struct{ req, iteration int }
+	jobs := make(chan job)
+	go func() {
+		defer close(jobs)
+		for it := 0; it < iterations; it++ {
+			for i := range reqs {
+				jobs <- job{req: i, iteration: it}
+			}
+		}
+	}()
+
+	var (
+		mu       sync.Mutex
+		first    = make([]int, len(reqs))
+		failures []string
+		failed   int
+	)
+
+	fail := func(msg string) {
+		failed++
+		if len(failures) < maxFailures {
+			failures = append(failures, msg)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < parallelism; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				r := reqs[j.req].Clone(reqs[j.req].Context())
+				if bodies[j.req] != nil {
+					r.Body = io.NopCloser(bytes.NewReader(bodies[j.req]))
+				}
+
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, r)
+
+				mu.Lock()
+				switch {
+				case rec.Code < 200 || rec.Code > 299:
+					fail(fmt.Sprintf("%s %s (iteration %d): status %d\nbody:\n%s",
+						r.Method, r.URL, j.iteration, rec.Code, bytes.TrimSpace(rec.Body.Bytes())))
+				case first[j.req] == 0:
+					first[j.req] = rec.Code
+				case first[j.req] != rec.Code:
+					fail(fmt.Sprintf("%s %s (iteration %d): status %d, first response had %d",
+						r.Method, r.URL, j.iteration, rec.Code, first[j.req]))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for _, msg := range failures {
+		t.Errorf("gwutest: %s", msg)
+	}
+
+	if failed > len(failures) {
+		t.Errorf("gwutest: %d more failed responses", failed-len(failures))
+	}
+
+	for _, inv := range invariants {
+		inv(t)
+	}
+}
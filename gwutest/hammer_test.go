@@ -0,0 +1,68 @@
+package gwutest_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestHammer(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string]int{}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies[r.Method+" "+string(b)]++
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// Every request is sent with its body in every iteration.
+	invariant := false
+	gwutest.Hammer(t, h, []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/poems", strings.NewReader(`{"title":"Ode"}`)),
+		httptest.NewRequest(http.MethodGet, "/poems", nil),
+	}, 4, 25, func(testing.TB) { invariant = true })
+
+	if bodies[`POST {"title":"Ode"}`] != 25 || bodies["GET "] != 25 || !invariant {
+		t.Errorf("requests %v, invariant ran %v, want 25 of each and the invariant", bodies, invariant)
+	}
+}
+
+func TestHammerFailures(t *testing.T) {
+	var n atomic.Int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case n.Add(1) == 3:
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+
+	got := failures(func(t testing.TB) {
+		gwutest.Hammer(t, h, []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/poems", nil),
+			httptest.NewRequest(http.MethodGet, "/missing", nil),
+		}, 1, 15)
+	})
+
+	// Ten failures in detail and the count of the others: the 201 differing from the first 200, and the 404s.
+	if len(got) != 11 || !strings.Contains(got[10], "6 more failed responses") {
+		t.Fatalf("failures %q, want 10 and the count of the other 6", got)
+	}
+
+	joined := strings.Join(got, "\n")
+	if !strings.Contains(joined, "status 201, first response had 200") || !strings.Contains(joined, "status 404") {
+		t.Errorf("failures %q, want the changed status and the 404", got)
+	}
+}
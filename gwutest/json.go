@@ -0,0 +1,193 @@
+package gwutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// AssertJSON fails the test if the response does not have the status code and a JSON body equal to want.
+// It decodes the body into T, so fields T does not have are not compared. The ignored fields are removed from both
+// sides before comparing, they are JSON paths of dot-separated keys, where "*" matches all keys of an object and all
+// elements of an array, and a number matches an array element.
+// On failure, AssertJSON reports a diff and the raw body.
+//
+// Example usage:
+//
+//	gwutest.AssertJSON(t, rec, http.StatusCreated, Poem{Title: "Ode"}, "id", "lines.*.created")
+func AssertJSON[T any](t testing.TB, rec *httptest.ResponseRecorder, wantStatus int, want T, ignore ...string) {
+	t.Helper()
+
+	body := rec.Body.Bytes()
+	if rec.Code != wantStatus {
+		t.Errorf("gwutest: status %d, want %d\nbody:\n%s", rec.Code, wantStatus, body)
+	}
+
+	if mt, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type")); mt != gwu.ContentTypeJSON {
+		t.Errorf("gwutest: Content-Type %q, want application/json\nbody:\n%s", rec.Header().Get("Content-Type"), body)
+	}
+
+	var got T
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("gwutest: decode response into %T: %v\nbody:\n%s", got, err, body)
+	}
+
+	gotV, wantV := normalize(t, got), normalize(t, want)
+	for _, path := range ignore {
+		gotV, wantV = scrub(gotV, splitPath(path), nil), scrub(wantV, splitPath(path), nil)
+	}
+
+	if !reflect.DeepEqual(gotV, wantV) {
+		t.Errorf("gwutest: response body mismatch (-want +got):\n%s\nbody:\n%s", diff(indent(wantV), indent(gotV)), body)
+	}
+}
+
+// AssertError fails the test if the response does not have the status code and an error message containing msg.
+// It reads the message from a gwu.ErrorBody written by gwu.JSONError, or from a plain text body written by
+// gwu.TextError.
+func AssertError(t testing.TB, rec *httptest.ResponseRecorder, wantStatus int, msg string) {
+	t.Helper()
+
+	body := rec.Body.Bytes()
+	if rec.Code != wantStatus {
+		t.Errorf("gwutest: status %d, want %d\nbody:\n%s", rec.Code, wantStatus, body)
+	}
+
+	got := strings.TrimSpace(string(body))
+	if mt, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type")); mt == gwu.ContentTypeJSON {
+		var e gwu.ErrorBody
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Fatalf("gwutest: decode error body: %v\nbody:\n%s", err, body)
+		}
+
+		got = e.Error
+	}
+
+	if !strings.Contains(got, msg) {
+		t.Errorf("gwutest: error message %q does not contain %q\nbody:\n%s", got, msg, body)
+	}
+}
+
+// normalize returns v encoded as JSON and decoded into generic values.
+func normalize(t testing.TB, v any) any {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("gwutest: encode %T: %v", v, err)
+	}
+
+	var n any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&n); err != nil {
+		t.Fatalf("gwutest: decode %T: %v", v, err)
+	}
+
+	return n
+}
+
+// indent returns the generic JSON value encoded with sorted keys and indentation, without escaping HTML.
+func indent(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// splitPath splits a JSON path into its keys.
+func splitPath(path string) []string {
+	return strings.Split(path, ".")
+}
+
+// scrub replaces the values at the path in the generic JSON value with the result of fn, or removes them if fn is
+// nil. A "*" key matches all keys of an object and all elements of an array, a number matches an array element.
+func scrub(v any, path []string, fn func(any) any) any {
+	if len(path) == 0 {
+		return v
+	}
+
+	key, rest := path[0], path[1:]
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			if key != "*" && key != k {
+				continue
+			}
+
+			if len(rest) > 0 {
+				v[k] = scrub(e, rest, fn)
+			} else if fn == nil {
+				delete(v, k)
+			} else {
+				v[k] = fn(e)
+			}
+		}
+	case []any:
+		for i, e := range v {
+			if key != "*" && key != strconv.Itoa(i) {
+				continue
+			}
+
+			if len(rest) > 0 {
+				v[i] = scrub(e, rest, fn)
+			} else if fn == nil {
+				v[i] = nil
+			} else {
+				v[i] = fn(e)
+			}
+		}
+	}
+
+	return v
+}
+
+// diff returns a line diff of want and got, removed lines are prefixed with "-", added lines with "+".
+func diff(want, got string) string {
+	a, b := strings.Split(want, "\n"), strings.Split(got, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			sb.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,72 @@
+package gwutest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+type line struct {
+	Text    string `json:"text"`
+	Created string `json:"created"`
+}
+
+type linedPoem struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	Lines []line `json:"lines"`
+}
+
+// jsonRecorder returns a recorder of a JSON response with the status and body.
+func jsonRecorder(status int, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", gwu.ContentTypeJSON)
+	rec.WriteHeader(status)
+	rec.Body.WriteString(body)
+
+	return rec
+}
+
+func TestAssertJSON(t *testing.T) {
+	rec := jsonRecorder(http.StatusCreated, `{"id": 7, "title": "Ode", "draft": true, "lines": [
+		{"text": "Thou", "created": "2026-10-14T12:00:00Z"}, {"text": "still", "created": "2026-10-14T12:01:00Z"}]}`)
+
+	// The ignored fields differ, and draft is not a field of linedPoem.
+	want := linedPoem{Title: "Ode", Lines: []line{{Text: "Thou"}, {Text: "still"}}}
+	gwutest.AssertJSON(t, rec, http.StatusCreated, want, "id", "lines.*.created")
+
+	// Generic values compare all fields.
+	var generic any
+	_ = json.Unmarshal([]byte(`{"a": [1, {"b": null}]}`), &generic)
+	gwutest.AssertJSON(t, jsonRecorder(http.StatusOK, `{"a":[1,{"b":null}]}`), http.StatusOK, generic)
+}
+
+func TestAssertJSONFailures(t *testing.T) {
+	rec := jsonRecorder(http.StatusOK, `{"id": 7, "title": "Ode", "lines": [{"text": "Thou"}]}`)
+	got := failures(func(t testing.TB) {
+		gwutest.AssertJSON(t, rec, http.StatusCreated, linedPoem{ID: 7, Title: "Ode", Lines: []line{{Text: "Thy"}}})
+	})
+
+	if len(got) != 2 || !strings.Contains(got[0], "status 200, want 201") {
+		t.Fatalf("failures %q, want the status and the body mismatch", got)
+	}
+
+	if !strings.Contains(got[1], `-       "text": "Thy"`) || !strings.Contains(got[1], `+       "text": "Thou"`) {
+		t.Errorf("failure %s, want a diff of the text", got[1])
+	}
+
+	got = failures(func(t testing.TB) {
+		rec := httptest.NewRecorder()
+		rec.Body.WriteString("Ode")
+		gwutest.AssertJSON(t, rec, http.StatusOK, linedPoem{})
+	})
+
+	if len(got) != 2 || !strings.Contains(got[0], "Content-Type") || !strings.Contains(got[1], "decode response") {
+		t.Errorf("failures %q, want the Content-Type and the decode error", got)
+	}
+}
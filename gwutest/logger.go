@@ -0,0 +1,167 @@
+package gwutest
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Entry is a log record captured by a CaptureLogger.
+type Entry struct {
+	Level slog.Level
+	Msg   string
+	Attrs map[string]any
+}
+
+// String returns the entry like slog.TextHandler formats it, without time.
+func (e Entry) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "level=%s msg=%q", e.Level, e.Msg)
+	keys := make([]string, 0, len(e.Attrs))
+	for k := range e.Attrs {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%v", k, e.Attrs[k])
+	}
+
+	return sb.String()
+}
+
+// CaptureLogger is a gwu.LeveledLogger that captures its records for assertions, create it with Logger.
+// CaptureLogger is safe for concurrent use, handlers may log from many request goroutines.
+type CaptureLogger struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Logger returns an empty CaptureLogger.
+func Logger() *CaptureLogger {
+	return &CaptureLogger{}
+}
+
+func (l *CaptureLogger) Debug(msg string, args ...any) { l.log(slog.LevelDebug, msg, args) }
+func (l *CaptureLogger) Info(msg string, args ...any)  { l.log(slog.LevelInfo, msg, args) }
+func (l *CaptureLogger) Warn(msg string, args ...any)  { l.log(slog.LevelWarn, msg, args) }
+func (l *CaptureLogger) Error(msg string, args ...any) { l.log(slog.LevelError, msg, args) }
+
+// log captures a record, the args are key-value pairs or slog.Attr like for slog.Logger.
+func (l *CaptureLogger) log(level slog.Level, msg string, args []any) {
+	attrs := make(map[string]any)
+	r := slog.NewRecord(time.Time{}, level, msg, 0)
+	r.Add(args...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.Resolve().Any()
+		return true
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, Entry{Level: level, Msg: msg, Attrs: attrs})
+}
+
+// Entries returns the captured entries in the order they were logged.
+func (l *CaptureLogger) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]Entry(nil), l.entries...)
+}
+
+// Reset discards the captured entries.
+func (l *CaptureLogger) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = nil
+}
+
+// Filter returns the captured entries with the level.
+func (l *CaptureLogger) Filter(level slog.Level) []Entry {
+	var entries []Entry
+	for _, e := range l.Entries() {
+		if e.Level == level {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// Contains reports whether an entry of any level with a message containing msg and the key-value pairs was logged.
+// Values are compared with reflect.DeepEqual, or by their formatted value if the value is a string, so an error
+// attribute matches its message.
+func (l *CaptureLogger) Contains(msg string, kvs ...any) bool {
+	return contains(l.Entries(), msg, kvs)
+}
+
+// Logged reports whether an entry with the level, a message containing msg, and the key-value pairs was logged, like
+// Contains.
+func (l *CaptureLogger) Logged(level slog.Level, msg string, kvs ...any) bool {
+	return contains(l.Filter(level), msg, kvs)
+}
+
+// contains reports whether one of the entries has a message containing msg and the key-value pairs.
+func contains(entries []Entry, msg string, kvs []any) bool {
+	for _, e := range entries {
+		if strings.Contains(e.Msg, msg) && hasAttrs(e, kvs) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AssertLogged fails the test if no entry matches, see Logged. It lists the captured entries on failure.
+//
+// Example usage:
+//
+//	log.AssertLogged(t, slog.LevelDebug, "non-existent poem", "id", id)
+func (l *CaptureLogger) AssertLogged(t testing.TB, level slog.Level, msg string, kvs ...any) {
+	t.Helper()
+
+	if l.Logged(level, msg, kvs...) {
+		return
+	}
+
+	var sb strings.Builder
+	for _, e := range l.Entries() {
+		sb.WriteString("\n\t" + e.String())
+	}
+
+	t.Errorf("gwutest: no %s entry with message %q and attributes %v, captured:%s", level, msg, kvs, sb.String())
+}
+
+// hasAttrs reports whether the entry has the key-value pairs.
+func hasAttrs(e Entry, kvs []any) bool {
+	for i := 0; i+1 < len(kvs); i += 2 {
+		key, ok := kvs[i].(string)
+		if !ok {
+			return false
+		}
+
+		got, ok := e.Attrs[key]
+		if !ok {
+			return false
+		}
+
+		want := kvs[i+1]
+		if s, ok := want.(string); ok && fmt.Sprint(got) == s {
+			continue
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			return false
+		}
+	}
+
+	return true
+}
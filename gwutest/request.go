@@ -0,0 +1,84 @@
+package gwutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// Request returns a request for the path, matched against the pattern by an http.ServeMux, so the path values of
+// the pattern are set like in a real handler. A CnIn reading path values with r.PathValue only works with a matched
+// request, a request created with httptest.NewRequest has no path values.
+//
+// The body is sent as is if it is a string, []byte, or io.Reader, a nil body sends none, and any other body is
+// JSON-encoded. The mods modify the request before it is matched, e.g. to set headers.
+//
+// Request panics if the path does not match the pattern, or the pattern or body are invalid.
+//
+// Example usage:
+//
+//	r := gwutest.Request(http.MethodGet, "GET /poem/{id}", "/poem/7", nil)
+//	r.PathValue("id") // "7"
+func Request(method, pattern, path string, body any, mods ...func(*http.Request)) *http.Request {
+	b, isJSON := requestBody(body)
+	r := httptest.NewRequest(method, path, b)
+	if isJSON {
+		r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+	}
+
+	for _, mod := range mods {
+		mod(r)
+	}
+
+	var matched *http.Request
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, func(_ http.ResponseWriter, r *http.Request) {
+		matched = r
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), r)
+	if matched == nil {
+		panic(fmt.Sprintf("gwutest: %s %s does not match the pattern %q", method, path, pattern))
+	}
+
+	return matched
+}
+
+// requestBody returns the reader of a body for Request, and whether it is JSON-encoded.
+func requestBody(body any) (io.Reader, bool) {
+	switch b := body.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return strings.NewReader(b), false
+	case []byte:
+		return bytes.NewReader(b), false
+	case io.Reader:
+		return b, false
+	default:
+		j, err := json.Marshal(b)
+		if err != nil {
+			panic(fmt.Sprintf("gwutest: encode request body: %v", err))
+		}
+
+		return bytes.NewReader(j), true
+	}
+}
+
+// RunCnIn runs the CnIn with the request and HandleOpts created with Opts, and returns its input and error.
+//
+// Example usage:
+//
+//	id, err := gwutest.RunCnIn(t, IDIn("id"), gwutest.Request(http.MethodGet, "/poem/{id}", "/poem/7", nil))
+func RunCnIn[In any](t testing.TB, inFn gwu.CnIn[In], r *http.Request, optFns ...gwu.HandleOptsFunc) (In, error) {
+	t.Helper()
+
+	return inFn(r, Opts(optFns...))
+}
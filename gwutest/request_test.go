@@ -0,0 +1,53 @@
+package gwutest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestRequest(t *testing.T) {
+	r := gwutest.Request(http.MethodPost, "POST /authors/{author}/poems/{id}", "/authors/keats/poems/7?draft=1",
+		poem{Title: "Ode"}, func(r *http.Request) { r.Header.Set("Accept-Language", "en") })
+
+	if r.PathValue("author") != "keats" || r.PathValue("id") != "7" || r.URL.Query().Get("draft") != "1" {
+		t.Errorf("path values %q %q, query %q, want keats, 7, and the query", r.PathValue("author"),
+			r.PathValue("id"), r.URL.RawQuery)
+	}
+
+	if r.Header.Get("Content-Type") != gwu.ContentTypeJSON || r.Header.Get("Accept-Language") != "en" {
+		t.Errorf("header %v, want the JSON Content-Type and the header of the mod", r.Header)
+	}
+
+	// A string body is sent as is, without Content-Type.
+	r = gwutest.Request(http.MethodPost, "POST /poems", "/poems", "title=Ode")
+	if r.Header.Get("Content-Type") != "" || r.ContentLength != int64(len("title=Ode")) {
+		t.Errorf("Content-Type %q, length %d, want the raw body", r.Header.Get("Content-Type"), r.ContentLength)
+	}
+}
+
+func TestRequestMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Request did not panic for a path not matching the pattern")
+		}
+	}()
+
+	gwutest.Request(http.MethodGet, "GET /poems/{id}", "/authors/7", nil)
+}
+
+func TestRunCnIn(t *testing.T) {
+	got, err := gwutest.RunCnIn(t, poemIn, gwutest.Request(http.MethodPost, "POST /authors/{author}/poems",
+		"/authors/keats/poems", poem{Title: "Ode"}))
+	if err != nil || got != (poem{Title: "Ode", Author: "keats"}) {
+		t.Errorf("RunCnIn %+v, %v, want the poem of keats", got, err)
+	}
+
+	id, err := gwutest.RunCnIn(t, gwu.PathInt("id"), gwutest.Request(http.MethodGet, "GET /poems/{id}",
+		"/poems/seven", nil))
+	if id != 0 || err == nil {
+		t.Errorf("RunCnIn %d, %v, want the error of the invalid id", id, err)
+	}
+}
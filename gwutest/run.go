@@ -0,0 +1,140 @@
+package gwutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// Case is a request to a handler and the expected response, see Run.
+type Case struct {
+	Name   string
+	Method string
+	Path   string
+	Header http.Header
+	// Body is JSON-encoded, a nil body sends none.
+	Body any
+
+	// WantStatus is the expected status code, zero skips the check.
+	WantStatus int
+	// WantBody is the expected body: a BodyContains, a func([]byte) error predicate, or a value compared to the
+	// JSON body like AssertJSON. A nil WantBody skips the check.
+	WantBody any
+	// WantLog are the entries the handler is expected to log to Log.
+	WantLog []WantLog
+	// Log is the logger of the handler, it is reset before the case runs. Required for WantLog.
+	Log *CaptureLogger
+
+	// Parallel runs the case in parallel with the other parallel cases.
+	Parallel bool
+	// Setup runs before the request, e.g. to seed a store, and Teardown after the case.
+	Setup    func(t *testing.T)
+	Teardown func(t *testing.T)
+}
+
+// BodyContains is a WantBody matching bodies that contain the string.
+type BodyContains string
+
+// WantLog is an expected log entry with the level and a message containing Msg.
+type WantLog struct {
+	Level slog.Level
+	Msg   string
+}
+
+// Run runs every case as a subtest against the handler.
+//
+// Cases sharing a Log must not run in parallel.
+//
+// Example usage:
+//
+//	gwutest.Run(t, rt, []gwutest.Case{
+//		{Name: "unknown poem", Method: http.MethodGet, Path: "/poem/0", WantStatus: http.StatusNotFound,
+//			WantBody: gwutest.BodyContains("not found"), Log: log,
+//			WantLog: []gwutest.WantLog{{Level: slog.LevelDebug, Msg: "non-existent poem"}}},
+//	})
+func Run(t *testing.T, h http.Handler, cases []Case) {
+	t.Helper()
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if c.Parallel {
+				t.Parallel()
+			}
+
+			if c.Teardown != nil {
+				t.Cleanup(func() { c.Teardown(t) })
+			}
+
+			if c.Setup != nil {
+				c.Setup(t)
+			}
+
+			if c.Log != nil {
+				c.Log.Reset()
+			}
+
+			b, isJSON := requestBody(c.Body)
+			r := httptest.NewRequest(c.Method, c.Path, b)
+			if isJSON {
+				r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+			}
+
+			for k, v := range c.Header {
+				r.Header[k] = v
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if c.WantStatus != 0 && rec.Code != c.WantStatus {
+				t.Errorf("gwutest: status %d, want %d\nbody:\n%s", rec.Code, c.WantStatus, rec.Body)
+			}
+
+			checkBody(t, rec.Body.Bytes(), c.WantBody)
+
+			for _, want := range c.WantLog {
+				if c.Log == nil {
+					t.Fatalf("gwutest: WantLog requires a Log")
+				}
+
+				c.Log.AssertLogged(t, want.Level, want.Msg)
+			}
+		})
+	}
+}
+
+// checkBody fails the test if the body does not match the WantBody of a Case.
+func checkBody(t *testing.T, body []byte, want any) {
+	t.Helper()
+
+	switch want := want.(type) {
+	case nil:
+	case BodyContains:
+		if !bytes.Contains(body, []byte(want)) {
+			t.Errorf("gwutest: body does not contain %q\nbody:\n%s", string(want), body)
+		}
+	case func([]byte) error:
+		if err := want(body); err != nil {
+			t.Errorf("gwutest: body: %v\nbody:\n%s", err, body)
+		}
+	default:
+		var got any
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&got); err != nil {
+			t.Fatalf("gwutest: decode response: %v\nbody:\n%s", err, body)
+		}
+
+		if wantV := normalize(t, want); !reflect.DeepEqual(got, wantV) {
+			t.Errorf("gwutest: response body mismatch (-want +got):\n%s\nbody:\n%s",
+				diff(indent(wantV), indent(got)), strings.TrimSpace(string(body)))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package gwutest_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestRun(t *testing.T) {
+	log := gwutest.Logger()
+	rt := http.NewServeMux()
+	gwu.HandleRoute(rt, "POST /authors/{author}/poems", poemIn, createPoem, gwu.Log(log))
+	gwu.HandleRoute(rt, "GET /poems/{id}", gwu.PathInt("id"),
+		func(_ context.Context, id int64, opts gwu.HandleOpts) (poem, int, error) {
+			opts.Log.Debug("requested non-existent poem", "id", id)
+			return poem{}, http.StatusNotFound, errors.New("no poem")
+		}, gwu.Log(log))
+
+	var steps []string
+	gwutest.Run(t, rt, []gwutest.Case{
+		{Name: "value", Method: http.MethodPost, Path: "/authors/keats/poems", Body: poem{Title: "Ode"},
+			Header: http.Header{"Accept-Language": {"en"}}, WantStatus: http.StatusCreated,
+			WantBody: poem{ID: 7, Title: "Ode", Author: "keats", Lang: "en"}},
+		{Name: "contains", Method: http.MethodGet, Path: "/poems/7", WantStatus: http.StatusNotFound,
+			WantBody: gwutest.BodyContains("no poem"), Log: log,
+			WantLog: []gwutest.WantLog{{Level: slog.LevelDebug, Msg: "non-existent poem"}}},
+		{Name: "predicate", Method: http.MethodPost, Path: "/authors/keats/poems", Body: "{",
+			WantStatus: http.StatusBadRequest, WantBody: func(b []byte) error {
+				if len(b) == 0 {
+					return errors.New("empty")
+				}
+
+				return nil
+			}},
+		{Name: "hooks", Method: http.MethodGet, Path: "/poems/7",
+			Setup:    func(*testing.T) { steps = append(steps, "setup") },
+			Teardown: func(*testing.T) { steps = append(steps, "teardown") }},
+	})
+
+	if len(steps) != 2 || steps[0] != "setup" || steps[1] != "teardown" {
+		t.Errorf("steps %v, want the setup and then the teardown", steps)
+	}
+}
+
+// TestRunParallel runs the parallel cases after the others, once Run returned.
+func TestRunParallel(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func(*testing.T) {
+		return func(*testing.T) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			order = append(order, name)
+		}
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	t.Run("cases", func(t *testing.T) {
+		gwutest.Run(t, h, []gwutest.Case{
+			{Name: "parallel", Method: http.MethodGet, Path: "/", Parallel: true, Setup: record("parallel")},
+			{Name: "serial", Method: http.MethodGet, Path: "/", Setup: record("serial")},
+		})
+		record("returned")(t)
+	})
+
+	if len(order) != 3 || order[0] != "serial" || order[1] != "returned" || order[2] != "parallel" {
+		t.Errorf("order %v, want the parallel case after Run returned", order)
+	}
+}
@@ -0,0 +1,155 @@
+package gwutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// Client sends JSON requests to a test server, create it with Server and call it with Get, Post, Put, Patch, and
+// Delete.
+type Client struct {
+	// URL is the base URL of the server.
+	URL string
+	// Header is sent with every request, set headers shared by the requests of a test with it.
+	Header http.Header
+	// HTTP is the underlying client, it defaults to the client of the test server.
+	HTTP *http.Client
+}
+
+// Server starts an httptest.Server for the handler, closed when the test finishes, and returns a Client for it.
+//
+// Example usage:
+//
+//	c := gwutest.Server(t, rt)
+//	c.Header.Set("Authorization", "Bearer test")
+//	poem, _, err := gwutest.Post[NewPoem, Poem](c, "/poem", NewPoem{Title: "Ode"})
+func Server(t testing.TB, h http.Handler) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	return &Client{URL: srv.URL, Header: make(http.Header), HTTP: srv.Client()}
+}
+
+// HTTPError is the error of a response with a status code that is no 2xx status code.
+type HTTPError struct {
+	Status int
+	// Message is the error message of a gwu.ErrorBody, or the trimmed plain text body.
+	Message string
+	Body    []byte
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.Status, e.Message)
+}
+
+// Get sends a GET request to the path and decodes the JSON response into Out.
+// A response with a status code that is no 2xx status code returns an *HTTPError.
+// The returned response's body can be read again.
+func Get[Out any](c *Client, path string) (Out, *http.Response, error) {
+	return send[any, Out](c, http.MethodGet, path, nil)
+}
+
+// Post sends a POST request with the JSON-encoded input to the path, like Get.
+func Post[In, Out any](c *Client, path string, in In) (Out, *http.Response, error) {
+	return send[In, Out](c, http.MethodPost, path, in)
+}
+
+// Put sends a PUT request with the JSON-encoded input to the path, like Get.
+func Put[In, Out any](c *Client, path string, in In) (Out, *http.Response, error) {
+	return send[In, Out](c, http.MethodPut, path, in)
+}
+
+// Patch sends a PATCH request with the JSON-encoded input to the path, like Get.
+func Patch[In, Out any](c *Client, path string, in In) (Out, *http.Response, error) {
+	return send[In, Out](c, http.MethodPatch, path, in)
+}
+
+// Delete sends a DELETE request to the path, like Get.
+func Delete[Out any](c *Client, path string) (Out, *http.Response, error) {
+	return send[any, Out](c, http.MethodDelete, path, nil)
+}
+
+// send sends a request with the JSON-encoded input, a nil input sends no body.
+func send[In, Out any](c *Client, method, path string, in In) (Out, *http.Response, error) {
+	var out Out
+
+	var body io.Reader
+	if !isNil(in) {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return out, nil, fmt.Errorf("gwutest: encode request: %w", err)
+		}
+
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.URL, "/")+path, body)
+	if err != nil {
+		return out, nil, err
+	}
+
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Accept", gwu.ContentTypeJSON)
+	if body != nil {
+		req.Header.Set("Content-Type", gwu.ContentTypeJSON)
+	}
+
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return out, nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return out, resp, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return out, resp, newHTTPError(resp, b)
+	}
+
+	if len(b) == 0 {
+		return out, resp, nil
+	}
+
+	if err := json.Unmarshal(b, &out); err != nil {
+		return out, resp, fmt.Errorf("gwutest: decode response into %T: %w, body: %s", out, err, b)
+	}
+
+	return out, resp, nil
+}
+
+// newHTTPError returns the HTTPError of an error response.
+func newHTTPError(resp *http.Response, body []byte) *HTTPError {
+	e := &HTTPError{Status: resp.StatusCode, Message: strings.TrimSpace(string(body)), Body: body}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt == gwu.ContentTypeJSON {
+		var eb gwu.ErrorBody
+		if json.Unmarshal(body, &eb) == nil && eb.Error != "" {
+			e.Message = eb.Error
+		}
+	}
+
+	return e
+}
@@ -0,0 +1,49 @@
+package gwutest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestServer(t *testing.T) {
+	rt := http.NewServeMux()
+	gwu.HandleRoute(rt, "POST /authors/{author}/poems", poemIn, createPoem)
+	gwu.HandleRoute(rt, "GET /poems/{id}", gwu.PathInt("id"),
+		func(context.Context, int64, gwu.HandleOpts) (poem, int, error) {
+			return poem{}, http.StatusNotFound, errors.New("no poem")
+		}, gwu.Errors(gwu.JSONError))
+
+	c := gwutest.Server(t, rt)
+	c.Header.Set("Accept-Language", "en")
+
+	// The header of the client is sent with every request.
+	got, resp, err := gwutest.Post[poem, poem](c, "/authors/keats/poems", poem{Title: "Ode"})
+	if want := (poem{ID: 7, Title: "Ode", Author: "keats", Lang: "en"}); err != nil || got != want {
+		t.Errorf("Post %+v, %v, want %+v", got, err, want)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("status %d, want 201", resp.StatusCode)
+	}
+
+	// The message of an error response is read from the ErrorBody of JSONError, or the plain text of TextError.
+	var httpErr *gwutest.HTTPError
+	_, resp, err = gwutest.Get[poem](c, "/poems/7")
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusNotFound || httpErr.Message != "no poem" {
+		t.Errorf("Get: %v, want the HTTPError of the 404", err)
+	}
+
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Get: response %v, want the 404", resp)
+	}
+
+	_, _, err = gwutest.Post[string, poem](c, "/authors/keats/poems", "not a poem")
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusBadRequest || httpErr.Message == "" {
+		t.Errorf("Post: %v, want the HTTPError of the 400 with the message", err)
+	}
+}
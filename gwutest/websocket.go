@@ -0,0 +1,273 @@
+package gwutest
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// wsTimeout is the time a WSClient waits for the server.
+const wsTimeout = 5 * time.Second
+
+// WebSocket opcodes of the frames a WSClient sends and reads, see RFC 6455 section 5.2.
+const (
+	WSText   = 0x1
+	WSBinary = 0x2
+	WSClose  = 0x8
+	WSPing   = 0x9
+	WSPong   = 0xa
+)
+
+// WSClient is a minimal WebSocket client to test gwu.HandleWS handlers, create it with DialWS. It sends masked
+// frames like a browser, answers pings, and fails the test if the server does not answer in 5 seconds.
+// A WSClient is not safe for concurrent use.
+type WSClient struct {
+	t    testing.TB
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// DialWS opens a WebSocket connection to the ws:// or http:// URL, like of a Server, and fails the test if the
+// handshake fails. The header is sent with the opening handshake, the connection is closed when the test finishes.
+//
+// Example usage:
+//
+//	srv := httptest.NewServer(rt)
+//	defer srv.Close()
+//
+//	c := gwutest.DialWS(t, srv.URL+"/echo", nil)
+//	c.Send(Msg{Text: "hi"})
+//	var got Msg
+//	c.Receive(&got)
+func DialWS(t testing.TB, rawURL string, header http.Header) *WSClient {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("DialWS: %v", err)
+	}
+
+	conn, err := net.DialTimeout("tcp", u.Host, wsTimeout)
+	if err != nil {
+		t.Fatalf("DialWS: %v", err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	var key [16]byte
+	_, _ = rand.Read(key[:])
+	req, _ := http.NewRequest(http.MethodGet, "http://"+u.Host+u.RequestURI(), nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(key[:]))
+
+	_ = conn.SetDeadline(time.Now().Add(wsTimeout))
+	if err := req.Write(conn); err != nil {
+		t.Fatalf("DialWS: %v", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatalf("DialWS: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("DialWS: status %d, want 101: %s", resp.StatusCode, body)
+	}
+
+	return &WSClient{t: t, conn: conn, br: br}
+}
+
+// Send writes the value as JSON text message.
+func (c *WSClient) Send(v any) {
+	c.t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		c.t.Fatalf("WSClient.Send: %v", err)
+	}
+
+	c.WriteFrame(true, WSText, b)
+}
+
+// WriteFrame writes a single masked frame, use it to send fragmented messages or control frames.
+func (c *WSClient) WriteFrame(fin bool, opcode int, payload []byte) {
+	c.t.Helper()
+
+	head := byte(opcode)
+	if fin {
+		head |= 0x80
+	}
+
+	frame := []byte{head}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	var mask [4]byte
+	_, _ = rand.Read(mask[:])
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	c.WriteRaw(frame)
+}
+
+// WriteRaw writes the bytes to the connection as they are, use it to send invalid frames.
+func (c *WSClient) WriteRaw(b []byte) {
+	c.t.Helper()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(wsTimeout))
+	if _, err := c.conn.Write(b); err != nil {
+		c.t.Fatalf("WSClient: write: %v", err)
+	}
+}
+
+// Receive reads the next text or binary message and decodes its JSON into v. It fails the test if the server
+// closes the connection instead.
+func (c *WSClient) Receive(v any) {
+	c.t.Helper()
+
+	opcode, payload := c.readMessage()
+	if opcode == WSClose {
+		code, reason := closePayload(payload)
+		c.t.Fatalf("WSClient.Receive: connection closed with code %d: %s", code, reason)
+	}
+
+	if err := json.Unmarshal(payload, v); err != nil {
+		c.t.Fatalf("WSClient.Receive: decoding %s: %v", payload, err)
+	}
+}
+
+// ReadClose reads until the server closes the connection and returns the code and reason of its close frame,
+// 1005 if it has no code, and 1006 if the connection closed without close frame. Messages before are discarded.
+func (c *WSClient) ReadClose() (code int, reason string) {
+	c.t.Helper()
+
+	for {
+		_, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 1006, ""
+		}
+
+		if opcode == WSClose {
+			return closePayload(payload)
+		}
+	}
+}
+
+// Close sends a close frame with the code 1000 and closes the connection.
+func (c *WSClient) Close() {
+	c.t.Helper()
+
+	c.WriteFrame(true, WSClose, binary.BigEndian.AppendUint16(nil, 1000))
+	c.conn.Close()
+}
+
+// ReadFrame reads the next frame as is, it neither answers pings nor joins fragmented messages. Use it to test
+// control frames, like the pings of gwu.PingInterval.
+func (c *WSClient) ReadFrame() (fin bool, opcode int, payload []byte) {
+	c.t.Helper()
+
+	fin, opcode, payload, err := c.readFrame()
+	if err != nil {
+		c.t.Fatalf("WSClient: read: %v", err)
+	}
+
+	return fin, opcode, payload
+}
+
+// readMessage reads the next message or close frame, it answers pings and joins fragmented messages.
+func (c *WSClient) readMessage() (opcode int, payload []byte) {
+	c.t.Helper()
+
+	for {
+		fin, op, p, err := c.readFrame()
+		if err != nil {
+			c.t.Fatalf("WSClient: read: %v", err)
+		}
+
+		switch op {
+		case WSPing:
+			c.WriteFrame(true, WSPong, p)
+		case WSPong:
+		case WSClose:
+			return op, p
+		case 0:
+			payload = append(payload, p...)
+		default:
+			opcode, payload = op, p
+		}
+
+		if op != WSPing && op != WSPong && fin {
+			return opcode, payload
+		}
+	}
+}
+
+// readFrame reads a single unmasked server frame.
+func (c *WSClient) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	_ = c.conn.SetReadDeadline(time.Now().Add(wsTimeout))
+
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	length := uint64(head[1] & 0x7f)
+	switch length {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = binary.BigEndian.Uint64(b[:])
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	return head[0]&0x80 != 0, int(head[0] & 0x0f), payload, nil
+}
+
+// closePayload returns the code and reason of the payload of a close frame.
+func closePayload(p []byte) (code int, reason string) {
+	if len(p) < 2 {
+		return 1005, ""
+	}
+
+	return int(binary.BigEndian.Uint16(p)), string(p[2:])
+}
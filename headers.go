@@ -0,0 +1,73 @@
+package gwu
+
+import (
+	"net/http"
+	"time"
+)
+
+// StaticHeaders sets headers on every response of the handler, including error responses.
+// Headers the Exec sets with HandleOpts.Header override the static headers. Applying StaticHeaders more than once
+// merges the headers, later values replace earlier ones of the same key. A header without values is removed from the
+// response.
+//
+// Example usage:
+//
+//	gwu.Get(rt, "/internal/stats", gwu.Empty(), ctrl.Stats, gwu.StaticHeaders(http.Header{"X-Robots-Tag": {"noindex"}}))
+func StaticHeaders(h http.Header) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		headers := opt.headers.Clone()
+		if headers == nil {
+			headers = make(http.Header, len(h))
+		}
+
+		for k, v := range h {
+			headers[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+		}
+
+		opt.headers = headers
+	}
+}
+
+// Deprecated marks the handler's route as deprecated with the Deprecation header, the Sunset header with the date
+// the route will be removed, and a Link header to its documentation with the relation type "deprecation".
+// A zero sunset or an empty link omits the header. Deprecated sets the headers like StaticHeaders, see Sunset to name
+// a successor and count the remaining callers.
+func Deprecated(sunset time.Time, link string) HandleOptsFunc {
+	h := http.Header{"Deprecation": {"true"}}
+	if !sunset.IsZero() {
+		h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+
+	if link != "" {
+		h.Set("Link", "<"+link+`>; rel="deprecation"`)
+	}
+
+	return StaticHeaders(h)
+}
+
+// Header returns the header map of the request's response, set headers in the Exec with it.
+// Outside of a request, Header returns nil.
+func (o HandleOpts) Header() http.Header {
+	if o.req.w == nil {
+		return nil
+	}
+
+	return o.req.w.Header()
+}
+
+// setHeaders sets the static headers on the response.
+func (o HandleOpts) setHeaders(w http.ResponseWriter) {
+	setHeaders(w.Header(), o.headers)
+}
+
+// setHeaders copies the static headers to h, it removes the headers without values.
+func setHeaders(h, static http.Header) {
+	for k, v := range static {
+		if len(v) == 0 {
+			delete(h, k)
+			continue
+		}
+
+		h[k] = append([]string(nil), v...)
+	}
+}
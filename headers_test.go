@@ -0,0 +1,106 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+)
+
+func TestStaticHeaders(t *testing.T) {
+	exec := func(_ context.Context, id string, opts gwu.HandleOpts) (string, int, error) {
+		switch id {
+		case "500":
+			return "", http.StatusInternalServerError, errors.New("database down")
+		case "dynamic":
+			opts.Header().Set("Cache-Control", "no-store")
+		}
+
+		return "Ode", http.StatusOK, nil
+	}
+
+	rt := gwu.NewRouter(gwu.StaticHeaders(http.Header{"x-robots-tag": {"noindex"}, "Cache-Control": {"max-age=60"}}))
+	gwu.Get(rt, "/poems/{id}", gwu.PathVal("id"), exec,
+		gwu.StaticHeaders(http.Header{"Cache-Control": {"max-age=3600"}, "Vary": {"Accept", "Origin"}}))
+
+	tests := []struct {
+		path         string
+		status       int
+		cacheControl string
+	}{
+		{"/poems/7", http.StatusOK, "max-age=3600"},
+		{"/poems/500", http.StatusInternalServerError, "max-age=3600"},
+		// Headers the Exec sets win over the static headers.
+		{"/poems/dynamic", http.StatusOK, "no-store"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		h := rec.Header()
+		if rec.Code != tt.status || h.Get("X-Robots-Tag") != "noindex" || h.Get("Cache-Control") != tt.cacheControl ||
+			!slices.Equal(h.Values("Vary"), []string{"Accept", "Origin"}) {
+			t.Errorf("%s: %d with %v, want %d with the merged static headers and Cache-Control %q", tt.path, rec.Code, h,
+				tt.status, tt.cacheControl)
+		}
+	}
+}
+
+// TestStaticHeadersRemove drops a header of the Router for a route with a header without values.
+func TestStaticHeadersRemove(t *testing.T) {
+	rt := gwu.NewRouter(gwu.StaticHeaders(http.Header{"X-Robots-Tag": {"noindex"}}))
+	gwu.Get(rt, "/sitemap", gwu.Empty(), noContent, gwu.StaticHeaders(http.Header{"X-Robots-Tag": nil}))
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sitemap", nil))
+	if _, ok := rec.Header()["X-Robots-Tag"]; ok || rec.Code != http.StatusNoContent {
+		t.Errorf("%d with %v, want 204 without X-Robots-Tag", rec.Code, rec.Header())
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	fail := func(context.Context, any, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		return gwu.NoBody{}, http.StatusInternalServerError, errors.New("database down")
+	}
+
+	sunset := time.Date(2027, 3, 31, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	tests := []struct {
+		name   string
+		opt    gwu.HandleOptsFunc
+		header http.Header
+	}{
+		{"all", gwu.Deprecated(sunset, "https://example.org/docs/v2"), http.Header{
+			"Deprecation": {"true"},
+			"Sunset":      {"Wed, 31 Mar 2027 10:00:00 GMT"},
+			"Link":        {`<https://example.org/docs/v2>; rel="deprecation"`},
+		}},
+		{"without sunset and link", gwu.Deprecated(time.Time{}, ""), http.Header{"Deprecation": {"true"}}},
+	}
+
+	for _, tt := range tests {
+		for _, status := range []int{http.StatusNoContent, http.StatusInternalServerError} {
+			exec := noContent[any]
+			if status == http.StatusInternalServerError {
+				exec = fail
+			}
+
+			rec := httptest.NewRecorder()
+			gwu.Handle(gwu.Empty(), exec, tt.opt).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/poems", nil))
+			if rec.Code != status {
+				t.Errorf("%s: status %d, want %d", tt.name, rec.Code, status)
+			}
+
+			for _, key := range []string{"Deprecation", "Sunset", "Link"} {
+				if got := rec.Header().Values(key); !slices.Equal(got, tt.header.Values(key)) {
+					t.Errorf("%s: %d: %s %q, want %q", tt.name, status, key, got, tt.header.Values(key))
+				}
+			}
+		}
+	}
+}
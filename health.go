@@ -0,0 +1,135 @@
+package gwu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Check is a named health check, see Health. Run returns an error if the dependency it checks is unhealthy.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// HealthHandler is an http.Handler running health checks, create it with Health.
+// Change its fields before serving requests.
+type HealthHandler struct {
+	// Timeout is the time a check may take before it counts as failed, defaults to 2 seconds.
+	Timeout time.Duration
+	// CacheFor is the time the handler responds with the previous results instead of running the checks again,
+	// defaults to 1 second. A negative duration disables the cache.
+	CacheFor time.Duration
+	// Clock defaults to RealClock.
+	Clock Clock
+
+	checks []Check
+
+	mu     sync.Mutex
+	report HealthReport
+	code   int
+	at     time.Time
+}
+
+// HealthReport is the JSON body of HealthHandler responses.
+// Status is "ok" if all checks pass, "fail" otherwise. Checks maps the name of every check to "ok", "fail", or
+// "timeout", the errors of the checks are not included.
+type HealthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Health returns a HealthHandler running the checks concurrently, use it for a readiness endpoint.
+// It responds with http.StatusOK if all checks pass, and with http.StatusServiceUnavailable otherwise.
+//
+// Example usage:
+//
+//	mux.Handle("GET /readyz", gwu.Health(gwu.Check{Name: "db", Run: db.PingContext}))
+//	mux.Handle("GET /healthz", gwu.Liveness())
+func Health(checks ...Check) *HealthHandler {
+	return &HealthHandler{Timeout: 2 * time.Second, CacheFor: time.Second, checks: checks}
+}
+
+// Liveness returns an http.Handler that always responds with http.StatusOK and never runs checks, use it for a
+// liveness endpoint.
+func Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = writeJSON(w, HealthReport{Status: "ok"}, http.StatusOK)
+	})
+}
+
+func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report, code := h.run(r.Context())
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// run runs the checks, or returns the cached results. Concurrent requests wait for a single run.
+func (h *HealthHandler) run(ctx context.Context) (HealthReport, int) {
+	clock := h.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.code != 0 && h.CacheFor >= 0 && clock.Since(h.at) < h.CacheFor {
+		return h.report, h.code
+	}
+
+	// The results are shared with later requests, so a canceled request must not fail the checks.
+	ctx = context.WithoutCancel(ctx)
+
+	results := make([]string, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.check(ctx, clock, c)
+		}()
+	}
+
+	wg.Wait()
+
+	report := HealthReport{Status: "ok", Checks: make(map[string]string, len(h.checks))}
+	code := http.StatusOK
+	for i, c := range h.checks {
+		report.Checks[c.Name] = results[i]
+		if results[i] != "ok" {
+			report.Status = "fail"
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	h.report, h.code, h.at = report, code, clock.Now()
+
+	return report, code
+}
+
+// check runs a single check with the timeout and returns its result.
+func (h *HealthHandler) check(ctx context.Context, clock Clock, c Check) string {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Run(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return "fail"
+		}
+
+		return "ok"
+	case <-clock.After(h.Timeout):
+		return "timeout"
+	}
+}
@@ -0,0 +1,163 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+)
+
+// checkHealth serves a health request and decodes the report.
+func checkHealth(t *testing.T, h http.Handler, clock *gwu.ManualClock) (gwu.HealthReport, int) {
+	t.Helper()
+
+	var rec *httptest.ResponseRecorder
+	r := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	if clock != nil {
+		rec = serveAdvancing(h, r, clock)
+	} else {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+	}
+
+	if ct, cc := rec.Header().Get("Content-Type"), rec.Header().Get("Cache-Control"); ct != gwu.ContentTypeJSON ||
+		cc != "no-store" {
+		t.Errorf("Content-Type %q and Cache-Control %q, want JSON and no-store", ct, cc)
+	}
+
+	var report gwu.HealthReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body, err)
+	}
+
+	return report, rec.Code
+}
+
+func pass(context.Context) error { return nil }
+
+func fail(context.Context) error { return errors.New("connection refused") }
+
+// hang blocks until its check is canceled.
+func hang(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []gwu.Check
+		status int
+		want   gwu.HealthReport
+		hangs  bool
+	}{
+		{"pass", []gwu.Check{{Name: "db", Run: pass}, {Name: "cache", Run: pass}}, http.StatusOK,
+			gwu.HealthReport{Status: "ok", Checks: map[string]string{"db": "ok", "cache": "ok"}}, false},
+		{"fail", []gwu.Check{{Name: "db", Run: pass}, {Name: "cache", Run: fail}}, http.StatusServiceUnavailable,
+			gwu.HealthReport{Status: "fail", Checks: map[string]string{"db": "ok", "cache": "fail"}}, false},
+		{"timeout", []gwu.Check{{Name: "db", Run: pass}, {Name: "queue", Run: hang}},
+			http.StatusServiceUnavailable,
+			gwu.HealthReport{Status: "fail", Checks: map[string]string{"db": "ok", "queue": "timeout"}}, true},
+		{"no checks", nil, http.StatusOK, gwu.HealthReport{Status: "ok"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+			h := gwu.Health(tt.checks...)
+			h.Clock = clock
+
+			// The clock only advances if a check hangs, so the others cannot time out.
+			var advance *gwu.ManualClock
+			if tt.hangs {
+				advance = clock
+			}
+
+			report, code := checkHealth(t, h, advance)
+			if code != tt.status || report.Status != tt.want.Status || !maps.Equal(report.Checks, tt.want.Checks) {
+				t.Errorf("%d %+v, want %d %+v", code, report, tt.status, tt.want)
+			}
+		})
+	}
+}
+
+// TestHealthTimeout counts a check as timed out after the Timeout, and cancels it.
+func TestHealthTimeout(t *testing.T) {
+	start := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	clock := gwu.NewManualClock(start)
+	canceled := make(chan struct{})
+	h := gwu.Health(gwu.Check{Name: "queue", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}})
+	h.Clock, h.Timeout = clock, 500*time.Millisecond
+
+	if report, _ := checkHealth(t, h, clock); report.Checks["queue"] != "timeout" {
+		t.Errorf("report %+v, want the queue timed out", report)
+	}
+
+	if elapsed := clock.Since(start); elapsed < 500*time.Millisecond || elapsed >= 2*time.Second {
+		t.Errorf("timed out after %v, want the Timeout of 500ms", elapsed)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("the timed out check was not canceled")
+	}
+}
+
+// TestHealthCached responds with the previous results for CacheFor, and runs the checks again after.
+func TestHealthCached(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	runs := 0
+	healthy := true
+	h := gwu.Health(gwu.Check{Name: "db", Run: func(context.Context) error {
+		runs++
+		if !healthy {
+			return errors.New("connection refused")
+		}
+
+		return nil
+	}})
+	h.Clock = clock
+
+	if _, code := checkHealth(t, h, nil); code != http.StatusOK || runs != 1 {
+		t.Fatalf("status %d after %d runs, want 200 after 1", code, runs)
+	}
+
+	healthy = false
+	clock.Advance(999 * time.Millisecond)
+	if _, code := checkHealth(t, h, nil); code != http.StatusOK || runs != 1 {
+		t.Errorf("status %d after %d runs, want the cached 200", code, runs)
+	}
+
+	clock.Advance(time.Millisecond)
+	if report, code := checkHealth(t, h, nil); code != http.StatusServiceUnavailable || runs != 2 ||
+		report.Checks["db"] != "fail" {
+		t.Errorf("%d %+v after %d runs, want the failed check run again", code, report, runs)
+	}
+
+	// A negative CacheFor runs the checks for every request.
+	h.CacheFor = -1
+	checkHealth(t, h, nil)
+	if checkHealth(t, h, nil); runs != 4 {
+		t.Errorf("%d runs, want 4 without cache", runs)
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gwu.Liveness().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"status":"ok"}`+"\n" {
+		t.Errorf("%d %s, want 200 ok without checks", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,65 @@
+package gwu
+
+import (
+	"errors"
+	"net/http"
+)
+
+// BeforeFunc is a hook that runs before the CnIn, see Before.
+// A BeforeFunc returning an error aborts the request, return only safe to display errors.
+type BeforeFunc func(r *http.Request, opts HandleOpts) error
+
+// AfterFunc is a hook that runs after the Exec with its status code and error, see After.
+type AfterFunc func(r *http.Request, opts HandleOpts, code int, err error)
+
+// Before adds hooks that run before the CnIn, in the order they are added.
+// Unlike other options, Before accumulates, so the hooks of a Router, its groups, and the route all run.
+//
+// If a hook returns an error, Handle responds with it and neither the remaining hooks, the CnIn, nor the Exec run.
+// The status code is http.StatusInternalServerError, return a StatusError for another status code.
+//
+// Example usage:
+//
+//	api := rt.Group("/api", gwu.Before(requireToken))
+func Before(fns ...BeforeFunc) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.before = append(opt.before[:len(opt.before):len(opt.before)], fns...)
+	}
+}
+
+// After adds hooks that run after the Exec, before the response is written. The hooks run in the reverse order
+// they are added, so hooks added by a Router run last, like middleware wrapping the hooks of its routes.
+// Like Before, After accumulates.
+func After(fns ...AfterFunc) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.after = append(opt.after[:len(opt.after):len(opt.after)], fns...)
+	}
+}
+
+// runBefore runs the Before hooks and returns the error of the first failing hook and its status code. It skips them
+// in requests of SelfTest.
+func (o HandleOpts) runBefore(r *http.Request) (int, error) {
+	if len(o.before) > 0 && o.selfTest() != nil {
+		return 0, nil
+	}
+
+	for _, fn := range o.before {
+		if err := fn(r, o.enrichedLog()); err != nil {
+			var statusErr *StatusError
+			if errors.As(err, &statusErr) {
+				return statusErr.Status, err
+			}
+
+			return http.StatusInternalServerError, err
+		}
+	}
+
+	return 0, nil
+}
+
+// runAfter runs the After hooks in reverse order.
+func (o HandleOpts) runAfter(r *http.Request, code int, err error) {
+	for i := len(o.after) - 1; i >= 0; i-- {
+		o.after[i](r, o, code, err)
+	}
+}
@@ -0,0 +1,117 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// recorder records the steps of a request.
+type recorder []string
+
+// middleware returns middleware recording when it enters and leaves the handler.
+func (rec *recorder) middleware(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*rec = append(*rec, name+" in")
+			next.ServeHTTP(w, r)
+			*rec = append(*rec, name+" out")
+		})
+	}
+}
+
+// hooks returns the options adding a Before and an After hook recording the name.
+func (rec *recorder) hooks(name string) []gwu.HandleOptsFunc {
+	return []gwu.HandleOptsFunc{
+		gwu.Before(func(*http.Request, gwu.HandleOpts) error {
+			*rec = append(*rec, "before "+name)
+			return nil
+		}),
+		gwu.After(func(*http.Request, gwu.HandleOpts, int, error) {
+			*rec = append(*rec, "after "+name)
+		}),
+	}
+}
+
+// TestRouterOrder runs the middleware of the Router and its groups around the hooks, the CnIn, and the Exec.
+func TestRouterOrder(t *testing.T) {
+	var rec recorder
+	in := func(*http.Request, gwu.HandleOpts) (any, error) {
+		rec = append(rec, "in")
+		return nil, nil
+	}
+
+	exec := func(context.Context, any, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		rec = append(rec, "exec")
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	}
+
+	rt := gwu.NewRouter(rec.hooks("router")...)
+	rt.Use(rec.middleware("router mw 1"), rec.middleware("router mw 2"))
+	api := rt.Group("/api", rec.hooks("group")...)
+	api.Use(rec.middleware("group mw"))
+	gwu.Get(api, "/poems", in, exec, append(rec.hooks("route 1"), rec.hooks("route 2")...)...)
+
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/poems", nil))
+
+	want := recorder{
+		"router mw 1 in", "router mw 2 in", "group mw in",
+		"before router", "before group", "before route 1", "before route 2",
+		"in", "exec",
+		"after route 2", "after route 1", "after group", "after router",
+		"group mw out", "router mw 2 out", "router mw 1 out",
+	}
+
+	if !slices.Equal(rec, want) {
+		t.Errorf("order:\n%q\nwant:\n%q", rec, want)
+	}
+}
+
+// TestBeforeAborts skips the remaining hooks, the CnIn, and the Exec after a failing Before hook.
+func TestBeforeAborts(t *testing.T) {
+	var rec recorder
+	deny := gwu.Before(func(*http.Request, gwu.HandleOpts) error {
+		rec = append(rec, "deny")
+		return gwu.WithStatus(http.StatusForbidden, errPoemNotFound)
+	})
+
+	exec := func(context.Context, any, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		rec = append(rec, "exec")
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	}
+
+	rt := gwu.NewRouter(deny)
+	rt.Use(rec.middleware("mw"))
+	gwu.Get(rt, "/poems", gwu.Empty(), exec, rec.hooks("route")...)
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/poems", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status %d, want 403", w.Code)
+	}
+
+	if want := (recorder{"mw in", "deny", "mw out"}); !slices.Equal(rec, want) {
+		t.Errorf("order %q, want %q", rec, want)
+	}
+}
+
+// TestUseAfterRegistration does not wrap the routes registered before Use.
+func TestUseAfterRegistration(t *testing.T) {
+	var rec recorder
+	rt := gwu.NewRouter()
+	gwu.Get(rt, "/early", gwu.Empty(), noContent)
+	rt.Use(rec.middleware("mw"))
+	gwu.Get(rt, "/late", gwu.Empty(), noContent)
+
+	for path, want := range map[string]recorder{"/early": nil, "/late": {"mw in", "mw out"}} {
+		rec = nil
+		rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		if !slices.Equal(rec, want) {
+			t.Errorf("%s: %q, want %q", path, rec, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package gwu
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// hostRouter is a Router scoped to a host, see Router.Host.
+type hostRouter struct {
+	labels []string
+	rt     *Router
+}
+
+// Host returns a Router whose routes only match requests for the host, with the prefix, options, and middleware of
+// rt. A label of the host in braces, like {tenant}.example.com, matches any label, and the matched label is available
+// as a path value of the same name, read it with PathVal.
+//
+// Requests for other hosts are served by the routes of rt without host.
+//
+// Example usage:
+//
+//	admin := rt.Host("admin.example.com")
+//	tenants := rt.Host("{tenant}.example.com")
+//	gwu.Get(tenants, "/poems", gwu.PathVal("tenant"), ctrl.All)
+func (rt *Router) Host(host string) *Router {
+	if host == "" || strings.ContainsAny(host, "/ ") {
+		panic(fmt.Sprintf("gwu: invalid host %q", host))
+	}
+
+	sub := &Router{
+		routes: newRoutes(strings.ToLower(host), rt.opts),
+		prefix: rt.prefix,
+		opts:   rt.opts[:len(rt.opts):len(rt.opts)],
+		mw:     rt.mw[:len(rt.mw):len(rt.mw)],
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.hosts = append(rt.hosts, &hostRouter{labels: strings.Split(sub.host, "."), rt: sub})
+
+	return sub
+}
+
+// serveHost serves the request with the first matching host router. It reports whether it handled the request.
+func (rt *Router) serveHost(w http.ResponseWriter, r *http.Request) bool {
+	rt.mu.Lock()
+	hosts := rt.hosts
+	rt.mu.Unlock()
+
+	if len(hosts) == 0 {
+		return false
+	}
+
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	labels := strings.Split(strings.ToLower(host), ".")
+	for _, h := range hosts {
+		if values, ok := h.match(labels); ok {
+			if len(values) > 0 {
+				// Set the values on a clone, the caller's request and its path values stay untouched.
+				r = r.Clone(r.Context())
+				for name, v := range values {
+					r.SetPathValue(name, v)
+				}
+			}
+
+			h.rt.ServeHTTP(w, r)
+			return true
+		}
+	}
+
+	return false
+}
+
+// match reports whether the labels of a host match the host router, and returns the values of its wildcard labels.
+func (h *hostRouter) match(labels []string) (map[string]string, bool) {
+	if len(labels) != len(h.labels) {
+		return nil, false
+	}
+
+	var values map[string]string
+	for i, label := range h.labels {
+		if name, ok := strings.CutPrefix(label, "{"); ok && strings.HasSuffix(name, "}") {
+			if labels[i] == "" {
+				return nil, false
+			}
+
+			if values == nil {
+				values = make(map[string]string)
+			}
+
+			values[strings.TrimSuffix(name, "}")] = labels[i]
+			continue
+		}
+
+		if label != labels[i] {
+			return nil, false
+		}
+	}
+
+	return values, true
+}
@@ -0,0 +1,45 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+func TestRouterHost(t *testing.T) {
+	rt := gwu.NewRouter()
+	rt.Host("api.example.com").Handle("GET /poems", textHandler("api"))
+	rt.Host("admin.example.com").Handle("GET /poems", textHandler("admin"))
+	gwu.HandleRoute(rt.Host("{tenant}.poems.example.com"), "GET /poems", gwu.PathVal("tenant"),
+		func(_ context.Context, tenant string, _ gwu.HandleOpts) (string, int, error) {
+			return tenant, http.StatusOK, nil
+		})
+
+	tests := []struct {
+		target string
+		code   int
+		body   string
+	}{
+		{"http://api.example.com/poems", http.StatusOK, "api"},
+		{"http://admin.example.com:8080/poems", http.StatusOK, "admin"},
+		{"http://acme.poems.example.com/poems", http.StatusOK, `"acme"` + "\n"},
+		{"http://shop.example.com/poems", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, r)
+
+		if rec.Code != tt.code || tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("%s: %d %q, want %d %q", tt.target, rec.Code, rec.Body, tt.code, tt.body)
+		}
+
+		if v := r.PathValue("tenant"); v != "" {
+			t.Errorf("%s: path value tenant %q set on the caller's request", tt.target, v)
+		}
+	}
+}
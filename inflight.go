@@ -0,0 +1,134 @@
+package gwu
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+)
+
+// ErrOverloaded is the error of requests shed by ShedAbove.
+// Is safe to display to the client.
+var ErrOverloaded = errors.New("server overloaded, retry later")
+
+// Observer receives the in-flight requests of handlers, set it with Observe. The route is the pattern of the
+// handler, like "GET /users/{id}", or empty for a handler registered without HandleRoute or a Router.
+// An Observer must be safe for concurrent use and fast, Handle calls it on every request.
+type Observer interface {
+	// InFlight adds delta to the route's in-flight requests, +1 when a request enters the handler and -1 when it
+	// leaves. Adding deltas keeps the gauge accurate whatever order concurrent requests call it in.
+	InFlight(route string, delta int)
+	// Shed reports a request of the route rejected by ShedAbove, it does not count as in flight.
+	Shed(route string)
+}
+
+// TenantObserver is an Observer receiving the tenant of the requests, see TenantOpts. Observe sets it like any
+// Observer. Handle reports requests with a tenant to TenantInFlight and TenantShed instead of InFlight and Shed.
+type TenantObserver interface {
+	Observer
+	// TenantInFlight adds delta to the in-flight requests of the route and tenant, like InFlight.
+	TenantInFlight(route, tenant string, delta int)
+	// TenantShed reports a request of the route and tenant rejected by ShedAbove, like Shed.
+	TenantShed(route, tenant string)
+}
+
+// Observe sets the Observer of the handler's in-flight requests, see ShedAbove to limit them.
+//
+// A request is in flight from the moment Handle receives it until its response is written, including the Before
+// hooks, the CnIn, and the encoding of the output. Every request that enters is left exactly once, also if the
+// handler panics or the client disconnects. A PhaseObserver also receives the durations of the phases of every
+// request.
+//
+// Example usage:
+//
+//	type gauge struct{ *prometheus.GaugeVec }
+//
+//	func (g gauge) InFlight(route string, delta int) { g.WithLabelValues(route).Add(float64(delta)) }
+//	func (g gauge) Shed(route string)                { shed.WithLabelValues(route).Inc() }
+//
+//	rt := gwu.NewRouter(gwu.Observe(gauge{inFlight}))
+func Observe(obs Observer) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.observer = obs
+	}
+}
+
+// ShedAbove limits the in-flight requests of each handler to n, see Observe. Requests above the limit are rejected
+// immediately with ErrOverloaded, http.StatusServiceUnavailable, and "Retry-After: 1", before the Before hooks and
+// the CnIn run. The limit applies per handler, so a Router with ShedAbove limits each of its routes separately.
+// A limit <= 0 disables shedding.
+//
+// Example usage:
+//
+//	gwu.Post(rt, "/reports", gwu.JSON[ReportQuery](), ctrl.Report, gwu.ShedAbove(32))
+func ShedAbove(n int) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.shedAbove = n
+	}
+}
+
+// inFlight is the gauge of a handler's in-flight requests, Handle creates one per handler.
+type inFlight struct {
+	n      atomic.Int64
+	max    int64
+	route  string
+	obs    Observer
+	tenant TenantObserver
+}
+
+// newInFlight returns the gauge for the options, or nil if the handler neither observes nor sheds requests.
+func newInFlight(o HandleOpts) *inFlight {
+	if o.observer == nil && o.shedAbove <= 0 {
+		return nil
+	}
+
+	g := &inFlight{max: int64(max(o.shedAbove, 0)), obs: o.observer}
+	g.tenant, _ = o.observer.(TenantObserver)
+	if o.route != nil {
+		g.route = o.route.String()
+	}
+
+	return g
+}
+
+// enter counts a request of the tenant, empty if it has none, that enters the handler. It reports false if the
+// request is shed and does not count.
+func (g *inFlight) enter(tenant string) bool {
+	if n := g.n.Add(1); g.max > 0 && n > g.max {
+		g.n.Add(-1)
+		switch {
+		case g.tenant != nil && tenant != "":
+			g.tenant.TenantShed(g.route, tenant)
+		case g.obs != nil:
+			g.obs.Shed(g.route)
+		}
+
+		return false
+	}
+
+	g.observe(tenant, 1)
+
+	return true
+}
+
+// leave counts a request of the tenant that leaves the handler, call it exactly once for every request enter admitted.
+func (g *inFlight) leave(tenant string) {
+	g.n.Add(-1)
+	g.observe(tenant, -1)
+}
+
+// observe reports the delta of the in-flight requests of the tenant to the Observer.
+func (g *inFlight) observe(tenant string, delta int) {
+	switch {
+	case g.tenant != nil && tenant != "":
+		g.tenant.TenantInFlight(g.route, tenant, delta)
+	case g.obs != nil:
+		g.obs.InFlight(g.route, delta)
+	}
+}
+
+// shed writes the response of a request rejected by ShedAbove.
+func (o HandleOpts) shed(w http.ResponseWriter, r *http.Request) {
+	o.setHeaders(w)
+	w.Header().Set("Retry-After", "1")
+	o.writeError(w, r, ErrOverloaded, http.StatusServiceUnavailable)
+}
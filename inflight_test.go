@@ -0,0 +1,122 @@
+package gwu_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// gauge is an Observer tracking the in-flight requests, their peak, and the shed requests.
+type gauge struct {
+	mu     sync.Mutex
+	n      int
+	peak   int
+	shed   int
+	routes map[string]bool
+}
+
+func (g *gauge) InFlight(route string, delta int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.n += delta
+	g.peak = max(g.peak, g.n)
+	g.routes[route] = true
+}
+
+func (g *gauge) Shed(string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.shed++
+}
+
+func TestShedAboveConcurrent(t *testing.T) {
+	const limit, requests = 2, 8
+
+	g := &gauge{routes: make(map[string]bool)}
+	entered := make(chan struct{}, requests)
+	release := make(chan struct{})
+	rt := gwu.NewRouter(gwu.Observe(g), gwu.ShedAbove(limit))
+	gwu.HandleRoute(rt, "GET /poems/{mode}", gwu.PathVal("mode"), func(_ context.Context, mode string,
+		_ gwu.HandleOpts) (gwu.NoBody, int, error) {
+		entered <- struct{}{}
+		<-release
+		if mode == "panic" {
+			panic("boom")
+		}
+
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	})
+
+	srv := httptest.NewUnstartedServer(rt)
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.Start()
+	defer srv.Close()
+
+	// Occupy the limit with a slow and a panicking request, then send the rest while both are in flight.
+	codes := make(chan int, requests)
+	var wg sync.WaitGroup
+	get := func(path string) {
+		defer wg.Done()
+
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			codes <- 0
+			return
+		}
+
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") != "1" {
+			t.Errorf("shed response without Retry-After: 1")
+		}
+
+		codes <- resp.StatusCode
+	}
+
+	wg.Add(2)
+	go get("/poems/slow")
+	go get("/poems/panic")
+	<-entered
+	<-entered
+
+	for range requests - limit {
+		wg.Add(1)
+		get("/poems/slow")
+	}
+
+	close(release)
+	wg.Wait()
+	close(codes)
+
+	counts := make(map[int]int)
+	for code := range codes {
+		counts[code]++
+	}
+
+	// The panicking request's connection is closed by the server without response.
+	want := map[int]int{http.StatusNoContent: 1, 0: 1, http.StatusServiceUnavailable: requests - limit}
+	for code, n := range want {
+		if counts[code] != n {
+			t.Errorf("responses %v, want %v", counts, want)
+			break
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.routes["GET /poems/{mode}"] {
+		t.Errorf("observed routes %v, want GET /poems/{mode}", g.routes)
+	}
+
+	if g.n != 0 || g.peak != limit || g.shed != requests-limit {
+		t.Errorf("gauge %d, peak %d, shed %d, want 0, %d, and %d", g.n, g.peak, g.shed, limit, requests-limit)
+	}
+}
@@ -0,0 +1,172 @@
+package gwu
+
+import (
+	"errors"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ErrIPNotAllowed is the error of requests from a client IP rejected by IPFilter.
+// Is safe to display to the client.
+var ErrIPNotAllowed = errors.New("client IP not allowed")
+
+// IPFilterConfig are the CIDRs of IPFilter, like "10.0.0.0/8" or "2001:db8::/32". A single IP is a CIDR of only
+// that IP. IPv4-mapped IPv6 addresses match the CIDRs of their IPv4 address.
+type IPFilterConfig struct {
+	// Allow are the CIDRs of the allowed client IPs, an empty Allow allows every IP not denied.
+	Allow []string
+	// Deny are the CIDRs of the denied client IPs, they take precedence over Allow.
+	Deny []string
+	// TrustedProxies are the CIDRs of the proxies whose Forwarded or X-Forwarded-For headers are trusted. The
+	// headers of requests from other peers are ignored, the peer's address is the client IP.
+	TrustedProxies []string
+}
+
+// IPFilter rejects requests from client IPs that are denied or not allowed with ErrIPNotAllowed and
+// http.StatusForbidden, and logs them at warn level with the client IP. Like Before, IPFilter accumulates, so a
+// request must pass the filters of a Router, its groups, and the route.
+//
+// The client IP is the address of the peer, unless the peer is a trusted proxy. Then it is the last address of the
+// Forwarded header, or the X-Forwarded-For header without Forwarded header, that is not a trusted proxy, or the first
+// address if all are. Requests whose client IP cannot be determined are rejected.
+//
+// Example usage:
+//
+//	admin := rt.Group("/admin", gwu.IPFilter(gwu.IPFilterConfig{
+//		Allow:          []string{"203.0.113.0/24", "10.8.0.0/16"},
+//		TrustedProxies: []string{"10.0.0.1"},
+//	}))
+func IPFilter(cfg IPFilterConfig) HandleOptsFunc {
+	var errs []error
+	allow, deny := parsePrefixes(cfg.Allow, &errs), parsePrefixes(cfg.Deny, &errs)
+	trusted := parsePrefixes(cfg.TrustedProxies, &errs)
+
+	filter := func(r *http.Request, opts HandleOpts) error {
+		ip, ok := clientIP(r, trusted)
+		if ok && !containsAddr(deny, ip) && (len(allow) == 0 || containsAddr(allow, ip)) {
+			return nil
+		}
+
+		client := ip.String()
+		if !ok {
+			client = r.RemoteAddr
+		}
+
+		logWarn(opts.Log, "client IP not allowed", "ip", client, "method", r.Method, "path", FullPath(r))
+		return WithStatus(http.StatusForbidden, ErrIPNotAllowed)
+	}
+
+	return func(opt *HandleOpts) {
+		for _, err := range errs {
+			opt.invalid("IPFilter: %v", err)
+		}
+
+		Before(filter)(opt)
+	}
+}
+
+// parsePrefixes parses the CIDRs and single IPs, it appends the invalid ones to errs.
+func parsePrefixes(cidrs []string, errs *[]error) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, s := range cidrs {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(s)
+			if addrErr != nil {
+				*errs = append(*errs, err)
+				continue
+			}
+
+			p = netip.PrefixFrom(addr, addr.BitLen())
+		}
+
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+
+		prefixes = append(prefixes, p.Masked())
+	}
+
+	return prefixes
+}
+
+// containsAddr reports whether one of the prefixes contains the address.
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientIP returns the client IP of the request, see IPFilter. It reports false if it cannot be determined.
+func clientIP(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	peer, ok := parseHost(r.RemoteAddr)
+	if !ok || !containsAddr(trusted, peer) {
+		return peer, ok
+	}
+
+	hops := forwardedFor(r.Header)
+	if len(hops) == 0 {
+		return peer, true
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseHost(hops[i])
+		if !ok {
+			return netip.Addr{}, false
+		}
+
+		if i == 0 || !containsAddr(trusted, addr) {
+			return addr, true
+		}
+	}
+
+	return peer, true
+}
+
+// forwardedFor returns the addresses of the for parameters of the Forwarded header, or of the X-Forwarded-For
+// header if there is no Forwarded header, in order.
+func forwardedFor(h http.Header) []string {
+	var hops []string
+	if values := h.Values("Forwarded"); len(values) > 0 {
+		for _, v := range values {
+			for _, elem := range strings.Split(v, ",") {
+				for _, pair := range strings.Split(elem, ";") {
+					k, v, _ := strings.Cut(strings.TrimSpace(pair), "=")
+					if strings.EqualFold(k, "for") {
+						hops = append(hops, strings.Trim(v, `"`))
+					}
+				}
+			}
+		}
+
+		return hops
+	}
+
+	for _, v := range h.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(v, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+
+	return hops
+}
+
+// parseHost parses an address with or without port, IPv6 addresses with port in brackets, and unmaps
+// IPv4-mapped addresses.
+func parseHost(s string) (netip.Addr, bool) {
+	if ap, err := netip.ParseAddrPort(s); err == nil {
+		return ap.Addr().Unmap(), true
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr.Unmap(), true
+}
@@ -0,0 +1,128 @@
+package gwu_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestIPFilter(t *testing.T) {
+	cfg := gwu.IPFilterConfig{
+		Allow:          []string{"203.0.113.0/24", "2001:db8::/32", "198.51.100.7"},
+		Deny:           []string{"203.0.113.66"},
+		TrustedProxies: []string{"10.0.0.0/8", "fd00::1"},
+	}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded string
+		xff       string
+		// client is the client IP of a rejected request, empty if the request is allowed.
+		client string
+	}{
+		{"allowed peer", "203.0.113.5:4711", "", "", ""},
+		{"single IP", "198.51.100.7:4711", "", "", ""},
+		{"denied peer", "203.0.113.66:4711", "", "", "203.0.113.66"},
+		{"peer not allowed", "192.0.2.1:4711", "", "", "192.0.2.1"},
+		{"IPv6 peer", "[2001:db8::5]:4711", "", "", ""},
+		{"IPv6 peer not allowed", "[2001:db9::5]:4711", "", "", "2001:db9::5"},
+		{"IPv4-mapped peer", "[::ffff:203.0.113.5]:4711", "", "", ""},
+		{"invalid peer", "somewhere", "", "", "somewhere"},
+
+		// The headers of untrusted peers are ignored, they cannot forge their IP.
+		{"untrusted XFF", "192.0.2.1:4711", "", "203.0.113.5", "192.0.2.1"},
+		{"untrusted Forwarded", "192.0.2.1:4711", "for=203.0.113.5", "", "192.0.2.1"},
+		{"untrusted peer allowed", "203.0.113.5:4711", "", "192.0.2.1", ""},
+
+		{"trusted proxy", "10.0.0.1:4711", "", "203.0.113.5", ""},
+		{"trusted proxy without header", "10.0.0.1:4711", "", "", "10.0.0.1"},
+		{"trusted chain", "10.0.0.1:4711", "", "203.0.113.5, 10.0.0.2, 10.0.0.3", ""},
+		// The client prepends a forged address, the last untrusted hop is the client.
+		{"forged XFF", "10.0.0.1:4711", "", "203.0.113.5, 192.0.2.1", "192.0.2.1"},
+		{"forged XFF behind proxies", "10.0.0.1:4711", "", "203.0.113.5, 192.0.2.1, 10.0.0.2", "192.0.2.1"},
+		{"multiple XFF headers", "10.0.0.1:4711", "", "192.0.2.1\x00203.0.113.5", ""},
+		{"all trusted", "10.0.0.1:4711", "", "10.0.0.3, 10.0.0.2", "10.0.0.3"},
+		{"invalid hop", "10.0.0.1:4711", "", "203.0.113.5, garbage", "10.0.0.1:4711"},
+		{"IPv6 trusted proxy", "[fd00::1]:4711", "", "2001:db8::5", ""},
+
+		{"Forwarded", "10.0.0.1:4711", "for=203.0.113.5;proto=https", "", ""},
+		{"Forwarded IPv6", "10.0.0.1:4711", `for="[2001:db8::5]:4711"`, "", ""},
+		{"Forwarded chain", "10.0.0.1:4711", "for=192.0.2.1, for=203.0.113.5;by=10.0.0.1, for=10.0.0.2", "", ""},
+		// Forwarded takes precedence, X-Forwarded-For is ignored.
+		{"Forwarded precedence", "10.0.0.1:4711", "for=192.0.2.1", "203.0.113.5", "192.0.2.1"},
+		{"Forwarded precedence allowed", "10.0.0.1:4711", "for=203.0.113.5", "192.0.2.1", ""},
+		{"Forwarded obfuscated", "10.0.0.1:4711", "for=_hidden", "", "10.0.0.1:4711"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := gwutest.Logger()
+			h := gwu.Handle(gwu.Empty(), noContent, gwu.Log(log), gwu.IPFilter(cfg))
+
+			r := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			r.RemoteAddr = tt.remote
+			if tt.forwarded != "" {
+				r.Header.Set("Forwarded", tt.forwarded)
+			}
+
+			for _, v := range strings.Split(tt.xff, "\x00") {
+				if v != "" {
+					r.Header.Add("X-Forwarded-For", v)
+				}
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if tt.client == "" {
+				if rec.Code != http.StatusNoContent {
+					t.Errorf("status %d, want the request allowed", rec.Code)
+				}
+
+				return
+			}
+
+			gwutest.AssertError(t, rec, http.StatusForbidden, gwu.ErrIPNotAllowed.Error())
+			log.AssertLogged(t, slog.LevelWarn, "client IP not allowed", "ip", tt.client, "path", "/admin")
+		})
+	}
+}
+
+func TestIPFilterInvalid(t *testing.T) {
+	_, err := gwu.TryHandle(gwu.Empty(), noContent, gwu.IPFilter(gwu.IPFilterConfig{
+		Allow:          []string{"203.0.113.0/33"},
+		TrustedProxies: []string{"proxy"},
+	}))
+
+	if err == nil || strings.Count(err.Error(), "IPFilter: ") != 2 {
+		t.Errorf("error %v, want the invalid Allow and TrustedProxies", err)
+	}
+}
+
+// TestIPFilterAccumulates requires a request to pass the filters of the Router and the route.
+func TestIPFilterAccumulates(t *testing.T) {
+	rt := gwu.NewRouter(gwu.IPFilter(gwu.IPFilterConfig{Allow: []string{"203.0.113.0/24"}}))
+	gwu.HandleRoute(rt, "GET /admin", gwu.Empty(), noContent,
+		gwu.IPFilter(gwu.IPFilterConfig{Deny: []string{"203.0.113.66"}}))
+
+	for remote, want := range map[string]int{
+		"203.0.113.5:4711":  http.StatusNoContent,
+		"203.0.113.66:4711": http.StatusForbidden,
+		"192.0.2.1:4711":    http.StatusForbidden,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		r.RemoteAddr = remote
+
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, r)
+		if rec.Code != want {
+			t.Errorf("%s: status %d, want %d", remote, rec.Code, want)
+		}
+	}
+}
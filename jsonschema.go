@@ -0,0 +1,244 @@
+package gwu
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SchemaErrors are the mismatches of a request body with the schema of JSONSchema, in order of their JSON pointers.
+// Is safe to display to the client.
+type SchemaErrors []*SchemaError
+
+// Error lists the mismatches one per line with their JSON pointers as URI fragments, like "#/lines/0: string, want
+// integer".
+func (e SchemaErrors) Error() string {
+	var b strings.Builder
+	for i, err := range e {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+
+		b.WriteString("#" + err.Pointer + ": " + err.Msg)
+	}
+
+	return b.String()
+}
+
+// JSONSchema CnIn validates the request body against the JSON Schema document before decoding it into the given data
+// type In with the handler's JSONCodec, so the schema is the source of truth, not the Go type. It returns the
+// mismatches as SchemaErrors wrapped in a ValidationError, Handle responds to it with the ValidationErrorStatus,
+// http.StatusBadRequest by default, or set it to http.StatusUnprocessableEntity.
+//
+// JSONSchema compiles the schema once and panics if it is invalid or uses unsupported keywords. It supports type,
+// including a type and "null" like ["string", "null"], enum, required, properties, additionalProperties, items,
+// minimum, maximum, minLength, maxLength, minItems, maxItems, and pattern. Annotations like title, description, and
+// format are ignored, references are not supported.
+//
+// Example usage:
+//
+//	//go:embed order.schema.json
+//	var orderSchema []byte
+//
+//	gwu.Post(rt, "/orders", gwu.JSONSchema[NewOrder](orderSchema), ctrl.CreateOrder,
+//		gwu.ValidationErrorStatus(http.StatusUnprocessableEntity))
+func JSONSchema[In any](schema []byte) CnIn[In] {
+	var doc any
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		panic(fmt.Errorf("gwu: JSONSchema: invalid schema: %w", err))
+	}
+
+	s, err := compileSchema(doc, "#")
+	if err != nil {
+		panic(fmt.Errorf("gwu: JSONSchema: %w", err))
+	}
+
+	return func(r *http.Request, opts HandleOpts) (In, error) {
+		var in In
+		b, err := readBody(r.Body)
+		if err != nil {
+			return in, ErrDecodeRequest
+		}
+
+		var v any
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return in, ErrDecodeRequest
+		}
+
+		var errs []*SchemaError
+		s.validate(v, valuePath{path: "$"}, nil, &errs)
+		if len(errs) > 0 {
+			return in, &ValidationError{Err: SchemaErrors(errs)}
+		}
+
+		if err := opts.JSONCodec().Unmarshal(b, &in); err != nil {
+			return in, ErrDecodeRequest
+		}
+
+		return in, nil
+	}
+}
+
+// schemaAnnotations are the keywords JSONSchema ignores.
+var schemaAnnotations = []string{
+	"$schema", "$id", "$comment", "title", "description", "default", "examples", "format", "deprecated", "readOnly",
+	"writeOnly",
+}
+
+// compileSchema compiles a decoded JSON Schema document, at is its location for errors.
+func compileSchema(doc any, at string) (*Schema, error) {
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: schema is %s, want object", at, jsonType(doc))
+	}
+
+	// Compile the keywords in order, so errors of invalid schemas are deterministic.
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	s := &Schema{}
+	for _, key := range keys {
+		v := m[key]
+		var err error
+		switch key {
+		case "type":
+			err = compileType(s, v)
+		case "enum":
+			if s.Enum, ok = v.([]any); !ok {
+				err = fmt.Errorf("is %s, want array", jsonType(v))
+			}
+		case "required":
+			s.Required, err = compileStrings(v)
+		case "properties":
+			props, ok := v.(map[string]any)
+			if !ok {
+				err = fmt.Errorf("is %s, want object", jsonType(v))
+				break
+			}
+
+			s.Properties = make(map[string]*Schema, len(props))
+			for name, prop := range props {
+				s.Properties[name], err = compileSchema(prop, valuePath{pointer: at + "/properties"}.property(name).pointer)
+				if err != nil {
+					return nil, err
+				}
+			}
+		case "additionalProperties":
+			if b, ok := v.(bool); ok {
+				s.closed = !b
+				break
+			}
+
+			s.AdditionalProperties, err = compileSchema(v, at+"/additionalProperties")
+			if err != nil {
+				return nil, err
+			}
+		case "items":
+			if s.Items, err = compileSchema(v, at+"/items"); err != nil {
+				return nil, err
+			}
+		case "minimum":
+			s.Minimum, err = compileNumber(v)
+		case "maximum":
+			s.Maximum, err = compileNumber(v)
+		case "minLength":
+			s.MinLength, err = compileCount(v)
+		case "maxLength":
+			s.MaxLength, err = compileCount(v)
+		case "minItems":
+			s.MinItems, err = compileCount(v)
+		case "maxItems":
+			s.MaxItems, err = compileCount(v)
+		case "pattern":
+			if s.Pattern, ok = v.(string); !ok {
+				err = fmt.Errorf("is %s, want string", jsonType(v))
+			} else {
+				s.pattern, err = regexp.Compile(s.Pattern)
+			}
+		default:
+			if !slices.Contains(schemaAnnotations, key) {
+				err = errors.New("unsupported keyword")
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", at, key, err)
+		}
+	}
+
+	return s, nil
+}
+
+// compileType sets the type of the schema, a type name or an array of a type name and "null".
+func compileType(s *Schema, v any) error {
+	names, ok := v.([]any)
+	if !ok {
+		names = []any{v}
+	}
+
+	for _, name := range names {
+		switch name {
+		case "null":
+			s.Nullable = true
+		case "string", "boolean", "number", "integer", "array", "object":
+			if s.Type != "" {
+				return fmt.Errorf("%v: more than one type besides null", v)
+			}
+
+			s.Type = name.(string)
+		default:
+			return fmt.Errorf("unknown type %v", name)
+		}
+	}
+
+	return nil
+}
+
+// compileStrings returns the decoded JSON array of strings.
+func compileStrings(v any) ([]string, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("is %s, want array", jsonType(v))
+	}
+
+	ss := make([]string, len(arr))
+	for i, e := range arr {
+		if ss[i], ok = e.(string); !ok {
+			return nil, fmt.Errorf("is %s, want string", jsonType(e))
+		}
+	}
+
+	return ss, nil
+}
+
+// compileNumber returns the decoded JSON number.
+func compileNumber(v any) (*float64, error) {
+	n, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("is %s, want number", jsonType(v))
+	}
+
+	return &n, nil
+}
+
+// compileCount returns the decoded JSON non-negative integer.
+func compileCount(v any) (*int, error) {
+	n, ok := v.(float64)
+	if !ok || !isInteger(n) || n < 0 {
+		return nil, fmt.Errorf("%v, want non-negative integer", v)
+	}
+
+	c := int(n)
+	return &c, nil
+}
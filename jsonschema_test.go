@@ -0,0 +1,54 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestJSONSchemaGolden(t *testing.T) {
+	schema, err := os.ReadFile("testdata/jsonschema/poem.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := gwu.Handle(gwu.JSONSchema[map[string]any](schema), func(_ context.Context, in map[string]any,
+		_ gwu.HandleOpts) (map[string]any, int, error) {
+		return in, http.StatusOK, nil
+	}, gwu.Errors(gwu.JSONError))
+
+	tests := []struct {
+		name, body string
+	}{
+		{"valid", `{"title": "Ode", "lines": ["a"], "rating": 5, "form": 1.0, "subtitle": null, "tags": {"a": "b"}}`},
+		{"integer forms", `{"title": "Ode", "lines": ["a"], "rating": 4.0, "form": 1e0, "score": 1e2}`},
+		{"type", `{"title": 1, "lines": "a", "rating": "5", "subtitle": 2, "explicit": "yes", "score": true}`},
+		{"required", `{}`},
+		{"enum", `{"title": "Ode", "lines": ["a"], "rating": 1, "form": "haiku"}`},
+		{"additionalProperties", `{"title": "Ode", "lines": ["a"], "rating": 1, "author": "Keats", "tags": {"a": 1}}`},
+		{"items", `{"title": "Ode", "lines": ["a", 2], "rating": 1}`},
+		{"minimum", `{"title": "Ode", "lines": ["a"], "rating": 0}`},
+		{"maximum", `{"title": "Ode", "lines": ["a"], "rating": 6}`},
+		{"integer", `{"title": "Ode", "lines": ["a"], "rating": 1.5}`},
+		{"minLength", `{"title": "", "lines": ["a"], "rating": 1}`},
+		{"maxLength", `{"title": "Ode to a Nightingale, Ode", "lines": ["a"], "rating": 1}`},
+		{"minItems", `{"title": "Ode", "lines": [], "rating": 1}`},
+		{"maxItems", `{"title": "Ode", "lines": ["a", "b", "c", "d"], "rating": 1}`},
+		{"pattern", `{"title": "ode", "lines": ["a"], "rating": 1}`},
+		{"root type", `[]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+			gwutest.Golden(t, rec, "testdata/jsonschema/"+strings.ReplaceAll(tt.name, " ", "_")+".golden.json")
+		})
+	}
+}
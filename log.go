@@ -0,0 +1,122 @@
+package gwu
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// fallbackLogger is the logger used when no logger is configured, it is created once on first use.
+var fallbackLogger = sync.OnceValue(func() Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+})
+
+// orFallback returns log, or the fallback logger if log is nil.
+func orFallback(log Logger) Logger {
+	if log == nil {
+		return fallbackLogger()
+	}
+
+	return log
+}
+
+// NoopLogger returns a Logger that discards everything.
+// Use it in tests, or to silence a handler explicitly with the Log option.
+func NoopLogger() Logger {
+	return noopLogger{}
+}
+
+type logCtxKey struct{}
+
+// ContextWithLogger returns a copy of ctx that carries the given logger.
+// Retrieve it with LoggerFrom.
+func ContextWithLogger(ctx context.Context, log Logger) context.Context {
+	return context.WithValue(ctx, logCtxKey{}, log)
+}
+
+// LoggerFrom returns the logger stored in ctx by ContextWithLogger.
+// Handle stores the request's logger in the context passed to the Exec, so that code without access to the
+// HandleOpts, like services, can log with the request's contextual attributes.
+//
+// If ctx carries no logger, LoggerFrom returns a logger that discards everything, never nil.
+func LoggerFrom(ctx context.Context) Logger {
+	log, ok := ctx.Value(logCtxKey{}).(Logger)
+	if !ok || log == nil {
+		return noopLogger{}
+	}
+
+	return log
+}
+
+// noopLogger discards all log records.
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, ...any) {}
+func (noopLogger) Info(string, ...any)  {}
+func (noopLogger) Warn(string, ...any)  {}
+func (noopLogger) Error(string, ...any) {}
+
+// LeveledLogger extends the Logger with the Warn and Error levels, slog.Logger implements it.
+// Gwu logs warnings and errors with these levels if the configured logger implements LeveledLogger, otherwise it
+// logs them with Info.
+type LeveledLogger interface {
+	Logger
+	Warn(string, ...any)
+	Error(string, ...any)
+}
+
+// logWarn logs at the warn level, or with Info if log does not implement LeveledLogger.
+func logWarn(log Logger, msg string, args ...any) {
+	if log, ok := log.(LeveledLogger); ok {
+		log.Warn(msg, args...)
+		return
+	}
+
+	log.Info(msg, args...)
+}
+
+// logError logs at the error level, or with Info if log does not implement LeveledLogger.
+func logError(log Logger, msg string, args ...any) {
+	if log, ok := log.(LeveledLogger); ok {
+		log.Error(msg, args...)
+		return
+	}
+
+	log.Info(msg, args...)
+}
+
+// attrLogger adds attributes to every record it logs.
+type attrLogger struct {
+	log   Logger
+	attrs []any
+}
+
+// withAttrs returns a logger that adds the given key-value pairs to every record, like slog.Logger.With.
+func withAttrs(log Logger, args ...any) Logger {
+	if len(args) == 0 {
+		return log
+	}
+
+	if l, ok := log.(attrLogger); ok {
+		return attrLogger{log: l.log, attrs: append(l.attrs[:len(l.attrs):len(l.attrs)], args...)}
+	}
+
+	return attrLogger{log: log, attrs: args}
+}
+
+func (l attrLogger) Debug(msg string, args ...any) {
+	l.log.Debug(msg, append(l.attrs[:len(l.attrs):len(l.attrs)], args...)...)
+}
+
+func (l attrLogger) Info(msg string, args ...any) {
+	l.log.Info(msg, append(l.attrs[:len(l.attrs):len(l.attrs)], args...)...)
+}
+
+func (l attrLogger) Warn(msg string, args ...any) {
+	logWarn(l.log, msg, append(l.attrs[:len(l.attrs):len(l.attrs)], args...)...)
+}
+
+func (l attrLogger) Error(msg string, args ...any) {
+	logError(l.log, msg, append(l.attrs[:len(l.attrs):len(l.attrs)], args...)...)
+}
@@ -0,0 +1,84 @@
+package gwu_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// loadPoem is a service without access to the HandleOpts, it logs with the logger of the context.
+func loadPoem(ctx context.Context, id int64) smallPoem {
+	gwu.LoggerFrom(ctx).Info("loading poem", "id", id)
+	return smallPoem{ID: id, Title: "Ode"}
+}
+
+func TestLoggerFrom(t *testing.T) {
+	requestID := func(r *http.Request, opts gwu.HandleOpts) error {
+		gwu.EnrichLog(opts, "request_id", r.Header.Get("X-Request-ID"))
+		return nil
+	}
+
+	log := gwutest.Logger()
+	rt := gwu.NewRouter(gwu.Log(log), gwu.Before(requestID))
+	gwu.HandleRoute(rt, "GET /poems/{id}", gwu.PathInt("id"),
+		func(ctx context.Context, id int64, _ gwu.HandleOpts) (smallPoem, int, error) {
+			return loadPoem(ctx, id), http.StatusOK, nil
+		})
+
+	r := httptest.NewRequest(http.MethodGet, "/poems/7", nil)
+	r.Header.Set("X-Request-ID", "req-1")
+	rt.ServeHTTP(httptest.NewRecorder(), r)
+
+	// The service logs with the attributes of the route and the request.
+	log.AssertLogged(t, slog.LevelInfo, "loading poem", "id", int64(7), "request_id", "req-1", "method", "GET",
+		"route", "/poems/{id}")
+}
+
+func TestLoggerFromEmptyContext(t *testing.T) {
+	for _, ctx := range []context.Context{context.Background(), gwu.ContextWithLogger(context.Background(), nil)} {
+		log := gwu.LoggerFrom(ctx)
+		if log == nil {
+			t.Fatal("LoggerFrom returned nil")
+		}
+
+		log.Info("discarded")
+	}
+
+	log := gwutest.Logger()
+	if got := gwu.LoggerFrom(gwu.ContextWithLogger(context.Background(), log)); got != log {
+		t.Errorf("LoggerFrom %v, want the stored logger", got)
+	}
+}
+
+// TestFallbackLogger shares one fallback logger between all handlers without a logger, including gwu.Log(nil).
+func TestFallbackLogger(t *testing.T) {
+	var logs []gwu.Logger
+	exec := func(_ context.Context, _ any, opts gwu.HandleOpts) (gwu.NoBody, int, error) {
+		logs = append(logs, opts.Log)
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	}
+
+	for _, h := range []http.Handler{
+		gwu.Handle(gwu.Empty(), exec),
+		gwu.Handle(gwu.Empty(), exec),
+		gwu.Handle(gwu.Empty(), exec, gwu.Log(nil)),
+		gwu.Handle(gwu.Empty(), exec, gwu.Log(gwutest.Logger()), gwu.Log(nil)),
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("status %d, want 204", rec.Code)
+		}
+	}
+
+	for i, log := range logs {
+		if log == nil || log != logs[0] {
+			t.Errorf("handler %d: logger %v, want the fallback logger %v", i, log, logs[0])
+		}
+	}
+}
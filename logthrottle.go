@@ -0,0 +1,188 @@
+package gwu
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ThrottleClientErrorLogs logs at most limit records of client errors, responses with a 4xx status code, per window
+// and client, like to keep a scanner sending malformed requests from flooding the logs. The key function identifies
+// the client, nil uses the IP of the peer.
+//
+// The throttled records are the debug records of failed CnIns, of Execs and BeforeFuncs returning a 4xx error, and
+// of aborted requests. Once a client's window ended, the number of records suppressed in it is logged at debug level
+// as one record, "client error logs suppressed", with the client, the number, and the window. The summary is logged
+// when the window ends, timed by the handler's Clock, with the logger of the first suppressed request. Clients
+// without client errors for a window are forgotten. Failures, responses with a 5xx status code, are never throttled.
+//
+// Set it on a Router to share the budgets of the clients between its routes.
+//
+// Example usage:
+//
+//	rt := gwu.NewRouter(gwu.ThrottleClientErrorLogs(10, time.Minute, nil))
+func ThrottleClientErrorLogs(limit int, window time.Duration, key func(r *http.Request) string) HandleOptsFunc {
+	if key == nil {
+		key = peerIP
+	}
+
+	t := &logThrottle{limit: limit, window: window, key: key, windows: make(map[string]*throttleWindow)}
+	return func(opt *HandleOpts) {
+		if limit < 0 || window <= 0 {
+			opt.invalid("ThrottleClientErrorLogs: limit %d is negative or window %s is not positive", limit, window)
+		}
+
+		opt.logThrottle = t
+	}
+}
+
+// logThrottle is the configuration and state of ThrottleClientErrorLogs.
+type logThrottle struct {
+	limit  int
+	window time.Duration
+	key    func(r *http.Request) string
+
+	mu      sync.Mutex
+	windows map[string]*throttleWindow
+	// sweep is the time of the next eviction of ended windows.
+	sweep time.Time
+	// flushing is set while a goroutine waits for the end of a window with suppressed records, see flush.
+	flushing bool
+}
+
+// throttleWindow are the client error records of a client in its current window.
+type throttleWindow struct {
+	end        time.Time
+	logged     int
+	suppressed int
+}
+
+// throttleSummary is the number of records suppressed for a client in an ended window.
+type throttleSummary struct {
+	client     string
+	suppressed int
+}
+
+// allow counts a record of the client and reports whether to log it, it returns the summaries of the ended windows.
+// If it suppressed the record and no flush is pending, it returns the time until the window ends, to flush it then.
+func (t *logThrottle) allow(client string, now time.Time) (bool, []throttleSummary, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var summaries []throttleSummary
+	if !now.Before(t.sweep) {
+		summaries = t.evict(now)
+	}
+
+	w, ok := t.windows[client]
+	if !ok {
+		w = &throttleWindow{end: now.Add(t.window)}
+		t.windows[client] = w
+		if t.sweep.IsZero() || w.end.Before(t.sweep) {
+			t.sweep = w.end
+		}
+	}
+
+	if w.logged >= t.limit {
+		w.suppressed++
+		if t.flushing {
+			return false, summaries, 0
+		}
+
+		t.flushing = true
+		return false, summaries, w.end.Sub(now)
+	}
+
+	w.logged++
+	return true, summaries, 0
+}
+
+// flush logs the summaries of the windows ended when the channel of the clock fires, and waits for the next window
+// with suppressed records, until there is none.
+func (t *logThrottle) flush(clock Clock, log Logger, ended <-chan time.Time) {
+	for {
+		now := <-ended
+		t.mu.Lock()
+		summaries := t.evict(now)
+		next := t.nextFlush(now)
+		t.mu.Unlock()
+
+		t.logSummaries(log, summaries)
+		if next <= 0 {
+			return
+		}
+
+		ended = clock.After(next)
+	}
+}
+
+// nextFlush returns the time until the first window with suppressed records ends, it clears flushing if there is
+// none. The caller must hold t.mu.
+func (t *logThrottle) nextFlush(now time.Time) time.Duration {
+	var end time.Time
+	for _, w := range t.windows {
+		if w.suppressed > 0 && (end.IsZero() || w.end.Before(end)) {
+			end = w.end
+		}
+	}
+
+	if end.IsZero() {
+		t.flushing = false
+		return 0
+	}
+
+	return end.Sub(now)
+}
+
+// logSummaries logs the summaries of ended windows.
+func (t *logThrottle) logSummaries(log Logger, summaries []throttleSummary) {
+	for _, s := range summaries {
+		log.Debug("client error logs suppressed", "client", s.client, "suppressed", s.suppressed,
+			"window", t.window.String())
+	}
+}
+
+// evict removes the ended windows, returns the summaries of those with suppressed records, and sets the time of the
+// next eviction. The caller must hold t.mu.
+func (t *logThrottle) evict(now time.Time) []throttleSummary {
+	var summaries []throttleSummary
+	t.sweep = time.Time{}
+	for client, w := range t.windows {
+		if !now.Before(w.end) {
+			if w.suppressed > 0 {
+				summaries = append(summaries, throttleSummary{client: client, suppressed: w.suppressed})
+			}
+
+			delete(t.windows, client)
+			continue
+		}
+
+		if t.sweep.IsZero() || w.end.Before(t.sweep) {
+			t.sweep = w.end
+		}
+	}
+
+	return summaries
+}
+
+// logClientError logs a debug record of a response with the status code, throttled by ThrottleClientErrorLogs if the
+// code is a 4xx status code.
+func (o HandleOpts) logClientError(r *http.Request, code int, msg string, args ...any) {
+	if o.logThrottle == nil || code < http.StatusBadRequest || code >= http.StatusInternalServerError {
+		o.Log.Debug(msg, args...)
+		return
+	}
+
+	t := o.logThrottle
+	clock := o.Clock()
+	ok, summaries, flushIn := t.allow(t.key(r), clock.Now())
+	t.logSummaries(o.Log, summaries)
+	if flushIn > 0 {
+		// The channel is created before the goroutine starts, so a ManualClock advanced afterward fires it.
+		go t.flush(clock, o.Log, clock.After(flushIn))
+	}
+
+	if ok {
+		o.Log.Debug(msg, args...)
+	}
+}
@@ -0,0 +1,62 @@
+package gwu_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// TestThrottleClientErrorLogsBurst sends a burst of malformed requests of two clients, only the first records of a
+// client are logged, and the summary of the suppressed ones once the window ended.
+func TestThrottleClientErrorLogsBurst(t *testing.T) {
+	const limit, burst = 3, 20
+
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	log := gwutest.Logger()
+	h := gwu.Handle(gwu.JSON[benchPoem](), echoPoem, gwu.Log(log), gwu.WithClock(clock),
+		gwu.ThrottleClientErrorLogs(limit, time.Minute, nil))
+
+	send := func(ip string) {
+		r := httptest.NewRequest(http.MethodPost, "/poems", strings.NewReader(`{"title":`))
+		r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+		r.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("status %d, want 400", rec.Code)
+		}
+	}
+
+	for range burst {
+		send("192.0.2.1")
+	}
+
+	send("192.0.2.2")
+
+	if got := len(log.Filter(slog.LevelDebug)); got != limit+1 {
+		t.Errorf("%d records logged, want %d of the burst and 1 of the other client", got, limit)
+	}
+
+	// The summary is logged when the window ends, without another client error.
+	clock.Advance(time.Minute)
+	eventually(t, func() bool { return log.Contains("client error logs suppressed") })
+	log.AssertLogged(t, slog.LevelDebug, "client error logs suppressed", "client", "192.0.2.1",
+		"suppressed", int64(burst-limit), "window", "1m0s")
+	if log.Contains("client error logs suppressed", "client", "192.0.2.2") {
+		t.Error("summary of a client without suppressed records")
+	}
+
+	// The next window has a new budget.
+	log.Reset()
+	send("192.0.2.1")
+	if got := len(log.Filter(slog.LevelDebug)); got != 1 {
+		t.Errorf("%d records logged in the next window, want 1", got)
+	}
+}
@@ -0,0 +1,209 @@
+package gwu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrInvalidWait is the error of responses to long-polling requests with an invalid wait query parameter.
+// Is safe to display to the client.
+var ErrInvalidWait = errors.New("invalid wait duration")
+
+// defaultPollInterval is the interval LongPoll fetches in without a Notifier.
+const defaultPollInterval = time.Second
+
+// PollResult is the Out of a LongPoll Exec.
+type PollResult[Out any] struct {
+	Data Out `json:"data"`
+	// Token identifies the state of the data, the client passes it with its next request.
+	Token string `json:"token"`
+	// Changed is false if the wait expired without a change, Data is empty then.
+	Changed bool `json:"changed"`
+}
+
+// Notifier notifies long-polling requests of changes, see PollNotifier. Broadcaster implements it.
+type Notifier interface {
+	// Subscribe returns a channel receiving a value on every change, and a function ending the subscription.
+	Subscribe() (<-chan struct{}, func())
+}
+
+// PollOption configures a LongPoll Exec.
+type PollOption func(*pollConfig)
+
+type pollConfig struct {
+	notifier  Notifier
+	interval  time.Duration
+	noContent bool
+}
+
+// PollNotifier makes LongPoll fetch when the Notifier reports a change, instead of in an interval.
+func PollNotifier(n Notifier) PollOption {
+	return func(c *pollConfig) {
+		c.notifier = n
+	}
+}
+
+// PollInterval sets the interval LongPoll fetches in without a Notifier, defaults to one second.
+func PollInterval(d time.Duration) PollOption {
+	return func(c *pollConfig) {
+		c.interval = d
+	}
+}
+
+// PollNoContent makes LongPoll respond with http.StatusNoContent and no body if the wait expires without a
+// change, instead of http.StatusOK and a PollResult with Changed false. The client keeps its token.
+func PollNoContent() PollOption {
+	return func(c *pollConfig) {
+		c.noContent = true
+	}
+}
+
+// LongPoll returns an Exec that waits for a change of the data. It calls fetch, which returns the data, the token
+// of the data, and whether the data changed compared to the input, e.g. the token passed by the client. If the data
+// did not change, LongPoll waits for a notification or the next interval, see PollOption, and calls fetch again.
+// It responds with the changed data and its token, or without change once maxWait expired.
+//
+// The client can shorten the wait with the wait query parameter, e.g. wait=10s, LongPoll responds to an invalid
+// wait with ErrInvalidWait and http.StatusBadRequest. LongPoll stops waiting when the client disconnects.
+// A fetch error is returned with the status of a StatusError, or http.StatusInternalServerError.
+//
+// Example usage:
+//
+//	gwu.Get(rt, "/poems/changes", SinceIn(), gwu.LongPoll(store.ChangesSince, 30*time.Second,
+//		gwu.PollNotifier(store.Changes)), gwu.LongLived())
+func LongPoll[In, Out any](
+	fetch func(ctx context.Context, in In) (Out, string, bool, error),
+	maxWait time.Duration,
+	optFns ...PollOption,
+) Exec[In, PollResult[Out]] {
+	c := pollConfig{interval: defaultPollInterval}
+	for _, fn := range optFns {
+		fn(&c)
+	}
+
+	return func(ctx context.Context, in In, opts HandleOpts) (PollResult[Out], int, error) {
+		wait, err := pollWait(opts, maxWait)
+		if err != nil {
+			return PollResult[Out]{}, http.StatusBadRequest, err
+		}
+
+		// Subscribe before the first fetch, so no change between the fetch and the wait is missed.
+		var notify <-chan struct{}
+		if c.notifier != nil {
+			ch, unsubscribe := c.notifier.Subscribe()
+			defer unsubscribe()
+			notify = ch
+		}
+
+		clock := opts.Clock()
+		expired := clock.After(wait)
+		for {
+			out, token, changed, err := fetch(ctx, in)
+			if err != nil {
+				code := http.StatusInternalServerError
+				var statusErr *StatusError
+				if errors.As(err, &statusErr) {
+					code = statusErr.Status
+				}
+
+				return PollResult[Out]{}, code, err
+			}
+
+			if changed {
+				return PollResult[Out]{Data: out, Token: token, Changed: true}, http.StatusOK, nil
+			}
+
+			var tick <-chan time.Time
+			if notify == nil {
+				tick = clock.After(c.interval)
+			}
+
+			select {
+			case <-notify:
+			case <-tick:
+			case <-expired:
+				return unchanged[Out](c, token)
+			case <-ctx.Done():
+				return unchanged[Out](c, token)
+			}
+		}
+	}
+}
+
+// unchanged returns the response of a wait that expired without change.
+func unchanged[Out any](c pollConfig, token string) (PollResult[Out], int, error) {
+	if c.noContent {
+		return PollResult[Out]{}, http.StatusNoContent, nil
+	}
+
+	return PollResult[Out]{Token: token}, http.StatusOK, nil
+}
+
+// pollWait returns the wait of a long-polling request, the wait query parameter capped to maxWait.
+func pollWait(opts HandleOpts, maxWait time.Duration) (time.Duration, error) {
+	if opts.req.r == nil {
+		return maxWait, nil
+	}
+
+	v := opts.req.r.URL.Query().Get("wait")
+	if v == "" {
+		return maxWait, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, ErrInvalidWait
+	}
+
+	return min(d, maxWait), nil
+}
+
+// Broadcaster is a Notifier that notifies all subscribers on Notify. The zero value is ready to use.
+//
+// Example usage:
+//
+//	var changes gwu.Broadcaster
+//	store.OnChange(changes.Notify)
+//	gwu.Get(rt, "/poems/changes", SinceIn(),
+//		gwu.LongPoll(store.ChangesSince, 30*time.Second, gwu.PollNotifier(&changes)))
+type Broadcaster struct {
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+// Subscribe returns a channel receiving a value after every Notify, and a function ending the subscription.
+// Notifications are coalesced while a subscriber has not received the previous one.
+func (b *Broadcaster) Subscribe() (<-chan struct{}, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subs == nil {
+		b.subs = make(map[chan struct{}]struct{})
+	}
+
+	ch := make(chan struct{}, 1)
+	b.subs[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subs, ch)
+	}
+}
+
+// Notify notifies all subscribers of a change.
+func (b *Broadcaster) Notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
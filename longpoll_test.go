@@ -0,0 +1,270 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// fetched is a result of the fetch of a poller.
+type fetched struct {
+	data, token string
+	changed     bool
+	err         error
+}
+
+// poller is the data of a LongPoll, its fetch returns the results the test sends.
+type poller struct {
+	results chan fetched
+}
+
+func newPoller() *poller {
+	return &poller{results: make(chan fetched)}
+}
+
+func (p *poller) fetch(ctx context.Context, since string) (string, string, bool, error) {
+	select {
+	case f := <-p.results:
+		return f.data, f.token, f.changed, f.err
+	case <-ctx.Done():
+		return "", since, false, ctx.Err()
+	}
+}
+
+// fakeNotifier is a Notifier recording its subscriptions.
+type fakeNotifier struct {
+	changes      chan struct{}
+	unsubscribed chan struct{}
+}
+
+func newFakeNotifier() *fakeNotifier {
+	return &fakeNotifier{changes: make(chan struct{}, 1), unsubscribed: make(chan struct{})}
+}
+
+func (n *fakeNotifier) Subscribe() (<-chan struct{}, func()) {
+	return n.changes, func() { close(n.unsubscribed) }
+}
+
+// longPoll serves the request in its own goroutine, the channel receives the response once served.
+func longPoll(h http.Handler, r *http.Request) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		done <- rec
+	}()
+
+	return done
+}
+
+// pollResponse returns the response of the long-polling request, or fails the test if it takes too long.
+func pollResponse(t *testing.T, done <-chan *httptest.ResponseRecorder) (*httptest.ResponseRecorder,
+	gwu.PollResult[string]) {
+	t.Helper()
+
+	var rec *httptest.ResponseRecorder
+	select {
+	case rec = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no response")
+	}
+
+	var res gwu.PollResult[string]
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatalf("%s: %v", rec.Body, err)
+		}
+	}
+
+	return rec, res
+}
+
+func pollHandler(p *poller, n gwu.Notifier, clock gwu.Clock, optFns ...gwu.PollOption) http.Handler {
+	if n != nil {
+		optFns = append(optFns, gwu.PollNotifier(n))
+	}
+
+	return gwu.Handle(gwu.QueryVal("since"), gwu.LongPoll(p.fetch, 30*time.Second, optFns...), gwu.WithClock(clock))
+}
+
+func TestLongPollImmediate(t *testing.T) {
+	p, n := newPoller(), newFakeNotifier()
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	done := longPoll(pollHandler(p, n, clock), httptest.NewRequest(http.MethodGet, "/poems/changes?since=t1", nil))
+
+	p.results <- fetched{data: "Ode", token: "t2", changed: true}
+	rec, res := pollResponse(t, done)
+	if rec.Code != http.StatusOK || res != (gwu.PollResult[string]{Data: "Ode", Token: "t2", Changed: true}) {
+		t.Errorf("%d %+v, want 200 with the changed data", rec.Code, res)
+	}
+
+	<-n.unsubscribed
+}
+
+// TestLongPollNotify fetches again when the Notifier reports a change.
+func TestLongPollNotify(t *testing.T) {
+	p, n := newPoller(), newFakeNotifier()
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	done := longPoll(pollHandler(p, n, clock), httptest.NewRequest(http.MethodGet, "/poems/changes?since=t1", nil))
+
+	p.results <- fetched{token: "t1"}
+	n.changes <- struct{}{}
+	p.results <- fetched{data: "Ode", token: "t2", changed: true}
+
+	rec, res := pollResponse(t, done)
+	if rec.Code != http.StatusOK || res != (gwu.PollResult[string]{Data: "Ode", Token: "t2", Changed: true}) {
+		t.Errorf("%d %+v, want 200 with the changed data", rec.Code, res)
+	}
+
+	<-n.unsubscribed
+}
+
+func TestLongPollTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		wait    time.Duration
+		optFns  []gwu.PollOption
+		status  int
+		changed bool
+	}{
+		{"max wait", "/poems/changes?since=t1", 30 * time.Second, nil, http.StatusOK, false},
+		{"shorter wait", "/poems/changes?since=t1&wait=5s", 5 * time.Second, nil, http.StatusOK, false},
+		{"wait capped", "/poems/changes?since=t1&wait=1h", 30 * time.Second, nil, http.StatusOK, false},
+		{"no content", "/poems/changes?since=t1", 30 * time.Second, []gwu.PollOption{gwu.PollNoContent()},
+			http.StatusNoContent, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, n := newPoller(), newFakeNotifier()
+			clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+			done := longPoll(pollHandler(p, n, clock, tt.optFns...), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			// The wait started before the first fetch.
+			p.results <- fetched{token: "t1"}
+			clock.Advance(tt.wait - time.Millisecond)
+			select {
+			case rec := <-done:
+				t.Fatalf("response %d before the wait expired", rec.Code)
+			case <-time.After(10 * time.Millisecond):
+			}
+
+			clock.Advance(time.Millisecond)
+			rec, res := pollResponse(t, done)
+			if rec.Code != tt.status || res != (gwu.PollResult[string]{Token: res.Token}) {
+				t.Fatalf("%d %+v, want %d without data", rec.Code, res, tt.status)
+			}
+
+			if tt.status == http.StatusNoContent {
+				if rec.Body.Len() != 0 {
+					t.Errorf("body %q, want none", rec.Body)
+				}
+			} else if res.Token != "t1" {
+				t.Errorf("token %q, want the token of the client", res.Token)
+			}
+		})
+	}
+}
+
+// TestLongPollDisconnect stops waiting once the client disconnects.
+func TestLongPollDisconnect(t *testing.T) {
+	p, n := newPoller(), newFakeNotifier()
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest(http.MethodGet, "/poems/changes?since=t1", nil).WithContext(ctx)
+	done := longPoll(pollHandler(p, n, clock), r)
+
+	p.results <- fetched{token: "t1"}
+	cancel()
+
+	// The clock does not move, only the disconnect ends the wait.
+	pollResponse(t, done)
+	<-n.unsubscribed
+}
+
+// TestLongPollInterval fetches in the PollInterval without a Notifier.
+func TestLongPollInterval(t *testing.T) {
+	fetches := 0
+	fetch := func(context.Context, string) (string, string, bool, error) {
+		fetches++
+		return "Ode", "t2", fetches == 3, nil
+	}
+
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	h := gwu.Handle(gwu.QueryVal("since"), gwu.LongPoll(fetch, 30*time.Second, gwu.PollInterval(100*time.Millisecond)),
+		gwu.WithClock(clock))
+
+	start := clock.Now()
+	rec := serveAdvancing(h, httptest.NewRequest(http.MethodGet, "/poems/changes?since=t1", nil), clock)
+	if rec.Code != http.StatusOK || fetches != 3 {
+		t.Errorf("%d after %d fetches, want 200 after 3", rec.Code, fetches)
+	}
+
+	if waited := clock.Since(start); waited < 200*time.Millisecond || waited >= time.Second {
+		t.Errorf("waited %v, want two intervals", waited)
+	}
+}
+
+func TestLongPollErrors(t *testing.T) {
+	notFound := gwu.WithStatus(http.StatusNotFound, errPoemNotFound)
+	for _, tt := range []struct {
+		name, target string
+		err          error
+		status       int
+		want         string
+	}{
+		{"invalid wait", "/poems/changes?wait=soon", nil, http.StatusBadRequest, gwu.ErrInvalidWait.Error()},
+		{"negative wait", "/poems/changes?wait=-1s", nil, http.StatusBadRequest, gwu.ErrInvalidWait.Error()},
+		{"fetch error", "/poems/changes", errors.New("database down"), http.StatusInternalServerError, "database down"},
+		{"fetch status error", "/poems/changes", notFound, http.StatusNotFound, errPoemNotFound.Error()},
+	} {
+		fetch := func(context.Context, any) (string, string, bool, error) {
+			return "", "", false, tt.err
+		}
+
+		rec := httptest.NewRecorder()
+		h := gwu.Handle(gwu.Empty(), gwu.LongPoll(fetch, time.Second))
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		gwutest.AssertError(t, rec, tt.status, tt.want)
+	}
+}
+
+func TestBroadcaster(t *testing.T) {
+	var b gwu.Broadcaster
+	first, unsubscribe := b.Subscribe()
+	second, _ := b.Subscribe()
+
+	// Notifications are coalesced until received.
+	b.Notify()
+	b.Notify()
+	for name, ch := range map[string]<-chan struct{}{"first": first, "second": second} {
+		select {
+		case <-ch:
+		default:
+			t.Errorf("%s subscriber not notified", name)
+		}
+
+		select {
+		case <-ch:
+			t.Errorf("%s subscriber notified twice", name)
+		default:
+		}
+	}
+
+	unsubscribe()
+	b.Notify()
+	select {
+	case <-first:
+		t.Error("notified after unsubscribing")
+	case <-second:
+	}
+}
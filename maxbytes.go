@@ -0,0 +1,71 @@
+package gwu
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrRequestTooLarge is the error of responses to requests with a body exceeding MaxRequestBytes.
+// Is safe to display to the client.
+var ErrRequestTooLarge = errors.New("request body too large")
+
+// MaxRequestBytes limits the request body to n bytes before the CnIn runs, no matter how the CnIn reads it.
+// Handle responds to larger bodies with ErrRequestTooLarge and http.StatusRequestEntityTooLarge.
+//
+// Set it on a Router or Group to limit all of its routes, and override it per route. A negative n removes the
+// limit, e.g. for an upload route.
+//
+// Example usage:
+//
+//	api := rt.Group("/api", gwu.MaxRequestBytes(1<<20))
+//	gwu.Post(api, "/upload", upload.In, upload.Exec, gwu.MaxRequestBytes(-1))
+func MaxRequestBytes(n int64) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		if n == 0 {
+			opt.invalid("MaxRequestBytes: limit must not be 0, use a negative limit to remove it")
+			return
+		}
+
+		opt.bodyMax = n
+	}
+}
+
+// limitedBody is a request body limited with http.MaxBytesReader that remembers if the limit was exceeded.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		b.exceeded = true
+	}
+
+	return n, err
+}
+
+// limitBody limits the request body to the handler's MaxRequestBytes. It reports false if the Content-Length
+// already exceeds the limit.
+func (o HandleOpts) limitBody(w http.ResponseWriter, r *http.Request) (*limitedBody, bool) {
+	if o.bodyMax <= 0 || r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+
+	if r.ContentLength > o.bodyMax {
+		return nil, false
+	}
+
+	body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, o.bodyMax)}
+	r.Body = body
+
+	return body, true
+}
+
+// tooLarge reports whether the request body exceeded the limit.
+func (b *limitedBody) tooLarge() bool {
+	return b != nil && b.exceeded
+}
@@ -0,0 +1,86 @@
+package gwu_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// upload returns a request with a body of n bytes, without Content-Length if chunked.
+func upload(path string, n int, chunked bool) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(strings.Repeat("a", n)))
+	if chunked {
+		r.Body = io.NopCloser(r.Body)
+		r.ContentLength = -1
+	}
+
+	return r
+}
+
+func TestMaxRequestBytes(t *testing.T) {
+	rt := gwu.NewRouter()
+	api := rt.Group("/api", gwu.MaxRequestBytes(16), gwu.Errors(gwu.JSONError))
+	gwu.Post(api, "/poems", gwu.RawBody(), discardBody)
+	gwu.Post(api, "/drafts", gwu.RawBody(), discardBody, gwu.MaxRequestBytes(64))
+	gwu.Post(api, "/upload", gwu.RawBody(), discardBody, gwu.MaxRequestBytes(-1))
+
+	tests := []struct {
+		path   string
+		n      int
+		status int
+	}{
+		{"/api/poems", 16, http.StatusNoContent},
+		{"/api/poems", 17, http.StatusRequestEntityTooLarge},
+		{"/api/drafts", 64, http.StatusNoContent},
+		{"/api/drafts", 65, http.StatusRequestEntityTooLarge},
+		{"/api/upload", 1 << 20, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		// A Content-Length over the limit is rejected before the CnIn, a longer chunked body while reading it.
+		for _, chunked := range []bool{false, true} {
+			rec := httptest.NewRecorder()
+			rt.ServeHTTP(rec, upload(tt.path, tt.n, chunked))
+			if rec.Code != tt.status {
+				t.Errorf("%s with %d bytes, chunked %v: status %d, want %d", tt.path, tt.n, chunked, rec.Code, tt.status)
+				continue
+			}
+
+			if tt.status == http.StatusRequestEntityTooLarge {
+				gwutest.AssertError(t, rec, http.StatusRequestEntityTooLarge, gwu.ErrRequestTooLarge.Error())
+				if rec.Header().Get("Content-Type") != gwu.ContentTypeJSON {
+					t.Errorf("%s: Content-Type %q, want the JSON error of the group", tt.path,
+						rec.Header().Get("Content-Type"))
+				}
+			}
+		}
+	}
+}
+
+// TestMaxRequestBytesJSON responds with 413 instead of a decoding error if the JSON body is cut off by the limit.
+func TestMaxRequestBytesJSON(t *testing.T) {
+	h := gwu.Handle(gwu.JSON[smallPoem](), createSmallPoem, gwu.MaxRequestBytes(16))
+
+	r := httptest.NewRequest(http.MethodPost, "/poems", strings.NewReader(`{"id": 7, "title": "Ode to Autumn"}`))
+	r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+	r.ContentLength = -1
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	gwutest.AssertError(t, rec, http.StatusRequestEntityTooLarge, gwu.ErrRequestTooLarge.Error())
+}
+
+func TestMaxRequestBytesZero(t *testing.T) {
+	defer func() {
+		if v := recover(); v == nil || !strings.Contains(v.(error).Error(), "MaxRequestBytes") {
+			t.Errorf("panic %v, want the invalid limit", v)
+		}
+	}()
+
+	gwu.Handle(gwu.RawBody(), discardBody, gwu.MaxRequestBytes(0))
+}
@@ -0,0 +1,170 @@
+package gwu
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// memo is the cache of Memo.
+type memo[Out any] struct {
+	compute func(ctx context.Context) (Out, error)
+	maxAge  time.Duration
+
+	mu  sync.Mutex
+	val Out
+	has bool
+	at  time.Time
+	// gen counts the invalidations, valGen is the generation of val.
+	gen, valGen uint64
+	refreshing  bool
+	// loading is closed when the first computation is done, loadErr is its error.
+	loading chan struct{}
+	loadErr error
+}
+
+// Memo returns an Exec serving the value computed by compute from a cache, use it for expensive endpoints whose
+// result rarely changes, like a list that only changes on writes. Register it with Empty.
+//
+// The first request computes the value, concurrent requests wait for the same computation. A value older than maxAge,
+// or invalidated by a receive from invalidate, is stale: the next request starts recomputing it in the background
+// and is served the stale value, like all requests until the new value is computed, so recomputing never delays a
+// request. Concurrent recomputations are collapsed into one. A maxAge <= 0 keeps the value until it is invalidated.
+//
+// If recomputing fails, Memo keeps serving the last value and logs the error with the request's logger, the next
+// request retries. If the first computation fails, the waiting requests respond with the error like an Exec's error
+// without status code, see RegisterError, and the next request retries. A panic of compute is a failure with an
+// error, it is recovered. Memo computes with the request's context values, but not its cancellation.
+// Its age is measured with the handler's Clock. Memo stops receiving from invalidate when it is closed.
+//
+// The write handlers nudge the Memo by sending on the channel, without blocking if a nudge is pending:
+//
+//	changed := make(chan struct{}, 1)
+//	gwu.Get(rt, "/poems", gwu.Empty(), gwu.Memo(store.AllPoems, changed, time.Minute))
+//
+//	// After a write:
+//	select {
+//	case changed <- struct{}{}:
+//	default:
+//	}
+func Memo[Out any](
+	compute func(ctx context.Context) (Out, error), invalidate <-chan struct{}, maxAge time.Duration,
+) Exec[any, Out] {
+	m := &memo[Out]{compute: compute, maxAge: maxAge}
+	if invalidate != nil {
+		go func() {
+			for range invalidate {
+				m.mu.Lock()
+				m.gen++
+				m.mu.Unlock()
+			}
+		}()
+	}
+
+	return m.exec
+}
+
+// exec serves the cached value and starts recomputing it if it is stale.
+func (m *memo[Out]) exec(ctx context.Context, _ any, opts HandleOpts) (Out, int, error) {
+	clock := opts.Clock()
+
+	m.mu.Lock()
+	for !m.has {
+		if m.loading == nil {
+			if err := m.load(ctx, clock); err != nil {
+				m.mu.Unlock()
+				var zero Out
+				return zero, 0, err
+			}
+
+			break
+		}
+
+		loading := m.loading
+		m.mu.Unlock()
+		select {
+		case <-loading:
+		case <-ctx.Done():
+			var zero Out
+			return zero, 0, ctx.Err()
+		}
+
+		m.mu.Lock()
+		if !m.has && m.loading == nil {
+			err := m.loadErr
+			m.mu.Unlock()
+			var zero Out
+			return zero, 0, err
+		}
+	}
+
+	stale := m.valGen != m.gen || m.maxAge > 0 && clock.Since(m.at) > m.maxAge
+	if stale && !m.refreshing {
+		m.refreshing = true
+		go m.refresh(context.WithoutCancel(ctx), clock, opts.Log)
+	}
+
+	v := m.val
+	m.mu.Unlock()
+
+	return v, http.StatusOK, nil
+}
+
+// load computes the first value, the caller must hold m.mu, load releases it while computing.
+func (m *memo[Out]) load(ctx context.Context, clock Clock) error {
+	loading := make(chan struct{})
+	m.loading = loading
+	gen := m.gen
+	m.mu.Unlock()
+
+	v, err := m.call(context.WithoutCancel(ctx))
+
+	m.mu.Lock()
+	m.loading, m.loadErr = nil, err
+	if err == nil {
+		m.set(v, gen, clock)
+	}
+
+	close(loading)
+
+	return err
+}
+
+// refresh recomputes the value in the background, it keeps the last value if computing fails.
+func (m *memo[Out]) refresh(ctx context.Context, clock Clock, log Logger) {
+	m.mu.Lock()
+	gen := m.gen
+	m.mu.Unlock()
+
+	v, err := m.call(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.refreshing = false
+	if err != nil {
+		logWarn(log, "memo refresh failed, serving the last value", "error", err)
+		return
+	}
+
+	m.set(v, gen, clock)
+}
+
+// call calls compute and returns a panic of it as error, so a panic neither leaves the waiting requests blocked nor
+// crashes the process from the goroutine of refresh.
+func (m *memo[Out]) call(ctx context.Context) (v Out, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("memo compute panicked: %v", p)
+		}
+	}()
+
+	return m.compute(ctx)
+}
+
+// set stores the value computed at the generation gen, the caller must hold m.mu.
+func (m *memo[Out]) set(v Out, gen uint64, clock Clock) {
+	m.val, m.has, m.at, m.valGen = v, true, clock.Now(), gen
+}
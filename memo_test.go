@@ -0,0 +1,213 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// eventually fails the test if cond does not hold within a second.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+	}
+}
+
+// counter is a compute function returning the number of its calls.
+type counter struct {
+	calls atomic.Int64
+}
+
+func (c *counter) compute(context.Context) (int64, error) {
+	return c.calls.Add(1), nil
+}
+
+func callMemo(t *testing.T, exec gwu.Exec[any, int64], optFns ...gwu.HandleOptsFunc) int64 {
+	t.Helper()
+
+	v, _, err := gwutest.CallExec(context.Background(), exec, nil, optFns...)
+	if err != nil {
+		t.Fatalf("memo failed: %v", err)
+	}
+
+	return v
+}
+
+func TestMemoCaches(t *testing.T) {
+	var c counter
+	exec := gwu.Memo(c.compute, nil, time.Minute)
+
+	for range 3 {
+		if v := callMemo(t, exec); v != 1 {
+			t.Fatalf("got %d, want the cached 1", v)
+		}
+	}
+}
+
+func TestMemoStaleWhileRevalidate(t *testing.T) {
+	clock := gwu.NewManualClock(time.Unix(0, 0))
+	release := make(chan struct{})
+	var calls atomic.Int64
+	exec := gwu.Memo(func(context.Context) (int64, error) {
+		if n := calls.Add(1); n > 1 {
+			<-release
+			return n, nil
+		}
+
+		return 1, nil
+	}, nil, time.Minute)
+
+	callMemo(t, exec, gwu.WithClock(clock))
+	clock.Advance(2 * time.Minute)
+
+	// The recomputation blocks, the stale value is served meanwhile and concurrent recomputations are collapsed.
+	for range 5 {
+		if v := callMemo(t, exec, gwu.WithClock(clock)); v != 1 {
+			t.Fatalf("got %d during recomputation, want the stale 1", v)
+		}
+	}
+
+	eventually(t, func() bool { return calls.Load() == 2 })
+	close(release)
+	eventually(t, func() bool { return callMemo(t, exec, gwu.WithClock(clock)) == 2 })
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("compute called %d times, want 2", n)
+	}
+}
+
+func TestMemoInvalidate(t *testing.T) {
+	var c counter
+	invalidate := make(chan struct{})
+	defer close(invalidate)
+
+	exec := gwu.Memo(c.compute, invalidate, 0)
+	if v := callMemo(t, exec); v != 1 {
+		t.Fatalf("got %d, want 1", v)
+	}
+
+	invalidate <- struct{}{}
+	eventually(t, func() bool { return callMemo(t, exec) == 2 })
+}
+
+func TestMemoRefreshFailureKeepsValue(t *testing.T) {
+	clock := gwu.NewManualClock(time.Unix(0, 0))
+	log := gwutest.Logger()
+	var calls atomic.Int64
+	exec := gwu.Memo(func(context.Context) (int64, error) {
+		switch calls.Add(1) {
+		case 1:
+			return 1, nil
+		case 2:
+			return 0, errors.New("store down")
+		default:
+			panic("store on fire")
+		}
+	}, nil, time.Minute)
+
+	ctx := gwu.ContextWithLogger(context.Background(), log)
+	call := func() int64 {
+		v, _, err := gwutest.CallExec(ctx, exec, nil, gwu.WithClock(clock))
+		if err != nil {
+			t.Fatalf("memo failed: %v", err)
+		}
+
+		return v
+	}
+
+	call()
+	clock.Advance(2 * time.Minute)
+	for _, want := range []int64{2, 3} {
+		if v := call(); v != 1 {
+			t.Fatalf("got %d, want the last value 1", v)
+		}
+
+		eventually(t, func() bool { return calls.Load() == want && len(log.Filter(slog.LevelWarn)) == int(want-1) })
+	}
+
+	log.AssertLogged(t, slog.LevelWarn, "memo refresh failed, serving the last value", "error", "store down")
+	log.AssertLogged(t, slog.LevelWarn, "memo refresh failed, serving the last value",
+		"error", "memo compute panicked: store on fire")
+}
+
+func TestMemoFirstComputationFails(t *testing.T) {
+	var calls atomic.Int64
+	exec := gwu.Memo(func(context.Context) (int64, error) {
+		switch calls.Add(1) {
+		case 1:
+			return 0, errors.New("store down")
+		case 2:
+			panic("store on fire")
+		default:
+			return 3, nil
+		}
+	}, nil, time.Minute)
+
+	for _, want := range []string{"store down", "memo compute panicked: store on fire"} {
+		if _, _, err := gwutest.CallExec(context.Background(), exec, nil); err == nil || err.Error() != want {
+			t.Fatalf("err = %v, want %q", err, want)
+		}
+	}
+
+	// The failures are not cached, and the panic did not leave the next request waiting.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if v, _, err := gwutest.CallExec(ctx, exec, nil); err != nil || v != 3 {
+		t.Fatalf("got %d, %v, want 3", v, err)
+	}
+}
+
+func TestMemoConcurrent(t *testing.T) {
+	clock := gwu.NewManualClock(time.Unix(0, 0))
+	var c counter
+	invalidate := make(chan struct{}, 1)
+	defer close(invalidate)
+
+	exec := gwu.Memo(c.compute, invalidate, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			last := int64(0)
+			for j := range 200 {
+				v, _, err := gwutest.CallExec(context.Background(), exec, nil, gwu.WithClock(clock))
+				if err != nil {
+					t.Errorf("memo failed: %v", err)
+					return
+				}
+
+				if v < last {
+					t.Errorf("got %d after %d, values must not go back", v, last)
+				}
+
+				last = v
+				switch {
+				case i == 0 && j%20 == 0:
+					select {
+					case invalidate <- struct{}{}:
+					default:
+					}
+				case i == 1 && j%50 == 0:
+					clock.Advance(2 * time.Minute)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,37 @@
+package gwu
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrMethodNotAllowed is the error of MethodNotAllowed responses. Is safe to display to the client.
+var ErrMethodNotAllowed = errors.New("method not allowed")
+
+// MethodNotAllowed returns an http.Handler responding with http.StatusMethodNotAllowed, the Allow header listing
+// the allowed methods, and ErrMethodNotAllowed as TextError.
+//
+// A Router responds like it to requests matching the path of routes for other methods only, using the Router's
+// Errors option if set, and the headers of its StaticHeaders and SecurityHeaders.
+func MethodNotAllowed(allowed ...string) http.Handler {
+	return &methodNotAllowed{errFn: TextError, allow: allowed}
+}
+
+type methodNotAllowed struct {
+	errFn ErrorFunc
+	allow []string
+	// header are the static headers of the response.
+	header http.Header
+}
+
+func (h *methodNotAllowed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, h.allow)
+}
+
+// write responds with the allowed methods, a Router passes the methods of the routes matching the request.
+func (h *methodNotAllowed) write(w http.ResponseWriter, r *http.Request, allow []string) {
+	setHeaders(w.Header(), h.header)
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	h.errFn(w, r, ErrMethodNotAllowed, http.StatusMethodNotAllowed)
+}
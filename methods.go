@@ -0,0 +1,57 @@
+package gwu
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Get registers a handler for GET requests to the path with HandleRoute.
+// The path must start with a slash, Get panics with the offending path otherwise.
+//
+// Example usage:
+//
+//	gwu.Get(mux, "/poem/{id}", gwu.PathVal("id"), ctrl.ByID)
+func Get[In, Out any](mux Mux, path string, inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) {
+	handleMethod(mux, http.MethodGet, path, inFn, fn, optFns)
+}
+
+// Head registers a handler for HEAD requests to the path, like Get.
+func Head[In, Out any](mux Mux, path string, inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) {
+	handleMethod(mux, http.MethodHead, path, inFn, fn, optFns)
+}
+
+// Post registers a handler for POST requests to the path, like Get.
+func Post[In, Out any](mux Mux, path string, inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) {
+	handleMethod(mux, http.MethodPost, path, inFn, fn, optFns)
+}
+
+// Put registers a handler for PUT requests to the path, like Get.
+func Put[In, Out any](mux Mux, path string, inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) {
+	handleMethod(mux, http.MethodPut, path, inFn, fn, optFns)
+}
+
+// Patch registers a handler for PATCH requests to the path, like Get.
+func Patch[In, Out any](mux Mux, path string, inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) {
+	handleMethod(mux, http.MethodPatch, path, inFn, fn, optFns)
+}
+
+// Delete registers a handler for DELETE requests to the path, like Get.
+func Delete[In, Out any](mux Mux, path string, inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) {
+	handleMethod(mux, http.MethodDelete, path, inFn, fn, optFns)
+}
+
+// Options registers a handler for OPTIONS requests to the path, like Get.
+func Options[In, Out any](mux Mux, path string, inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) {
+	handleMethod(mux, http.MethodOptions, path, inFn, fn, optFns)
+}
+
+// handleMethod validates the path and registers the handler for the method and path with HandleRoute.
+// A Router cannot validate the path itself, it would take a path without slash, like "poem/{id}", for a host.
+func handleMethod[In, Out any](mux Mux, method, path string, inFn CnIn[In], fn Exec[In, Out], optFns []HandleOptsFunc) {
+	if !strings.HasPrefix(path, "/") {
+		panic(fmt.Sprintf("gwu: %s %q: path must start with a slash", method, path))
+	}
+
+	HandleRoute(mux, method+" "+path, inFn, fn, optFns...)
+}
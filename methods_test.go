@@ -0,0 +1,59 @@
+package gwu_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+func TestMethodHelpers(t *testing.T) {
+	helpers := map[string]func(gwu.Mux, string){
+		http.MethodGet:     func(mux gwu.Mux, path string) { gwu.Get(mux, path, gwu.Empty(), noContent) },
+		http.MethodHead:    func(mux gwu.Mux, path string) { gwu.Head(mux, path, gwu.Empty(), noContent) },
+		http.MethodPost:    func(mux gwu.Mux, path string) { gwu.Post(mux, path, gwu.Empty(), noContent) },
+		http.MethodPut:     func(mux gwu.Mux, path string) { gwu.Put(mux, path, gwu.Empty(), noContent) },
+		http.MethodPatch:   func(mux gwu.Mux, path string) { gwu.Patch(mux, path, gwu.Empty(), noContent) },
+		http.MethodDelete:  func(mux gwu.Mux, path string) { gwu.Delete(mux, path, gwu.Empty(), noContent) },
+		http.MethodOptions: func(mux gwu.Mux, path string) { gwu.Options(mux, path, gwu.Empty(), noContent) },
+	}
+
+	for method, register := range helpers {
+		for name, mux := range map[string]gwu.Mux{"ServeMux": http.NewServeMux(), "Router": gwu.NewRouter()} {
+			register(mux, "/poem/{id}")
+
+			for other := range helpers {
+				rec := httptest.NewRecorder()
+				mux.(http.Handler).ServeHTTP(rec, httptest.NewRequest(other, "/poem/7", nil))
+
+				// A route for GET also matches HEAD requests.
+				want := http.StatusMethodNotAllowed
+				if other == method || method == http.MethodGet && other == http.MethodHead {
+					want = http.StatusNoContent
+				}
+
+				if rec.Code != want {
+					t.Errorf("%s %s: %s request: status %d, want %d", name, method, other, rec.Code, want)
+				}
+			}
+		}
+	}
+}
+
+// TestMethodHelpersInvalidPath panics with the method and path if the path does not start with a slash.
+func TestMethodHelpersInvalidPath(t *testing.T) {
+	for name, mux := range map[string]gwu.Mux{"ServeMux": http.NewServeMux(), "Router": gwu.NewRouter()} {
+		func() {
+			defer func() {
+				if v := recover(); v == nil || !strings.Contains(fmt.Sprint(v), `"poem/{id}"`) {
+					t.Errorf("%s: panic %v, want the path", name, v)
+				}
+			}()
+
+			gwu.Get(mux, "poem/{id}", gwu.Empty(), noContent)
+		}()
+	}
+}
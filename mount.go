@@ -0,0 +1,69 @@
+package gwu
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Mount registers the handler for all paths under the prefix with the mux, and strips the prefix from the path of
+// the requests before passing them to the handler. Unlike http.StripPrefix, Mount keeps the full path available,
+// retrieve it with FullPath. Handle logs the full path and the route including the prefix.
+//
+// If mux is a Router, the Router's prefix is stripped as well. Mount panics if the prefix does not start with a
+// slash.
+//
+// Example usage:
+//
+//	gwu.Mount(mux, "/api/poetry", rt) // GET /api/poetry/poem/{id} is served by the route GET /poem/{id} of rt
+func Mount(mux Mux, prefix string, handler http.Handler) {
+	if !strings.HasPrefix(prefix, "/") {
+		panic(fmt.Sprintf("gwu: mount prefix %q must start with a slash", prefix))
+	}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	strip := prefix
+	if rt, ok := mux.(*Router); ok {
+		strip = rt.prefix + prefix
+	}
+
+	mux.Handle(prefix+"/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, stripPrefix(r, strip))
+	}))
+}
+
+// Mount mounts the sub-router under the prefix, see Mount.
+func (rt *Router) Mount(prefix string, sub *Router) {
+	Mount(rt, prefix, sub)
+}
+
+type mountCtxKey struct{}
+
+// stripPrefix returns a shallow copy of r with the prefix stripped from the path, and the prefix added to the
+// stripped prefixes of the context.
+func stripPrefix(r *http.Request, prefix string) *http.Request {
+	r2 := r.WithContext(context.WithValue(r.Context(), mountCtxKey{}, mountPrefix(r.Context())+prefix))
+	u := *r.URL
+	u.Path = strings.TrimPrefix(u.Path, prefix)
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	u.RawPath = strings.TrimPrefix(u.RawPath, prefix)
+	r2.URL = &u
+
+	return r2
+}
+
+// mountPrefix returns the prefixes stripped from the path of the request of ctx.
+func mountPrefix(ctx context.Context) string {
+	prefix, _ := ctx.Value(mountCtxKey{}).(string)
+	return prefix
+}
+
+// FullPath returns the path of the request including the prefixes stripped by Mount and Versioned, use it to log
+// or to generate links.
+func FullPath(r *http.Request) string {
+	return mountPrefix(r.Context()) + r.URL.Path
+}
@@ -0,0 +1,86 @@
+package gwu_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// mountedPoem is the input of the route of a mounted Router: the path value and the full path.
+type mountedPoem struct {
+	ID, Path string
+}
+
+func mountedIn(r *http.Request, _ gwu.HandleOpts) (mountedPoem, error) {
+	return mountedPoem{ID: r.PathValue("id"), Path: gwu.FullPath(r)}, nil
+}
+
+func logMounted(_ context.Context, p mountedPoem, opts gwu.HandleOpts) (string, int, error) {
+	opts.Log.Info("loading poem", "id", p.ID)
+	return p.ID + " " + p.Path, http.StatusOK, nil
+}
+
+func TestMount(t *testing.T) {
+	log := gwutest.Logger()
+	poems := gwu.NewRouter(gwu.Log(log))
+	gwu.Get(poems, "/poem/{id}", mountedIn, logMounted)
+
+	rt := gwu.NewRouter()
+	rt.Group("/api").Mount("/poetry/", poems)
+
+	mux := http.NewServeMux()
+	gwu.Mount(mux, "/api/poetry", poems)
+
+	for name, h := range map[string]http.Handler{"Router": rt, "ServeMux": mux} {
+		log.Reset()
+		got, _ := gwutest.Do[any, string](t, h, http.MethodGet, "/api/poetry/poem/7", nil, gwutest.Status(http.StatusOK))
+		if got != "7 /api/poetry/poem/7" {
+			t.Errorf("%s: %q, want the path value and the full path", name, got)
+		}
+
+		// The logged route is the registered pattern with the prefix.
+		log.AssertLogged(t, slog.LevelInfo, "loading poem", "id", "7", "method", "GET", "route",
+			"/api/poetry/poem/{id}")
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/poetry/songs", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: unknown path of the mounted router: status %d, want 404", name, rec.Code)
+		}
+	}
+}
+
+// TestMountNested strips the prefixes of nested mounts and keeps them for the full path.
+func TestMountNested(t *testing.T) {
+	log := gwutest.Logger()
+	poems := gwu.NewRouter(gwu.Log(log))
+	gwu.Get(poems, "/poem/{id}", mountedIn, logMounted)
+
+	v1 := gwu.NewRouter()
+	v1.Mount("/poetry", poems)
+	rt := gwu.NewRouter()
+	rt.Mount("/api/v1", v1)
+
+	got, _ := gwutest.Do[any, string](t, rt, http.MethodGet, "/api/v1/poetry/poem/ode%2Fto", nil,
+		gwutest.Status(http.StatusOK))
+	if got != "ode/to /api/v1/poetry/poem/ode/to" {
+		t.Errorf("%q, want the decoded path value and the full path", got)
+	}
+
+	log.AssertLogged(t, slog.LevelInfo, "loading poem", "route", "/api/v1/poetry/poem/{id}")
+}
+
+func TestMountInvalidPrefix(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Mount did not panic")
+		}
+	}()
+
+	gwu.Mount(http.NewServeMux(), "api", gwu.NewRouter())
+}
@@ -0,0 +1,112 @@
+package gwu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// StatusItem is the result of an item of a batch in a MultiStatus.
+type StatusItem[Out any] struct {
+	// Index is the index of the item in the batch.
+	Index  int
+	Status int
+	// Data is the output of a successful item, it is not encoded for failed items.
+	Data Out
+	// Error is the error of a failed item, it is not encoded for successful items.
+	Error string
+}
+
+// failed reports whether the item failed, items with a 4xx or 5xx status failed.
+func (s StatusItem[Out]) failed() bool {
+	return s.Status >= http.StatusBadRequest
+}
+
+// MarshalJSON encodes the item with the data of a successful item, or the error of a failed item.
+func (s StatusItem[Out]) MarshalJSON() ([]byte, error) {
+	if s.failed() {
+		return json.Marshal(struct {
+			Index  int    `json:"index"`
+			Status int    `json:"status"`
+			Error  string `json:"error"`
+		}{s.Index, s.Status, s.Error})
+	}
+
+	return json.Marshal(struct {
+		Index  int `json:"index"`
+		Status int `json:"status"`
+		Data   Out `json:"data"`
+	}{s.Index, s.Status, s.Data})
+}
+
+// MultiStatus is an Out value with a status per item, for batch operations, see Batch. Handle sets the
+// X-Failed-Count header to the number of failed items, and responds with http.StatusOK if some, but not all items
+// failed, instead of the status code returned by the Exec.
+type MultiStatus[Out any] []StatusItem[Out]
+
+// failures returns the number of failed items.
+func (m MultiStatus[Out]) failures() (failed, total int) {
+	for _, item := range m {
+		if item.failed() {
+			failed++
+		}
+	}
+
+	return failed, len(m)
+}
+
+// multiStatus is implemented by all MultiStatus types.
+type multiStatus interface {
+	failures() (failed, total int)
+}
+
+// multiStatusCode sets the X-Failed-Count header of a MultiStatus and returns the status code of the response.
+func multiStatusCode(w http.ResponseWriter, ms multiStatus, code int) int {
+	failed, total := ms.failures()
+	w.Header().Set("X-Failed-Count", strconv.Itoa(failed))
+	if failed > 0 && failed < total {
+		return http.StatusOK
+	}
+
+	return code
+}
+
+// Batch Exec calls the given Exec for every item of the input and returns the results as MultiStatus. It responds
+// with http.StatusOK, unless all items failed, then it responds with the status code of the first item.
+// An error returned for an item is its Error, like any error of an Exec it must be safe to display to the client.
+// An item's error without status code gets its status code and message like with Handle, see RegisterError.
+//
+// Example usage:
+//
+//	gwu.Post(rt, "/poems/batch", gwu.JSON[[]Poem](), gwu.Batch(ctrl.Create))
+func Batch[In, Out any](fn Exec[In, Out]) Exec[[]In, MultiStatus[Out]] {
+	return func(ctx context.Context, in []In, opts HandleOpts) (MultiStatus[Out], int, error) {
+		res := make(MultiStatus[Out], len(in))
+		for i, item := range in {
+			out, code, err := fn(ctx, item, opts)
+			if err != nil && code == 0 {
+				code, err = execErrStatus(err)
+			}
+
+			res[i] = StatusItem[Out]{Index: i, Status: code, Data: out}
+			if code == 0 {
+				res[i].Status = http.StatusOK
+			}
+
+			if err != nil {
+				if code < http.StatusBadRequest {
+					res[i].Status = http.StatusInternalServerError
+				}
+
+				res[i].Error = err.Error()
+			}
+		}
+
+		if failed, total := res.failures(); total > 0 && failed == total {
+			return res, res[0].Status, nil
+		}
+
+		return res, http.StatusOK, nil
+	}
+}
@@ -0,0 +1,95 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// createTitled creates a poem with the title, it fails for empty and duplicate titles.
+func createTitled(_ context.Context, title string, _ gwu.HandleOpts) (smallPoem, int, error) {
+	switch title {
+	case "":
+		return smallPoem{}, http.StatusUnprocessableEntity, errors.New("title is required")
+	case "Ode":
+		return smallPoem{}, http.StatusConflict, errors.New("poem exists")
+	case "broken":
+		return smallPoem{}, http.StatusOK, errors.New("status without error")
+	case "unknown":
+		return smallPoem{}, 0, errors.New("database down")
+	}
+
+	return smallPoem{ID: int64(len(title)), Title: title}, http.StatusCreated, nil
+}
+
+func TestBatch(t *testing.T) {
+	h := gwu.Handle(gwu.JSON[[]string](), gwu.Batch(createTitled))
+
+	tests := []struct {
+		name, body string
+		status     int
+		failed     string
+		want       string
+	}{
+		{"mixed", `["Elegy", "", "Ode", "Sonnet"]`, http.StatusOK, "2", `[` +
+			`{"index":0,"status":201,"data":{"id":5,"title":"Elegy"}},` +
+			`{"index":1,"status":422,"error":"title is required"},` +
+			`{"index":2,"status":409,"error":"poem exists"},` +
+			`{"index":3,"status":201,"data":{"id":6,"title":"Sonnet"}}]`},
+		{"all succeed", `["Elegy"]`, http.StatusOK, "0", `[{"index":0,"status":201,"data":{"id":5,"title":"Elegy"}}]`},
+		{"all fail", `["Ode", ""]`, http.StatusConflict, "2", `[` +
+			`{"index":0,"status":409,"error":"poem exists"},` +
+			`{"index":1,"status":422,"error":"title is required"}]`},
+		{"error without failing status", `["broken", "Elegy"]`, http.StatusOK, "1", `[` +
+			`{"index":0,"status":500,"error":"status without error"},` +
+			`{"index":1,"status":201,"data":{"id":5,"title":"Elegy"}}]`},
+		{"error without status", `["unknown", "Elegy"]`, http.StatusOK, "1", `[` +
+			`{"index":0,"status":500,"error":"database down"},` +
+			`{"index":1,"status":201,"data":{"id":5,"title":"Elegy"}}]`},
+		{"empty", `[]`, http.StatusOK, "0", `[]`},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/poems/batch", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != tt.status || rec.Header().Get("X-Failed-Count") != tt.failed {
+			t.Errorf("%s: %d with X-Failed-Count %q, want %d with %q", tt.name, rec.Code,
+				rec.Header().Get("X-Failed-Count"), tt.status, tt.failed)
+		}
+
+		if got := strings.TrimSpace(rec.Body.String()); got != tt.want {
+			t.Errorf("%s: body\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestMultiStatus responds with 200 to a mixed MultiStatus of any Exec, and with its status code otherwise.
+func TestMultiStatus(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		items  gwu.MultiStatus[string]
+		status int
+	}{
+		{"mixed", gwu.MultiStatus[string]{{Index: 0, Status: 202, Data: "queued"}, {Index: 1, Status: 404}},
+			http.StatusOK},
+		{"all succeed", gwu.MultiStatus[string]{{Index: 0, Status: 202, Data: "queued"}}, http.StatusAccepted},
+	} {
+		exec := func(context.Context, any, gwu.HandleOpts) (gwu.MultiStatus[string], int, error) {
+			return tt.items, http.StatusAccepted, nil
+		}
+
+		rec := httptest.NewRecorder()
+		gwu.Handle(gwu.Empty(), exec).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs", nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package gwu
+
+import (
+	"context"
+	"net/http"
+)
+
+// NoBody is the output of Execs responding without body, see HandleNoOut. Handle writes only the status code of an
+// Exec returning NoBody, with the headers set before, but without body and Content-Type.
+type NoBody struct{}
+
+// ExecNoOut executes the endpoint logic of an action without response body, like deleting a resource, see
+// HandleNoOut. It returns the status code and error.
+//
+// Important: Return only safe to display errors, Handle writes an ExecNoOut function's error to the response.
+type ExecNoOut[In any] func(context.Context, In, HandleOpts) (int, error)
+
+// Exec adapts the ExecNoOut to an Exec returning NoBody, see HandleNoOut. Use it to register an ExecNoOut with
+// HandleRoute or the method helpers, like Delete.
+func (fn ExecNoOut[In]) Exec() Exec[In, NoBody] {
+	return func(ctx context.Context, in In, opts HandleOpts) (NoBody, int, error) {
+		code, err := fn(ctx, in, opts)
+		if err == nil && (code == 0 || code == http.StatusOK) {
+			code = http.StatusNoContent
+		}
+
+		return NoBody{}, code, err
+	}
+}
+
+// HandleNoOut works like Handle with an ExecNoOut, for endpoints without response body. On success, it writes only
+// the status code: http.StatusNoContent if the ExecNoOut returns http.StatusOK or 0, since a 200 without body is
+// a 204, and any other status code, like http.StatusAccepted, as is. Errors are written with the handler's
+// ErrorFunc, like with Handle.
+//
+// Example usage:
+//
+//	mux.Handle("POST /poem/{id}/publish", gwu.HandleNoOut(gwu.PathVal("id"), ctrl.Publish))
+func HandleNoOut[In any](inFn CnIn[In], fn ExecNoOut[In], optFns ...HandleOptsFunc) http.Handler {
+	return Handle(inFn, fn.Exec(), optFns...)
+}
+
+// Skip returns the zero Out and the status code for an Exec, and makes Handle write only the status code, without
+// body and Content-Type, like for NoBody. Use it for responses whose Out has no meaningful body, like a
+// http.StatusResetContent, or a http.StatusNotModified after comparing the ETag. Headers set with HandleOpts.Header,
+// like the ETag, are preserved. Responses with http.StatusNoContent and http.StatusNotModified never have a body, even
+// without Skip.
+//
+// Example usage:
+//
+//	func (c *Ctrl) ByID(ctx context.Context, id string, opts gwu.HandleOpts) (Poem, int, error) {
+//		poem := c.repo.Poem(ctx, id)
+//		opts.Header().Set("ETag", poem.ETag())
+//		if gwu.RequestFrom(ctx).Header.Get("If-None-Match") == poem.ETag() { // with gwu.ExposeRequest
+//			return gwu.Skip[Poem](opts, http.StatusNotModified)
+//		}
+//
+//		return poem, http.StatusOK, nil
+//	}
+func Skip[Out any](opts HandleOpts, code int) (Out, int, error) {
+	if opts.req.vals != nil {
+		opts.req.vals.noBody = true
+	}
+
+	var zero Out
+	return zero, code, nil
+}
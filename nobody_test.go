@@ -0,0 +1,103 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// assertNoBody fails the test if the response does not have the status, or has a body or Content-Type.
+func assertNoBody(t *testing.T, name string, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+
+	if rec.Code != status || rec.Body.Len() != 0 || rec.Header().Get("Content-Type") != "" {
+		t.Errorf("%s: %d with %d body bytes and Content-Type %q, want %d without body", name, rec.Code,
+			rec.Body.Len(), rec.Header().Get("Content-Type"), status)
+	}
+}
+
+func TestHandleNoOut(t *testing.T) {
+	tests := []struct {
+		name         string
+		code, status int
+	}{
+		{"zero", 0, http.StatusNoContent},
+		{"ok", http.StatusOK, http.StatusNoContent},
+		{"no content", http.StatusNoContent, http.StatusNoContent},
+		{"accepted", http.StatusAccepted, http.StatusAccepted},
+		{"reset content", http.StatusResetContent, http.StatusResetContent},
+	}
+
+	for _, tt := range tests {
+		publish := func(context.Context, string, gwu.HandleOpts) (int, error) { return tt.code, nil }
+
+		rec := httptest.NewRecorder()
+		gwu.HandleNoOut(gwu.PathVal("id"), publish).ServeHTTP(rec, httptest.NewRequest(http.MethodPost,
+			"/poem/7/publish", nil))
+		assertNoBody(t, tt.name, rec, tt.status)
+	}
+}
+
+// TestHandleNoOutError writes errors with the ErrorFunc like Handle.
+func TestHandleNoOutError(t *testing.T) {
+	remove := gwu.ExecNoOut[string](func(context.Context, string, gwu.HandleOpts) (int, error) {
+		return http.StatusNotFound, errPoemNotFound
+	})
+
+	// The adapted Exec registers an ExecNoOut with the method helpers.
+	rt := gwu.NewRouter()
+	gwu.Delete(rt, "/poem/{id}", gwu.PathVal("id"), remove.Exec())
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/poem/7", nil))
+	gwutest.AssertError(t, rec, http.StatusNotFound, errPoemNotFound.Error())
+}
+
+// TestSkip writes no body for an Out, but keeps the headers set by the Exec.
+func TestSkip(t *testing.T) {
+	for _, status := range []int{http.StatusNotModified, http.StatusResetContent, http.StatusOK} {
+		h := gwu.Handle(gwu.Empty(), func(_ context.Context, _ any, opts gwu.HandleOpts) (smallPoem, int, error) {
+			opts.Header().Set("ETag", `"v7"`)
+			return gwu.Skip[smallPoem](opts, status)
+		})
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/poem/7", nil))
+		assertNoBody(t, http.StatusText(status), rec, status)
+		if rec.Header().Get("ETag") != `"v7"` {
+			t.Errorf("%d: ETag %q, want the ETag of the Exec", status, rec.Header().Get("ETag"))
+		}
+	}
+}
+
+// TestSkipError still writes the error of an Exec using Skip.
+func TestSkipError(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(_ context.Context, _ any, opts gwu.HandleOpts) (smallPoem, int, error) {
+		_, _, _ = gwu.Skip[smallPoem](opts, http.StatusNotModified)
+		return smallPoem{}, http.StatusInternalServerError, errors.New("database down")
+	}, gwu.Log(gwutest.Logger()))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/poem/7", nil))
+	if rec.Code != http.StatusInternalServerError || rec.Body.Len() == 0 {
+		t.Errorf("%d with %q, want the error", rec.Code, rec.Body)
+	}
+}
+
+// TestStatusWithoutBody writes no body for 204 and 304 even if the Exec returns an Out.
+func TestStatusWithoutBody(t *testing.T) {
+	for _, status := range []int{http.StatusNoContent, http.StatusNotModified} {
+		h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (smallPoem, int, error) {
+			return smallPoem{ID: 7, Title: "Ode"}, status, nil
+		})
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/poem/7", nil))
+		assertNoBody(t, http.StatusText(status), rec, status)
+	}
+}
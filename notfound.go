@@ -0,0 +1,20 @@
+package gwu
+
+import "net/http"
+
+// NotFoundHandler returns an http.Handler responding with ErrNotFound and http.StatusNotFound, written with the
+// Errors option, defaults to TextError. It sets the headers of StaticHeaders and SecurityHeaders on the response, and
+// logs the path of the request with the Debug level.
+//
+// A Router responds with it to requests that match none of its routes, using the Router's options.
+func NotFoundHandler(optFns ...HandleOptsFunc) http.Handler {
+	opts := newHandleOpts(optFns)
+	log := orFallback(opts.Log)
+	errFn := opts.errFnOr(TextError)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Debug("no route matches request", "method", r.Method, "host", r.Host, "path", FullPath(r))
+		opts.setHeaders(w)
+		errFn(w, r, ErrNotFound, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,67 @@
+package gwu_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestNotFoundHandler(t *testing.T) {
+	log := gwutest.Logger()
+	rec := httptest.NewRecorder()
+	gwu.NotFoundHandler(gwu.Log(log)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/songs/7", nil))
+
+	// Like Handle, the errors are plain text by default.
+	gwutest.AssertError(t, rec, http.StatusNotFound, gwu.ErrNotFound.Error())
+	if ct := rec.Header().Get("Content-Type"); ct != gwu.ContentTypeText {
+		t.Errorf("Content-Type %q, want %q", ct, gwu.ContentTypeText)
+	}
+
+	log.AssertLogged(t, slog.LevelDebug, "no route matches request", "method", http.MethodGet, "path", "/songs/7")
+}
+
+// TestRouterNotFound responds to unmatched requests with the Errors of the Router, real routes, including one for /,
+// still win.
+func TestRouterNotFound(t *testing.T) {
+	rt := gwu.NewRouter(gwu.Errors(gwu.JSONError))
+	rt.Handle("GET /poems", textHandler("poems"))
+	rt.Handle("GET /{$}", textHandler("home"))
+
+	for target, want := range map[string]string{"/poems": "poems", "/": "home"} {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("GET %s: %d %q, want 200 %q", target, rec.Code, rec.Body, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/songs", nil))
+	gwutest.AssertError(t, rec, http.StatusNotFound, gwu.ErrNotFound.Error())
+	if ct := rec.Header().Get("Content-Type"); ct != gwu.ContentTypeJSON {
+		t.Errorf("Content-Type %q, want %q", ct, gwu.ContentTypeJSON)
+	}
+
+	// The 405 responses use the Errors of the Router too.
+	rec = httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/poems", nil))
+	gwutest.AssertError(t, rec, http.StatusMethodNotAllowed, gwu.ErrMethodNotAllowed.Error())
+	if ct := rec.Header().Get("Content-Type"); ct != gwu.ContentTypeJSON {
+		t.Errorf("405 Content-Type %q, want %q", ct, gwu.ContentTypeJSON)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gwu.MethodNotAllowed(http.MethodGet, http.MethodPut).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	gwutest.AssertError(t, rec, http.StatusMethodNotAllowed, gwu.ErrMethodNotAllowed.Error())
+	if ct, allow := rec.Header().Get("Content-Type"), rec.Header().Get("Allow"); ct != gwu.ContentTypeText ||
+		allow != "GET, PUT" {
+		t.Errorf("Content-Type %q and Allow %q, want plain text and GET, PUT", ct, allow)
+	}
+}
@@ -0,0 +1,100 @@
+package gwu
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Opt is an optional value of type T, it distinguishes an absent value from the zero value without a pointer, like
+// a query parameter ?limit=0 from no limit parameter at all. The zero Opt is absent, create a present one with Some.
+//
+// Bind sets Opt fields only if the parameter is present, and the JSON CnIn only if the field is present and not
+// null. Opt encodes an absent value as null, tag the field with omitzero to omit it, like with omitempty, the Spec
+// documents it as optional either way. The types Bind supports for Opt fields are the ones it supports for fields.
+//
+// Example usage:
+//
+//	type PoemQuery struct {
+//		Limit gwu.Opt[int] `query:"limit"`
+//	}
+//
+//	limit := q.Limit.Or(20)
+type Opt[T any] struct {
+	v  T
+	ok bool
+}
+
+// Some returns a present Opt with the value.
+func Some[T any](v T) Opt[T] {
+	return Opt[T]{v: v, ok: true}
+}
+
+// Get returns the value and whether it is present.
+func (o Opt[T]) Get() (T, bool) {
+	return o.v, o.ok
+}
+
+// Or returns the value if it is present, def otherwise.
+func (o Opt[T]) Or(def T) T {
+	if !o.ok {
+		return def
+	}
+
+	return o.v
+}
+
+// IsZero reports whether the value is absent, encoding/json omits absent values of fields tagged with omitzero.
+func (o Opt[T]) IsZero() bool {
+	return !o.ok
+}
+
+// MarshalJSON encodes the value, or null if it is absent.
+func (o Opt[T]) MarshalJSON() ([]byte, error) {
+	if !o.ok {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(o.v)
+}
+
+// UnmarshalJSON decodes a present value, null is absent.
+func (o *Opt[T]) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		*o = Opt[T]{}
+		return nil
+	}
+
+	var v T
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	*o = Some(v)
+	return nil
+}
+
+// optional is implemented by pointers to Opt, it lets Bind and the Spec handle Opt of any type.
+type optional interface {
+	optType() reflect.Type
+	setAny(v any)
+}
+
+func (o *Opt[T]) optType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (o *Opt[T]) setAny(v any) {
+	*o = Some(v.(T))
+}
+
+var optionalType = reflect.TypeFor[optional]()
+
+// optElem returns the type of the value of an Opt type, or nil if t is no Opt.
+func optElem(t reflect.Type) reflect.Type {
+	if t.Kind() != reflect.Struct || !reflect.PointerTo(t).Implements(optionalType) {
+		return nil
+	}
+
+	return reflect.New(t).Interface().(optional).optType()
+}
@@ -0,0 +1,119 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestOpt(t *testing.T) {
+	var absent gwu.Opt[int]
+	if v, ok := absent.Get(); v != 0 || ok || absent.Or(20) != 20 || !absent.IsZero() {
+		t.Errorf("absent Opt: %v, %v, Or %v, IsZero %v, want absent", v, ok, absent.Or(20), absent.IsZero())
+	}
+
+	zero := gwu.Some(0)
+	if v, ok := zero.Get(); v != 0 || !ok || zero.Or(20) != 0 || zero.IsZero() {
+		t.Errorf("Some(0): %v, %v, Or %v, IsZero %v, want present", v, ok, zero.Or(20), zero.IsZero())
+	}
+}
+
+type pageOut struct {
+	Limit  gwu.Opt[int]    `json:"limit"`
+	Cursor gwu.Opt[string] `json:"cursor,omitzero"`
+}
+
+func TestOptJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		page pageOut
+		want string
+	}{
+		{"absent", pageOut{}, `{"limit":null}`},
+		{"present zero", pageOut{Limit: gwu.Some(0), Cursor: gwu.Some("")}, `{"limit":0,"cursor":""}`},
+		{"present", pageOut{Limit: gwu.Some(20), Cursor: gwu.Some("c2")}, `{"limit":20,"cursor":"c2"}`},
+	}
+
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.page)
+		if err != nil || string(b) != tt.want {
+			t.Errorf("%s: %s, %v, want %s", tt.name, b, err, tt.want)
+		}
+
+		var got pageOut
+		if err := json.Unmarshal(b, &got); err != nil || got != tt.page {
+			t.Errorf("%s: decoded %+v, %v, want %+v", tt.name, got, err, tt.page)
+		}
+	}
+
+	var got pageOut
+	if err := json.Unmarshal([]byte(`{"limit": "ten"}`), &got); err == nil {
+		t.Errorf("decoded %+v, want an error for the invalid value", got)
+	}
+}
+
+type pageQuery struct {
+	Limit gwu.Opt[int]    `query:"limit"`
+	Since gwu.Opt[string] `header:"X-Since"`
+}
+
+func TestOptBind(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		since  []string
+		want   pageQuery
+	}{
+		{"absent", "/poems", nil, pageQuery{}},
+		{"present zero", "/poems?limit=0", []string{""}, pageQuery{Limit: gwu.Some(0), Since: gwu.Some("")}},
+		{"present", "/poems?limit=20", []string{"2026-10-14"}, pageQuery{Limit: gwu.Some(20),
+			Since: gwu.Some("2026-10-14")}},
+	}
+
+	opts := gwutest.Opts()
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		for _, v := range tt.since {
+			r.Header.Add("X-Since", v)
+		}
+
+		got, err := gwu.Bind[pageQuery]()(r, opts)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: %+v, %v, want %+v", tt.name, got, err, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	gwu.Handle(gwu.Bind[pageQuery](), noContent[pageQuery]).ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+		"/poems?limit=ten", nil))
+	gwutest.AssertError(t, rec, http.StatusBadRequest, gwu.ErrInvalidParam.Error())
+}
+
+// TestOptJSONBody decodes fields of a request body, a missing field and null are absent.
+func TestOptJSONBody(t *testing.T) {
+	h := gwu.Handle(gwu.JSON[pageOut](), func(_ context.Context, p pageOut, _ gwu.HandleOpts) (pageOut, int, error) {
+		return p, http.StatusOK, nil
+	})
+
+	for body, want := range map[string]string{
+		`{}`:                          `{"limit":null}`,
+		`{"limit": null}`:             `{"limit":null}`,
+		`{"limit": 0, "cursor": ""}`:  `{"limit":0,"cursor":""}`,
+		`{"limit": 7, "cursor": "c"}`: `{"limit":7,"cursor":"c"}`,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/poems", strings.NewReader(body))
+		r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if got := strings.TrimSpace(rec.Body.String()); rec.Code != http.StatusOK || got != want {
+			t.Errorf("%s: %d %s, want %s", body, rec.Code, got, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package gwu
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// optSpec describes an option for the validation of option combinations.
+type optSpec struct {
+	// set reports whether the option is in effect.
+	set func(o HandleOpts) bool
+	// conflicts lists the options that cannot be combined with the option, and why.
+	conflicts map[string]string
+}
+
+// optSpecs is the registry of options, keyed by the name of the option's function.
+var optSpecs = map[string]optSpec{
+	"Log":                   {set: func(o HandleOpts) bool { return o.Log != nil }},
+	"ExposeRequest":         {set: func(o HandleOpts) bool { return o.exposeRequest }},
+	"DecodeErrorStatus":     {set: func(o HandleOpts) bool { return o.decodeErrStatus != 0 }},
+	"ValidationErrorStatus": {set: func(o HandleOpts) bool { return o.valErrStatus != 0 }},
+	"WarnSlow":              {set: func(o HandleOpts) bool { return o.slowThreshold > 0 }},
+	"LongLived":             {set: func(o HandleOpts) bool { return o.longLived }},
+	"LogBodies": {
+		set: func(o HandleOpts) bool { return o.bodyLogMax > 0 },
+		conflicts: map[string]string{
+			"LongLived":  "long-lived handlers stream their bodies, which LogBodies cannot capture",
+			"SampleLogs": "SampleLogs drops the debug records of LogBodies for requests that are not sampled",
+		},
+	},
+	"SampleLogs":              {set: func(o HandleOpts) bool { return o.sampler != nil }},
+	"WithClock":               {set: func(o HandleOpts) bool { return o.clock != nil }},
+	"ErrorLog":                {set: func(o HandleOpts) bool { return o.errLog != nil }},
+	"ErrorLogOnly":            {set: func(o HandleOpts) bool { return o.errLogOnly }},
+	"TraceContext":            {set: func(o HandleOpts) bool { return o.traceContext }},
+	"CORS":                    {set: func(o HandleOpts) bool { return o.cors != nil }},
+	"Errors":                  {set: func(o HandleOpts) bool { return o.errFn != nil }},
+	"Collect":                 {set: func(o HandleOpts) bool { return o.spec != nil }},
+	"Before":                  {set: func(o HandleOpts) bool { return len(o.before) > 0 }},
+	"After":                   {set: func(o HandleOpts) bool { return len(o.after) > 0 }},
+	"SPA":                     {set: func(o HandleOpts) bool { return o.spa != "" }},
+	"RedirectTrailingSlash":   {set: func(o HandleOpts) bool { return o.slash == slashRedirect }},
+	"StripTrailingSlash":      {set: func(o HandleOpts) bool { return o.slash == slashStrip }},
+	"StaticHeaders":           {set: func(o HandleOpts) bool { return len(o.headers) > 0 }},
+	"SecurityHeaders":         {set: func(o HandleOpts) bool { return o.securityHeaders }},
+	"MaxRequestBytes":         {set: func(o HandleOpts) bool { return o.bodyMax > 0 }},
+	"BodyReadTimeout":         {set: func(o HandleOpts) bool { return o.bodyTimeout > 0 }},
+	"CollectRouteErrors":      {set: func(o HandleOpts) bool { return o.collectRouteErrs }},
+	"WithJSONCodec":           {set: func(o HandleOpts) bool { return o.jsonCodec != nil }},
+	"WriteTimeout":            {set: func(o HandleOpts) bool { return o.writeTimeout != 0 }},
+	"Observe":                 {set: func(o HandleOpts) bool { return o.observer != nil }},
+	"ShedAbove":               {set: func(o HandleOpts) bool { return o.shedAbove > 0 }},
+	"ResponseDigest":          {set: func(o HandleOpts) bool { return o.respDigest != "" }},
+	"VerifyDigest":            {set: func(o HandleOpts) bool { return o.reqDigests != nil }},
+	"GoneAfterSunset":         {set: func(o HandleOpts) bool { return o.goneAfterSunset }},
+	"CreatedOnPost":           {set: func(o HandleOpts) bool { return o.createdOnPost }},
+	"Example":                 {set: func(o HandleOpts) bool { return o.example != nil }},
+	"TenantOpts":              {set: func(o HandleOpts) bool { return o.tenantFn != nil }},
+	"VersionedOut":            {set: func(o HandleOpts) bool { return o.outTransform != nil }},
+	"TranslateFields":         {set: func(o HandleOpts) bool { return o.translate != nil }},
+	"EnumCompat":              {set: func(o HandleOpts) bool { return o.enumCompat != nil }},
+	"HonorClientTimeout":      {set: func(o HandleOpts) bool { return o.clientTimeout != nil }},
+	"FieldNaming":             {set: func(o HandleOpts) bool { return o.fieldNaming != nil }},
+	"MaxMessageBytes":         {set: func(o HandleOpts) bool { return o.wsMaxMsg != 0 }},
+	"PingInterval":            {set: func(o HandleOpts) bool { return o.wsPing != 0 }},
+	"ThrottleClientErrorLogs": {set: func(o HandleOpts) bool { return o.logThrottle != nil }},
+}
+
+// invalid records an invalid option value, validate reports it.
+func (o *HandleOpts) invalid(format string, args ...any) {
+	o.errs = append(o.errs, fmt.Errorf(format, args...))
+}
+
+// optNames returns the names of all options in effect, sorted.
+func (o HandleOpts) optNames() []string {
+	var names []string
+	for name, spec := range optSpecs {
+		if spec.set(o) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// validate returns all invalid option values and conflicting options in effect.
+// An option applied more than once is no conflict, the last application overrides the previous ones.
+func (o HandleOpts) validate() error {
+	errs := append([]error(nil), o.errs...)
+
+	names := o.optNames()
+	for _, name := range names {
+		conflicts := optSpecs[name].conflicts
+		for _, other := range names {
+			if reason, ok := conflicts[other]; ok {
+				errs = append(errs, fmt.Errorf("%s conflicts with %s: %s", name, other, reason))
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("gwu: invalid options: %w", errors.Join(errs...))
+}
+
+// validStatus reports whether code is an error status code.
+func validStatus(code int) bool {
+	return code >= http.StatusBadRequest && code <= 599
+}
@@ -0,0 +1,67 @@
+package gwu_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// TestOptionConflicts combines every pair of conflicting options, in both orders.
+func TestOptionConflicts(t *testing.T) {
+	tests := []struct {
+		name        string
+		opt, other  gwu.HandleOptsFunc
+		want        string
+		unconflicts gwu.HandleOptsFunc
+	}{
+		{"LogBodies and LongLived", gwu.LogBodies(1024, nil), gwu.LongLived(),
+			"LogBodies conflicts with LongLived: ", gwu.LogBodies(0, nil)},
+		{"LogBodies and SampleLogs", gwu.LogBodies(1024, nil), gwu.SampleLogs(0.1),
+			"LogBodies conflicts with SampleLogs: ", gwu.LogBodies(0, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, opts := range [][]gwu.HandleOptsFunc{{tt.opt, tt.other}, {tt.other, tt.opt}} {
+				_, err := gwu.TryHandle(gwu.Empty(), noContent[any], opts...)
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("TryHandle error %v, want %q", err, tt.want)
+				}
+
+				func() {
+					defer func() {
+						if msg := fmt.Sprint(recover()); !strings.Contains(msg, tt.want) {
+							t.Errorf("Handle panicked with %q, want %q", msg, tt.want)
+						}
+					}()
+
+					gwu.Handle(gwu.Empty(), noContent[any], opts...)
+				}()
+			}
+
+			// Each option is valid on its own, and a later option turning the conflicting one off resolves it.
+			for _, opts := range [][]gwu.HandleOptsFunc{{tt.opt}, {tt.other}, {tt.opt, tt.other, tt.unconflicts}} {
+				if _, err := gwu.TryHandle(gwu.Empty(), noContent[any], opts...); err != nil {
+					t.Errorf("TryHandle: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestOptionConflictsJoined reports all conflicts and invalid values of a handler at once.
+func TestOptionConflictsJoined(t *testing.T) {
+	_, err := gwu.TryHandle(gwu.Empty(), noContent[any], gwu.LogBodies(1024, nil), gwu.LongLived(),
+		gwu.SampleLogs(0.1), gwu.VerifyDigest("md5"))
+	if err == nil {
+		t.Fatal("TryHandle accepted the conflicting options")
+	}
+
+	for _, want := range []string{"LogBodies conflicts with LongLived", "LogBodies conflicts with SampleLogs", "md5"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q, want %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package gwu
+
+import "net/http"
+
+// Raw is an Out value that takes over the response completely.
+// If an Exec returns a non-nil Raw, Handle skips its status and encoding logic, including its error handling, and
+// calls the Raw function with the real http.ResponseWriter and *http.Request instead. Handle writes nothing itself.
+//
+// Use Raw for responses gwu cannot express, like reverse proxying or handing off a connection upgrade.
+// Note that the CnIn still runs before the Exec and may already have consumed the request body.
+//
+// Example usage:
+//
+//	func (c *Controller) Proxy(_ context.Context, _ any, _ gwu.HandleOpts) (gwu.Raw, int, error) {
+//		return gwu.Raw(c.proxy.ServeHTTP), 0, nil
+//	}
+type Raw func(w http.ResponseWriter, r *http.Request)
@@ -0,0 +1,84 @@
+package gwu_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// TestRawHijack takes over the connection with a Raw, the client receives the raw response byte for byte.
+func TestRawHijack(t *testing.T) {
+	var in bool
+	cnIn := func(*http.Request, gwu.HandleOpts) (any, error) {
+		in = true
+		return nil, nil
+	}
+
+	exec := func(context.Context, any, gwu.HandleOpts) (gwu.Raw, int, error) {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			conn, buf, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				t.Errorf("hijack: %v", err)
+				return
+			}
+			defer conn.Close()
+
+			_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: text/x-poem\r\nContent-Length: 3\r\n\r\nOde")
+			_ = buf.Flush()
+		}, http.StatusInternalServerError, errors.New("ignored with a Raw")
+	}
+
+	srv := httptest.NewServer(gwu.Handle(cnIn, exec, gwu.SecurityHeaders(gwu.SecurityConfig{})))
+	t.Cleanup(srv.Close)
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, _ = io.WriteString(conn, "GET /poem HTTP/1.1\r\nHost: poems\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	want := http.Header{"Content-Type": {"text/x-poem"}, "Content-Length": {"3"}}
+	if resp.StatusCode != http.StatusOK || !reflect.DeepEqual(resp.Header, want) || string(body) != "Ode" {
+		t.Errorf("response %d %v %q, want the raw response only", resp.StatusCode, resp.Header, body)
+	}
+
+	if !in {
+		t.Error("the CnIn did not run")
+	}
+}
+
+// TestRawIgnoresError writes the Raw's response instead of the error the Exec returned with it.
+func TestRawIgnoresError(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.Raw, int, error) {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = io.WriteString(w, "id,title\n7,Ode\n")
+		}, http.StatusBadGateway, errors.New("upstream failed")
+	}, gwu.Errors(gwu.JSONError))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/poems.csv", nil))
+
+	if rec.Code != http.StatusAccepted || rec.Header().Get("Content-Type") != "text/csv" ||
+		!strings.HasPrefix(rec.Body.String(), "id,title") || strings.Contains(rec.Body.String(), "upstream") {
+		t.Errorf("response %d %s %q, want the Raw's response", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+}
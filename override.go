@@ -0,0 +1,55 @@
+package gwu
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// MethodOverride returns middleware serving POST requests with the X-HTTP-Method-Override header as requests with
+// the method of the header, for clients that can only send GET and POST. It only overrides the allowed methods and
+// responds to others with ErrMethodNotAllowed as TextError. Requests with another method than POST are never
+// overridden.
+//
+// The original method is stored in the request's context, retrieve it with OriginalMethod. Handle adds it to the
+// handler's logger as the original_method attribute.
+//
+// Example usage:
+//
+//	srv := &http.Server{Handler: gwu.MethodOverride(http.MethodPut, http.MethodDelete)(rt)}
+func MethodOverride(allowed ...string) func(http.Handler) http.Handler {
+	methods := make([]string, len(allowed))
+	for i, m := range allowed {
+		methods[i] = strings.ToUpper(m)
+	}
+
+	notAllowed := &methodNotAllowed{errFn: TextError, allow: methods}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method := strings.ToUpper(strings.TrimSpace(r.Header.Get("X-HTTP-Method-Override")))
+			if r.Method != http.MethodPost || method == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !slices.Contains(methods, method) {
+				notAllowed.ServeHTTP(w, r)
+				return
+			}
+
+			r2 := r.WithContext(context.WithValue(r.Context(), methodCtxKey{}, r.Method))
+			r2.Method = method
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
+
+type methodCtxKey struct{}
+
+// OriginalMethod returns the method of a request overridden by MethodOverride.
+func OriginalMethod(ctx context.Context) (string, bool) {
+	m, ok := ctx.Value(methodCtxKey{}).(string)
+	return m, ok
+}
@@ -0,0 +1,95 @@
+package gwu_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestMethodOverride(t *testing.T) {
+	var deleted string
+	deletePoem := func(ctx context.Context, id string, opts gwu.HandleOpts) (gwu.NoBody, int, error) {
+		opts.Log.Info("deleting poem", "id", id)
+		deleted = id
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	}
+
+	log := gwutest.Logger()
+	rt := gwu.NewRouter(gwu.Log(log))
+	gwu.Delete(rt, "/poem/{id}", gwu.PathVal("id"), deletePoem)
+	gwu.Post(rt, "/poems", gwu.Empty(), noContent)
+	h := gwu.MethodOverride("delete", http.MethodPatch)(rt)
+
+	tests := []struct {
+		name, method, path, override string
+		status                       int
+		deleted                      string
+	}{
+		{"overridden", http.MethodPost, "/poem/7", "DELETE", http.StatusNoContent, "7"},
+		{"lower case", http.MethodPost, "/poem/8", " delete ", http.StatusNoContent, "8"},
+		{"DELETE", http.MethodDelete, "/poem/9", "", http.StatusNoContent, "9"},
+		{"GET is not overridden", http.MethodGet, "/poem/7", "DELETE", http.StatusMethodNotAllowed, ""},
+		{"PUT is not overridden", http.MethodPut, "/poem/7", "DELETE", http.StatusMethodNotAllowed, ""},
+		{"disallowed method", http.MethodPost, "/poem/7", "PUT", http.StatusMethodNotAllowed, ""},
+		{"POST without override", http.MethodPost, "/poems", "", http.StatusNoContent, ""},
+		{"allowed method without route", http.MethodPost, "/poems", "PATCH", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted = ""
+			log.Reset()
+
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.override != "" {
+				r.Header.Set("X-HTTP-Method-Override", tt.override)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			if rec.Code != tt.status || deleted != tt.deleted {
+				t.Fatalf("%d, deleted %q, want %d, deleted %q", rec.Code, deleted, tt.status, tt.deleted)
+			}
+
+			if tt.deleted == "" {
+				return
+			}
+
+			if tt.override == "" {
+				if entries := log.Filter(slog.LevelInfo); len(entries) != 1 || entries[0].Attrs["original_method"] != nil {
+					t.Errorf("logged %v, want no original method", entries)
+				}
+
+				return
+			}
+
+			log.AssertLogged(t, slog.LevelInfo, "deleting poem", "id", tt.deleted, "method", "DELETE",
+				"original_method", "POST")
+		})
+	}
+}
+
+// TestMethodOverrideDisallowed responds with ErrMethodNotAllowed and the allowed methods.
+func TestMethodOverrideDisallowed(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/poem/7", nil)
+	r.Header.Set("X-HTTP-Method-Override", "PUT")
+
+	rec := httptest.NewRecorder()
+	gwu.MethodOverride(http.MethodDelete)(textHandler("served")).ServeHTTP(rec, r)
+
+	gwutest.AssertError(t, rec, http.StatusMethodNotAllowed, gwu.ErrMethodNotAllowed.Error())
+	if got := rec.Header().Get("Allow"); got != "DELETE" {
+		t.Errorf("Allow %q, want DELETE", got)
+	}
+}
+
+func TestOriginalMethod(t *testing.T) {
+	if m, ok := gwu.OriginalMethod(context.Background()); ok {
+		t.Errorf("OriginalMethod %q without override", m)
+	}
+}
@@ -0,0 +1,109 @@
+package gwu
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// paramError is an ErrMissingParam or ErrInvalidParam of a named parameter. The CnIns create it once per parameter
+// and return it for every failing request, the message is only formatted when the error is rendered.
+type paramError struct {
+	err  error
+	name string
+}
+
+func (e *paramError) Error() string {
+	return e.err.Error() + " " + strconv.Quote(e.name)
+}
+
+func (e *paramError) Unwrap() error {
+	return e.err
+}
+
+// PathInt CnIn reads the path value with the given key as base 10 integer. It responds to a missing path value
+// with ErrMissingParam and to a value that is no integer with ErrInvalidParam, both with the DecodeErrorStatus.
+//
+// PathInt does not allocate, use it for the common GET /poem/{id} endpoint.
+func PathInt(key string) CnIn[int64] {
+	missing, invalid := &paramError{err: ErrMissingParam, name: key}, &paramError{err: ErrInvalidParam, name: key}
+	return func(r *http.Request, opts HandleOpts) (int64, error) {
+		v := r.PathValue(key)
+		if v == "" {
+			opts.noWildcard(key)
+			return 0, missing
+		}
+
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, invalid
+		}
+
+		return n, nil
+	}
+}
+
+// QueryVal CnIn reads the first value of the query parameter with the given key, or "" if there is none, like
+// url.Values.Get. It scans the raw query instead of parsing it, so it only allocates for escaped values.
+func QueryVal(key string) CnIn[string] {
+	return func(r *http.Request, _ HandleOpts) (string, error) {
+		v, _ := queryValue(r.URL.RawQuery, key)
+		return v, nil
+	}
+}
+
+// QueryInt CnIn reads the first value of the query parameter with the given key as base 10 integer, like QueryVal.
+// It returns def if the parameter is missing, and responds to a value that is no integer with ErrInvalidParam and
+// the DecodeErrorStatus.
+func QueryInt(key string, def int64) CnIn[int64] {
+	invalid := &paramError{err: ErrInvalidParam, name: key}
+	return func(r *http.Request, _ HandleOpts) (int64, error) {
+		v, ok := queryValue(r.URL.RawQuery, key)
+		if !ok {
+			return def, nil
+		}
+
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, invalid
+		}
+
+		return n, nil
+	}
+}
+
+// queryValue returns the first value of the key in the raw query, like url.ParseQuery followed by url.Values.Get.
+// Pairs that url.ParseQuery rejects are skipped, escaped keys and values are unescaped.
+func queryValue(query, key string) (string, bool) {
+	for query != "" {
+		var pair string
+		pair, query, _ = strings.Cut(query, "&")
+		if pair == "" || strings.Contains(pair, ";") {
+			continue
+		}
+
+		k, v, _ := strings.Cut(pair, "=")
+		if strings.ContainsAny(k, "%+") {
+			var err error
+			if k, err = url.QueryUnescape(k); err != nil {
+				continue
+			}
+		}
+
+		if k != key {
+			continue
+		}
+
+		if strings.ContainsAny(v, "%+") {
+			var err error
+			if v, err = url.QueryUnescape(v); err != nil {
+				continue
+			}
+		}
+
+		return v, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,49 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// paramRequest returns a request for /poem/7?page=2&q=ode with the path value id.
+func paramRequest() *http.Request {
+	return gwutest.Request(http.MethodGet, "GET /poem/{id}", "/poem/7?page=2&q=ode", nil)
+}
+
+// noContent is an Exec responding without body.
+func noContent[In any](context.Context, In, gwu.HandleOpts) (gwu.NoBody, int, error) {
+	return gwu.NoBody{}, http.StatusNoContent, nil
+}
+
+func TestParamAllocs(t *testing.T) {
+	r := paramRequest()
+	base := allocsPerRequest(gwu.Handle(gwu.Empty(), noContent[any]), r)
+	tests := []struct {
+		name string
+		h    http.Handler
+	}{
+		{"PathInt", gwu.Handle(gwu.PathInt("id"), noContent[int64])},
+		{"QueryVal", gwu.Handle(gwu.QueryVal("q"), noContent[string])},
+		{"QueryInt", gwu.Handle(gwu.QueryInt("page", 1), noContent[int64])},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allocsPerRequest(tt.h, r); got > base {
+				t.Errorf("%v allocs per request, want the %v of Empty", got, base)
+			}
+		})
+	}
+}
+
+func BenchmarkPathInt(b *testing.B) {
+	gwutest.Bench(b, gwu.Handle(gwu.PathInt("id"), noContent[int64]), paramRequest())
+}
+
+func BenchmarkQueryInt(b *testing.B) {
+	gwutest.Bench(b, gwu.Handle(gwu.QueryInt("page", 1), noContent[int64]), paramRequest())
+}
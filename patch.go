@@ -0,0 +1,584 @@
+package gwu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPatch is the error of malformed merge patches and JSON Patch documents, see MergePatch and JSONPatch.
+	// Is safe to display to the client.
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrPatchTestFailed is the error of a JSON Patch whose test operation failed, see ApplyPatch.
+	// Is safe to display to the client.
+	ErrPatchTestFailed = errors.New("patch test failed")
+	// ErrPatchNotApplicable is the error of a well-formed patch that cannot be applied to the resource, like a JSON
+	// Patch removing a missing member or a merge patch resulting in an invalid resource.
+	// Is safe to display to the client.
+	ErrPatchNotApplicable = errors.New("patch cannot be applied to the resource")
+)
+
+// MergePatch CnIn applies the JSON merge patch of the request body, see RFC 7396 and the media type
+// application/merge-patch+json, onto the current resource and returns the patched resource. The load function
+// fetches the current resource, its error is returned as is and must be safe to display to the client.
+//
+// Members of the patch replace the members of the resource, objects merge recursively, and null removes a member.
+// MergePatch encodes and decodes the resource with the handler's JSONCodec, so the patched resource is validated
+// by decoding it, like with JSON. It responds to a malformed patch with ErrInvalidPatch and
+// http.StatusBadRequest, and to a patch resulting in a resource that does not decode with ErrPatchNotApplicable
+// and http.StatusUnprocessableEntity.
+//
+// Example usage:
+//
+//	load := func(ctx context.Context, r *http.Request) (Poem, error) { return repo.Poem(ctx, r.PathValue("id")) }
+//	gwu.Patch(rt, "/poems/{id}", gwu.MergePatch(load), ctrl.Update)
+func MergePatch[T any](load func(ctx context.Context, r *http.Request) (T, error)) CnIn[T] {
+	return func(r *http.Request, opts HandleOpts) (T, error) {
+		var out T
+		var patch any
+		if err := decodeJSONValue(r.Body, &patch); err != nil {
+			return out, WithStatus(http.StatusBadRequest, ErrInvalidPatch)
+		}
+
+		cur, err := load(r.Context(), r)
+		if err != nil {
+			return out, err
+		}
+
+		codec := opts.JSONCodec()
+		b, err := codec.Marshal(cur)
+		if err != nil {
+			return out, fmt.Errorf("gwu: merge patch: encode resource: %w", err)
+		}
+
+		var doc any
+		if err := decodeJSONValue(bytes.NewReader(b), &doc); err != nil {
+			return out, fmt.Errorf("gwu: merge patch: decode resource: %w", err)
+		}
+
+		if b, err = json.Marshal(mergePatch(doc, patch)); err != nil {
+			return out, fmt.Errorf("gwu: merge patch: encode patched resource: %w", err)
+		}
+
+		if err := codec.Unmarshal(b, &out); err != nil {
+			return out, WithStatus(http.StatusUnprocessableEntity, ErrPatchNotApplicable)
+		}
+
+		return out, nil
+	}
+}
+
+// mergePatch applies the merge patch onto the decoded JSON value, see RFC 7396 section 2.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any, len(p))
+	}
+
+	for name, v := range p {
+		if v == nil {
+			delete(t, name)
+			continue
+		}
+
+		t[name] = mergePatch(t[name], v)
+	}
+
+	return t
+}
+
+// decodeJSONValue decodes a single JSON value, keeping numbers as json.Number so they round-trip exactly.
+func decodeJSONValue(r io.Reader, v *any) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+
+	if dec.More() {
+		return errors.New("unexpected data after the JSON value")
+	}
+
+	return nil
+}
+
+// PatchOp is an operation of a JSON Patch document, see RFC 6902 and JSONPatch.
+type PatchOp struct {
+	// Op is one of add, remove, replace, move, copy, and test.
+	Op string `json:"op"`
+	// Path is the JSON pointer of the target, like /lines/0.
+	Path string `json:"path"`
+	// From is the JSON pointer of the source of move and copy.
+	From string `json:"from,omitempty"`
+	// Value is the value of add, replace, and test, null is a value.
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch CnIn decodes the JSON Patch document of the request body, see RFC 6902 and the media type
+// application/json-patch+json, and validates its operations: the op, the JSON pointers, the presence of the value
+// or from member, and that no member is repeated. Apply the operations to the resource with ApplyPatch.
+//
+// JSONPatch responds to a malformed document with ErrInvalidPatch and http.StatusBadRequest, the error names the
+// invalid operation.
+//
+// Example usage:
+//
+//	gwu.Patch(rt, "/poems/{id}", gwu.Join(gwu.PathVal("id"), gwu.JSONPatch()), ctrl.Patch)
+//
+//	func (c *Ctrl) Patch(ctx context.Context, in gwu.Pair[string, []gwu.PatchOp], _ gwu.HandleOpts) (Poem, int, error) {
+//		cur, err := c.repo.Poem(ctx, in.First)
+//		...
+//		poem, err := gwu.ApplyPatch(cur, in.Second)
+//		var statusErr *gwu.StatusError
+//		if errors.As(err, &statusErr) {
+//			return Poem{}, statusErr.Status, statusErr.Err
+//		}
+//		...
+//	}
+func JSONPatch() CnIn[[]PatchOp] {
+	return func(r *http.Request, _ HandleOpts) ([]PatchOp, error) {
+		var raws []json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raws); err != nil {
+			return nil, WithStatus(http.StatusBadRequest, ErrInvalidPatch)
+		}
+
+		ops := make([]PatchOp, len(raws))
+		for i, raw := range raws {
+			if err := json.Unmarshal(raw, &ops[i]); err != nil {
+				return nil, WithStatus(http.StatusBadRequest, fmt.Errorf("%w: operation %d", ErrInvalidPatch, i))
+			}
+
+			err := ops[i].validate()
+			if name, ok := duplicateMember(raw); ok {
+				err = fmt.Errorf("duplicate member %q", name)
+			}
+
+			if err != nil {
+				return nil, WithStatus(http.StatusBadRequest, fmt.Errorf("%w: %s (operation %d)", ErrInvalidPatch, err, i))
+			}
+		}
+
+		return ops, nil
+	}
+}
+
+// duplicateMember returns the first member name repeated in the JSON object, like the op of RFC 6902 appendix A.13.
+func duplicateMember(raw json.RawMessage) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return "", false
+	}
+
+	seen := make(map[string]struct{})
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", false
+		}
+
+		name, _ := tok.(string)
+		if _, ok := seen[name]; ok {
+			return name, true
+		}
+
+		seen[name] = struct{}{}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return "", false
+		}
+	}
+
+	return "", false
+}
+
+// validate returns the problem of a malformed operation, see RFC 6902 section 4.
+func (op PatchOp) validate() error {
+	if _, err := parsePointer(op.Path); err != nil {
+		return fmt.Errorf("path: %w", err)
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return fmt.Errorf("%s without value", op.Op)
+		}
+	case "move", "copy":
+		if _, err := parsePointer(op.From); err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+
+		if op.Op == "move" && strings.HasPrefix(op.Path, op.From+"/") {
+			return errors.New("move into its own child")
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+
+	return nil
+}
+
+// ApplyPatch applies the operations of a JSON Patch document to the JSON encoding of doc, in order, and decodes the
+// result into a new T. It applies all operations or none, doc is not modified.
+//
+// The errors are StatusErrors safe to display to the client: ErrPatchTestFailed with http.StatusConflict if a test
+// operation fails, ErrPatchNotApplicable with http.StatusUnprocessableEntity if an operation targets a missing
+// location or the result does not decode into T, and ErrInvalidPatch with http.StatusBadRequest for operations not
+// validated by JSONPatch. Errors encoding doc are returned unwrapped.
+func ApplyPatch[T any](doc T, ops []PatchOp) (T, error) {
+	var out T
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return out, fmt.Errorf("gwu: apply patch: encode resource: %w", err)
+	}
+
+	var v any
+	if err := decodeJSONValue(bytes.NewReader(b), &v); err != nil {
+		return out, fmt.Errorf("gwu: apply patch: decode resource: %w", err)
+	}
+
+	for i, op := range ops {
+		if v, err = op.apply(v); err != nil {
+			return out, WithStatus(patchStatus(err), fmt.Errorf("%w (operation %d)", err, i))
+		}
+	}
+
+	if b, err = json.Marshal(v); err != nil {
+		return out, fmt.Errorf("gwu: apply patch: encode patched resource: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &out); err != nil {
+		return out, WithStatus(http.StatusUnprocessableEntity, ErrPatchNotApplicable)
+	}
+
+	return out, nil
+}
+
+// apply applies the operation to the decoded JSON value and returns the result.
+func (op PatchOp) apply(doc any) (any, error) {
+	if err := op.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+	}
+
+	path, _ := parsePointer(op.Path)
+	var value any
+	if op.Value != nil {
+		if err := decodeJSONValue(bytes.NewReader(op.Value), &value); err != nil {
+			return nil, fmt.Errorf("%w: value: %s", ErrInvalidPatch, err)
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return patchAt(doc, path, addMember(value))
+	case "remove":
+		return patchAt(doc, path, removeMember)
+	case "replace":
+		return patchAt(doc, path, replaceMember(value))
+	case "move", "copy":
+		from, _ := parsePointer(op.From)
+		v, ok := lookupPointer(doc, from)
+		if !ok {
+			return nil, notApplicable("from %q does not exist", op.From)
+		}
+
+		if op.Op == "move" {
+			var err error
+			if doc, err = patchAt(doc, from, removeMember); err != nil {
+				return nil, err
+			}
+		} else {
+			v = copyJSONValue(v)
+		}
+
+		return patchAt(doc, path, addMember(v))
+	default: // test
+		v, ok := lookupPointer(doc, path)
+		if !ok || !equalJSONValues(v, value) {
+			return nil, fmt.Errorf("%w: path %q", ErrPatchTestFailed, op.Path)
+		}
+
+		return doc, nil
+	}
+}
+
+// notApplicable returns ErrPatchNotApplicable with the detail.
+func notApplicable(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrPatchNotApplicable, fmt.Sprintf(format, args...))
+}
+
+// patchStatus returns the status code of an error applying a JSON Patch operation.
+func patchStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrPatchTestFailed):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidPatch):
+		return http.StatusBadRequest
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// memberFunc changes the member with the reference token in the container, an object or an array, and returns the
+// changed container.
+type memberFunc func(container any, token string) (any, error)
+
+// patchAt runs fn for the last reference token of the JSON pointer in its container, and returns the changed doc.
+// An empty pointer, the whole doc, is changed as member "" of a container holding only the doc.
+func patchAt(doc any, path []string, fn memberFunc) (any, error) {
+	if len(path) == 0 {
+		root, err := fn(map[string]any{"": doc}, "")
+		if err != nil {
+			return nil, err
+		}
+
+		return root.(map[string]any)[""], nil
+	}
+
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, ok := lookupMember(doc, path[0])
+	if !ok {
+		return nil, notApplicable("%q does not exist", formatPointer(path[:1]))
+	}
+
+	child, err := patchAt(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c := doc.(type) {
+	case map[string]any:
+		c[path[0]] = child
+	case []any:
+		i, _ := arrayIndex(path[0], len(c))
+		c[i] = child
+	}
+
+	return doc, nil
+}
+
+// addMember adds the value, see RFC 6902 section 4.1.
+func addMember(value any) memberFunc {
+	return func(container any, token string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			c[token] = value
+			return c, nil
+		case []any:
+			if token == "-" {
+				return append(c, value), nil
+			}
+
+			i, ok := arrayIndex(token, len(c)+1)
+			if !ok {
+				return nil, notApplicable("index %q is out of range", token)
+			}
+
+			return append(c[:i], append([]any{value}, c[i:]...)...), nil
+		default:
+			return nil, notApplicable("%q is no object or array", token)
+		}
+	}
+}
+
+// removeMember removes the member, see RFC 6902 section 4.2.
+func removeMember(container any, token string) (any, error) {
+	switch c := container.(type) {
+	case map[string]any:
+		if _, ok := c[token]; !ok {
+			return nil, notApplicable("member %q does not exist", token)
+		}
+
+		delete(c, token)
+		return c, nil
+	case []any:
+		i, ok := arrayIndex(token, len(c))
+		if !ok {
+			return nil, notApplicable("index %q is out of range", token)
+		}
+
+		return append(c[:i], c[i+1:]...), nil
+	default:
+		return nil, notApplicable("%q is no object or array", token)
+	}
+}
+
+// replaceMember replaces the existing member, see RFC 6902 section 4.3.
+func replaceMember(value any) memberFunc {
+	return func(container any, token string) (any, error) {
+		if _, ok := lookupMember(container, token); !ok {
+			return nil, notApplicable("%q does not exist", token)
+		}
+
+		switch c := container.(type) {
+		case map[string]any:
+			c[token] = value
+		case []any:
+			i, _ := arrayIndex(token, len(c))
+			c[i] = value
+		}
+
+		return container, nil
+	}
+}
+
+// parsePointer parses a JSON pointer into its unescaped reference tokens, see RFC 6901.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+
+	if p[0] != '/' {
+		return nil, fmt.Errorf("JSON pointer %q does not start with /", p)
+	}
+
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		if strings.Contains(strings.NewReplacer("~0", "", "~1", "").Replace(t), "~") {
+			return nil, fmt.Errorf("JSON pointer %q has an invalid escape", p)
+		}
+
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+
+	return tokens, nil
+}
+
+// formatPointer returns the JSON pointer of the reference tokens.
+func formatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString("/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(t))
+	}
+
+	return b.String()
+}
+
+// lookupPointer returns the value at the reference tokens of a JSON pointer.
+func lookupPointer(doc any, path []string) (any, bool) {
+	for _, t := range path {
+		var ok bool
+		if doc, ok = lookupMember(doc, t); !ok {
+			return nil, false
+		}
+	}
+
+	return doc, true
+}
+
+// lookupMember returns the member of an object or array with the reference token.
+func lookupMember(container any, token string) (any, bool) {
+	switch c := container.(type) {
+	case map[string]any:
+		v, ok := c[token]
+		return v, ok
+	case []any:
+		i, ok := arrayIndex(token, len(c))
+		if !ok {
+			return nil, false
+		}
+
+		return c[i], true
+	default:
+		return nil, false
+	}
+}
+
+// arrayIndex parses the reference token as array index below n, without leading zeros.
+func arrayIndex(token string, n int) (int, bool) {
+	if token == "" || len(token) > 1 && token[0] == '0' {
+		return 0, false
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= n || token[0] == '+' {
+		return 0, false
+	}
+
+	return i, true
+}
+
+// copyJSONValue returns a deep copy of a decoded JSON value.
+func copyJSONValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for name, e := range v {
+			c[name] = copyJSONValue(e)
+		}
+
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, e := range v {
+			c[i] = copyJSONValue(e)
+		}
+
+		return c
+	default:
+		return v
+	}
+}
+
+// equalJSONValues reports whether two decoded JSON values are equal, see RFC 6902 section 4.6. Numbers are equal
+// if their values are.
+func equalJSONValues(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for name, e := range a {
+			if f, ok := b[name]; !ok || !equalJSONValues(e, f) {
+				return false
+			}
+		}
+
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for i := range a {
+			if !equalJSONValues(a[i], b[i]) {
+				return false
+			}
+		}
+
+		return true
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		if a == b {
+			return true
+		}
+
+		fa, errA := a.Float64()
+		fb, errB := b.Float64()
+		return errA == nil && errB == nil && fa == fb
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,222 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// TestMergePatchRFC7396 runs the examples of RFC 7396 section 3 and appendix A.
+func TestMergePatchRFC7396(t *testing.T) {
+	tests := []struct {
+		name, target, patch, want string
+	}{
+		{
+			"section 3",
+			`{"title": "Goodbye!", "author": {"givenName": "John", "familyName": "Doe"}, "tags": ["example", "sample"],
+				"content": "This will be unchanged"}`,
+			`{"title": "Hello!", "phoneNumber": "+01-123-456-7890", "author": {"familyName": null}, "tags": ["example"]}`,
+			`{"title": "Hello!", "author": {"givenName": "John"}, "tags": ["example"], "content": "This will be unchanged",
+				"phoneNumber": "+01-123-456-7890"}`,
+		},
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"remove member", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"remove one of two members", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"replace array with string", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"replace string with array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"merge nested object", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"replace array of objects", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"replace array", `["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{"replace object with array", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"replace with null", `{"a":"foo"}`, `null`, `null`},
+		{"replace with string", `{"a":"foo"}`, `"bar"`, `"bar"`},
+		{"keep null member", `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{"replace array with object", `[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{"remove missing nested member", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			load := func(context.Context, *http.Request) (any, error) {
+				var v any
+				err := json.Unmarshal([]byte(tt.target), &v)
+
+				return v, err
+			}
+
+			r := gwutest.Request(http.MethodPatch, "PATCH /doc", "/doc", tt.patch)
+			got, err := gwutest.RunCnIn(t, gwu.MergePatch(load), r)
+			if err != nil {
+				t.Fatalf("MergePatch: %v", err)
+			}
+
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+// TestJSONPatchRFC6902 runs the examples of RFC 6902 appendix A, decoding the documents with JSONPatch and applying
+// them with ApplyPatch. A wantErr is the error the example expects.
+func TestJSONPatchRFC6902(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, want string
+		wantErr                error
+		wantStatus             int
+	}{
+		{
+			name:  "A.1 adding an object member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:  `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:  "A.2 adding an array element",
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:  "A.3 removing an object member",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+			want:  `{"foo": "bar"}`,
+		},
+		{
+			name:  "A.4 removing an array element",
+			doc:   `{"foo": ["bar", "qux", "baz"]}`,
+			patch: `[{"op": "remove", "path": "/foo/1"}]`,
+			want:  `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:  "A.5 replacing a value",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			want:  `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:  "A.6 moving a value",
+			doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:  "A.7 moving an array element",
+			doc:   `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			want:  `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name: "A.8 testing a value: success",
+			doc:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch: `[{"op": "test", "path": "/baz", "value": "qux"},
+				{"op": "test", "path": "/foo/1", "value": 2}]`,
+			want: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:       "A.9 testing a value: error",
+			doc:        `{"baz": "qux"}`,
+			patch:      `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			wantErr:    gwu.ErrPatchTestFailed,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:  "A.10 adding a nested member object",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			want:  `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:  "A.11 ignoring unrecognized elements",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			want:  `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:       "A.12 adding to a nonexistent target",
+			doc:        `{"foo": "bar"}`,
+			patch:      `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			wantErr:    gwu.ErrPatchNotApplicable,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "A.13 invalid JSON Patch document",
+			doc:        `{"foo": "bar"}`,
+			patch:      `[{"op": "add", "path": "/baz", "value": "qux", "op": "remove"}]`,
+			wantErr:    gwu.ErrInvalidPatch,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "A.14 ~ escape ordering",
+			doc:   `{"/": 9, "~1": 10}`,
+			patch: `[{"op": "test", "path": "/~01", "value": 10}]`,
+			want:  `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:       "A.15 comparing strings and numbers",
+			doc:        `{"/": 9, "~1": 10}`,
+			patch:      `[{"op": "test", "path": "/~01", "value": "10"}]`,
+			wantErr:    gwu.ErrPatchTestFailed,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:  "A.16 adding an array value",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			want:  `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc any
+			_ = json.Unmarshal([]byte(tt.doc), &doc)
+
+			r := gwutest.Request(http.MethodPatch, "PATCH /doc", "/doc", tt.patch)
+			ops, err := gwutest.RunCnIn(t, gwu.JSONPatch(), r)
+			if err == nil {
+				doc, err = gwu.ApplyPatch(doc, ops)
+			}
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("patch: %v", err)
+				}
+
+				assertJSON(t, doc, tt.want)
+				return
+			}
+
+			var statusErr *gwu.StatusError
+			if !errors.Is(err, tt.wantErr) || !errors.As(err, &statusErr) || statusErr.Status != tt.wantStatus {
+				t.Errorf("error %v, want %v with status %d", err, tt.wantErr, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// assertJSON fails the test if the JSON encoding of got is not the JSON value want.
+func assertJSON(t *testing.T, got any, want string) {
+	t.Helper()
+
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("encoding %v: %v", got, err)
+	}
+
+	var g, w any
+	_ = json.Unmarshal(b, &g)
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("decoding want %s: %v", want, err)
+	}
+
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
@@ -0,0 +1,48 @@
+package gwu
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidPath is the error of a path value that is malformed or tries to traverse upward with "..".
+// Is safe to display to the client.
+var ErrInvalidPath = errors.New("invalid path")
+
+// PathRest CnIn reads the remainder matched by a {key...} wildcard, cleaned of empty and "." segments and without
+// leading or trailing slash, e.g. "a/b" for /files/a/b/ and the pattern /files/{key...}. An empty remainder is "".
+//
+// The remainder is decoded, like every path value, so an encoded slash %2F separates segments and %2E%2E is "..".
+// PathRest rejects remainders with ".." segments with ErrInvalidPath and http.StatusBadRequest.
+func PathRest(key string) CnIn[string] {
+	segs := PathSegments(key)
+	return func(r *http.Request, opts HandleOpts) (string, error) {
+		s, err := segs(r, opts)
+		return strings.Join(s, "/"), err
+	}
+}
+
+// PathSegments CnIn reads the remainder matched by a {key...} wildcard split into its segments, like PathRest.
+// An empty remainder has no segments.
+func PathSegments(key string) CnIn[[]string] {
+	return func(r *http.Request, opts HandleOpts) ([]string, error) {
+		v := r.PathValue(key)
+		if v == "" {
+			opts.noWildcard(key)
+		}
+
+		var segs []string
+		for _, seg := range strings.Split(v, "/") {
+			switch seg {
+			case "", ".":
+			case "..":
+				return nil, WithStatus(http.StatusBadRequest, ErrInvalidPath)
+			default:
+				segs = append(segs, seg)
+			}
+		}
+
+		return segs, nil
+	}
+}
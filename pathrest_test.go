@@ -0,0 +1,88 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestPathRest(t *testing.T) {
+	echo := func(_ context.Context, rest string, _ gwu.HandleOpts) (string, int, error) {
+		return rest, http.StatusOK, nil
+	}
+
+	mux := http.NewServeMux()
+	gwu.HandleRoute(mux, "GET /files/{path...}", gwu.PathRest("path"), echo, gwu.Errors(gwu.TextError))
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+		want   string
+	}{
+		{"segments", "/files/odes/keats.txt", http.StatusOK, `"odes/keats.txt"`},
+		{"trailing slash", "/files/odes/", http.StatusOK, `"odes"`},
+		{"empty", "/files/", http.StatusOK, `""`},
+		{"encoded slash", "/files/odes%2Fkeats.txt", http.StatusOK, `"odes/keats.txt"`},
+		{"encoded space", "/files/to%20autumn.txt", http.StatusOK, `"to autumn.txt"`},
+		{"encoded dot-dot", "/files/odes/%2E%2E%2F%2E%2E%2Fetc", http.StatusBadRequest, gwu.ErrInvalidPath.Error()},
+		{"encoded slash before dot-dot", "/files/odes%2F..%2Fpasswd", http.StatusBadRequest,
+			gwu.ErrInvalidPath.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.status || strings.TrimSpace(rec.Body.String()) != tt.want {
+				t.Errorf("%d %q, want %d %q", rec.Code, rec.Body, tt.status, tt.want)
+			}
+		})
+	}
+}
+
+// TestPathRestDotDot rejects ".." segments anywhere in the remainder, also where the mux did not clean the path.
+func TestPathRestDotDot(t *testing.T) {
+	for _, v := range []string{"..", "../etc/passwd", "odes/../../etc", "odes/.."} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetPathValue("path", v)
+
+		rest, err := gwu.PathRest("path")(r, gwutest.Opts())
+		var statusErr *gwu.StatusError
+		if rest != "" || !errors.As(err, &statusErr) || statusErr.Status != http.StatusBadRequest ||
+			!errors.Is(err, gwu.ErrInvalidPath) {
+			t.Errorf("%q: %q, %v, want ErrInvalidPath with 400", v, rest, err)
+		}
+	}
+
+	// A dot-dot inside a name is no traversal.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetPathValue("path", "odes/..keats")
+	if rest, err := gwu.PathRest("path")(r, gwutest.Opts()); rest != "odes/..keats" || err != nil {
+		t.Errorf("%q, %v, want odes/..keats", rest, err)
+	}
+}
+
+func TestPathSegments(t *testing.T) {
+	for v, want := range map[string][]string{
+		"":                nil,
+		"/":               nil,
+		"a/b/c":           {"a", "b", "c"},
+		"/a//./b/":        {"a", "b"},
+		"poems/to autumn": {"poems", "to autumn"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetPathValue("path", v)
+
+		got, err := gwu.PathSegments("path")(r, gwutest.Opts())
+		if err != nil || strings.Join(got, "|") != strings.Join(want, "|") || (want == nil) != (got == nil) {
+			t.Errorf("%q: %q, %v, want %q", v, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package gwu
+
+import "time"
+
+// PhaseObserver is an Observer receiving the durations of the phases of every request, to tell whether a slow route
+// spends its time reading the body, in the Exec, or writing the response. Observe sets it like any Observer.
+type PhaseObserver interface {
+	Observer
+	// Phases reports the phases of a finished request of the route.
+	Phases(route string, p Phases)
+}
+
+// Phases are the durations of the phases of a request, see PhaseObserver. Phases a request did not reach, like the
+// Exec of a request whose input failed to decode, are zero.
+type Phases struct {
+	// Decode is the CnIn, including reading the request body.
+	Decode time.Duration
+	// Exec is the Exec.
+	Exec time.Duration
+	// Encode is encoding the output as JSON, including VersionedOut and EnumCompat.
+	Encode time.Duration
+	// Write is writing the response, including error responses, streams, and files, which are encoded while written.
+	Write time.Duration
+	// Total is the time from entering the handler until the response is written. It also covers the Before and After
+	// hooks, so it is at least the sum of the phases.
+	Total time.Duration
+}
+
+// Attrs returns the phases in milliseconds as key-value pairs, decode_ms, exec_ms, encode_ms, write_ms, and
+// total_ms, for log attributes or span events.
+//
+// Example usage:
+//
+//	func (o obs) Phases(route string, p gwu.Phases) { o.log.Info("request phases", p.Attrs()...) }
+func (p Phases) Attrs() []any {
+	return []any{
+		"decode_ms", ms(p.Decode), "exec_ms", ms(p.Exec), "encode_ms", ms(p.Encode), "write_ms", ms(p.Write),
+		"total_ms", ms(p.Total),
+	}
+}
+
+// ms returns d in milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// phase is a phase of a request, see Phases.
+type phase int
+
+const (
+	phaseDecode phase = iota
+	phaseExec
+	phaseEncode
+	phaseWrite
+	// phaseNone is the time outside the phases, like the Before hooks.
+	phaseNone
+)
+
+// phaseTimer measures the phases of a request, see PhaseObserver. Its methods do nothing on a nil phaseTimer, so
+// Handle calls them unconditionally.
+type phaseTimer struct {
+	clock       Clock
+	start, last time.Time
+	cur         phase
+	d           [phaseNone + 1]time.Duration
+}
+
+// startPhases returns the phaseTimer of the request if the handler has a PhaseObserver, nil otherwise.
+func (o HandleOpts) startPhases() *phaseTimer {
+	if _, ok := o.observer.(PhaseObserver); !ok || o.req.vals == nil {
+		return nil
+	}
+
+	clock := o.Clock()
+	now := clock.Now()
+	t := &o.req.vals.phases
+	*t = phaseTimer{clock: clock, start: now, last: now, cur: phaseNone}
+
+	return t
+}
+
+// enter ends the current phase and starts p.
+func (t *phaseTimer) enter(p phase) {
+	if t == nil {
+		return
+	}
+
+	now := t.clock.Now()
+	t.d[t.cur] += now.Sub(t.last)
+	t.last, t.cur = now, p
+}
+
+// finishPhases ends the current phase and reports the phases to the handler's PhaseObserver.
+func (o HandleOpts) finishPhases(t *phaseTimer) {
+	t.enter(phaseNone)
+	route := ""
+	if o.inFlight != nil {
+		route = o.inFlight.route
+	}
+
+	o.observer.(PhaseObserver).Phases(route, Phases{
+		Decode: t.d[phaseDecode],
+		Exec:   t.d[phaseExec],
+		Encode: t.d[phaseEncode],
+		Write:  t.d[phaseWrite],
+		Total:  t.last.Sub(t.start),
+	})
+}
@@ -0,0 +1,179 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+)
+
+// phaseLog is a PhaseObserver recording the phases of the requests.
+type phaseLog struct {
+	*gauge
+	mu     sync.Mutex
+	routes []string
+	phases []gwu.Phases
+}
+
+func newPhaseLog() *phaseLog {
+	return &phaseLog{gauge: &gauge{routes: make(map[string]bool)}}
+}
+
+func (l *phaseLog) Phases(route string, p gwu.Phases) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.routes = append(l.routes, route)
+	l.phases = append(l.phases, p)
+}
+
+// last returns the phases of the last request.
+func (l *phaseLog) last(t *testing.T) gwu.Phases {
+	t.Helper()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.phases) == 0 {
+		t.Fatal("no phases reported")
+	}
+
+	return l.phases[len(l.phases)-1]
+}
+
+// clockedPoem advances the clock while it is encoded.
+type clockedPoem struct {
+	clock *gwu.ManualClock
+}
+
+func (p clockedPoem) MarshalJSON() ([]byte, error) {
+	p.clock.Advance(3 * time.Millisecond)
+	return []byte(`{"title":"Ode"}`), nil
+}
+
+// clockedWriter advances the clock with every write.
+type clockedWriter struct {
+	*httptest.ResponseRecorder
+	clock *gwu.ManualClock
+}
+
+func (w clockedWriter) Write(b []byte) (int, error) {
+	w.clock.Advance(4 * time.Millisecond)
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestPhases(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	advance := func(d time.Duration) { clock.Advance(d) }
+
+	in := func(r *http.Request, _ gwu.HandleOpts) (string, error) {
+		advance(5 * time.Millisecond)
+		if id := r.PathValue("id"); id != "bad" {
+			return id, nil
+		}
+
+		return "", gwu.WithStatus(http.StatusBadRequest, errors.New("invalid id"))
+	}
+
+	exec := func(_ context.Context, id string, _ gwu.HandleOpts) (clockedPoem, int, error) {
+		advance(50 * time.Millisecond)
+		if id == "missing" {
+			return clockedPoem{}, http.StatusNotFound, errPoemNotFound
+		}
+
+		return clockedPoem{clock: clock}, http.StatusOK, nil
+	}
+
+	obs := newPhaseLog()
+	rt := gwu.NewRouter(gwu.Observe(obs), gwu.WithClock(clock), gwu.Before(func(*http.Request, gwu.HandleOpts) error {
+		advance(2 * time.Millisecond)
+		return nil
+	}))
+	gwu.Get(rt, "/poems/{id}", in, exec)
+
+	// Phases a request did not reach are zero, the Before hook counts toward the total only.
+	tests := []struct {
+		name string
+		id   string
+		want gwu.Phases
+	}{
+		{"success", "7", gwu.Phases{Decode: 5 * time.Millisecond, Exec: 50 * time.Millisecond,
+			Encode: 3 * time.Millisecond, Write: 4 * time.Millisecond, Total: 64 * time.Millisecond}},
+		{"exec error", "missing", gwu.Phases{Decode: 5 * time.Millisecond, Exec: 50 * time.Millisecond,
+			Write: 4 * time.Millisecond, Total: 61 * time.Millisecond}},
+		{"decode error", "bad", gwu.Phases{Decode: 5 * time.Millisecond, Write: 4 * time.Millisecond,
+			Total: 11 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		rt.ServeHTTP(clockedWriter{httptest.NewRecorder(), clock}, httptest.NewRequest(http.MethodGet,
+			"/poems/"+tt.id, nil))
+		if got := obs.last(t); got != tt.want {
+			t.Errorf("%s: %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if obs.routes[0] != "GET /poems/{id}" {
+		t.Errorf("route %q, want the pattern", obs.routes[0])
+	}
+}
+
+// TestPhasesSum sleeps in the Exec, the phases sum up to about the total with the real clock.
+func TestPhasesSum(t *testing.T) {
+	obs := newPhaseLog()
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (smallPoem, int, error) {
+		time.Sleep(20 * time.Millisecond)
+		return smallPoem{ID: 7, Title: "Ode"}, http.StatusOK, nil
+	}, gwu.Observe(obs))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/poems/7", nil))
+
+	p := obs.last(t)
+	sum := p.Decode + p.Exec + p.Encode + p.Write
+	if p.Exec < 20*time.Millisecond || sum > p.Total || p.Total-sum > 5*time.Millisecond {
+		t.Errorf("%+v, want an Exec of at least 20ms and phases summing up to about the total", p)
+	}
+}
+
+func TestPhasesAttrs(t *testing.T) {
+	p := gwu.Phases{Decode: 1500 * time.Microsecond, Exec: 20 * time.Millisecond, Write: time.Millisecond,
+		Total: 23 * time.Millisecond}
+	want := []any{"decode_ms", 1.5, "exec_ms", 20.0, "encode_ms", 0.0, "write_ms", 1.0, "total_ms", 23.0}
+
+	got := p.Attrs()
+	if len(got) != len(want) {
+		t.Fatalf("%v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%v, want %v", got, want)
+			break
+		}
+	}
+}
+
+// discardPhases is a PhaseObserver ignoring the phases, it does not allocate.
+type discardPhases struct {
+	*gauge
+}
+
+func (discardPhases) Phases(string, gwu.Phases) {}
+
+// TestPhasesAllocs observes the phases without allocating.
+func TestPhasesAllocs(t *testing.T) {
+	skipAllocsWithRace(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	without := allocsPerRequest(gwu.Handle(gwu.Empty(), getSmallPoem), r)
+	obs := discardPhases{&gauge{routes: make(map[string]bool)}}
+	with := allocsPerRequest(gwu.Handle(gwu.Empty(), getSmallPoem, gwu.Observe(obs)), r)
+	if with > without {
+		t.Errorf("%v allocs with a PhaseObserver, want the %v without", with, without)
+	}
+}
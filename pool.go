@@ -0,0 +1,83 @@
+package gwu
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which encode buffers are not returned to the pool, so that a single large
+// response does not pin its memory.
+const maxPooledBuffer = 256 << 10
+
+// jsonBuffer is a buffer with an encoder writing to it, pooled to reuse both across responses.
+type jsonBuffer struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+var jsonBuffers = sync.Pool{New: func() any {
+	b := &jsonBuffer{}
+	b.enc = json.NewEncoder(&b.buf)
+	return b
+}}
+
+// getJSONBuffer returns an empty buffer from the pool, return it with putJSONBuffer.
+func getJSONBuffer() *jsonBuffer {
+	return jsonBuffers.Get().(*jsonBuffer)
+}
+
+// putJSONBuffer returns the buffer to the pool, its bytes must not be used afterward.
+func putJSONBuffer(b *jsonBuffer) {
+	if b.buf.Cap() > maxPooledBuffer {
+		return
+	}
+
+	b.buf.Reset()
+	jsonBuffers.Put(b)
+}
+
+// readChunkSize is the size of the chunks readBody reads request bodies into.
+const readChunkSize = 32 << 10
+
+var readChunks = sync.Pool{New: func() any {
+	b := make([]byte, readChunkSize)
+	return &b
+}}
+
+// readBody reads r to EOF like io.ReadAll, but reads into pooled chunks and copies them into a single slice of the
+// exact size. This allocates once per body instead of growing a slice, and the chunks are reused across requests.
+func readBody(r io.Reader) ([]byte, error) {
+	var buf [8]*[]byte
+	chunks := buf[:0]
+	defer func() {
+		for _, c := range chunks {
+			readChunks.Put(c)
+		}
+	}()
+
+	n := 0
+	for {
+		c := readChunks.Get().(*[]byte)
+		chunks = append(chunks, c)
+
+		m, err := io.ReadFull(r, *c)
+		n += m
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b := make([]byte, n)
+	for i, c := range chunks {
+		copy(b[i*readChunkSize:], *c)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,221 @@
+package gwu_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// discardWriter is a reusable http.ResponseWriter discarding the response, so only the encoding is measured.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) WriteHeader(int)             {}
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+
+// unpooledJSON writes the data like IntoJSON, encoded into a new buffer, the baseline of the pooled buffers.
+func unpooledJSON(w http.ResponseWriter, data any, statusCode int) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(data); err != nil {
+		http.Error(w, gwu.ErrEncodeResponse.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", gwu.ContentTypeJSON)
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// jsonSizes are the sizes of the encoded responses of BenchmarkWriteJSON and TestWriteJSONAllocs.
+var jsonSizes = []struct {
+	name string
+	data map[string]string
+}{
+	{"1KiB", map[string]string{"text": strings.Repeat("a", 1<<10)}},
+	{"64KiB", map[string]string{"text": strings.Repeat("a", 64<<10)}},
+}
+
+func BenchmarkWriteJSON(b *testing.B) {
+	w := &discardWriter{header: make(http.Header)}
+	for _, s := range jsonSizes {
+		b.Run(s.name+"/pooled", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				gwu.IntoJSON(w, nil, s.data, http.StatusOK)
+			}
+		})
+
+		b.Run(s.name+"/unpooled", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				unpooledJSON(w, s.data, http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestWriteJSONAllocs(t *testing.T) {
+	skipAllocsWithRace(t)
+	w := &discardWriter{header: make(http.Header)}
+	var small float64
+	for i, s := range jsonSizes {
+		pooled := testing.AllocsPerRun(100, func() { gwu.IntoJSON(w, nil, s.data, http.StatusOK) })
+		unpooled := testing.AllocsPerRun(100, func() { unpooledJSON(w, s.data, http.StatusOK) })
+		if pooled >= unpooled {
+			t.Errorf("%s: %v allocs with pooled buffers, want fewer than the %v without", s.name, pooled, unpooled)
+		}
+
+		if i == 0 {
+			small = pooled
+		} else if pooled > small {
+			t.Errorf("%s: %v allocs, want no more than the %v of %s", s.name, pooled, small, jsonSizes[0].name)
+		}
+	}
+}
+
+// rawSizes are the sizes of the bodies of BenchmarkRawBody and TestRawBodyAllocs.
+var rawSizes = []struct {
+	name string
+	size int
+}{
+	{"64KiB", 64 << 10},
+	{"1MiB", 1 << 20},
+}
+
+// rawBody returns a body of n bytes, every byte depends on its offset and the seed.
+func rawBody(n int, seed byte) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i) ^ seed
+	}
+
+	return b
+}
+
+// readAllBody reads the body like RawBody with io.ReadAll, the baseline of the pooled chunks.
+func readAllBody(r *http.Request, _ gwu.HandleOpts) ([]byte, error) {
+	return io.ReadAll(r.Body)
+}
+
+func TestRawBody(t *testing.T) {
+	opts := gwutest.Opts()
+	for _, n := range []int{0, 1, 32<<10 - 1, 32 << 10, 32<<10 + 1, 8 * 32 << 10, 8*32<<10 + 1, 1 << 20} {
+		want := rawBody(n, 7)
+
+		// Reading one byte at a time fills the chunks with short reads.
+		for name, body := range map[string]io.Reader{
+			"reader":   bytes.NewReader(want),
+			"one byte": iotest.OneByteReader(bytes.NewReader(want)),
+		} {
+			got, err := gwu.RawBody()(httptest.NewRequest(http.MethodPost, "/hook", body), opts)
+			if err != nil || len(got) != n || cap(got) != n || !bytes.Equal(got, want) {
+				t.Errorf("%d bytes from the %s: %d bytes with capacity %d, %v, want the body", n, name, len(got),
+					cap(got), err)
+			}
+		}
+	}
+}
+
+func TestRawBodyError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/hook", iotest.TimeoutReader(bytes.NewReader(rawBody(64<<10, 0))))
+	if _, err := gwu.RawBody()(r, gwutest.Opts()); !errors.Is(err, gwu.ErrDecodeRequest) {
+		t.Errorf("error %v, want ErrDecodeRequest", err)
+	}
+}
+
+// TestRawBodyConcurrent reads distinct bodies from concurrent requests and retains them, reusing the chunks must not
+// change the bodies of earlier requests. Run it with -race.
+func TestRawBodyConcurrent(t *testing.T) {
+	h := gwu.Handle(gwu.RawBody(), func(_ context.Context, b []byte, _ gwu.HandleOpts) ([]byte, int, error) {
+		return b, http.StatusOK, nil
+	})
+
+	const requests = 16
+	retained := make([][]byte, requests)
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 4; j++ {
+				want := rawBody(40<<10+i, byte(i))
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(want)))
+				var got []byte
+				if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || !bytes.Equal(got, want) {
+					t.Errorf("request %d: body changed while the handler ran", i)
+					return
+				}
+
+				retained[i], _ = gwu.RawBody()(httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(want)),
+					gwutest.Opts())
+			}
+		}()
+	}
+
+	wg.Wait()
+	for i, b := range retained {
+		if !bytes.Equal(b, rawBody(40<<10+i, byte(i))) {
+			t.Errorf("request %d: retained body changed after the request", i)
+		}
+	}
+}
+
+func BenchmarkRawBody(b *testing.B) {
+	opts := gwutest.Opts()
+	for _, s := range rawSizes {
+		body := rawBody(s.size, 0)
+		for name, in := range map[string]gwu.CnIn[[]byte]{"pooled": gwu.RawBody(), "ReadAll": readAllBody} {
+			b.Run(s.name+"/"+name, func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(s.size))
+
+				r := httptest.NewRequest(http.MethodPost, "/hook", nil)
+				rd := bytes.NewReader(body)
+				r.Body = io.NopCloser(rd)
+				for i := 0; i < b.N; i++ {
+					rd.Reset(body)
+					if _, err := in(r, opts); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRawBodyAllocs(t *testing.T) {
+	skipAllocsWithRace(t)
+	opts := gwutest.Opts()
+	for _, s := range rawSizes {
+		body := rawBody(s.size, 0)
+		r := httptest.NewRequest(http.MethodPost, "/hook", nil)
+		rd := bytes.NewReader(body)
+		r.Body = io.NopCloser(rd)
+
+		allocs := func(in gwu.CnIn[[]byte]) float64 {
+			return testing.AllocsPerRun(20, func() {
+				rd.Reset(body)
+				_, _ = in(r, opts)
+			})
+		}
+
+		if pooled, readAll := allocs(gwu.RawBody()), allocs(readAllBody); pooled >= readAll {
+			t.Errorf("%s: %v allocs with pooled chunks, want fewer than the %v of io.ReadAll", s.name, pooled, readAll)
+		}
+	}
+}
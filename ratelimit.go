@@ -0,0 +1,153 @@
+package gwu
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is the error of requests rejected by RateLimit.
+// Is safe to display to the client.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// Limit is the decision of a Limiter about a request and the state of the client's budget afterward.
+type Limit struct {
+	// Allowed reports whether the request is within the budget.
+	Allowed bool
+	// Limit is the number of requests allowed per window.
+	Limit int
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+	// Reset is the time until the budget is restored.
+	Reset time.Duration
+}
+
+// Limiter decides whether a client's request is within its budget, WindowLimiter implements it.
+// Allow must be safe for concurrent use.
+type Limiter interface {
+	Allow(key string) (Limit, error)
+}
+
+// RateLimit rejects requests over the budget of their client with ErrRateLimited, http.StatusTooManyRequests, and a
+// Retry-After header. The key function identifies the client, nil uses the IP of the peer.
+//
+// Every response of the handler, not only rejections, carries the client's budget in the RateLimit-Limit,
+// RateLimit-Remaining, and RateLimit-Reset headers of the IETF RateLimit header fields draft, the reset in seconds.
+// If the Limiter fails, RateLimit logs the error with the ErrorLog and lets the request pass without headers.
+//
+// Example usage:
+//
+//	limiter := gwu.NewWindowLimiter(100, time.Minute, nil)
+//	api := rt.Group("/api", gwu.RateLimit(limiter, func(r *http.Request) string { return r.Header.Get("X-API-Key") }))
+func RateLimit(limiter Limiter, key func(r *http.Request) string) HandleOptsFunc {
+	if key == nil {
+		key = peerIP
+	}
+
+	return Before(func(r *http.Request, opts HandleOpts) error {
+		l, err := limiter.Allow(key(r))
+		if err != nil {
+			opts.logFailure("rate limiter failed", "method", r.Method, "path", FullPath(r), "error", err)
+			return nil
+		}
+
+		reset := strconv.FormatInt(int64((l.Reset+time.Second-1)/time.Second), 10)
+		if h := opts.Header(); h != nil {
+			h.Set("RateLimit-Limit", strconv.Itoa(l.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(max(l.Remaining, 0)))
+			h.Set("RateLimit-Reset", reset)
+			if !l.Allowed {
+				h.Set("Retry-After", reset)
+			}
+		}
+
+		if !l.Allowed {
+			return WithStatus(http.StatusTooManyRequests, ErrRateLimited)
+		}
+
+		return nil
+	})
+}
+
+// peerIP returns the IP of the request's peer, or the RemoteAddr if it cannot be parsed.
+func peerIP(r *http.Request) string {
+	if addr, ok := parseHost(r.RemoteAddr); ok {
+		return addr.String()
+	}
+
+	return r.RemoteAddr
+}
+
+// WindowLimiter is an in-memory Limiter allowing a number of requests per fixed window and key, it evicts the
+// windows of idle keys as new requests arrive. Use a shared Limiter, like one backed by Redis, if several instances
+// serve the same clients.
+type WindowLimiter struct {
+	limit  int
+	window time.Duration
+	clock  Clock
+
+	mu      sync.Mutex
+	windows map[string]*limitWindow
+	// sweep is the time of the next eviction of expired windows.
+	sweep time.Time
+}
+
+// limitWindow is the budget of a key in its current window.
+type limitWindow struct {
+	end  time.Time
+	used int
+}
+
+// NewWindowLimiter returns a WindowLimiter allowing limit requests per window, it tells the time with the clock, nil
+// uses RealClock.
+func NewWindowLimiter(limit int, window time.Duration, clock Clock) *WindowLimiter {
+	if clock == nil {
+		clock = RealClock()
+	}
+
+	return &WindowLimiter{limit: limit, window: window, clock: clock, windows: make(map[string]*limitWindow)}
+}
+
+// Allow counts the request of the key and reports whether it is within the budget of the current window.
+func (l *WindowLimiter) Allow(key string) (Limit, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	if !now.Before(l.sweep) {
+		l.evict(now)
+	}
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.end) {
+		w = &limitWindow{end: now.Add(l.window)}
+		l.windows[key] = w
+		if l.sweep.IsZero() || w.end.Before(l.sweep) {
+			l.sweep = w.end
+		}
+	}
+
+	allowed := w.used < l.limit
+	if allowed {
+		w.used++
+	}
+
+	return Limit{Allowed: allowed, Limit: l.limit, Remaining: l.limit - w.used, Reset: w.end.Sub(now)}, nil
+}
+
+// evict removes the expired windows and sets the time of the next eviction, the caller must hold l.mu.
+func (l *WindowLimiter) evict(now time.Time) {
+	l.sweep = time.Time{}
+	for key, w := range l.windows {
+		if !now.Before(w.end) {
+			delete(l.windows, key)
+			continue
+		}
+
+		if l.sweep.IsZero() || w.end.Before(l.sweep) {
+			l.sweep = w.end
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package gwu_test
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// limited serves a request from the peer and returns the response.
+func limited(h http.Handler, peer string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/poems", nil)
+	r.RemoteAddr = peer
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+
+	return rec
+}
+
+// assertBudget fails the test if the response does not carry the status and RateLimit headers.
+func assertBudget(t *testing.T, rec *httptest.ResponseRecorder, status int, remaining, reset, retryAfter string) {
+	t.Helper()
+
+	h := rec.Header()
+	if rec.Code != status || h.Get("RateLimit-Limit") != "3" || h.Get("RateLimit-Remaining") != remaining ||
+		h.Get("RateLimit-Reset") != reset || h.Get("Retry-After") != retryAfter {
+		t.Errorf("%d with limit %q, remaining %q, reset %q, Retry-After %q, want %d with 3, %q, %q, %q", rec.Code,
+			h.Get("RateLimit-Limit"), h.Get("RateLimit-Remaining"), h.Get("RateLimit-Reset"), h.Get("Retry-After"),
+			status, remaining, reset, retryAfter)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	h := gwu.Handle(gwu.Empty(), noContent, gwu.RateLimit(gwu.NewWindowLimiter(3, time.Minute, clock), nil))
+
+	// The budget counts down with every request, the reset is rounded up to seconds.
+	assertBudget(t, limited(h, "192.0.2.1:4711"), http.StatusNoContent, "2", "60", "")
+	clock.Advance(10 * time.Second)
+	assertBudget(t, limited(h, "192.0.2.1:4712"), http.StatusNoContent, "1", "50", "")
+	clock.Advance(500 * time.Millisecond)
+	assertBudget(t, limited(h, "192.0.2.1:4711"), http.StatusNoContent, "0", "50", "")
+
+	rec := limited(h, "192.0.2.1:4711")
+	assertBudget(t, rec, http.StatusTooManyRequests, "0", "50", "50")
+	gwutest.AssertError(t, rec, http.StatusTooManyRequests, gwu.ErrRateLimited.Error())
+
+	// Other clients have their own budget.
+	assertBudget(t, limited(h, "[2001:db8::1]:4711"), http.StatusNoContent, "2", "60", "")
+
+	// The budget is restored after the window, and counts down again.
+	clock.Advance(49 * time.Second)
+	assertBudget(t, limited(h, "192.0.2.1:4711"), http.StatusTooManyRequests, "0", "1", "1")
+	clock.Advance(500 * time.Millisecond)
+	assertBudget(t, limited(h, "192.0.2.1:4711"), http.StatusNoContent, "2", "60", "")
+	assertBudget(t, limited(h, "192.0.2.1:4711"), http.StatusNoContent, "1", "60", "")
+}
+
+// TestRateLimitKey limits the clients identified by the key function.
+func TestRateLimitKey(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	key := func(r *http.Request) string { return r.Header.Get("X-API-Key") }
+	h := gwu.Handle(gwu.Empty(), noContent, gwu.RateLimit(gwu.NewWindowLimiter(3, time.Minute, clock), key))
+
+	for i, want := range []int{http.StatusNoContent, http.StatusNoContent, http.StatusNoContent,
+		http.StatusTooManyRequests} {
+		r := httptest.NewRequest(http.MethodGet, "/poems", nil)
+		r.Header.Set("X-API-Key", "k1")
+		r.RemoteAddr = "192.0.2." + strconv.Itoa(i+1) + ":4711"
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != want {
+			t.Errorf("request %d from another peer: status %d, want %d", i+1, rec.Code, want)
+		}
+	}
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(string) (gwu.Limit, error) {
+	return gwu.Limit{}, errors.New("redis: connection refused")
+}
+
+// TestRateLimitFailure lets requests pass without headers if the Limiter fails, and logs it with the ErrorLog.
+func TestRateLimitFailure(t *testing.T) {
+	log := gwutest.Logger()
+	h := gwu.Handle(gwu.Empty(), noContent, gwu.ErrorLog(log), gwu.RateLimit(failingLimiter{}, nil))
+
+	rec := limited(h, "192.0.2.1:4711")
+	if rec.Code != http.StatusNoContent || rec.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("%d with headers %v, want 204 without RateLimit headers", rec.Code, rec.Header())
+	}
+
+	log.AssertLogged(t, slog.LevelError, "rate limiter failed", "error", "redis: connection refused")
+}
@@ -0,0 +1,150 @@
+package gwu
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMissingReplayHeaders is the error of requests to a NoReplay CnIn without X-Timestamp or X-Nonce header.
+	// Is safe to display to the client.
+	ErrMissingReplayHeaders = errors.New("missing X-Timestamp or X-Nonce header")
+	// ErrStaleTimestamp is the error of requests with an X-Timestamp outside the window of NoReplay, a SkewError of
+	// TimestampWindow wraps it.
+	// Is safe to display to the client.
+	ErrStaleTimestamp = errors.New("request timestamp is invalid or outside the allowed window")
+	// ErrReplayedNonce is the error of requests with an X-Nonce already used within the window of NoReplay.
+	// Is safe to display to the client.
+	ErrReplayedNonce = errors.New("request nonce was already used")
+	// ErrNonceStore is the error of requests NoReplay cannot check because the NonceStore failed.
+	// Is safe to display to the client.
+	ErrNonceStore = errors.New("failed to check request nonce")
+)
+
+// NonceStore records the nonces of NoReplay, MemoryNonceStore implements it.
+type NonceStore interface {
+	// Add records the nonce until it expires, it reports false if the nonce is already recorded and not expired.
+	// A nonce expires after the expiry time, it is still recorded at the expiry time itself.
+	// Add must be safe for concurrent use and atomic, two concurrent calls with the same nonce must not both succeed.
+	Add(nonce string, expires time.Time) (bool, error)
+}
+
+// NoReplay CnIn protects the given CnIn, typically one verifying a signature, against replayed requests. It requires
+// an X-Timestamp header with Unix seconds within window of the handler's Clock, and an X-Nonce header not used
+// before within the window. Violations return the errors ErrMissingReplayHeaders, ErrStaleTimestamp, or
+// ErrReplayedNonce with http.StatusUnauthorized.
+//
+// NoReplay checks the timestamp before and records the nonce after the given CnIn succeeded, so requests failing
+// the signature check cannot use up nonces. The signature must cover the timestamp and nonce, otherwise a replay
+// can simply change them.
+//
+// Example usage:
+//
+//	nonces := gwu.NewMemoryNonceStore(nil)
+//	gwu.Post(rt, "/webhook", gwu.NoReplay(SignedIn(secret), 5*time.Minute, nonces), hooks.Receive)
+func NoReplay[In any](inFn CnIn[In], window time.Duration, store NonceStore) CnIn[In] {
+	return func(r *http.Request, opts HandleOpts) (In, error) {
+		var in In
+
+		ts, nonce := r.Header.Get("X-Timestamp"), r.Header.Get("X-Nonce")
+		if ts == "" || nonce == "" {
+			return in, WithStatus(http.StatusUnauthorized, ErrMissingReplayHeaders)
+		}
+
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return in, WithStatus(http.StatusUnauthorized, ErrStaleTimestamp)
+		}
+
+		at := time.Unix(sec, 0)
+		if d := opts.Clock().Since(at); d > window || d < -window {
+			return in, WithStatus(http.StatusUnauthorized, ErrStaleTimestamp)
+		}
+
+		in, err = inFn(r, opts)
+		if err != nil {
+			return in, err
+		}
+
+		// A nonce must be kept as long as its timestamp is within the window, including the end of the window.
+		ok, err := store.Add(nonce, at.Add(window))
+		if err != nil {
+			opts.logFailure("nonce store failed", "method", r.Method, "path", FullPath(r), "error", err)
+			return in, WithStatus(http.StatusInternalServerError, ErrNonceStore)
+		}
+
+		if !ok {
+			return in, WithStatus(http.StatusUnauthorized, ErrReplayedNonce)
+		}
+
+		return in, nil
+	}
+}
+
+// MemoryNonceStore is an in-memory NonceStore, it evicts expired nonces as new nonces are added.
+// Use a shared store, like a database, if several instances serve the same endpoint.
+type MemoryNonceStore struct {
+	clock Clock
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	// sweep is the time of the next eviction of expired nonces.
+	sweep time.Time
+}
+
+// NewMemoryNonceStore returns an empty MemoryNonceStore telling the time with the clock, nil uses RealClock.
+func NewMemoryNonceStore(clock Clock) *MemoryNonceStore {
+	if clock == nil {
+		clock = RealClock()
+	}
+
+	return &MemoryNonceStore{clock: clock, nonces: make(map[string]time.Time)}
+}
+
+// Add records the nonce until it expires, it reports false if the nonce is already recorded and not expired.
+func (s *MemoryNonceStore) Add(nonce string, expires time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if now.After(s.sweep) {
+		s.evict(now)
+	}
+
+	if exp, ok := s.nonces[nonce]; ok && !now.After(exp) {
+		return false, nil
+	}
+
+	s.nonces[nonce] = expires
+	if s.sweep.IsZero() || expires.Before(s.sweep) {
+		s.sweep = expires
+	}
+
+	return true, nil
+}
+
+// Len returns the number of recorded nonces, including expired ones not yet evicted.
+func (s *MemoryNonceStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.nonces)
+}
+
+// evict removes the expired nonces and sets the time of the next eviction, the caller must hold s.mu.
+func (s *MemoryNonceStore) evict(now time.Time) {
+	s.sweep = time.Time{}
+	for nonce, exp := range s.nonces {
+		if now.After(exp) {
+			delete(s.nonces, nonce)
+			continue
+		}
+
+		if s.sweep.IsZero() || exp.Before(s.sweep) {
+			s.sweep = exp
+		}
+	}
+}
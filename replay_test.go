@@ -0,0 +1,166 @@
+package gwu_test
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// webhook returns a webhook request with the timestamp and nonce headers, empty values omit them.
+func webhook(ts time.Time, nonce string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	if !ts.IsZero() {
+		r.Header.Set("X-Timestamp", strconv.FormatInt(ts.Unix(), 10))
+	}
+
+	if nonce != "" {
+		r.Header.Set("X-Nonce", nonce)
+	}
+
+	return r
+}
+
+// signed is a CnIn accepting requests with a valid signature header.
+func signed(r *http.Request, _ gwu.HandleOpts) (string, error) {
+	if r.Header.Get("X-Signature") == "forged" {
+		return "", gwu.WithStatus(http.StatusUnauthorized, errors.New("invalid signature"))
+	}
+
+	return "payload", nil
+}
+
+func TestNoReplayTimestamp(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	h := gwu.Handle(gwu.NoReplay(signed, 5*time.Minute, gwu.NewMemoryNonceStore(clock)), noContent[string],
+		gwu.WithClock(clock))
+
+	now := clock.Now()
+	tests := []struct {
+		name string
+		r    *http.Request
+		err  error
+	}{
+		{"now", webhook(now, "n1"), nil},
+		{"at the boundary", webhook(now.Add(-5*time.Minute), "n2"), nil},
+		{"future at the boundary", webhook(now.Add(5*time.Minute), "n3"), nil},
+		{"just past the boundary", webhook(now.Add(-5*time.Minute-time.Second), "n4"), gwu.ErrStaleTimestamp},
+		{"future past the boundary", webhook(now.Add(5*time.Minute+time.Second), "n5"), gwu.ErrStaleTimestamp},
+		{"missing timestamp", webhook(time.Time{}, "n6"), gwu.ErrMissingReplayHeaders},
+		{"missing nonce", webhook(now, ""), gwu.ErrMissingReplayHeaders},
+	}
+
+	invalid := webhook(time.Time{}, "n7")
+	invalid.Header.Set("X-Timestamp", "yesterday")
+	tests = append(tests, struct {
+		name string
+		r    *http.Request
+		err  error
+	}{"invalid timestamp", invalid, gwu.ErrStaleTimestamp})
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, tt.r)
+		if tt.err == nil {
+			if rec.Code != http.StatusNoContent {
+				t.Errorf("%s: status %d, want 204", tt.name, rec.Code)
+			}
+
+			continue
+		}
+
+		gwutest.AssertError(t, rec, http.StatusUnauthorized, tt.err.Error())
+	}
+}
+
+func TestNoReplayNonce(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	store := gwu.NewMemoryNonceStore(clock)
+	h := gwu.Handle(gwu.NoReplay(signed, 5*time.Minute, store), noContent[string], gwu.WithClock(clock))
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	// A request failing the signature check does not use up its nonce.
+	forged := webhook(clock.Now(), "n1")
+	forged.Header.Set("X-Signature", "forged")
+	gwutest.AssertError(t, serve(forged), http.StatusUnauthorized, "invalid signature")
+
+	if rec := serve(webhook(clock.Now(), "n1")); rec.Code != http.StatusNoContent {
+		t.Fatalf("first request: status %d, want 204", rec.Code)
+	}
+
+	gwutest.AssertError(t, serve(webhook(clock.Now(), "n1")), http.StatusUnauthorized, gwu.ErrReplayedNonce.Error())
+
+	// The replay is rejected as long as its timestamp is within the window.
+	sent := clock.Now()
+	clock.Advance(5 * time.Minute)
+	gwutest.AssertError(t, serve(webhook(sent, "n1")), http.StatusUnauthorized, gwu.ErrReplayedNonce.Error())
+
+	clock.Advance(time.Second)
+	gwutest.AssertError(t, serve(webhook(sent, "n1")), http.StatusUnauthorized, gwu.ErrStaleTimestamp.Error())
+}
+
+// TestMemoryNonceStoreEviction evicts the nonces once they expired.
+func TestMemoryNonceStoreEviction(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	store := gwu.NewMemoryNonceStore(clock)
+
+	for i, expires := range []time.Duration{time.Minute, 2 * time.Minute, 10 * time.Minute} {
+		if ok, err := store.Add("n"+strconv.Itoa(i), clock.Now().Add(expires)); !ok || err != nil {
+			t.Fatalf("Add: %v, %v", ok, err)
+		}
+	}
+
+	if ok, _ := store.Add("n0", clock.Now().Add(time.Minute)); ok {
+		t.Error("Add succeeded for a recorded nonce")
+	}
+
+	// The second nonce is kept at its expiry time.
+	clock.Advance(2 * time.Minute)
+	if ok, _ := store.Add("n3", clock.Now().Add(time.Minute)); !ok || store.Len() != 3 {
+		t.Errorf("%d nonces after the first expired, want the 2 not expired and the new one", store.Len())
+	}
+
+	if ok, _ := store.Add("n1", clock.Now().Add(time.Minute)); ok {
+		t.Error("Add succeeded for a nonce at its expiry time")
+	}
+
+	// An expired nonce can be used again.
+	if ok, _ := store.Add("n0", clock.Now().Add(time.Minute)); !ok {
+		t.Error("Add failed for an expired nonce")
+	}
+
+	clock.Advance(time.Hour)
+	if ok, _ := store.Add("n4", clock.Now().Add(time.Minute)); !ok || store.Len() != 1 {
+		t.Errorf("%d nonces after all expired, want 1", store.Len())
+	}
+}
+
+type failingNonceStore struct{}
+
+func (failingNonceStore) Add(string, time.Time) (bool, error) {
+	return false, errors.New("redis: connection refused")
+}
+
+// TestNoReplayStoreFailure responds with 500 and logs with the ErrorLog if the NonceStore fails.
+func TestNoReplayStoreFailure(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	log := gwutest.Logger()
+	h := gwu.Handle(gwu.NoReplay(signed, 5*time.Minute, failingNonceStore{}), noContent[string], gwu.WithClock(clock),
+		gwu.ErrorLog(log))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, webhook(clock.Now(), "n1"))
+	gwutest.AssertError(t, rec, http.StatusInternalServerError, gwu.ErrNonceStore.Error())
+	log.AssertLogged(t, slog.LevelError, "nonce store failed", "error", "redis: connection refused")
+}
@@ -0,0 +1,26 @@
+package gwu
+
+import (
+	"context"
+	"net/http"
+)
+
+type requestCtxKey struct{}
+
+// ExposeRequest makes the *http.Request available to the Exec, retrieve it with RequestFrom.
+//
+// ExposeRequest is an escape hatch for the rare Exec that needs details the CnIn abstraction does not cover, like
+// trailers or protocol specifics. Prefer a CnIn wherever possible. Note that the CnIn runs first and may already
+// have consumed the request body.
+func ExposeRequest() HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.exposeRequest = true
+	}
+}
+
+// RequestFrom returns the *http.Request stored in ctx by Handle, if the handler was created with ExposeRequest.
+// Otherwise, RequestFrom returns nil.
+func RequestFrom(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(requestCtxKey{}).(*http.Request)
+	return r
+}
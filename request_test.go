@@ -0,0 +1,35 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+func TestExposeRequest(t *testing.T) {
+	var got *http.Request
+	exec := func(ctx context.Context, _ any, _ gwu.HandleOpts) (gwu.NoBody, int, error) {
+		got = gwu.RequestFrom(ctx)
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/poems?q=ode", nil)
+	r.Header.Set("X-Poem", "ode")
+	gwu.Handle(gwu.Empty(), exec, gwu.ExposeRequest()).ServeHTTP(httptest.NewRecorder(), r)
+	if got == nil || got.URL.RawQuery != "q=ode" || got.Header.Get("X-Poem") != "ode" {
+		t.Errorf("RequestFrom %v, want the request", got)
+	}
+
+	got = nil
+	gwu.Handle(gwu.Empty(), exec).ServeHTTP(httptest.NewRecorder(), r)
+	if got != nil {
+		t.Errorf("RequestFrom %v without ExposeRequest, want nil", got)
+	}
+
+	if gwu.RequestFrom(context.Background()) != nil {
+		t.Error("RequestFrom of an empty context not nil")
+	}
+}
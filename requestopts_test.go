@@ -0,0 +1,53 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// TestRequestOptsConcurrent mutates the HandleOpts of concurrent requests, run it with -race. Every request must
+// only see its own state, and no mutation may reach the handler's options.
+func TestRequestOptsConcurrent(t *testing.T) {
+	log := gwutest.Logger()
+	in := func(r *http.Request, opts gwu.HandleOpts) (string, error) {
+		id := r.URL.Query().Get("id")
+		tenantKey.Set(opts, id)
+		opts.Header().Set("X-Request-Id", id)
+		opts.Log = gwutest.Logger()
+
+		return id, nil
+	}
+
+	exec := func(_ context.Context, id string, opts gwu.HandleOpts) (string, int, error) {
+		if tenant, _ := tenantKey.Get(opts); tenant != id || opts.Header().Get("X-Request-Id") != id {
+			return "", http.StatusConflict, errors.New("state of another request")
+		}
+
+		opts.Log.Info("served", "id", id)
+		opts.Log = nil
+
+		return id, http.StatusOK, nil
+	}
+
+	h := gwu.Handle(in, exec, gwu.Log(log))
+
+	const requests, iterations = 50, 8
+	reqs := make([]*http.Request, requests)
+	for i := range reqs {
+		reqs[i] = httptest.NewRequest(http.MethodGet, "/?id="+strconv.Itoa(i), nil)
+	}
+
+	// The loggers set in the CnIn and Exec stayed in their request's HandleOpts.
+	gwutest.Hammer(t, h, reqs, 8, iterations, func(t testing.TB) {
+		if got := len(log.Entries()); got != requests*iterations {
+			t.Errorf("%d entries on the handler's logger, want %d", got, requests*iterations)
+		}
+	})
+}
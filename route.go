@@ -0,0 +1,107 @@
+package gwu
+
+import (
+	"reflect"
+	"strings"
+)
+
+// pattern is a parsed http.ServeMux pattern: [METHOD ][HOST]/[PATH].
+type pattern struct {
+	method string
+	host   string
+	path   string
+}
+
+// parsePattern splits an http.ServeMux pattern into its method, host, and path.
+func parsePattern(s string) pattern {
+	var p pattern
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		p.method, s = s[:i], strings.TrimLeft(s[i:], " \t")
+	}
+
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		p.host, p.path = s[:i], s[i:]
+	} else {
+		p.host = s
+	}
+
+	return p
+}
+
+// pathKey returns the host and path of the pattern without wildcard names, the paths of patterns with the same key
+// match the same requests.
+func (p pattern) pathKey() string {
+	return p.host + normalizePath(p.path)
+}
+
+// String returns the pattern in the http.ServeMux syntax.
+func (p pattern) String() string {
+	if p.method == "" {
+		return p.host + p.path
+	}
+
+	return p.method + " " + p.host + p.path
+}
+
+// logAttrs returns the logger attributes describing the route.
+func (p pattern) logAttrs() []any {
+	var attrs []any
+	if p.method != "" {
+		attrs = append(attrs, "method", p.method)
+	}
+
+	if p.host != "" {
+		attrs = append(attrs, "host", p.host)
+	}
+
+	return append(attrs, "route", p.path)
+}
+
+// HandleRoute creates a handler with Handle and registers it for the pattern with the mux.
+// It derives the method, host, and route from the pattern and adds them to the handler's logger, so the pattern
+// is written only once.
+//
+// If mux is a Router, HandleRoute validates and prefixes the pattern, and applies the options of the Router first,
+// see Router.Handle for invalid patterns.
+// If the handler has a Spec, see Collect, HandleRoute adds the route to it.
+//
+// Example usage:
+//
+//	gwu.HandleRoute(mux, "GET /poem/{id}", gwu.PathVal("id"), ctrl.ByID)
+func HandleRoute[In, Out any](mux Mux, pattern string, inFn CnIn[In], fn Exec[In, Out], optFns ...HandleOptsFunc) {
+	rt, isRouter := mux.(*Router)
+	p := parsePattern(pattern)
+	if isRouter {
+		p = rt.pattern(pattern)
+		if !rt.checkPattern(p) {
+			return
+		}
+
+		optFns = rt.options(optFns)
+	}
+
+	h, opts, err := handle(inFn, fn, withRoute(optFns, p))
+	if err != nil {
+		panic(err)
+	}
+
+	in, out := reflect.TypeFor[In](), reflect.TypeFor[Out]()
+	if opts.spec != nil {
+		opts.spec.add(p, in, out, opts)
+	}
+
+	if !isRouter {
+		mux.Handle(pattern, h)
+		return
+	}
+
+	rt.registerRoute(p, h, opts, in, out)
+}
+
+// withRoute appends the option setting the handler's route to the options.
+func withRoute(opts []HandleOptsFunc, p pattern) []HandleOptsFunc {
+	return append(opts[:len(opts):len(opts)], func(opt *HandleOpts) {
+		opt.route = &p
+	})
+}
@@ -0,0 +1,348 @@
+package gwu
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Mux registers an http.Handler for a pattern, *http.ServeMux and *Router implement Mux.
+type Mux interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// Router is an http.Handler built on http.ServeMux that groups routes by path prefix and shares options between
+// the routes of a group. Register typed handlers with HandleRoute.
+//
+// The options of a route are applied in this order, later options override earlier ones: the Defaults, the
+// options of the Router, the options of each Group from the outermost to the innermost, and the route's options.
+//
+// A request to a route passes, in this order: the middleware added with Use, from the Router's to the innermost
+// Group's, each in the order added; the Before hooks in the order their options are applied; the CnIn and the Exec;
+// and the After hooks in reverse order, see Before and After.
+type Router struct {
+	*routes
+	prefix string
+	opts   []HandleOptsFunc
+	mw     []func(http.Handler) http.Handler
+}
+
+// routes is the state shared by a Router and its groups.
+type routes struct {
+	mux      *http.ServeMux
+	slash    slashMode
+	notFound http.Handler
+	// host is the host the routes are scoped to, see Router.Host. Host routers dispatch by host themselves, so the
+	// patterns are registered with the mux without host.
+	host        string
+	collectErrs bool
+	notAllowed  *methodNotAllowed
+	mu          sync.Mutex
+	// root serves the requests the mux matches with the pattern /, see newRoutes. rootTaken reports whether a route
+	// for / took it over.
+	root      *catchAll
+	rootTaken bool
+	// methods are the methods of the registered patterns, sorted.
+	methods    []string
+	preflights map[string]*preflight
+	info       []RouteInfo
+	hosts      []*hostRouter
+	// sites are the call sites of the registered patterns, keyed by the pattern without wildcard names.
+	sites map[string]string
+	errs  []error
+}
+
+// newRoutes returns an empty set of routes for the host, empty for any host, with the options of its Router.
+//
+// The routes register a catchAll for the pattern /, it serves the requests matching no route with unmatched, so
+// that the mux matches every request once. A route for the path / takes it over, see register.
+func newRoutes(host string, opts []HandleOptsFunc) *routes {
+	o := newHandleOpts(opts)
+	rs := &routes{
+		mux:         http.NewServeMux(),
+		slash:       o.slash,
+		notFound:    NotFoundHandler(opts...),
+		host:        host,
+		collectErrs: o.collectRouteErrs,
+		preflights:  make(map[string]*preflight),
+		sites:       make(map[string]string),
+	}
+
+	rs.notAllowed = &methodNotAllowed{errFn: o.errFnOr(TextError), header: o.headers}
+	rs.root = newCatchAll(unmatched{rs})
+	rs.mux.Handle("/", rs.root)
+
+	return rs
+}
+
+// unmatched serves the requests matching no route of the routes: with MethodNotAllowed if routes for other methods
+// match the path, with the path toggled by serveSlash, or with the NotFoundHandler.
+type unmatched struct {
+	*routes
+}
+
+func (u unmatched) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if allow := u.allow(r); len(allow) > 0 {
+		u.notAllowed.write(w, r, allow)
+		return
+	}
+
+	if !u.serveSlash(w, r) {
+		u.notFound.ServeHTTP(w, r)
+	}
+}
+
+// allow returns the sorted methods of the routes matching the path of the request, for the Allow header. Like
+// http.ServeMux, it asks the mux for the handler of the request with each method of the routes.
+func (rs *routes) allow(r *http.Request) []string {
+	rs.mu.Lock()
+	methods := rs.methods
+	rs.mu.Unlock()
+
+	var allow []string
+	probe := *r
+	for _, m := range methods {
+		probe.Method = m
+		if h, _ := rs.mux.Handler(&probe); !isUnmatched(h) {
+			allow = append(allow, m)
+		}
+	}
+
+	if slices.Contains(allow, http.MethodGet) && !slices.Contains(allow, http.MethodHead) {
+		allow = append(allow, http.MethodHead)
+		slices.Sort(allow)
+	}
+
+	return allow
+}
+
+// isUnmatched reports whether the handler the mux matched a request with serves the requests matching no route.
+func isUnmatched(h http.Handler) bool {
+	c, ok := h.(*catchAll)
+	if !ok {
+		return false
+	}
+
+	_, ok = (*c.h.Load()).(unmatched)
+	return ok
+}
+
+// catchAll is the handler a Router registers for the pattern /, a route for / replaces its handler, since the mux
+// cannot register the same pattern twice.
+type catchAll struct {
+	h atomic.Pointer[http.Handler]
+}
+
+// newCatchAll returns a catchAll serving with h.
+func newCatchAll(h http.Handler) *catchAll {
+	c := &catchAll{}
+	c.set(h)
+
+	return c
+}
+
+// set replaces the handler of c.
+func (c *catchAll) set(h http.Handler) {
+	c.h.Store(&h)
+}
+
+func (c *catchAll) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*c.h.Load()).ServeHTTP(w, r)
+}
+
+// NewRouter returns a Router with the given options for all of its routes.
+//
+// The Router responds to requests with a method no route of the path is registered for with
+// http.StatusMethodNotAllowed and the Allow header, see MethodNotAllowed, and to requests for paths without routes
+// with http.StatusNotFound, see NotFoundHandler. See RedirectTrailingSlash and
+// StripTrailingSlash to handle paths that only match with or without a trailing slash.
+func NewRouter(opts ...HandleOptsFunc) *Router {
+	return &Router{
+		routes: newRoutes("", opts),
+		opts:   opts,
+	}
+}
+
+// Group returns a Router that registers its routes with the same mux, under the path prefix and with the given
+// options in addition to the options of rt. Nested groups concatenate their prefixes.
+//
+// Example usage:
+//
+//	api := rt.Group("/api", gwu.Log(log))
+//	gwu.HandleRoute(api, "GET /poems", gwu.Empty(), ctrl.All) // GET /api/poems
+func (rt *Router) Group(prefix string, opts ...HandleOptsFunc) *Router {
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		panic(fmt.Sprintf("gwu: group prefix %q must start with a slash", prefix))
+	}
+
+	return &Router{
+		routes: rt.routes,
+		prefix: rt.prefix + strings.TrimSuffix(prefix, "/"),
+		opts:   append(rt.opts[:len(rt.opts):len(rt.opts)], opts...),
+		mw:     rt.mw[:len(rt.mw):len(rt.mw)],
+	}
+}
+
+// Use adds middleware wrapping the handlers of the routes registered with rt afterward, including the routes of
+// groups created afterward. The middleware added first is the outermost.
+//
+// Use does not affect routes and groups created before the call, add middleware before registering routes.
+//
+// Example usage:
+//
+//	rt.Use(requestID, recoverer)
+func (rt *Router) Use(mw ...func(http.Handler) http.Handler) {
+	rt.mw = append(rt.mw[:len(rt.mw):len(rt.mw)], mw...)
+}
+
+// wrap wraps the handler with the router's middleware.
+func (rt *Router) wrap(h http.Handler) http.Handler {
+	for i := len(rt.mw) - 1; i >= 0; i-- {
+		h = rt.mw[i](h)
+	}
+
+	return h
+}
+
+// Handle registers the handler for the pattern, prefixed with the router's prefix.
+// The router's middleware wraps the handler, but its options do not apply, use HandleRoute for typed handlers.
+//
+// Handle panics with the offending pattern and the call site if the pattern is invalid or conflicts with a
+// registered pattern, see CollectRouteErrors to collect the errors instead.
+func (rt *Router) Handle(pattern string, handler http.Handler) {
+	p := rt.pattern(pattern)
+	if rt.checkPattern(p) && rt.register(p, rt.wrap(handler)) {
+		rt.addInfo(newRouteInfo(p, nil, nil, nil))
+	}
+}
+
+// HandleFunc registers the handler function for the pattern, like Handle.
+func (rt *Router) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	rt.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// ServeHTTP dispatches the request to the handler registered for it.
+// Requests that match none of the routes get the response of NotFoundHandler.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rt.serveHost(w, r) {
+		return
+	}
+
+	rt.mux.ServeHTTP(w, r)
+}
+
+// Mux returns the underlying http.ServeMux. Do not register the pattern / with it, the Router serves the requests
+// matching no route with it, register a route for / with the Router instead.
+func (rt *Router) Mux() *http.ServeMux {
+	return rt.mux
+}
+
+// pattern returns the pattern with the router's prefix.
+func (rt *Router) pattern(s string) pattern {
+	p := parsePattern(s)
+	p.path = rt.prefix + p.path
+	if rt.host != "" {
+		p.host = rt.host
+	}
+
+	return p
+}
+
+// options returns the router's options followed by the given options.
+func (rt *Router) options(opts []HandleOptsFunc) []HandleOptsFunc {
+	return append(rt.opts[:len(rt.opts):len(rt.opts)], opts...)
+}
+
+// register registers the handler with the mux and fails with a clear message on conflicting patterns, it reports
+// whether it registered the handler. A route without method for the path / replaces the catchAll serving the requests
+// matching no route.
+func (rt *Router) register(p pattern, handler http.Handler) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if p.method == "" && p.path == "/" && !rt.rootTaken {
+		rt.root.set(handler)
+		rt.rootTaken = true
+		return true
+	}
+
+	if !rt.handle(p, handler) {
+		return false
+	}
+
+	if p.method != "" && !slices.Contains(rt.methods, p.method) {
+		// The slice is replaced, allow reads the methods without holding rt.mu.
+		methods := append(slices.Clone(rt.methods), p.method)
+		slices.Sort(methods)
+		rt.methods = methods
+	}
+
+	return true
+}
+
+// handle registers the handler with the mux and fails with a clear message on conflicting patterns, it reports
+// whether it registered the handler. The caller must hold rt.mu.
+func (rt *Router) handle(p pattern, handler http.Handler) (ok bool) {
+	// The call site is computed before the mux panics, the stack of the deferred function starts in the runtime.
+	site := callSite()
+	defer func() {
+		if v := recover(); v != nil {
+			rt.fail(fmt.Errorf("gwu: register %q at %s: %v", p.String(), site, v))
+			ok = false
+		}
+	}()
+
+	registered := p
+	if rt.host != "" {
+		registered.host = ""
+	}
+
+	rt.mux.Handle(registered.String(), handler)
+
+	return true
+}
+
+// addInfo records a registered route for Routes.
+func (rt *Router) addInfo(info RouteInfo) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.info = append(rt.info, info)
+}
+
+// registerRoute registers a handler created by HandleRoute, and the preflight handler for its path if it uses CORS.
+func (rt *Router) registerRoute(p pattern, handler http.Handler, opts HandleOpts, in, out reflect.Type) {
+	if !rt.register(p, rt.wrap(handler)) {
+		return
+	}
+
+	info := newRouteInfo(p, in, out, opts.names)
+	info.example = opts.example
+	rt.addInfo(info)
+	if opts.cors == nil || p.method == "" || p.method == http.MethodOptions {
+		return
+	}
+
+	rt.mu.Lock()
+	key := p.pathKey()
+	pf, ok := rt.preflights[key]
+	created := !ok
+	if created {
+		pf = &preflight{policies: make(map[string]*CORSPolicy)}
+		rt.preflights[key] = pf
+	}
+
+	pf.policies[p.method] = opts.cors
+	if p.method == http.MethodGet {
+		pf.policies[http.MethodHead] = opts.cors
+	}
+	rt.mu.Unlock()
+
+	if created {
+		rt.register(pattern{method: http.MethodOptions, host: p.host, path: p.path}, pf)
+	}
+}
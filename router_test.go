@@ -0,0 +1,295 @@
+package gwu_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// textHandler responds with the text.
+func textHandler(text string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, text)
+	})
+}
+
+func TestRouterMethodlessRouteAfterMethodRoute(t *testing.T) {
+	rt := gwu.NewRouter(gwu.CollectRouteErrors())
+	rt.Handle("GET /poems", textHandler("get"))
+	rt.Handle("/poems", textHandler("any"))
+	if err := rt.Err(); err != nil {
+		t.Fatalf("registering /poems after GET /poems: %v", err)
+	}
+
+	for method, want := range map[string]string{http.MethodGet: "get", http.MethodPost: "any", http.MethodDelete: "any"} {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(method, "/poems", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("%s /poems: %d %q, want 200 %q", method, rec.Code, rec.Body, want)
+		}
+	}
+
+	rt.Handle("/poems", textHandler("again"))
+	if rt.Err() == nil {
+		t.Error("registering /poems twice: no error")
+	}
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	rt := gwu.NewRouter()
+	rt.Handle("GET /poems", textHandler("get"))
+	rt.Handle("PUT /poems", textHandler("put"))
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/poems", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST /poems: status %d, want 405", rec.Code)
+	}
+
+	if got, want := rec.Header().Get("Allow"), "GET, HEAD, PUT"; got != want {
+		t.Errorf("Allow: %q, want %q", got, want)
+	}
+}
+
+func TestRouterUnmatched(t *testing.T) {
+	rt := gwu.NewRouter(gwu.RedirectTrailingSlash())
+	rt.Handle("GET /poems", textHandler("poems"))
+	admin := rt.Host("admin.example.com")
+	admin.Handle("GET /stats", textHandler("stats"))
+
+	tests := []struct {
+		method, target string
+		code           int
+		location       string
+	}{
+		{http.MethodGet, "/poems", http.StatusOK, ""},
+		{http.MethodGet, "/poems/", http.StatusPermanentRedirect, "/poems"},
+		{http.MethodGet, "/songs", http.StatusNotFound, ""},
+		{http.MethodPost, "/songs", http.StatusNotFound, ""},
+		{http.MethodPost, "/poems", http.StatusMethodNotAllowed, ""},
+		{http.MethodGet, "http://admin.example.com/stats/", http.StatusPermanentRedirect, "/stats"},
+		{http.MethodGet, "http://admin.example.com/poems", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != tt.code || rec.Header().Get("Location") != tt.location {
+			t.Errorf("%s %s: %d %q, want %d %q", tt.method, tt.target, rec.Code, rec.Header().Get("Location"), tt.code,
+				tt.location)
+		}
+	}
+}
+
+func TestRouterRootRoute(t *testing.T) {
+	rt := gwu.NewRouter(gwu.CollectRouteErrors())
+	rt.Handle("GET /poems", textHandler("poems"))
+	rt.Handle("/", textHandler("root"))
+	if err := rt.Err(); err != nil {
+		t.Fatalf("registering /: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/songs", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "root" {
+		t.Errorf("GET /songs: %d %q, want 200 \"root\"", rec.Code, rec.Body)
+	}
+}
+
+func TestRouterMatchesOnce(t *testing.T) {
+	h := gwu.Handle(gwu.PathVal("id"), func(context.Context, string, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /poem/{id}", h)
+	rt := gwu.NewRouter()
+	rt.Handle("GET /poem/{id}", h)
+
+	do := func(h http.Handler) func() {
+		r := httptest.NewRequest(http.MethodGet, "/poem/7", nil)
+		w := httptest.NewRecorder()
+		return func() { h.ServeHTTP(w, r) }
+	}
+
+	want := testing.AllocsPerRun(100, do(mux))
+	if got := testing.AllocsPerRun(100, do(rt)); got > want {
+		t.Errorf("%v allocs per request with the Router, want the %v of the http.ServeMux", got, want)
+	}
+}
+
+// pathValueHandler responds with the path value of the wildcard.
+func pathValueHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Method+" "+r.PathValue(name))
+	})
+}
+
+// TestRouterWildcardNames registers routes of a path that only differ in their wildcard names.
+func TestRouterWildcardNames(t *testing.T) {
+	rt := gwu.NewRouter(gwu.CollectRouteErrors())
+	rt.Handle("GET /poem/{id}", pathValueHandler("id"))
+	rt.Handle("DELETE /poem/{name}", pathValueHandler("name"))
+	rt.Handle("/poem/{slug}", pathValueHandler("slug"))
+	rt.Handle("GET /files/{path...}", pathValueHandler("path"))
+	rt.Handle("PUT /files/{rest...}", pathValueHandler("rest"))
+	if err := rt.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct{ method, target, want string }{
+		{http.MethodGet, "/poem/7", "GET 7"},
+		{http.MethodDelete, "/poem/7", "DELETE 7"},
+		{http.MethodPost, "/poem/7", "POST 7"},
+		{http.MethodPut, "/files/a/b", "PUT a/b"},
+	} {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != tt.want {
+			t.Errorf("%s %s: %d %q, want 200 %q", tt.method, tt.target, rec.Code, rec.Body, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/files/a", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD, PUT" {
+		t.Errorf("POST /files/a: %d, Allow %q, want 405 with GET, HEAD, PUT", rec.Code, rec.Header().Get("Allow"))
+	}
+}
+
+// TestRouterGroup concatenates the prefixes of nested groups and applies their options from the outermost to the
+// route's own.
+func TestRouterGroup(t *testing.T) {
+	level := func(name string) gwu.HandleOptsFunc {
+		return gwu.StaticHeaders(http.Header{"X-Level": {name}, "X-" + name: {"set"}})
+	}
+
+	rt := gwu.NewRouter(level("Router"))
+	api := rt.Group("/api/", level("API"))
+	v1 := api.Group("/v1", level("V1"))
+	gwu.HandleRoute(rt, "GET /health", gwu.Empty(), noContent)
+	gwu.HandleRoute(api, "GET /poems", gwu.Empty(), noContent)
+	gwu.HandleRoute(v1, "GET /poems", gwu.Empty(), noContent)
+	gwu.HandleRoute(v1, "GET /poems/{id}", gwu.Empty(), noContent, level("Route"))
+
+	// The group does not change the options of its parent.
+	gwu.HandleRoute(api, "GET /songs", gwu.Empty(), noContent)
+
+	tests := []struct {
+		path, level string
+		set         []string
+	}{
+		{"/health", "Router", []string{"Router"}},
+		{"/api/poems", "API", []string{"Router", "API"}},
+		{"/api/v1/poems", "V1", []string{"Router", "API", "V1"}},
+		{"/api/v1/poems/7", "Route", []string{"Router", "API", "V1", "Route"}},
+		{"/api/songs", "API", []string{"Router", "API"}},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != http.StatusNoContent || rec.Header().Get("X-Level") != tt.level {
+			t.Errorf("%s: %d with level %q, want 204 with %q", tt.path, rec.Code, rec.Header().Get("X-Level"), tt.level)
+		}
+
+		for _, name := range []string{"Router", "API", "V1", "Route"} {
+			if got, want := rec.Header().Get("X-"+name) != "", slices.Contains(tt.set, name); got != want {
+				t.Errorf("%s: X-%s set %v, want %v", tt.path, name, got, want)
+			}
+		}
+	}
+}
+
+// TestRouterGroupErrors applies the ErrorFunc of the innermost group to the errors of its routes.
+func TestRouterGroupErrors(t *testing.T) {
+	fail := func(context.Context, any, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		return gwu.NoBody{}, http.StatusNotFound, errPoemNotFound
+	}
+
+	rt := gwu.NewRouter()
+	gwu.HandleRoute(rt, "GET /poems", gwu.Empty(), fail)
+	gwu.HandleRoute(rt.Group("/api", gwu.Errors(gwu.JSONError)), "GET /poems", gwu.Empty(), fail)
+
+	for path, want := range map[string]string{"/poems": "text/plain; charset=utf-8", "/api/poems": gwu.ContentTypeJSON} {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound || rec.Header().Get("Content-Type") != want {
+			t.Errorf("%s: %d %q, want 404 %q", path, rec.Code, rec.Header().Get("Content-Type"), want)
+		}
+	}
+}
+
+// TestRouterGroupConflict panics naming both patterns of routes registered twice through groups.
+func TestRouterGroupConflict(t *testing.T) {
+	rt := gwu.NewRouter()
+	gwu.HandleRoute(rt.Group("/api"), "GET /poems", gwu.Empty(), noContent)
+
+	defer func() {
+		if v := recover(); v == nil || !strings.Contains(fmt.Sprint(v), `"GET /api/poems"`) {
+			t.Errorf("panic %v, want the conflicting pattern", v)
+		}
+	}()
+
+	gwu.HandleRoute(rt.Group("/api/"), "GET /poems", gwu.Empty(), noContent)
+}
+
+func TestRouterGroupInvalidPrefix(t *testing.T) {
+	defer func() {
+		if v := recover(); v == nil || !strings.Contains(fmt.Sprint(v), `"api"`) {
+			t.Errorf("panic %v, want the prefix", v)
+		}
+	}()
+
+	gwu.NewRouter().Group("api")
+}
+
+// TestRouterSubtree registers routes of a subtree and a path in it, which a method-less handler of either path would
+// conflict with.
+func TestRouterSubtree(t *testing.T) {
+	for _, patterns := range [][]string{
+		{"GET /poems/", "GET /poems/{id}", "GET /authors/", "PUT /authors/{name}/"},
+		{"GET /poems/{id}", "GET /poems/", "PUT /authors/{name}/", "GET /authors/"},
+	} {
+		rt := gwu.NewRouter(gwu.CollectRouteErrors())
+		for _, p := range patterns {
+			rt.Handle(p, textHandler(p))
+		}
+
+		if err := rt.Err(); err != nil {
+			t.Fatalf("registering %q: %v", patterns, err)
+		}
+
+		for _, tt := range []struct{ method, target, want, allow string }{
+			{http.MethodGet, "/poems/", "GET /poems/", ""},
+			{http.MethodGet, "/poems/7", "GET /poems/{id}", ""},
+			{http.MethodGet, "/poems/7/lines", "GET /poems/", ""},
+			{http.MethodPost, "/poems/7", "", "GET, HEAD"},
+			{http.MethodPut, "/authors/keats/", "PUT /authors/{name}/", ""},
+			{http.MethodDelete, "/authors/keats/", "", "GET, HEAD, PUT"},
+			{http.MethodDelete, "/authors/", "", "GET, HEAD"},
+		} {
+			rec := httptest.NewRecorder()
+			rt.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+			if tt.allow != "" {
+				if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != tt.allow {
+					t.Errorf("%q: %s %s: %d, Allow %q, want 405 with %q", patterns, tt.method, tt.target, rec.Code,
+						rec.Header().Get("Allow"), tt.allow)
+				}
+
+				continue
+			}
+
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.want {
+				t.Errorf("%q: %s %s: %d %q, want 200 %q", patterns, tt.method, tt.target, rec.Code, rec.Body, tt.want)
+			}
+		}
+	}
+}
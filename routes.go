@@ -0,0 +1,72 @@
+package gwu
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// RouteInfo describes a route registered with a Router, see Router.Routes.
+type RouteInfo struct {
+	Method  string `json:"method,omitempty"`
+	Pattern string `json:"pattern"`
+	// In and Out are the type names of the route's input and output, empty for routes registered with Handle.
+	In  string `json:"in,omitempty"`
+	Out string `json:"out,omitempty"`
+	// InType and OutType are the types of the route's input and output, nil for routes registered with Handle.
+	InType  reflect.Type `json:"-"`
+	OutType reflect.Type `json:"-"`
+	// Options are the names of the options in effect for the route, sorted.
+	Options []string `json:"options,omitempty"`
+
+	route   pattern
+	example any
+}
+
+// newRouteInfo returns the RouteInfo of a route.
+func newRouteInfo(p pattern, in, out reflect.Type, opts []string) RouteInfo {
+	info := RouteInfo{Method: p.method, Pattern: p.String(), InType: in, OutType: out, Options: opts, route: p}
+	if in != nil {
+		info.In = in.String()
+	}
+
+	if out != nil {
+		info.Out = out.String()
+	}
+
+	return info
+}
+
+// Routes returns the routes registered with the Router and its groups, in the order of registration, followed by
+// the routes of its host routers, see Router.Host. Handlers the Router registers itself, like the responses to
+// unregistered methods, are not included.
+func (rt *Router) Routes() []RouteInfo {
+	rt.mu.Lock()
+	routes := make([]RouteInfo, len(rt.info))
+	for i, info := range rt.info {
+		info.Options = append([]string(nil), info.Options...)
+		routes[i] = info
+	}
+
+	hosts := rt.hosts
+	rt.mu.Unlock()
+
+	for _, h := range hosts {
+		routes = append(routes, h.rt.Routes()...)
+	}
+
+	return routes
+}
+
+// RoutesHandler returns an http.Handler serving the routes of the Router as JSON, use it for a debug endpoint.
+//
+// Example usage:
+//
+//	rt.Handle("GET /debug/routes", gwu.RoutesHandler(rt))
+func RoutesHandler(rt *Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := writeJSON(w, rt.Routes(), http.StatusOK)
+		if err != nil {
+			orFallback(newHandleOpts(rt.opts).Log).Info(ErrEncodeResponse.Error(), "error", err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func smallPoemByID(_ context.Context, id string, _ gwu.HandleOpts) (smallPoem, int, error) {
+	return smallPoem{ID: 7, Title: "Ode"}, http.StatusOK, nil
+}
+
+func createSmallPoem(_ context.Context, p smallPoem, _ gwu.HandleOpts) (smallPoem, int, error) {
+	return p, http.StatusCreated, nil
+}
+
+func TestRoutes(t *testing.T) {
+	rt := gwu.NewRouter(gwu.Log(gwutest.Logger()))
+	api := rt.Group("/api", gwu.Errors(gwu.JSONError))
+	gwu.Get(api, "/poems/{id}", gwu.PathVal("id"), smallPoemByID)
+	gwu.Post(api, "/poems", gwu.JSON[smallPoem](), createSmallPoem, gwu.MaxRequestBytes(1024))
+	rt.Handle("GET /debug/routes", gwu.RoutesHandler(rt))
+	gwu.Get(rt.Host("admin.example.com"), "/stats", gwu.Empty(), noContent)
+
+	want := []gwu.RouteInfo{
+		{Method: http.MethodGet, Pattern: "GET /api/poems/{id}", In: "string", Out: "gwu_test.smallPoem",
+			InType: reflect.TypeFor[string](), OutType: reflect.TypeFor[smallPoem](), Options: []string{"Errors", "Log"}},
+		{Method: http.MethodPost, Pattern: "POST /api/poems", In: "gwu_test.smallPoem", Out: "gwu_test.smallPoem",
+			InType: reflect.TypeFor[smallPoem](), OutType: reflect.TypeFor[smallPoem](),
+			Options: []string{"Errors", "Log", "MaxRequestBytes"}},
+		{Method: http.MethodGet, Pattern: "GET /debug/routes"},
+		{Method: http.MethodGet, Pattern: "GET admin.example.com/stats", In: "interface {}", Out: "gwu.NoBody",
+			InType: reflect.TypeFor[any](), OutType: reflect.TypeFor[gwu.NoBody](), Options: []string{"Log"}},
+	}
+
+	got := rt.Routes()
+	if len(got) != len(want) {
+		t.Fatalf("%d routes %+v, want %d", len(got), got, len(want))
+	}
+
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Method != w.Method || g.Pattern != w.Pattern || g.In != w.In || g.Out != w.Out || g.InType != w.InType ||
+			g.OutType != w.OutType || !slices.Equal(g.Options, w.Options) {
+			t.Errorf("route %d: %+v, want %+v", i, g, w)
+		}
+	}
+
+	// The returned routes are copies.
+	got[0].Options[0] = "changed"
+	if rt.Routes()[0].Options[0] != "Errors" {
+		t.Error("changing the options of a returned route changed the Router")
+	}
+}
+
+func TestRoutesHandler(t *testing.T) {
+	rt := gwu.NewRouter()
+	gwu.Get(rt, "/poems/{id}", gwu.PathVal("id"), smallPoemByID)
+	rt.Handle("GET /debug/routes", gwu.RoutesHandler(rt))
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+
+	var got []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("%d %s: %v", rec.Code, rec.Body, err)
+	}
+
+	want := []map[string]any{
+		{"method": "GET", "pattern": "GET /poems/{id}", "in": "string", "out": "gwu_test.smallPoem"},
+		{"method": "GET", "pattern": "GET /debug/routes"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("routes %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,215 @@
+package gwu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// JSON-RPC 2.0 error codes, see https://www.jsonrpc.org/specification#error_object.
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+	// RPCServerError is the code of errors of an Exec without an RPCError.
+	RPCServerError = -32000
+)
+
+// RPCError is a JSON-RPC 2.0 error object. Return it from an Exec adapted with RPCOf to respond with its code.
+// Like any error of an Exec, the message must be safe to display to the client.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// RPCMethod is a JSON-RPC method, create it from an Exec with RPCOf.
+type RPCMethod func(ctx context.Context, params json.RawMessage, opts HandleOpts) (any, error)
+
+// RPCOf adapts the Exec to an RPCMethod. It decodes the params into In, by-position params decode into a slice or
+// array In, and encodes the Out as result. A call without params passes the zero In.
+//
+// Params that cannot be decoded, and errors of the Exec with a 400 or 422 status code or a ValidationError, are
+// answered with RPCInvalidParams. Other errors are answered with RPCServerError, unless they are an RPCError.
+func RPCOf[In, Out any](exec Exec[In, Out]) RPCMethod {
+	return func(ctx context.Context, params json.RawMessage, opts HandleOpts) (any, error) {
+		var in In
+		if len(params) > 0 {
+			if err := opts.JSONCodec().Unmarshal(params, &in); err != nil {
+				return nil, &RPCError{Code: RPCInvalidParams, Message: "Invalid params"}
+			}
+		}
+
+		out, code, err := exec(ctx, in, opts)
+		if err == nil {
+			return out, nil
+		}
+
+		var rpcErr *RPCError
+		var valErr *ValidationError
+		switch {
+		case errors.As(err, &rpcErr):
+			return nil, rpcErr
+		case errors.As(err, &valErr), code == http.StatusBadRequest, code == http.StatusUnprocessableEntity:
+			return nil, &RPCError{Code: RPCInvalidParams, Message: err.Error()}
+		default:
+			if code >= http.StatusInternalServerError {
+				opts.logFailure("rpc call failed", "status", code, "error", err)
+			}
+
+			return nil, &RPCError{Code: RPCServerError, Message: err.Error()}
+		}
+	}
+}
+
+// rpcResponse is a JSON-RPC 2.0 response object.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// RPC returns an http.Handler serving the JSON-RPC 2.0 methods, register it for POST requests. It supports
+// notifications, requests without id that get no response, and batches of requests. Valid envelopes are answered
+// with http.StatusOK and the JSON-RPC response, or http.StatusNoContent if all requests were notifications.
+//
+// The options apply like for Handle, the methods receive the request's HandleOpts.
+//
+// Example usage:
+//
+//	rt.Handle("POST /rpc", gwu.RPC(map[string]gwu.RPCMethod{
+//		"poem.byID":   gwu.RPCOf(ctrl.ByID),
+//		"poem.create": gwu.RPCOf(ctrl.Create),
+//	}))
+func RPC(methods map[string]RPCMethod, optFns ...HandleOptsFunc) http.Handler {
+	return Handle(rpcIn, func(ctx context.Context, body []byte, opts HandleOpts) (any, int, error) {
+		body = bytes.TrimSpace(body)
+		if len(body) == 0 || body[0] != '[' {
+			res, ok := callRPC(ctx, methods, body, opts)
+			if !ok {
+				return nil, http.StatusNoContent, nil
+			}
+
+			return res, http.StatusOK, nil
+		}
+
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return rpcFailure(nil, RPCParseError, "Parse error"), http.StatusOK, nil
+		}
+
+		if len(batch) == 0 {
+			return rpcFailure(nil, RPCInvalidRequest, "Invalid Request"), http.StatusOK, nil
+		}
+
+		var responses []rpcResponse
+		for _, req := range batch {
+			if res, ok := callRPC(ctx, methods, req, opts); ok {
+				responses = append(responses, res)
+			}
+		}
+
+		if len(responses) == 0 {
+			return nil, http.StatusNoContent, nil
+		}
+
+		return responses, http.StatusOK, nil
+	}, optFns...)
+}
+
+// rpcIn reads the raw request body of a JSON-RPC request.
+func rpcIn(r *http.Request, _ HandleOpts) ([]byte, error) {
+	b, err := readBody(r.Body)
+	if err != nil {
+		return nil, ErrDecodeRequest
+	}
+
+	return b, nil
+}
+
+// callRPC calls the method of a single request, it reports false for notifications.
+func callRPC(
+	ctx context.Context, methods map[string]RPCMethod, raw json.RawMessage, opts HandleOpts,
+) (rpcResponse, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || !json.Valid(raw) {
+			return rpcFailure(nil, RPCParseError, "Parse error"), true
+		}
+
+		return rpcFailure(nil, RPCInvalidRequest, "Invalid Request"), true
+	}
+
+	var req struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+	}
+
+	id, hasID := fields["id"]
+	if hasID && !validRPCID(id) || json.Unmarshal(raw, &req) != nil || req.JSONRPC != "2.0" || req.Method == "" ||
+		!validRPCParams(req.Params) {
+		if !validRPCID(id) {
+			id = nil
+		}
+
+		return rpcFailure(id, RPCInvalidRequest, "Invalid Request"), true
+	}
+
+	method, ok := methods[req.Method]
+	if !ok {
+		return rpcFailure(id, RPCMethodNotFound, "Method not found"), hasID
+	}
+
+	out, err := method(ctx, req.Params, opts)
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Code: RPCServerError, Message: err.Error()}
+		}
+
+		return rpcResponse{JSONRPC: "2.0", Error: rpcErr, ID: id}, hasID
+	}
+
+	result, err := opts.JSONCodec().Marshal(out)
+	if err != nil {
+		opts.logEncodeFailure(err)
+		return rpcFailure(id, RPCInternalError, "Internal error"), hasID
+	}
+
+	return rpcResponse{JSONRPC: "2.0", Result: result, ID: id}, hasID
+}
+
+// rpcFailure returns the error response with the code and message.
+func rpcFailure(id json.RawMessage, code int, msg string) rpcResponse {
+	return rpcResponse{JSONRPC: "2.0", Error: &RPCError{Code: code, Message: msg}, ID: id}
+}
+
+// validRPCID reports whether the id is a string, number, or null.
+func validRPCID(id json.RawMessage) bool {
+	if len(id) == 0 {
+		return true
+	}
+
+	switch id[0] {
+	case '"', 'n', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	default:
+		return false
+	}
+}
+
+// validRPCParams reports whether the params are absent, an array, or an object.
+func validRPCParams(params json.RawMessage) bool {
+	return len(params) == 0 || params[0] == '[' || params[0] == '{'
+}
@@ -0,0 +1,198 @@
+package gwu_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// subtractParams are the params of the subtract method of the specification, by position or by name.
+type subtractParams struct {
+	Minuend, Subtrahend int
+}
+
+func (p *subtractParams) UnmarshalJSON(b []byte) error {
+	if bytes.HasPrefix(b, []byte("[")) {
+		var pos [2]int
+		if err := json.Unmarshal(b, &pos); err != nil {
+			return err
+		}
+
+		p.Minuend, p.Subtrahend = pos[0], pos[1]
+		return nil
+	}
+
+	var named struct {
+		Minuend    int `json:"minuend"`
+		Subtrahend int `json:"subtrahend"`
+	}
+
+	err := json.Unmarshal(b, &named)
+	p.Minuend, p.Subtrahend = named.Minuend, named.Subtrahend
+
+	return err
+}
+
+// specMethods are the methods of the examples of the JSON-RPC 2.0 specification.
+var specMethods = map[string]gwu.RPCMethod{
+	"subtract": gwu.RPCOf(func(_ context.Context, p subtractParams, _ gwu.HandleOpts) (int, int, error) {
+		return p.Minuend - p.Subtrahend, http.StatusOK, nil
+	}),
+	"sum": gwu.RPCOf(func(_ context.Context, nums []int, _ gwu.HandleOpts) (int, int, error) {
+		sum := 0
+		for _, n := range nums {
+			sum += n
+		}
+
+		return sum, http.StatusOK, nil
+	}),
+	"get_data": gwu.RPCOf(func(context.Context, any, gwu.HandleOpts) ([]any, int, error) {
+		return []any{"hello", 5}, http.StatusOK, nil
+	}),
+	"update": gwu.RPCOf(func(context.Context, []int, gwu.HandleOpts) (any, int, error) {
+		return nil, http.StatusOK, nil
+	}),
+	"notify_hello": gwu.RPCOf(func(context.Context, []int, gwu.HandleOpts) (any, int, error) {
+		return nil, http.StatusOK, nil
+	}),
+}
+
+// TestRPCSpecExamples runs the examples of https://www.jsonrpc.org/specification#examples, an empty response is
+// no response.
+func TestRPCSpecExamples(t *testing.T) {
+	tests := []struct {
+		name, req, resp string
+	}{
+		{
+			"positional params",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`,
+			`{"jsonrpc": "2.0", "result": 19, "id": 1}`,
+		},
+		{
+			"positional params reversed",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": [23, 42], "id": 2}`,
+			`{"jsonrpc": "2.0", "result": -19, "id": 2}`,
+		},
+		{
+			"named params",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42}, "id": 3}`,
+			`{"jsonrpc": "2.0", "result": 19, "id": 3}`,
+		},
+		{
+			"named params reordered",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": {"minuend": 42, "subtrahend": 23}, "id": 4}`,
+			`{"jsonrpc": "2.0", "result": 19, "id": 4}`,
+		},
+		{"notification", `{"jsonrpc": "2.0", "method": "update", "params": [1,2,3,4,5]}`, ``},
+		{"notification without params", `{"jsonrpc": "2.0", "method": "foobar"}`, ``},
+		{
+			"non-existent method",
+			`{"jsonrpc": "2.0", "method": "foobar", "id": "1"}`,
+			`{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found"}, "id": "1"}`,
+		},
+		{
+			"invalid JSON",
+			`{"jsonrpc": "2.0", "method": "foobar, "params": "bar", "baz]`,
+			`{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`,
+		},
+		{
+			"invalid request object",
+			`{"jsonrpc": "2.0", "method": 1, "params": "bar"}`,
+			`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`,
+		},
+		{
+			"batch with invalid JSON",
+			`[
+				{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"},
+				{"jsonrpc": "2.0", "method"
+			]`,
+			`{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`,
+		},
+		{
+			"empty batch",
+			`[]`,
+			`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`,
+		},
+		{
+			"invalid batch",
+			`[1]`,
+			`[{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}]`,
+		},
+		{
+			"invalid batch of three",
+			`[1,2,3]`,
+			`[
+				{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}
+			]`,
+		},
+		{
+			"batch",
+			`[
+				{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"},
+				{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]},
+				{"jsonrpc": "2.0", "method": "subtract", "params": [42,23], "id": "2"},
+				{"foo": "boo"},
+				{"jsonrpc": "2.0", "method": "foo.get", "params": {"name": "myself"}, "id": "5"},
+				{"jsonrpc": "2.0", "method": "get_data", "id": "9"}
+			]`,
+			`[
+				{"jsonrpc": "2.0", "result": 7, "id": "1"},
+				{"jsonrpc": "2.0", "result": 19, "id": "2"},
+				{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found"}, "id": "5"},
+				{"jsonrpc": "2.0", "result": ["hello", 5], "id": "9"}
+			]`,
+		},
+		{
+			"batch of notifications",
+			`[
+				{"jsonrpc": "2.0", "method": "notify_sum", "params": [1,2,4]},
+				{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]}
+			]`,
+			``,
+		},
+	}
+
+	h := gwu.RPC(specMethods)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(tt.req)))
+
+			if tt.resp == "" {
+				if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+					t.Errorf("response %d %q, want 204 without body", rec.Code, rec.Body)
+				}
+
+				return
+			}
+
+			var want any
+			_ = json.Unmarshal([]byte(tt.resp), &want)
+			gwutest.AssertJSON(t, rec, http.StatusOK, want)
+		})
+	}
+}
+
+func TestRPCInvalidParams(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gwu.RPC(specMethods).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc",
+		strings.NewReader(`{"jsonrpc": "2.0", "method": "sum", "params": {"a": 1}, "id": 1}`)))
+
+	var resp struct {
+		Error gwu.RPCError `json:"error"`
+	}
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error.Code != gwu.RPCInvalidParams {
+		t.Errorf("response %s, want the error %d", rec.Body, gwu.RPCInvalidParams)
+	}
+}
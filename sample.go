@@ -0,0 +1,72 @@
+package gwu
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// SampleLogs logs Debug and Info records only for the given fraction of requests, use it for high-traffic routes.
+// The rate must be between 0, logging none, and 1, logging all requests. Warn and Error records of a LeveledLogger are
+// always logged.
+//
+// SampleLogs decides once per request, so all records of a sampled request are logged together.
+func SampleLogs(rate float64) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		validRate(opt, rate)
+		opt.sampler = &sampler{rate: rate, float64: rand.Float64}
+	}
+}
+
+// SampleLogsFrom works like SampleLogs, but draws the sampling decisions from the given source.
+// Use a seeded source to make the decisions deterministic in tests.
+func SampleLogsFrom(rate float64, src rand.Source) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		validRate(opt, rate)
+		var mu sync.Mutex
+		rnd := rand.New(src)
+		opt.sampler = &sampler{rate: rate, float64: func() float64 {
+			mu.Lock()
+			defer mu.Unlock()
+
+			return rnd.Float64()
+		}}
+	}
+}
+
+// validRate records an invalid sampling rate.
+func validRate(opt *HandleOpts, rate float64) {
+	if rate < 0 || rate > 1 {
+		opt.invalid("SampleLogs: rate %v is not between 0 and 1", rate)
+	}
+}
+
+// sampler decides whether a request's logs are sampled.
+type sampler struct {
+	rate    float64
+	float64 func() float64
+}
+
+// sample returns the logger to use for a request, it drops Debug and Info records for requests not sampled.
+func (s *sampler) sample(log Logger) Logger {
+	if s == nil || s.float64() < s.rate {
+		return log
+	}
+
+	return warnOnlyLogger{log: log}
+}
+
+// warnOnlyLogger drops Debug and Info records and passes Warn and Error records on.
+type warnOnlyLogger struct {
+	log Logger
+}
+
+func (warnOnlyLogger) Debug(string, ...any) {}
+func (warnOnlyLogger) Info(string, ...any)  {}
+
+func (l warnOnlyLogger) Warn(msg string, args ...any) {
+	logWarn(l.log, msg, args...)
+}
+
+func (l warnOnlyLogger) Error(msg string, args ...any) {
+	logError(l.log, msg, args...)
+}
@@ -0,0 +1,103 @@
+package gwu_test
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// logsAll logs a record of every level for request n.
+func logsAll(_ context.Context, n int, opts gwu.HandleOpts) (gwu.NoBody, int, error) {
+	opts.Log.Debug("debug", "n", n)
+	opts.Log.Info("info", "n", n)
+
+	log := opts.Log.(gwu.LeveledLogger)
+	log.Warn("warn", "n", n)
+	log.Error("error", "n", n)
+
+	return gwu.NoBody{}, http.StatusNoContent, nil
+}
+
+// sampled serves the requests and returns which of them logged the Debug record, it fails the test if a request
+// logged only part of its records.
+func sampled(t *testing.T, rate float64, src rand.Source, requests int) []bool {
+	t.Helper()
+
+	log := gwutest.Logger()
+	n := 0
+	in := func(*http.Request, gwu.HandleOpts) (int, error) {
+		n++
+		return n, nil
+	}
+
+	h := gwu.Handle(in, logsAll, gwu.Log(log), gwu.SampleLogsFrom(rate, src))
+	for range requests {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	got := make([]bool, requests)
+	for i := range got {
+		req := int64(i + 1)
+		debug, info := log.Logged(slog.LevelDebug, "debug", "n", req), log.Logged(slog.LevelInfo, "info", "n", req)
+		if debug != info {
+			t.Errorf("request %d: debug logged %v, info logged %v, want both or neither", req, debug, info)
+		}
+
+		if !log.Logged(slog.LevelWarn, "warn", "n", req) || !log.Logged(slog.LevelError, "error", "n", req) {
+			t.Errorf("request %d: want the warn and error records logged", req)
+		}
+
+		got[i] = debug
+	}
+
+	return got
+}
+
+func TestSampleLogs(t *testing.T) {
+	const requests = 400
+
+	got := sampled(t, 0.25, rand.NewPCG(1, 2), requests)
+
+	var kept int
+	for _, s := range got {
+		if s {
+			kept++
+		}
+	}
+
+	if kept < requests/8 || kept > requests*3/8 {
+		t.Errorf("%d of %d requests sampled, want about a quarter", kept, requests)
+	}
+
+	// The same seed makes the same decisions.
+	again := sampled(t, 0.25, rand.NewPCG(1, 2), requests)
+	for i := range got {
+		if got[i] != again[i] {
+			t.Fatalf("request %d: sampled %v, then %v with the same seed", i+1, got[i], again[i])
+		}
+	}
+}
+
+func TestSampleLogsBounds(t *testing.T) {
+	for _, rate := range []float64{0, 1} {
+		for i, s := range sampled(t, rate, rand.NewPCG(1, 2), 50) {
+			if s != (rate == 1) {
+				t.Errorf("rate %v: request %d sampled %v", rate, i+1, s)
+			}
+		}
+	}
+
+	for _, rate := range []float64{-0.1, 1.5} {
+		_, err := gwu.TryHandle(gwu.Empty(), noContent, gwu.SampleLogs(rate))
+		if err == nil || !strings.Contains(err.Error(), "is not between 0 and 1") {
+			t.Errorf("rate %v: error %v, want the invalid rate", rate, err)
+		}
+	}
+}
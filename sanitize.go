@@ -0,0 +1,313 @@
+package gwu
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// HTMLPolicy sanitizes the strings of fields tagged with `sanitize:"html"`, see Sanitize. StripTags implements it,
+// adapt a library like bluemonday to use its policies. A returned error must be safe to display to the client.
+type HTMLPolicy interface {
+	Sanitize(s string) (string, error)
+}
+
+// HTMLPolicyFunc adapts a function to an HTMLPolicy.
+type HTMLPolicyFunc func(s string) (string, error)
+
+func (f HTMLPolicyFunc) Sanitize(s string) (string, error) {
+	return f(s)
+}
+
+// StripTags returns the conservative HTMLPolicy removing all tags, comments, and the content of script and style
+// elements, so event handler attributes are removed with their tags. Text without tags passes unchanged, including
+// a "<" that does not start a tag, like in "a < b", and entities.
+func StripTags() HTMLPolicy {
+	return HTMLPolicyFunc(func(s string) (string, error) {
+		return stripTags(s), nil
+	})
+}
+
+// stripTags removes the tags of s, see StripTags.
+func stripTags(s string) string {
+	if !strings.Contains(s, "<") {
+		return s
+	}
+
+	var b strings.Builder
+	for {
+		i := tagStart(s)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+
+		b.WriteString(s[:i])
+		s = s[i:]
+
+		if strings.HasPrefix(s, "<!--") {
+			_, s, _ = strings.Cut(s[4:], "-->")
+			continue
+		}
+
+		name := tagName(s)
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return b.String()
+		}
+
+		s = s[end+1:]
+
+		// The content of script and style elements is no text, drop it up to the closing tag.
+		if name == "script" || name == "style" {
+			end := strings.Index(strings.ToLower(s), "</"+name)
+			if end < 0 {
+				return b.String()
+			}
+
+			s = s[end:]
+		}
+	}
+}
+
+// tagStart returns the index of the first "<" that starts a tag, a closing tag, a comment, or a declaration.
+func tagStart(s string) int {
+	for i := 0; i < len(s)-1; i++ {
+		if s[i] != '<' {
+			continue
+		}
+
+		c := s[i+1]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '/' || c == '!' || c == '?' {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// tagName returns the lowercase name of the opening tag at the start of s.
+func tagName(s string) string {
+	s = s[1:]
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+
+	if end >= 0 {
+		s = s[:end]
+	}
+
+	return strings.ToLower(s)
+}
+
+// Sanitize CnIn runs the policy on the fields tagged with `sanitize:"html"` of the input constructed by the given
+// CnIn, and replaces them with the sanitized strings. Tagged fields are strings, pointers to strings, or slices of
+// strings, Sanitize also sanitizes the fields of nested structs, pointers to structs, and slices of structs.
+// Fields without the tag are untouched.
+//
+// A policy error is returned as ValidationError naming the field by its JSON name, Handle responds to it with the
+// ValidationErrorStatus, http.StatusBadRequest by default. Sanitize panics if a type has an invalid sanitize tag.
+//
+// Example usage:
+//
+//	type NewPoem struct {
+//		Title string `json:"title" sanitize:"html"`
+//		Text  string `json:"text" sanitize:"html"`
+//	}
+//
+//	gwu.Post(rt, "/poem", gwu.Sanitize(gwu.JSON[NewPoem](), gwu.StripTags()), ctrl.Create)
+func Sanitize[In any](inFn CnIn[In], policy HTMLPolicy) CnIn[In] {
+	if err := checkSanitizeTags(reflect.TypeFor[In](), make(map[reflect.Type]bool)); err != nil {
+		panic(err)
+	}
+
+	return func(r *http.Request, opts HandleOpts) (In, error) {
+		in, err := inFn(r, opts)
+		if err != nil {
+			return in, err
+		}
+
+		if err := sanitizeValue(reflect.ValueOf(&in).Elem(), policy, ""); err != nil {
+			return in, &ValidationError{Err: err}
+		}
+
+		return in, nil
+	}
+}
+
+// sanitizeField is a field visited by Sanitize, either a tagged string field or a field that may contain some.
+type sanitizeField struct {
+	index  int
+	name   string
+	tagged bool
+}
+
+// sanitizePlans caches the fields of every struct type Sanitize visits, keyed by reflect.Type.
+var sanitizePlans sync.Map
+
+// sanitizeFields returns the fields of the struct type Sanitize visits.
+func sanitizeFields(t reflect.Type) ([]sanitizeField, error) {
+	if v, ok := sanitizePlans.Load(t); ok {
+		return v.([]sanitizeField), nil
+	}
+
+	names := make(map[int]string)
+	for _, f := range jsonFields(t) {
+		if len(f.index) == 1 {
+			names[f.index[0]] = f.name
+		}
+	}
+
+	var fields []sanitizeField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := names[i]
+		if name == "" {
+			name = sf.Name
+		}
+
+		tag, ok := sf.Tag.Lookup("sanitize")
+		switch {
+		case ok && tag != "html":
+			return nil, fmt.Errorf("gwu: sanitize %s: field %s: unknown sanitize tag %q", t, sf.Name, tag)
+		case ok && !isStrings(sf.Type):
+			return nil, fmt.Errorf("gwu: sanitize %s: field %s: %s is no string, *string, or []string", t, sf.Name,
+				sf.Type)
+		case ok:
+			fields = append(fields, sanitizeField{index: i, name: name, tagged: true})
+		case containsStructs(sf.Type):
+			fields = append(fields, sanitizeField{index: i, name: name})
+		}
+	}
+
+	v, _ := sanitizePlans.LoadOrStore(t, fields)
+	return v.([]sanitizeField), nil
+}
+
+// checkSanitizeTags returns the first invalid sanitize tag of the type and the types it contains.
+func checkSanitizeTags(t reflect.Type, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+
+	seen[t] = true
+
+	fields, err := sanitizeFields(t)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if err := checkSanitizeTags(t.Field(f.index).Type, seen); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isStrings reports whether the type is a string, *string, or []string.
+func isStrings(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.String
+}
+
+// containsStructs reports whether the type is a struct, a pointer to a struct, or a slice of them.
+func containsStructs(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct
+}
+
+// sanitizeValue sanitizes the tagged fields of the value, path is the name of the value for errors.
+func sanitizeValue(v reflect.Value, policy HTMLPolicy, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+
+		return sanitizeValue(v.Elem(), policy, path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := sanitizeValue(v.Index(i), policy, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	fields, err := sanitizeFields(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		name := f.name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		fv := v.Field(f.index)
+		if !f.tagged {
+			if err := sanitizeValue(fv, policy, name); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := sanitizeStrings(fv, policy, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sanitizeStrings runs the policy on the string, *string, or []string value.
+func sanitizeStrings(v reflect.Value, policy HTMLPolicy, name string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+
+		return sanitizeStrings(v.Elem(), policy, name)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := sanitizeStrings(v.Index(i), policy, fmt.Sprintf("%s[%d]", name, i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	s, err := policy.Sanitize(v.String())
+	if err != nil {
+		return fmt.Errorf("field %q: %w", name, err)
+	}
+
+	v.SetString(s)
+	return nil
+}
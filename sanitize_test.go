@@ -0,0 +1,127 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestStripTags(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"benign text", "Season of mists and mellow fruitfulness", "Season of mists and mellow fruitfulness"},
+		{"less than", "a < b and b<3", "a < b and b<3"},
+		{"entities", "Keats &amp; Shelley &lt;3", "Keats &amp; Shelley &lt;3"},
+		{"unicode", "Ode an die Freude – Schönheit", "Ode an die Freude – Schönheit"},
+		{"formatting", "<b>Ode</b> to <i>Autumn</i>", "Ode to Autumn"},
+		{"script", `Ode<script>alert("x")</script> to Autumn`, "Ode to Autumn"},
+		{"script upper case", `Ode<SCRIPT type="text/javascript">alert(1)</SCRIPT>!`, "Ode!"},
+		{"style", "<style>body{display:none}</style>Ode", "Ode"},
+		{"event handler", `<img src=x onerror="alert(1)">Ode`, "Ode"},
+		{"event handler on text", `<p onclick="steal()">Thou still unravish'd bride</p>`, "Thou still unravish'd bride"},
+		{"comment", "Ode<!-- <script>alert(1)</script> -->!", "Ode!"},
+		{"closing tag", "Ode</p>", "Ode"},
+		{"declaration", "<!DOCTYPE html>Ode", "Ode"},
+		{"unterminated tag", "Ode<img src=x onerror=alert(1)", "Ode"},
+		{"unterminated script", "Ode<script>alert(1)", "Ode"},
+	}
+
+	policy := gwu.StripTags()
+	for _, tt := range tests {
+		got, err := policy.Sanitize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+type sanitizedLine struct {
+	Text string `json:"text" sanitize:"html"`
+	Note string `json:"note"`
+}
+
+type sanitizedPoem struct {
+	Title  string          `json:"title" sanitize:"html"`
+	Author *string         `json:"author" sanitize:"html"`
+	Tags   []string        `json:"tags" sanitize:"html"`
+	Raw    string          `json:"raw"`
+	Lines  []sanitizedLine `json:"lines"`
+	First  *sanitizedLine  `json:"first"`
+}
+
+func echoSanitized(_ context.Context, p sanitizedPoem, _ gwu.HandleOpts) (sanitizedPoem, int, error) {
+	return p, http.StatusOK, nil
+}
+
+func TestSanitize(t *testing.T) {
+	h := gwu.Handle(gwu.Sanitize(gwu.JSON[sanitizedPoem](), gwu.StripTags()), echoSanitized)
+
+	author := "<b>John</b> Keats"
+	got, _ := gwutest.Do[sanitizedPoem, sanitizedPoem](t, h, http.MethodPost, "/poems", sanitizedPoem{
+		Title:  "<script>alert(1)</script>Ode",
+		Author: &author,
+		Tags:   []string{"<i>odes</i>", "autumn"},
+		Raw:    "<b>untouched</b>",
+		Lines:  []sanitizedLine{{Text: `<img onerror="x()">Season of mists`, Note: "<em>kept</em>"}},
+		First:  &sanitizedLine{Text: "<p>Season</p>", Note: "<p>kept</p>"},
+	}, gwutest.Status(http.StatusOK))
+
+	// Fields without the tag are untouched.
+	if got.Title != "Ode" || got.Author == nil || *got.Author != "John Keats" ||
+		strings.Join(got.Tags, ",") != "odes,autumn" || got.Raw != "<b>untouched</b>" ||
+		got.Lines[0] != (sanitizedLine{Text: "Season of mists", Note: "<em>kept</em>"}) ||
+		*got.First != (sanitizedLine{Text: "Season", Note: "<p>kept</p>"}) {
+		t.Errorf("sanitized %+v, want the tagged fields stripped", got)
+	}
+}
+
+// TestSanitizePolicyError responds to a policy error with 400 naming the field.
+func TestSanitizePolicyError(t *testing.T) {
+	reject := gwu.HTMLPolicyFunc(func(s string) (string, error) {
+		if strings.Contains(s, "<") {
+			return "", errors.New("contains HTML")
+		}
+
+		return s, nil
+	})
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/poems", strings.NewReader(
+		`{"title": "Ode", "raw": "<b>fine</b>", "lines": [{"text": "Thou"}, {"text": "<b>bold</b>"}]}`))
+	r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+	gwu.Handle(gwu.Sanitize(gwu.JSON[sanitizedPoem](), reject), echoSanitized).ServeHTTP(rec, r)
+
+	gwutest.AssertError(t, rec, http.StatusBadRequest, `field "lines[1].text": contains HTML`)
+}
+
+func TestSanitizeInvalidTag(t *testing.T) {
+	type bad struct {
+		Count int `sanitize:"html"`
+	}
+
+	type unknown struct {
+		Text string `sanitize:"sql"`
+	}
+
+	for name, fn := range map[string]func(){
+		"not a string": func() { gwu.Sanitize(gwu.JSON[bad](), gwu.StripTags()) },
+		"unknown tag":  func() { gwu.Sanitize(gwu.JSON[[]unknown](), gwu.StripTags()) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: Sanitize did not panic", name)
+				}
+			}()
+
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,301 @@
+package gwu
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is an OpenAPI 3 schema object, see Spec.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+
+	// pattern is the compiled Pattern of a schema compiled by JSONSchema.
+	pattern *regexp.Regexp
+	// closed rejects properties not in Properties, JSONSchema sets it for "additionalProperties": false.
+	closed bool
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schemas derives schemas from Go types by reflection over their json tags.
+// Named struct types become components, referenced with $ref.
+type schemas struct {
+	components map[string]*Schema
+	names      map[componentKey]string
+	// naming names the fields of the schemas derived next, see FieldNaming.
+	naming *fieldNaming
+}
+
+// componentKey identifies the component of a named struct type with the field names of a FieldNaming, see
+// fieldNaming.signature.
+type componentKey struct {
+	t         reflect.Type
+	signature string
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: make(map[string]*Schema), names: make(map[componentKey]string)}
+}
+
+// of returns the schema for the type.
+func (s *schemas) of(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		schema := *s.of(t.Elem())
+		if schema.Ref != "" {
+			// $ref siblings are ignored in OpenAPI 3.0, so nullable is lost for references.
+			return &schema
+		}
+
+		schema.Nullable = true
+		return &schema
+	}
+
+	if elem := optElem(t); elem != nil {
+		return s.of(reflect.PointerTo(elem))
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		// encoding/json encodes nil slices and maps as null.
+		return &Schema{Type: "array", Items: s.of(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		return s.ofStruct(t)
+	default:
+		// Interfaces, and types that cannot be encoded as JSON, allow any value.
+		return &Schema{}
+	}
+}
+
+// ofStruct returns the schema of a struct, named structs are referenced as components.
+func (s *schemas) ofStruct(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return s.object(t)
+	}
+
+	key := componentKey{t: t, signature: s.naming.signature(t)}
+	name, ok := s.names[key]
+	if !ok {
+		name = s.name(t)
+		s.names[key] = name
+		s.components[name] = &Schema{}
+		*s.components[name] = *s.object(t)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// object returns the object schema of the struct's fields.
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, f := range jsonFields(t) {
+		fs := s.of(f.typ)
+		if f.asString {
+			fs = &Schema{Type: "string"}
+		}
+
+		name := s.naming.name(f)
+		schema.Properties[name] = fs
+		if !f.omitEmpty && optElem(f.typ) == nil {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// name returns a unique component name for the named type.
+func (s *schemas) name(t reflect.Type) string {
+	base := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-' {
+			return r
+		}
+
+		return '_'
+	}, t.Name())
+
+	name := base
+	for i := 2; s.components[name] != nil; i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	return name
+}
+
+// jsonField is a struct field as encoding/json encodes it.
+type jsonField struct {
+	name      string
+	goName    string
+	typ       reflect.Type
+	index     []int
+	tagged    bool
+	omitEmpty bool
+	asString  bool
+}
+
+// jsonFields returns the fields of the struct as encoding/json encodes them, including promoted fields of
+// embedded structs without a json name.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(tag, ",")
+			ft := f.Type
+			if f.Anonymous && name == "" {
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+
+				if ft.Kind() == reflect.Struct {
+					walk(ft, append(index[:len(index):len(index)], i))
+					continue
+				}
+			}
+
+			if !f.IsExported() {
+				continue
+			}
+
+			field := jsonField{
+				name:      name,
+				goName:    f.Name,
+				typ:       f.Type,
+				index:     append(index[:len(index):len(index)], i),
+				tagged:    name != "",
+				omitEmpty: hasOpt(opts, "omitempty") || hasOpt(opts, "omitzero"),
+				asString:  hasOpt(opts, "string"),
+			}
+
+			if field.name == "" {
+				field.name = f.Name
+			}
+
+			fields = append(fields, field)
+		}
+	}
+
+	walk(t, nil)
+
+	// Like encoding/json, the shallowest field of a name wins, a tagged one among equally shallow fields.
+	// Equally shallow fields without a single tagged one cancel each other out.
+	byName := make(map[string][]jsonField)
+	for _, f := range fields {
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	dominant := fields[:0:0]
+	for _, f := range fields {
+		if winner, ok := dominantField(byName[f.name]); ok && slices.Equal(winner.index, f.index) {
+			dominant = append(dominant, f)
+		}
+	}
+
+	return dominant
+}
+
+// dominantField returns the field encoding/json encodes among fields with the same name.
+func dominantField(fields []jsonField) (jsonField, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields {
+		depth = min(depth, len(f.index))
+	}
+
+	var candidates []jsonField
+	tagged := 0
+	for _, f := range fields {
+		if len(f.index) == depth {
+			candidates = append(candidates, f)
+			if f.tagged {
+				tagged++
+			}
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+
+	if tagged == 1 {
+		for _, f := range candidates {
+			if f.tagged {
+				return f, true
+			}
+		}
+	}
+
+	return jsonField{}, false
+}
+
+func hasOpt(opts, opt string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,285 @@
+package gwu
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaError is a value that does not match a Schema, see Schema.Validate.
+type SchemaError struct {
+	// Path is the JSON path of the value, like $.lines[0].
+	Path string
+	// Pointer is the JSON pointer of the value, like /lines/0, it is empty for the root value.
+	Pointer string
+	Msg     string
+}
+
+func (e *SchemaError) Error() string {
+	return e.Path + ": " + e.Msg
+}
+
+// Validate validates a decoded JSON value against the schema, and returns all mismatches as *SchemaError joined
+// with errors.Join. It resolves references with the components of a Spec, keyed by name.
+//
+// Validate supports the subset of JSON Schema a Spec generates: types, formats of integers, nullable, required
+// properties, additional properties, items, and enums. Because OpenAPI 3.0 cannot mark references as nullable,
+// null matches every reference. It also validates minimum, maximum, minLength, maxLength, minItems, maxItems, and
+// pattern.
+func (s *Schema) Validate(v any, components map[string]*Schema) error {
+	var errs []*SchemaError
+	s.validate(v, valuePath{path: "$"}, components, &errs)
+
+	joined := make([]error, len(errs))
+	for i, err := range errs {
+		joined[i] = err
+	}
+
+	return errors.Join(joined...)
+}
+
+// valuePath is the location of a validated value, as JSON path and JSON pointer.
+type valuePath struct {
+	path, pointer string
+}
+
+func (p valuePath) property(name string) valuePath {
+	return valuePath{
+		path:    p.path + "." + name,
+		pointer: p.pointer + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(name),
+	}
+}
+
+func (p valuePath) index(i int) valuePath {
+	return valuePath{path: p.path + "[" + strconv.Itoa(i) + "]", pointer: p.pointer + "/" + strconv.Itoa(i)}
+}
+
+func (s *Schema) validate(v any, at valuePath, components map[string]*Schema, errs *[]*SchemaError) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, &SchemaError{Path: at.path, Pointer: at.pointer, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Ref != "" {
+		if v == nil {
+			return
+		}
+
+		ref, ok := components[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+		if !ok {
+			fail("unknown reference %s", s.Ref)
+			return
+		}
+
+		ref.validate(v, at, components, errs)
+		return
+	}
+
+	if v == nil {
+		if !s.Nullable && s.Type != "" {
+			fail("null, want %s", s.Type)
+		}
+
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(v, s.Enum) {
+		fail("%v is none of %v", v, s.Enum)
+	}
+
+	switch s.Type {
+	case "":
+	case "string", "boolean", "number", "array", "object":
+		if jsonType(v) != s.Type {
+			fail("%s, want %s", jsonType(v), s.Type)
+			return
+		}
+	case "integer":
+		if jsonType(v) != "number" {
+			fail("%s, want integer", jsonType(v))
+			return
+		} else if !isInteger(v) {
+			fail("%v, want integer", v)
+		}
+	}
+
+	// Like in JSON Schema, the keywords of a type apply to the values of that type, with or without a type.
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("length %d, want at least %d", n, *s.MinLength)
+		}
+
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("length %d, want at most %d", n, *s.MaxLength)
+		}
+
+		if s.Pattern != "" {
+			re := s.pattern
+			if re == nil {
+				var err error
+				if re, err = regexp.Compile(s.Pattern); err != nil {
+					fail("invalid pattern %q: %v", s.Pattern, err)
+					return
+				}
+			}
+
+			if !re.MatchString(v) {
+				fail("%q does not match the pattern %q", v, s.Pattern)
+			}
+		}
+	case float64, json.Number:
+		n, ok := toFloat(v)
+		if !ok {
+			return
+		}
+
+		if s.Minimum != nil && n < *s.Minimum {
+			fail("%v, want at least %v", v, *s.Minimum)
+		}
+
+		if s.Maximum != nil && n > *s.Maximum {
+			fail("%v, want at most %v", v, *s.Maximum)
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("%d items, want at least %d", len(v), *s.MinItems)
+		}
+
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("%d items, want at most %d", len(v), *s.MaxItems)
+		}
+
+		if s.Items != nil {
+			for i, e := range v {
+				s.Items.validate(e, at.index(i), components, errs)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				p := at.property(name)
+				*errs = append(*errs, &SchemaError{Path: p.path, Pointer: p.pointer, Msg: "required property is missing"})
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+
+		slices.Sort(names)
+		for _, name := range names {
+			e := v[name]
+			if prop, ok := s.Properties[name]; ok {
+				prop.validate(e, at.property(name), components, errs)
+			} else if s.closed {
+				p := at.property(name)
+				*errs = append(*errs, &SchemaError{Path: p.path, Pointer: p.pointer, Msg: "unknown property"})
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(e, at.property(name), components, errs)
+			}
+		}
+	}
+}
+
+// toFloat returns the float64 of a decoded JSON number.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// jsonType returns the JSON type of a decoded JSON value.
+func jsonType(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// isInteger reports whether a decoded JSON value is an integer number, like 1, 1.0, or 1e2.
+func isInteger(v any) bool {
+	switch n := v.(type) {
+	case float64:
+		return n == math.Trunc(n) && !math.IsInf(n, 0)
+	case json.Number:
+		r, ok := new(big.Rat).SetString(string(n))
+		return ok && r.IsInt()
+	default:
+		return false
+	}
+}
+
+// toRat returns the exact value of a decoded JSON number.
+func toRat(v any) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case float64:
+		if math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, false
+		}
+
+		return new(big.Rat).SetFloat64(n), true
+	case json.Number:
+		return new(big.Rat).SetString(string(n))
+	default:
+		return nil, false
+	}
+}
+
+// inEnum reports whether the value equals one of the enum values, see jsonEqual.
+func inEnum(v any, enum []any) bool {
+	return slices.ContainsFunc(enum, func(e any) bool { return jsonEqual(v, e) })
+}
+
+// jsonEqual reports whether the decoded JSON values are equal, numbers are equal by their value, so 1, 1.0, and
+// 1e0 are equal.
+func jsonEqual(a, b any) bool {
+	switch a := a.(type) {
+	case float64, json.Number:
+		ra, ok := toRat(a)
+		rb, okB := toRat(b)
+		return ok && okB && ra.Cmp(rb) == 0
+	case []any:
+		b, ok := b.([]any)
+		return ok && slices.EqualFunc(a, b, jsonEqual)
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for k, e := range a {
+			if eb, ok := b[k]; !ok || !jsonEqual(e, eb) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,108 @@
+package gwu
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SecurityConfig are the security headers set by SecurityHeaders and SecurityMiddleware. An empty field sets the
+// default for a JSON API, the value "-" omits the header.
+type SecurityConfig struct {
+	// ContentTypeOptions is the X-Content-Type-Options header, defaults to "nosniff".
+	ContentTypeOptions string
+	// ReferrerPolicy is the Referrer-Policy header, defaults to "no-referrer".
+	ReferrerPolicy string
+	// FrameOptions is the X-Frame-Options header, defaults to "DENY".
+	FrameOptions string
+	// ContentSecurityPolicy is the Content-Security-Policy header, defaults to "default-src 'none'; frame-ancestors
+	// 'none'", which denies framing like FrameOptions for browsers that support it.
+	ContentSecurityPolicy string
+	// HSTS sets the Strict-Transport-Security header, nil omits it. Browsers remember it for its MaxAge, so it is
+	// only set on explicit opt-in.
+	HSTS *HSTS
+}
+
+// HSTS is the Strict-Transport-Security header of a SecurityConfig.
+type HSTS struct {
+	// MaxAge is how long browsers only connect with HTTPS, a zero MaxAge clears a previous header.
+	MaxAge            time.Duration
+	IncludeSubDomains bool
+	// Preload asks for the inclusion in the browsers' preload lists, which is hard to revert, see https://hstspreload.org.
+	Preload bool
+}
+
+func (h HSTS) String() string {
+	v := "max-age=" + strconv.FormatInt(int64(h.MaxAge/time.Second), 10)
+	if h.IncludeSubDomains {
+		v += "; includeSubDomains"
+	}
+
+	if h.Preload {
+		v += "; preload"
+	}
+
+	return v
+}
+
+// header returns the headers of the config, omitted headers have no values. It skips empty fields unless defaults
+// is true.
+func (c SecurityConfig) header(defaults bool) http.Header {
+	h := make(http.Header, 5)
+	set := func(key, v, def string) {
+		switch {
+		case v == "-":
+			h[key] = nil
+		case v != "":
+			h[key] = []string{v}
+		case defaults:
+			h[key] = []string{def}
+		}
+	}
+
+	set("X-Content-Type-Options", c.ContentTypeOptions, "nosniff")
+	set("Referrer-Policy", c.ReferrerPolicy, "no-referrer")
+	set("X-Frame-Options", c.FrameOptions, "DENY")
+	set("Content-Security-Policy", c.ContentSecurityPolicy, "default-src 'none'; frame-ancestors 'none'")
+
+	if c.HSTS != nil {
+		h.Set("Strict-Transport-Security", c.HSTS.String())
+	}
+
+	return h
+}
+
+// SecurityHeaders sets the security headers of the config on every response of the handler, including error
+// responses, like StaticHeaders. Omitted headers are removed from the response, also if SecurityMiddleware set them.
+//
+// Applying SecurityHeaders again, e.g. for a route of a Router with SecurityHeaders, only changes the headers of the
+// non-empty fields, so a route can change or omit single headers. Drop an inherited HSTS header with
+// StaticHeaders(http.Header{"Strict-Transport-Security": nil}).
+//
+// Example usage:
+//
+//	rt := gwu.NewRouter(gwu.SecurityHeaders(gwu.SecurityConfig{HSTS: &gwu.HSTS{MaxAge: 365 * 24 * time.Hour}}))
+//	gwu.Get(rt, "/embed", gwu.Empty(), ctrl.Embed, gwu.SecurityHeaders(gwu.SecurityConfig{FrameOptions: "-"}))
+func SecurityHeaders(cfg SecurityConfig) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		StaticHeaders(cfg.header(!opt.securityHeaders))(opt)
+		opt.securityHeaders = true
+	}
+}
+
+// SecurityMiddleware sets the security headers of the config on every response of the handler, use it to cover
+// responses not written by gwu handlers, like the 404 of an http.ServeMux. Handlers can change or drop single headers
+// with SecurityHeaders.
+//
+// Example usage:
+//
+//	http.ListenAndServe(":8080", gwu.SecurityMiddleware(gwu.SecurityConfig{})(mux))
+func SecurityMiddleware(cfg SecurityConfig) func(http.Handler) http.Handler {
+	header := cfg.header(true)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			setHeaders(w.Header(), header)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
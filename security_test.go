@@ -0,0 +1,125 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+)
+
+var apiSecurityHeaders = http.Header{
+	"X-Content-Type-Options":    {"nosniff"},
+	"Referrer-Policy":           {"no-referrer"},
+	"X-Frame-Options":           {"DENY"},
+	"Content-Security-Policy":   {"default-src 'none'; frame-ancestors 'none'"},
+	"Strict-Transport-Security": {"max-age=31536000; includeSubDomains"},
+}
+
+// assertSecurityHeaders fails the test if the response does not have exactly the security headers.
+func assertSecurityHeaders(t *testing.T, rec *httptest.ResponseRecorder, want http.Header) {
+	t.Helper()
+
+	for key := range apiSecurityHeaders {
+		if got := rec.Header().Values(key); !slices.Equal(got, want.Values(key)) {
+			t.Errorf("%d: %s %q, want %q", rec.Code, key, got, want.Values(key))
+		}
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	poem := func(_ context.Context, id string, _ gwu.HandleOpts) (string, int, error) {
+		switch id {
+		case "7":
+			return "Ode", http.StatusOK, nil
+		case "500":
+			return "", http.StatusInternalServerError, errors.New("database down")
+		case "abort":
+			panic(gwu.Abort{Status: http.StatusInternalServerError, Err: errors.New("poem exploded")})
+		}
+
+		return "", http.StatusNotFound, errPoemNotFound
+	}
+
+	cfg := gwu.SecurityConfig{HSTS: &gwu.HSTS{MaxAge: 365 * 24 * time.Hour, IncludeSubDomains: true}}
+	rt := gwu.NewRouter(gwu.SecurityHeaders(cfg))
+	gwu.HandleRoute(rt, "GET /poems/{id}", gwu.PathVal("id"), poem)
+
+	// The responses of the Router to unmatched requests carry the headers too.
+	for _, tt := range []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, "/poems/7", http.StatusOK},
+		{http.MethodGet, "/poems/8", http.StatusNotFound},
+		{http.MethodGet, "/poems/500", http.StatusInternalServerError},
+		{http.MethodGet, "/poems/abort", http.StatusInternalServerError},
+		{http.MethodGet, "/songs", http.StatusNotFound},
+		{http.MethodPost, "/poems/7", http.StatusMethodNotAllowed},
+	} {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, rec.Code, tt.status)
+		}
+
+		assertSecurityHeaders(t, rec, apiSecurityHeaders)
+	}
+}
+
+// TestSecurityHeadersOverride changes and drops single headers of the Router for a route.
+func TestSecurityHeadersOverride(t *testing.T) {
+	rt := gwu.NewRouter(gwu.SecurityHeaders(gwu.SecurityConfig{HSTS: &gwu.HSTS{MaxAge: time.Hour}}))
+	gwu.HandleRoute(rt, "GET /embed", gwu.Empty(), noContent,
+		gwu.SecurityHeaders(gwu.SecurityConfig{FrameOptions: "-", ReferrerPolicy: "same-origin"}),
+		gwu.StaticHeaders(http.Header{"Strict-Transport-Security": nil}))
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/embed", nil))
+
+	assertSecurityHeaders(t, rec, http.Header{
+		"X-Content-Type-Options":  {"nosniff"},
+		"Referrer-Policy":         {"same-origin"},
+		"Content-Security-Policy": {"default-src 'none'; frame-ancestors 'none'"},
+	})
+}
+
+// TestSecurityMiddleware sets the headers on responses not written by gwu handlers, handlers can drop them.
+func TestSecurityMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	gwu.HandleRoute(mux, "GET /poems", gwu.Empty(), noContent)
+	gwu.HandleRoute(mux, "GET /embed", gwu.Empty(), noContent, gwu.SecurityHeaders(gwu.SecurityConfig{
+		FrameOptions: "-",
+	}))
+
+	cfg := gwu.SecurityConfig{HSTS: &gwu.HSTS{MaxAge: 365 * 24 * time.Hour, IncludeSubDomains: true}}
+	h := gwu.SecurityMiddleware(cfg)(mux)
+
+	for _, path := range []string{"/poems", "/unknown"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assertSecurityHeaders(t, rec, apiSecurityHeaders)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/embed", nil))
+	want := apiSecurityHeaders.Clone()
+	want.Del("X-Frame-Options")
+	assertSecurityHeaders(t, rec, want)
+}
+
+func TestHSTS(t *testing.T) {
+	for hsts, want := range map[gwu.HSTS]string{
+		{}:                         "max-age=0",
+		{MaxAge: 90 * time.Second}: "max-age=90",
+		{MaxAge: time.Hour, IncludeSubDomains: true, Preload: true}: "max-age=3600; includeSubDomains; preload",
+	} {
+		if got := hsts.String(); got != want {
+			t.Errorf("%+v: %q, want %q", hsts, got, want)
+		}
+	}
+}
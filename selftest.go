@@ -0,0 +1,281 @@
+package gwu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// selfTestTimeout is the time a route may take to respond to SelfTest.
+const selfTestTimeout = time.Second
+
+// selfTestCtxKey is the context key marking requests of SelfTest.
+type selfTestCtxKey struct{}
+
+// IsSelfTest reports whether the context is of a request made by SelfTest, skip side effects like writes to the
+// database or sending mails in the Exec then.
+func IsSelfTest(ctx context.Context) bool {
+	v, _ := ctx.Value(selfTestCtxKey{}).(bool)
+	return v
+}
+
+// Example sets the example input SelfTest requests the route with, see SelfTest. The example must be of the route's
+// input type.
+//
+// Example usage:
+//
+//	gwu.Post(rt, "/poems", gwu.JSON[Poem](), ctrl.Create, gwu.Example(Poem{Title: "Ode", Text: "..."}))
+func Example[In any](in In) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.example = in
+	}
+}
+
+// checkExample records an invalid option value if the example of Example is not of the input type In.
+func checkExample[In any](opts *HandleOpts) {
+	if opts.example == nil {
+		return
+	}
+
+	if _, ok := opts.example.(In); !ok {
+		opts.invalid("Example: %T is not of the input type %s", opts.example, reflect.TypeFor[In]())
+	}
+}
+
+// SelfTest requests every route registered with the Router, including the routes of its groups and hosts, once in
+// process and reports the routes that panic or respond with a 5xx status code, like for a missing template. Call it
+// in main before serving requests. It also reports the routes whose path CnIns, like PathVal and Bind, read a key
+// for which the route's pattern has no wildcard.
+//
+// The requests fill the wildcards of the patterns with "0" and carry the example of the route as JSON body, see
+// Example, or the zero value of the route's input type. Their context is marked, so Execs can skip side effects,
+// see IsSelfTest. The Before hooks of the routes, like RateLimit, are skipped, so SelfTest does not use up the
+// budgets of clients. Routes marked with LongLived, like streams, are skipped.
+//
+// The routes are requested concurrently, each must respond within a second of the Router's Clock. The context of
+// a route that did not is canceled, and SelfTest waits for it to return until ctx is done, so no request of SelfTest
+// outlives it.
+//
+// Example usage:
+//
+//	if err := gwu.SelfTest(ctx, rt); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	log.Fatal(http.ListenAndServe(":8080", rt))
+func SelfTest(ctx context.Context, rt *Router) error {
+	ctx = context.WithValue(ctx, selfTestCtxKey{}, true)
+
+	routes := rt.Routes()
+	errs := make([]error, len(routes))
+	clock := selfTestClock(rt)
+
+	var wg sync.WaitGroup
+	for i, info := range routes {
+		if slices.Contains(info.Options, "LongLived") {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = selfTest(ctx, rt, clock, info)
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// selfTestClock returns the Clock of the Router's options.
+func selfTestClock(rt *Router) Clock {
+	var opts HandleOpts
+	for _, fn := range rt.opts {
+		fn(&opts)
+	}
+
+	return opts.Clock()
+}
+
+// selfTest requests the route of info and returns an error if it panics, times out, responds with a 5xx status
+// code, or reads a path value without wildcard.
+func selfTest(ctx context.Context, rt *Router, clock Clock, info RouteInfo) error {
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r, err := selfTestRequest(reqCtx, info)
+	if err != nil {
+		return fmt.Errorf("gwu: self-test %s: %w", info.Pattern, err)
+	}
+
+	w := &selfTestWriter{header: make(http.Header), body: capBuffer{max: 256}}
+	done := make(chan any, 1)
+	go func() {
+		defer func() {
+			done <- recover()
+		}()
+
+		rt.ServeHTTP(w, r)
+	}()
+
+	select {
+	case v := <-done:
+		if v != nil {
+			return fmt.Errorf("gwu: self-test %s: panic: %v", info.Pattern, v)
+		}
+	case <-clock.After(selfTestTimeout):
+		cancel()
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+
+		return fmt.Errorf("gwu: self-test %s: no response within %s", info.Pattern, selfTestTimeout)
+	}
+
+	if len(w.noWildcard) > 0 {
+		return fmt.Errorf("gwu: self-test %s: no wildcard {%s} in the pattern", info.Pattern,
+			strings.Join(w.noWildcard, "}, {"))
+	}
+
+	if w.status >= http.StatusInternalServerError {
+		return fmt.Errorf("gwu: self-test %s: status %d: %s", info.Pattern, w.status,
+			strings.TrimSpace(w.body.buf.String()))
+	}
+
+	return nil
+}
+
+// selfTestRequest returns the request of SelfTest for the route of info.
+func selfTestRequest(ctx context.Context, info RouteInfo) (*http.Request, error) {
+	method := info.route.method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if in := info.example; in != nil || info.InType != nil && info.InType != reflect.TypeFor[struct{}]() {
+		if in == nil {
+			in = reflect.Zero(info.InType).Interface()
+		}
+
+		b, err := json.Marshal(in)
+		if err != nil {
+			return nil, fmt.Errorf("encode example: %w", err)
+		}
+
+		body = bytes.NewReader(b)
+	}
+
+	host := fillWildcards(info.route.host)
+	if host == "" {
+		host = "example.com"
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, "http://"+host+fillWildcards(info.route.path), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		r.Header.Set("Content-Type", ContentTypeJSON)
+	}
+
+	r.RemoteAddr = "192.0.2.1:1234"
+
+	return r, nil
+}
+
+// noWildcard reports the key of an empty path value to SelfTest, call it for empty path values only: SelfTest fills
+// all wildcards, so the route's pattern has no wildcard with the key.
+func (o HandleOpts) noWildcard(key string) {
+	if w := o.selfTest(); w != nil && !slices.Contains(w.noWildcard, key) {
+		w.noWildcard = append(w.noWildcard, key)
+	}
+}
+
+// selfTest returns the selfTestWriter of a request of SelfTest, or nil.
+func (o HandleOpts) selfTest() *selfTestWriter {
+	if o.req.vals == nil {
+		return nil
+	}
+
+	w := o.req.vals.resp.ResponseWriter
+	for {
+		switch v := w.(type) {
+		case *selfTestWriter:
+			return v
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = v.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// fillWildcards replaces the wildcards of the pattern's host or path with "0" and removes the {$} wildcard.
+func fillWildcards(path string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(path, '{')
+		if i < 0 {
+			break
+		}
+
+		j := strings.IndexByte(path[i:], '}')
+		if j < 0 {
+			break
+		}
+
+		b.WriteString(path[:i])
+		if path[i:i+j+1] != "{$}" {
+			b.WriteString("0")
+		}
+
+		path = path[i+j+1:]
+	}
+
+	b.WriteString(path)
+
+	return b.String()
+}
+
+// selfTestWriter records the status code and the beginning of the body of a SelfTest response, and the keys of the
+// path values read without wildcard, see noWildcard.
+type selfTestWriter struct {
+	header     http.Header
+	status     int
+	body       capBuffer
+	noWildcard []string
+}
+
+func (w *selfTestWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *selfTestWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *selfTestWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(b)
+}
+
+// Flush does nothing, it lets streaming handlers flush.
+func (w *selfTestWriter) Flush() {}
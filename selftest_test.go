@@ -0,0 +1,87 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+)
+
+func poemByID(_ context.Context, id int64, _ gwu.HandleOpts) (smallPoem, int, error) {
+	return smallPoem{ID: id}, http.StatusOK, nil
+}
+
+func poemsByAuthor(context.Context, string, gwu.HandleOpts) ([]smallPoem, int, error) {
+	return nil, http.StatusOK, nil
+}
+
+func TestSelfTest(t *testing.T) {
+	limiter := gwu.NewWindowLimiter(1, time.Minute, nil)
+	rt := gwu.NewRouter(gwu.RateLimit(limiter, nil))
+	gwu.HandleRoute(rt, "GET /poems/{id}", gwu.PathInt("id"), poemByID)
+	// The key does not match the wildcard.
+	gwu.HandleRoute(rt, "GET /authors/{name}", gwu.PathVal("author"), poemsByAuthor)
+	gwu.HandleRoute(rt, "GET /broken", gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (any, int, error) {
+		return nil, http.StatusInternalServerError, errors.New("template missing")
+	})
+
+	err := gwu.SelfTest(context.Background(), rt)
+	if err == nil {
+		t.Fatal("SelfTest passed, want the broken routes")
+	}
+
+	for _, want := range []string{"GET /authors/{name}: no wildcard {author}", "GET /broken: status 500"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q, want %q", err, want)
+		}
+	}
+
+	if strings.Contains(err.Error(), "/poems/") {
+		t.Errorf("error %q reports the healthy route", err)
+	}
+
+	// The RateLimit of the Router did not run for SelfTest.
+	if l, _ := limiter.Allow("192.0.2.1"); !l.Allowed {
+		t.Error("SelfTest used up the budget of its peer IP")
+	}
+}
+
+// TestSelfTestTimeout times out a route with the Router's Clock, SelfTest returns after the canceled request.
+func TestSelfTestTimeout(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	rt := gwu.NewRouter(gwu.WithClock(clock))
+
+	var returned atomic.Bool
+	gwu.HandleRoute(rt, "GET /hang", gwu.Empty(), func(ctx context.Context, _ any, _ gwu.HandleOpts) (any, int, error) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		returned.Store(true)
+
+		return nil, http.StatusOK, nil
+	})
+
+	errc := make(chan error, 1)
+	go func() { errc <- gwu.SelfTest(context.Background(), rt) }()
+
+	for {
+		select {
+		case err := <-errc:
+			if err == nil || !strings.Contains(err.Error(), "GET /hang: no response within 1s") {
+				t.Errorf("error %v, want the timeout of GET /hang", err)
+			}
+
+			if !returned.Load() {
+				t.Error("SelfTest returned before the timed-out request")
+			}
+
+			return
+		case <-time.After(time.Millisecond):
+			clock.Advance(time.Second)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package gwu
+
+import (
+	"net/http"
+	"strings"
+)
+
+// slashMode is how a Router handles requests whose path only matches with or without a trailing slash.
+type slashMode int
+
+const (
+	slashKeep slashMode = iota
+	slashRedirect
+	slashStrip
+)
+
+// RedirectTrailingSlash makes a Router redirect requests whose path does not match any route, but matches with a
+// trailing slash added or removed, to that path with http.StatusPermanentRedirect. The query is preserved, and
+// clients repeat the request with the same method and body, so POST requests stay POST requests.
+//
+// Set it with NewRouter, the options of groups and routes do not affect it. Note that http.ServeMux itself
+// redirects requests to a path without the trailing slash of a registered pattern like /dir/.
+//
+// Example usage:
+//
+//	rt := gwu.NewRouter(gwu.RedirectTrailingSlash()) // GET /poems/ redirects to /poems
+func RedirectTrailingSlash() HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.slash = slashRedirect
+	}
+}
+
+// StripTrailingSlash works like RedirectTrailingSlash, but serves the request with the matching path instead of
+// redirecting the client. Use it for internal APIs, where the canonical path need not be visible to clients.
+func StripTrailingSlash() HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.slash = slashStrip
+	}
+}
+
+// toggleSlash returns the request with a trailing slash added to or removed from its path, if that path matches a
+// route of the mux.
+func toggleSlash(mux *http.ServeMux, r *http.Request) (*http.Request, bool) {
+	if r.URL.Path == "/" {
+		return nil, false
+	}
+
+	u := *r.URL
+	if strings.HasSuffix(u.Path, "/") {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	} else {
+		u.Path += "/"
+		if u.RawPath != "" {
+			u.RawPath += "/"
+		}
+	}
+
+	r2 := r.Clone(r.Context())
+	r2.URL = &u
+	if h, p := mux.Handler(r2); p == "" || isUnmatched(h) {
+		return nil, false
+	}
+
+	return r2, true
+}
+
+// serveSlash handles a request whose path matches no route, but matches with or without a trailing slash, see
+// RedirectTrailingSlash. It reports whether it handled the request.
+func (rt *routes) serveSlash(w http.ResponseWriter, r *http.Request) bool {
+	if rt.slash == slashKeep {
+		return false
+	}
+
+	r2, ok := toggleSlash(rt.mux, r)
+	if !ok {
+		return false
+	}
+
+	if rt.slash == slashStrip {
+		rt.mux.ServeHTTP(w, r2)
+		return true
+	}
+
+	// The escaped path keeps encoded slashes of path values.
+	location := mountPrefix(r2.Context()) + r2.URL.EscapedPath()
+	if r2.URL.RawQuery != "" {
+		location += "?" + r2.URL.RawQuery
+	}
+
+	http.Redirect(w, r, location, http.StatusPermanentRedirect)
+
+	return true
+}
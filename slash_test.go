@@ -0,0 +1,113 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// slashRouter returns a Router with the option and routes with and without a trailing slash.
+func slashRouter(opt gwu.HandleOptsFunc) *gwu.Router {
+	echo := func(r *http.Request, _ gwu.HandleOpts) (string, error) {
+		return r.Method + " " + r.URL.RequestURI(), nil
+	}
+
+	exec := func(_ context.Context, s string, _ gwu.HandleOpts) (string, int, error) {
+		return s, http.StatusOK, nil
+	}
+
+	rt := gwu.NewRouter(opt)
+	gwu.Get(rt, "/poems", echo, exec)
+	gwu.Post(rt, "/poems", echo, exec)
+	gwu.Get(rt, "/poems/{id}", echo, exec)
+	gwu.Get(rt, "/authors/", echo, exec)
+	gwu.Get(rt, "/authors/{name}/", echo, exec)
+
+	return rt
+}
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	rt := slashRouter(gwu.RedirectTrailingSlash())
+
+	tests := []struct {
+		method, target string
+		status         int
+		location       string
+	}{
+		{http.MethodGet, "/poems", http.StatusOK, ""},
+		{http.MethodGet, "/poems/", http.StatusPermanentRedirect, "/poems"},
+		{http.MethodGet, "/poems/?sort=title&page=2", http.StatusPermanentRedirect, "/poems?sort=title&page=2"},
+		{http.MethodHead, "/poems/", http.StatusPermanentRedirect, "/poems"},
+		{http.MethodPost, "/poems/", http.StatusPermanentRedirect, "/poems"},
+		{http.MethodGet, "/poems/7/", http.StatusPermanentRedirect, "/poems/7"},
+		{http.MethodGet, "/poems/ode%2Fto/", http.StatusPermanentRedirect, "/poems/ode%2Fto"},
+		// http.ServeMux redirects to the subtree itself.
+		{http.MethodGet, "/authors/keats", http.StatusTemporaryRedirect, "/authors/keats/"},
+		{http.MethodGet, "/authors", http.StatusTemporaryRedirect, "/authors/"},
+		{http.MethodGet, "/songs/", http.StatusNotFound, ""},
+		{http.MethodGet, "/", http.StatusNotFound, ""},
+		// The path matches, the method does not.
+		{http.MethodDelete, "/poems", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader("{}")))
+		if rec.Code != tt.status || rec.Header().Get("Location") != tt.location {
+			t.Errorf("%s %s: %d %q, want %d %q", tt.method, tt.target, rec.Code, rec.Header().Get("Location"),
+				tt.status, tt.location)
+		}
+	}
+}
+
+// TestRedirectTrailingSlashMounted redirects to the full path of a mounted Router.
+func TestRedirectTrailingSlashMounted(t *testing.T) {
+	rt := gwu.NewRouter()
+	rt.Mount("/api", slashRouter(gwu.RedirectTrailingSlash()))
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/poems/ode%2Fto/?page=2", nil))
+	if rec.Code != http.StatusPermanentRedirect || rec.Header().Get("Location") != "/api/poems/ode%2Fto?page=2" {
+		t.Errorf("%d %q, want 308 to the full path", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestStripTrailingSlash(t *testing.T) {
+	rt := slashRouter(gwu.StripTrailingSlash())
+
+	tests := []struct {
+		method, target string
+		status         int
+		body           string
+	}{
+		{http.MethodGet, "/poems/?sort=title", http.StatusOK, `"GET /poems?sort=title"`},
+		{http.MethodPost, "/poems/", http.StatusOK, `"POST /poems"`},
+		{http.MethodGet, "/poems/ode%2Fto/", http.StatusOK, `"GET /poems/ode%2Fto"`},
+		{http.MethodGet, "/authors/keats", http.StatusTemporaryRedirect, ""},
+		{http.MethodGet, "/songs/", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader("{}")))
+		if rec.Code != tt.status || tt.body != "" && strings.TrimSpace(rec.Body.String()) != tt.body {
+			t.Errorf("%s %s: %d %q, want %d %s", tt.method, tt.target, rec.Code, rec.Body, tt.status, tt.body)
+		}
+	}
+}
+
+// TestTrailingSlashDefault responds with 404 to paths only matching with or without a trailing slash.
+func TestTrailingSlashDefault(t *testing.T) {
+	rt := gwu.NewRouter()
+	gwu.Get(rt, "/poems", gwu.Empty(), noContent)
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/poems/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /poems/: status %d, want 404", rec.Code)
+	}
+}
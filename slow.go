@@ -0,0 +1,46 @@
+package gwu
+
+import (
+	"net/http"
+	"time"
+)
+
+// WarnSlow logs a warning for every request that takes longer than the threshold to handle, including requests
+// that succeed. The warning carries the method, path, duration, status, and the X-Request-ID header if present.
+// A threshold <= 0 disables the warning.
+//
+// Exclude handlers that are intentionally long-lived, like streams, with LongLived. That way WarnSlow can be part of
+// the Defaults.
+func WarnSlow(threshold time.Duration) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.slowThreshold = threshold
+	}
+}
+
+// LongLived marks the handler as intentionally long-lived, WarnSlow does not warn for its requests.
+func LongLived() HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.longLived = true
+	}
+}
+
+// warnsSlow reports whether the handler logs slow requests.
+func (o HandleOpts) warnsSlow() bool {
+	return o.slowThreshold > 0 && !o.longLived
+}
+
+// warnIfSlow logs a warning if the request started at start exceeded the WarnSlow threshold.
+func warnIfSlow(opts HandleOpts, r *http.Request, w *statusWriter, start time.Time) {
+	opts = opts.enrichedLog()
+	d := opts.Clock().Since(start)
+	if d <= opts.slowThreshold {
+		return
+	}
+
+	args := []any{"method", r.Method, "path", FullPath(r), "duration", d, "status", w.status}
+	if id := r.Header.Get("X-Request-ID"); id != "" {
+		args = append(args, "request_id", id)
+	}
+
+	logWarn(opts.Log, "slow request", args...)
+}
@@ -0,0 +1,89 @@
+package gwu_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// takes returns an Exec that advances the clock by d and succeeds.
+func takes(clock *gwu.ManualClock, d time.Duration) gwu.Exec[any, gwu.NoBody] {
+	return func(context.Context, any, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		clock.Advance(d)
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	}
+}
+
+func TestWarnSlow(t *testing.T) {
+	tests := []struct {
+		name  string
+		took  time.Duration
+		opts  []gwu.HandleOptsFunc
+		warns bool
+	}{
+		{"slow", 2 * time.Second, nil, true},
+		{"at the threshold", time.Second, nil, false},
+		{"fast", 10 * time.Millisecond, nil, false},
+		{"long-lived", time.Minute, []gwu.HandleOptsFunc{gwu.LongLived()}, false},
+		{"disabled", time.Minute, []gwu.HandleOptsFunc{gwu.WarnSlow(0)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := gwutest.Logger()
+			clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+			opts := append([]gwu.HandleOptsFunc{gwu.Log(log), gwu.WithClock(clock), gwu.WarnSlow(time.Second)},
+				tt.opts...)
+			h := gwu.Handle(gwu.Empty(), takes(clock, tt.took), opts...)
+
+			r := httptest.NewRequest(http.MethodGet, "/poems/7", nil)
+			r.Header.Set("X-Request-ID", "req-1")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("status %d, want 204", rec.Code)
+			}
+
+			warnings := log.Filter(slog.LevelWarn)
+			if !tt.warns {
+				if len(warnings) != 0 {
+					t.Errorf("warnings %v, want none", warnings)
+				}
+
+				return
+			}
+
+			// A request that succeeds is still slow, and warned about once.
+			if len(warnings) != 1 {
+				t.Fatalf("warnings %v, want one", warnings)
+			}
+
+			log.AssertLogged(t, slog.LevelWarn, "slow request", "method", http.MethodGet,
+				"path", "/poems/7", "duration", tt.took.String(), "status", "204", "request_id", "req-1")
+		})
+	}
+}
+
+// TestWarnSlowWithoutRequestID omits the request_id if the request has no X-Request-ID header.
+func TestWarnSlowWithoutRequestID(t *testing.T) {
+	log := gwutest.Logger()
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	h := gwu.Handle(gwu.Empty(), takes(clock, 2*time.Second), gwu.Log(log), gwu.WithClock(clock),
+		gwu.WarnSlow(time.Second))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/poems", nil))
+
+	log.AssertLogged(t, slog.LevelWarn, "slow request", "path", "/poems")
+	for _, e := range log.Filter(slog.LevelWarn) {
+		if _, ok := e.Attrs["request_id"]; ok {
+			t.Errorf("entry %v, want no request_id", e)
+		}
+	}
+}
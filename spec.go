@@ -0,0 +1,344 @@
+package gwu
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Spec collects the routes registered with HandleRoute into an OpenAPI 3 document, set it with Collect.
+// Spec derives the schemas of the request and response bodies by reflection over the json tags of the In and
+// Out types. Routes without a method in their pattern are not documented.
+//
+// Example usage:
+//
+//	spec := gwu.NewSpec("Poems", "1.0.0")
+//	rt := gwu.NewRouter(gwu.Collect(spec))
+//	gwu.Get(rt, "/poem/{id}", gwu.PathVal("id"), ctrl.ByID, gwu.Doc(gwu.Operation{Summary: "Get a poem"}))
+//	rt.Handle("GET /openapi.json", spec.Handler())
+type Spec struct {
+	Title   string
+	Version string
+
+	mu  sync.Mutex
+	ops []specOp
+}
+
+// Operation is the documentation of a route in a Spec, set it with Doc.
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	OperationID string
+	// Status is the status code of the successful response, defaults to http.StatusOK.
+	Status int
+}
+
+// specOp is a route collected by a Spec.
+type specOp struct {
+	pattern   pattern
+	in        reflect.Type
+	out       reflect.Type
+	op        Operation
+	jsonError bool
+	naming    *fieldNaming
+}
+
+// NewSpec returns an empty Spec with the given API title and version.
+func NewSpec(title, version string) *Spec {
+	return &Spec{Title: title, Version: version}
+}
+
+// Collect adds the handler's route to the Spec when registered with HandleRoute.
+func Collect(spec *Spec) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.spec = spec
+	}
+}
+
+// Doc sets the documentation of the handler's route in the Spec.
+func Doc(op Operation) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.doc = op
+	}
+}
+
+// add adds a route to the spec.
+func (s *Spec) add(p pattern, in, out reflect.Type, opts HandleOpts) {
+	if p.method == "" {
+		return
+	}
+
+	op := opts.doc
+	if op.Status == 0 && opts.createdOnPost && opts.derivedStatus && p.method == http.MethodPost {
+		op.Status = http.StatusCreated
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ops = append(s.ops, specOp{
+		pattern:   p,
+		in:        in,
+		out:       out,
+		op:        op,
+		jsonError: opts.jsonErrors,
+		naming:    opts.fieldNaming,
+	})
+}
+
+// Document returns the OpenAPI document as a JSON-compatible value.
+func (s *Spec) Document() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schemas := newSchemas()
+	paths := make(map[string]any)
+	for _, op := range s.ops {
+		path, params := specPath(op.pattern.path)
+		ops, _ := paths[path].(map[string]any)
+		if ops == nil {
+			ops = make(map[string]any)
+			paths[path] = ops
+		}
+
+		schemas.naming = op.naming
+		ops[strings.ToLower(op.pattern.method)] = op.document(schemas, params)
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info":    map[string]any{"title": s.Title, "version": s.Version},
+		"paths":   paths,
+	}
+
+	if len(schemas.components) > 0 {
+		doc["components"] = map[string]any{"schemas": schemas.components}
+	}
+
+	return doc
+}
+
+// document returns the OpenAPI operation object.
+func (o specOp) document(schemas *schemas, params []string) map[string]any {
+	doc := make(map[string]any)
+	if o.op.Summary != "" {
+		doc["summary"] = o.op.Summary
+	}
+
+	if o.op.Description != "" {
+		doc["description"] = o.op.Description
+	}
+
+	if len(o.op.Tags) > 0 {
+		doc["tags"] = o.op.Tags
+	}
+
+	if o.op.OperationID != "" {
+		doc["operationId"] = o.op.OperationID
+	}
+
+	if len(params) > 0 {
+		var ps []any
+		for _, p := range params {
+			ps = append(ps, map[string]any{"name": p, "in": "path", "required": true, "schema": &Schema{Type: "string"}})
+		}
+
+		doc["parameters"] = ps
+	}
+
+	if hasBody(o.pattern.method, o.in) {
+		doc["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{ContentTypeJSON: map[string]any{"schema": schemas.of(o.in)}},
+		}
+	}
+
+	status := o.op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := map[string]any{"description": http.StatusText(status)}
+	responses := make(map[string]any)
+	switch {
+	case o.out == reflect.TypeFor[Raw](), o.out == reflect.TypeFor[NoBody]():
+	case o.out == reflect.TypeFor[File]():
+		success["content"] = map[string]any{ContentTypeOctetStream: map[string]any{
+			"schema": &Schema{Type: "string", Format: "binary"},
+		}}
+	case o.out.Implements(streamerType):
+		s := reflect.Zero(o.out).Interface().(streamer)
+		success["content"] = map[string]any{s.contentType(): map[string]any{"schema": schemas.of(s.elem())}}
+	case o.out.Implements(upsertedType):
+		elem := reflect.Zero(o.out).Interface().(upserted).elem()
+		content := map[string]any{ContentTypeJSON: map[string]any{"schema": schemas.of(elem)}}
+		success = map[string]any{"description": http.StatusText(http.StatusOK), "content": content}
+		status = http.StatusOK
+		responses[strconv.Itoa(http.StatusCreated)] = map[string]any{
+			"description": http.StatusText(http.StatusCreated),
+			"headers":     map[string]any{"Location": map[string]any{"schema": &Schema{Type: "string"}}},
+			"content":     content,
+		}
+	default:
+		success["content"] = map[string]any{ContentTypeJSON: map[string]any{"schema": schemas.of(o.out)}}
+	}
+
+	failure := map[string]any{
+		"description": "Error",
+		"content":     map[string]any{"text/plain": map[string]any{"schema": &Schema{Type: "string"}}},
+	}
+
+	if o.jsonError {
+		failure["content"] = map[string]any{ContentTypeJSON: map[string]any{
+			"schema": schemas.of(reflect.TypeFor[ErrorBody]()),
+		}}
+	}
+
+	responses[strconv.Itoa(status)] = success
+	responses["default"] = failure
+	doc["responses"] = responses
+
+	return doc
+}
+
+// hasBody reports whether a route with the method and In type reads a request body.
+// Only structs, maps, and slices are expected in the body of POST, PUT, and PATCH requests.
+func hasBody(method string, in reflect.Type) bool {
+	if !slices.Contains([]string{http.MethodPost, http.MethodPut, http.MethodPatch}, method) {
+		return false
+	}
+
+	for in.Kind() == reflect.Pointer {
+		in = in.Elem()
+	}
+
+	switch in.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+// specPath converts an http.ServeMux path to an OpenAPI path and returns the names of its path parameters.
+func specPath(path string) (string, []string) {
+	path = strings.TrimSuffix(path, "{$}")
+
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}' {
+			name := strings.TrimSuffix(seg[1:len(seg)-1], "...")
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// JSON returns the OpenAPI document encoded as JSON.
+func (s *Spec) JSON() ([]byte, error) {
+	return json.MarshalIndent(s.Document(), "", "  ")
+}
+
+// YAML returns the OpenAPI document encoded as YAML.
+func (s *Spec) YAML() ([]byte, error) {
+	b, err := json.Marshal(s.Document())
+	if err != nil {
+		return nil, err
+	}
+
+	var doc any
+	err = json.Unmarshal(b, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	writeYAML(&sb, doc, 0)
+
+	return []byte(sb.String()), nil
+}
+
+// Handler returns an http.Handler serving the OpenAPI document as JSON, usually registered at /openapi.json.
+func (s *Spec) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := s.JSON()
+		if err != nil {
+			JSONError(w, r, ErrEncodeResponse, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_, _ = w.Write(b)
+	})
+}
+
+// writeYAML writes a decoded JSON value as YAML, strings are always quoted.
+func writeYAML(sb *strings.Builder, v any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			sb.WriteString(" {}\n")
+			return
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		slices.Sort(keys)
+
+		sb.WriteString("\n")
+		for _, k := range keys {
+			sb.WriteString(pad + yamlString(k) + ":")
+			writeYAML(sb, v[k], indent+1)
+		}
+	case []any:
+		if len(v) == 0 {
+			sb.WriteString(" []\n")
+			return
+		}
+
+		sb.WriteString("\n")
+		for _, e := range v {
+			sb.WriteString(pad + "-")
+			if m, ok := e.(map[string]any); ok && len(m) > 0 {
+				// Write the first key on the dash line, the remaining keys aligned below it.
+				var inner strings.Builder
+				writeYAML(&inner, m, indent+1)
+				sb.WriteString(" " + strings.TrimPrefix(strings.TrimPrefix(inner.String(), "\n"), pad+"  "))
+				continue
+			}
+
+			writeYAML(sb, e, indent+1)
+		}
+	default:
+		b, _ := json.Marshal(v)
+		sb.WriteString(" " + string(b) + "\n")
+	}
+}
+
+// yamlString returns the string as a YAML key, quoted unless it is a plain word.
+// Numbers and words YAML resolves to other types, like status codes or "null", are quoted.
+func yamlString(s string) string {
+	word := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-')
+	}) < 0
+	plain := s != "" && (s[0] >= 'a' && s[0] <= 'z' || s[0] >= 'A' && s[0] <= 'Z') && word
+	if plain && !slices.Contains([]string{"null", "true", "false", "yes", "no", "on", "off"}, s) {
+		return s
+	}
+
+	b, _ := json.Marshal(s)
+
+	return string(b)
+}
@@ -0,0 +1,70 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestSpecErrorBodies(t *testing.T) {
+	custom := func(w http.ResponseWriter, _ *http.Request, err error, code int) {
+		http.Error(w, "poems: "+err.Error(), code)
+	}
+
+	spec := gwu.NewSpec("poems", "1.0.0")
+	rt := gwu.NewRouter(gwu.Collect(spec))
+	gwu.HandleRoute(rt, "GET /text", gwu.Empty(), getSmallPoem)
+	gwu.HandleRoute(rt, "GET /json", gwu.Empty(), getSmallPoem, gwu.Errors(gwu.JSONError))
+	gwu.HandleRoute(rt, "GET /custom", gwu.Empty(), getSmallPoem, gwu.Errors(custom))
+	gwu.HandleRoute(rt, "GET /custom-json", gwu.Empty(), getSmallPoem, gwu.Errors(gwu.JSONErrorFunc(custom)))
+	gwu.HandleRoute(rt, "GET /error-func", gwu.Empty(), getSmallPoem, gwu.Errors(gwu.ErrorFunc(gwu.TextError)))
+
+	b, err := spec.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Responses map[string]struct {
+				Content map[string]any `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{
+		"/text": "text/plain", "/json": gwu.ContentTypeJSON, "/custom": "text/plain",
+		"/custom-json": gwu.ContentTypeJSON, "/error-func": "text/plain",
+	} {
+		content := doc.Paths[path]["get"].Responses["default"].Content
+		if _, ok := content[want]; !ok || len(content) != 1 {
+			t.Errorf("%s: error content %v, want %s", path, content, want)
+		}
+	}
+}
+
+// TestSpecGolden compares the document of a small API with testdata/spec.json, run it with -update to accept changes.
+func TestSpecGolden(t *testing.T) {
+	spec := gwu.NewSpec("poems", "1.0.0")
+	rt := gwu.NewRouter(gwu.Collect(spec), gwu.CreatedOnPost())
+	gwu.HandleRoute(rt, "GET /poems/{id}", gwu.PathInt("id"), func(_ context.Context, id int64, _ gwu.HandleOpts) (
+		smallPoem, int, error) {
+		return smallPoem{ID: id}, http.StatusOK, nil
+	})
+	gwu.HandleRouteE(rt, "POST /poems", gwu.JSON[smallPoem](), createPoem)
+	gwu.HandleRoute(rt, "DELETE /poems/{id}", gwu.PathInt("id"), noContent[int64], gwu.Errors(gwu.JSONError))
+	rt.Handle("GET /openapi.json", spec.Handler())
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	gwutest.Golden(t, rec, "testdata/spec.json")
+}
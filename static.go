@@ -0,0 +1,154 @@
+package gwu
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrNotFound is the error of responses to requests for unknown resources. Is safe to display to the client.
+	ErrNotFound = errors.New("not found")
+	// ErrForbidden is the error of responses to requests for forbidden resources. Is safe to display to the client.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Static returns an http.Handler serving the files of fsys, e.g. an embed.FS, for GET and HEAD requests.
+// A request for a directory serves its index.html, Static never lists directories and responds to directories
+// without index.html with ErrForbidden. Unknown files get ErrNotFound, see SPA for a fallback.
+//
+// Static sets Cache-Control to cache files with a content hash in their name, like app.3f9a1c2b.js, for a year,
+// and makes clients revalidate all other files.
+//
+// Static writes errors with the Errors option, defaults to TextError. Strip a path prefix with http.StripPrefix.
+//
+// Example usage:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	sub, _ := fs.Sub(dist, "dist")
+//	mux.Handle("GET /", gwu.Static(sub, gwu.SPA("/app")))
+func Static(fsys fs.FS, optFns ...HandleOptsFunc) http.Handler {
+	opts := newHandleOpts(optFns)
+	if err := opts.validate(); err != nil {
+		panic(err)
+	}
+
+	opts.Log = orFallback(opts.Log)
+	errFn := opts.errFnOr(TextError)
+	allow := []string{http.MethodGet, http.MethodHead}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			(&methodNotAllowed{errFn: errFn, allow: allow}).ServeHTTP(w, r)
+			return
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" {
+			name = "."
+		}
+
+		err := serveFile(w, r, fsys, name, false)
+		if errors.Is(err, fs.ErrNotExist) && opts.spa != "" && underPrefix(r.URL.Path, opts.spa) {
+			err = serveFile(w, r, fsys, "index.html", true)
+		}
+
+		switch {
+		case err == nil:
+		case errors.Is(err, fs.ErrNotExist):
+			errFn(w, r, ErrNotFound, http.StatusNotFound)
+		case errors.Is(err, fs.ErrPermission):
+			errFn(w, r, ErrForbidden, http.StatusForbidden)
+		default:
+			opts.Log.Info("failed to serve file", "path", FullPath(r), "error", err)
+			errFn(w, r, errors.New(http.StatusText(http.StatusInternalServerError)), http.StatusInternalServerError)
+		}
+	})
+}
+
+// SPA makes Static serve the index.html at the root of its file system for unknown files under the path prefix, so
+// a single page application can route on the client. Use "/" to fall back for all paths.
+func SPA(prefix string) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		if !strings.HasPrefix(prefix, "/") {
+			opt.invalid("SPA: prefix %q must start with a slash", prefix)
+			return
+		}
+
+		opt.spa = prefix
+	}
+}
+
+// underPrefix reports whether the path is the prefix or below it.
+func underPrefix(p, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
+// serveFile serves the named file of fsys, or the index.html of a directory.
+// A directory without index.html is fs.ErrPermission.
+func serveFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string, fallback bool) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		index := path.Join(name, "index.html")
+		if _, err := fs.Stat(fsys, index); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fs.ErrPermission
+			}
+
+			return err
+		}
+
+		return serveFile(w, r, fsys, index, fallback)
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+
+		content = bytes.NewReader(b)
+	}
+
+	if !fallback && hashedName(info.Name()) {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), content)
+
+	return nil
+}
+
+// hashedName reports whether the file name contains a content hash, a dot or dash separated part of at least eight
+// hex digits before the extension, like app.3f9a1c2b.js or chunk-3f9a1c2b.css.
+func hashedName(name string) bool {
+	name = strings.TrimSuffix(name, path.Ext(name))
+	i := strings.LastIndexAny(name, ".-")
+	if i < 0 {
+		return false
+	}
+
+	hash := name[i+1:]
+
+	return len(hash) >= 8 && isLowerHex(strings.ToLower(hash))
+}
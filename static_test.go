@@ -0,0 +1,98 @@
+package gwu_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+var frontend = fstest.MapFS{
+	"index.html":            {Data: []byte("<h1>Poems</h1>")},
+	"app.3f9a1c2b.js":       {Data: []byte("console.log('odes')")},
+	"robots.txt":            {Data: []byte("User-agent: *")},
+	"docs/index.html":       {Data: []byte("<h1>Docs</h1>")},
+	"assets/logo.svg":       {Data: []byte("<svg/>")},
+	"assets/chunk-9b8c.css": {Data: []byte("h1{}")},
+}
+
+func TestStatic(t *testing.T) {
+	h := gwu.Static(frontend, gwu.SPA("/app"))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+		cache  string
+	}{
+		{"root index", http.MethodGet, "/", http.StatusOK, "<h1>Poems</h1>", "no-cache"},
+		{"file", http.MethodGet, "/robots.txt", http.StatusOK, "User-agent: *", "no-cache"},
+		{"hashed file", http.MethodGet, "/app.3f9a1c2b.js", http.StatusOK, "console.log('odes')",
+			"public, max-age=31536000, immutable"},
+		{"short hash", http.MethodGet, "/assets/chunk-9b8c.css", http.StatusOK, "h1{}", "no-cache"},
+		{"directory index", http.MethodGet, "/docs/", http.StatusOK, "<h1>Docs</h1>", "no-cache"},
+		{"head", http.MethodHead, "/robots.txt", http.StatusOK, "", "no-cache"},
+		{"spa fallback", http.MethodGet, "/app/poems/7", http.StatusOK, "<h1>Poems</h1>", "no-cache"},
+		{"spa prefix", http.MethodGet, "/app", http.StatusOK, "<h1>Poems</h1>", "no-cache"},
+		{"outside the spa prefix", http.MethodGet, "/application", http.StatusNotFound, "not found\n", ""},
+		{"unknown file", http.MethodGet, "/missing.js", http.StatusNotFound, "not found\n", ""},
+		{"escaping the root", http.MethodGet, "/../index.html", http.StatusOK, "<h1>Poems</h1>", "no-cache"},
+		{"directory without index", http.MethodGet, "/assets/", http.StatusForbidden, "forbidden\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/", nil)
+			r.URL.Path = tt.path
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if rec.Code != tt.status || rec.Body.String() != tt.body {
+				t.Errorf("%d %q, want %d %q", rec.Code, rec.Body, tt.status, tt.body)
+			}
+
+			if got := rec.Header().Get("Cache-Control"); got != tt.cache {
+				t.Errorf("Cache-Control %q, want %q", got, tt.cache)
+			}
+		})
+	}
+}
+
+// TestStaticErrors writes errors as TextError like Handle, or with the Errors option.
+func TestStaticErrors(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gwu.Static(frontend).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing.js", nil))
+	gwutest.AssertError(t, rec, http.StatusNotFound, gwu.ErrNotFound.Error())
+	if ct := rec.Header().Get("Content-Type"); ct != gwu.ContentTypeText {
+		t.Errorf("Content-Type %q, want %q", ct, gwu.ContentTypeText)
+	}
+
+	rec = httptest.NewRecorder()
+	gwu.Static(frontend, gwu.Errors(gwu.JSONError)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets", nil))
+	gwutest.AssertError(t, rec, http.StatusForbidden, gwu.ErrForbidden.Error())
+	if ct := rec.Header().Get("Content-Type"); ct != gwu.ContentTypeJSON {
+		t.Errorf("Content-Type %q, want %q", ct, gwu.ContentTypeJSON)
+	}
+
+	rec = httptest.NewRecorder()
+	gwu.Static(frontend).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/index.html", nil))
+	gwutest.AssertError(t, rec, http.StatusMethodNotAllowed, gwu.ErrMethodNotAllowed.Error())
+	if allow := rec.Header().Get("Allow"); allow != "GET, HEAD" {
+		t.Errorf("Allow %q, want GET, HEAD", allow)
+	}
+}
+
+func TestSPAInvalidPrefix(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Static did not panic on a prefix without slash")
+		}
+	}()
+
+	gwu.Static(frontend, gwu.SPA("app"))
+}
@@ -0,0 +1,61 @@
+package gwu
+
+import "net/http"
+
+// The media types of the bodies gwu reads and writes, use them to set or compare the Content-Type in user code.
+const (
+	// ContentTypeJSON is the media type of JSON responses and of the request bodies of the JSON CnIn.
+	ContentTypeJSON = "application/json"
+	// ContentTypeNDJSON is the media type of Stream responses, newline-delimited JSON.
+	ContentTypeNDJSON = "application/x-ndjson"
+	// ContentTypeEventStream is the media type of EventStream responses, Server-Sent Events.
+	ContentTypeEventStream = "text/event-stream"
+	// ContentTypeText is the Content-Type of TextError responses.
+	ContentTypeText = "text/plain; charset=utf-8"
+	// ContentTypeOctetStream is the media type the Spec documents for File responses.
+	ContentTypeOctetStream = "application/octet-stream"
+)
+
+// OK returns the output with http.StatusOK for an Exec, so successful returns read declaratively.
+//
+// Example usage:
+//
+//	func (c *Ctrl) ByID(ctx context.Context, id string, _ gwu.HandleOpts) (Poem, int, error) {
+//		poem, err := c.repo.Poem(ctx, id)
+//		if err != nil {
+//			return poem, http.StatusNotFound, ErrNotFound
+//		}
+//
+//		return gwu.OK(poem)
+//	}
+func OK[Out any](out Out) (Out, int, error) {
+	return out, http.StatusOK, nil
+}
+
+// Created returns the output with http.StatusCreated for an Exec, see OK. Set the Location header with
+// HandleOpts.Header before, or return an Upsert for a create-or-replace.
+//
+// Example usage:
+//
+//	opts.Header().Set("Location", "/poem/"+poem.ID)
+//	return gwu.Created(poem)
+func Created[Out any](out Out) (Out, int, error) {
+	return out, http.StatusCreated, nil
+}
+
+// Accepted returns the output with http.StatusAccepted for an Exec, like for a job that is processed later, see OK.
+func Accepted[Out any](out Out) (Out, int, error) {
+	return out, http.StatusAccepted, nil
+}
+
+// NoContent returns NoBody with http.StatusNoContent for an Exec without response body, see NoBody.
+//
+// Example usage:
+//
+//	func (c *Ctrl) Delete(ctx context.Context, id string, _ gwu.HandleOpts) (gwu.NoBody, int, error) {
+//		c.repo.Delete(ctx, id)
+//		return gwu.NoContent()
+//	}
+func NoContent() (NoBody, int, error) {
+	return NoBody{}, http.StatusNoContent, nil
+}
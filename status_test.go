@@ -0,0 +1,113 @@
+package gwu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// The helpers return the triple of an Exec and compile as a return statement of one.
+var (
+	_ gwu.Exec[any, smallPoem] = func(context.Context, any, gwu.HandleOpts) (smallPoem, int, error) {
+		return gwu.OK(smallPoem{})
+	}
+	_ gwu.Exec[any, gwu.NoBody] = func(context.Context, any, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		return gwu.NoContent()
+	}
+)
+
+func TestStatusHelpers(t *testing.T) {
+	type triple struct {
+		out  any
+		code int
+		err  error
+	}
+
+	poem := smallPoem{ID: 7, Title: "Ode"}
+	of := func(out any, code int, err error) triple { return triple{out, code, err} }
+	tests := []struct {
+		name      string
+		got, want triple
+	}{
+		{"OK", of(gwu.OK(poem)), triple{poem, http.StatusOK, nil}},
+		{"Created", of(gwu.Created(poem)), triple{poem, http.StatusCreated, nil}},
+		{"Accepted", of(gwu.Accepted(poem)), triple{poem, http.StatusAccepted, nil}},
+		{"NoContent", of(gwu.NoContent()), triple{gwu.NoBody{}, http.StatusNoContent, nil}},
+		{"OK with a pointer", of(gwu.OK(&poem)), triple{&poem, http.StatusOK, nil}},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: %+v, want %+v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// TestStatusHelpersHeaders keeps the headers set by the Exec, like the Location of a created resource.
+func TestStatusHelpersHeaders(t *testing.T) {
+	rt := gwu.NewRouter()
+	gwu.Post(rt, "/poems", gwu.Empty(), func(_ context.Context, _ any, opts gwu.HandleOpts) (smallPoem, int, error) {
+		opts.Header().Set("Location", "/poem/7")
+		return gwu.Created(smallPoem{ID: 7, Title: "Ode"})
+	})
+	gwu.Put(rt, "/poem/{id}", gwu.PathVal("id"), func(_ context.Context, id string, _ gwu.HandleOpts) (
+		gwu.Upserted[smallPoem], int, error) {
+		return gwu.OK(gwu.Upsert(smallPoem{ID: 7, Title: "Ode"}, true, "/poem/"+id))
+	})
+	gwu.Delete(rt, "/poem/{id}", gwu.PathVal("id"), func(context.Context, string, gwu.HandleOpts) (gwu.NoBody, int,
+		error) {
+		return gwu.NoContent()
+	})
+
+	tests := []struct {
+		method, path string
+		status       int
+		location     string
+		contentType  string
+	}{
+		{http.MethodPost, "/poems", http.StatusCreated, "/poem/7", gwu.ContentTypeJSON},
+		// The Upsert decides over the status code of OK.
+		{http.MethodPut, "/poem/7", http.StatusCreated, "/poem/7", gwu.ContentTypeJSON},
+		{http.MethodDelete, "/poem/7", http.StatusNoContent, "", ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.status || rec.Header().Get("Location") != tt.location ||
+			rec.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%s %s: %d with Location %q and Content-Type %q, want %d with %q and %q", tt.method, tt.path,
+				rec.Code, rec.Header().Get("Location"), rec.Header().Get("Content-Type"), tt.status, tt.location,
+				tt.contentType)
+		}
+	}
+}
+
+// TestContentTypes writes the bodies with the media types of the constants.
+func TestContentTypes(t *testing.T) {
+	stream := func(_ context.Context, send func(int) error) error { return send(1) }
+
+	tests := []struct {
+		name string
+		h    http.Handler
+		want string
+	}{
+		{"JSON", gwu.Handle(gwu.Empty(), getSmallPoem), gwu.ContentTypeJSON},
+		{"Stream", gwu.Handle(gwu.Empty(), streamOf(stream)), gwu.ContentTypeNDJSON},
+		{"TextError", gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (smallPoem, int, error) {
+			return smallPoem{}, http.StatusConflict, errors.New("poem exists")
+		}), gwu.ContentTypeText},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		tt.h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if got := rec.Header().Get("Content-Type"); got != tt.want {
+			t.Errorf("%s: Content-Type %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
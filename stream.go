@@ -0,0 +1,289 @@
+package gwu
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// ErrStreamClosed is the error send returns after a previous send failed, see Stream.
+var ErrStreamClosed = errors.New("stream closed")
+
+// defaultWriteTimeout is the write timeout of streams without a WriteTimeout option.
+const defaultWriteTimeout = 10 * time.Second
+
+// WriteTimeout sets how long a single write of a Stream may take before send fails, defaults to 10 seconds.
+// A negative timeout waits as long as the client takes to read the response.
+func WriteTimeout(d time.Duration) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.writeTimeout = d
+	}
+}
+
+// streamWriteTimeout returns the handler's write timeout, zero if writes may block.
+func (o HandleOpts) streamWriteTimeout() time.Duration {
+	switch {
+	case o.writeTimeout < 0:
+		return 0
+	case o.writeTimeout == 0:
+		return defaultWriteTimeout
+	default:
+		return o.writeTimeout
+	}
+}
+
+// Stream is an Out value that writes the values passed to send as newline-delimited JSON, each flushed to the
+// client. Handle calls the Stream with the request's context after the Exec returned.
+//
+// The stream applies backpressure: send blocks until the value is written, so a slow client slows the producer
+// down instead of making it buffer, and a stream holds a single encode buffer no matter how many values it sends.
+// If a write takes longer than the WriteTimeout, or the client disconnects, send returns the error and cancels
+// the context, later sends fail with ErrStreamClosed. The producer should return when send or its context fails.
+// send is not safe for concurrent use.
+//
+// Handle sends the headers with the first value. If the Stream returns an error before that, Handle responds with
+// the error and http.StatusInternalServerError, or the status code of a StatusError, like an Exec's error. Errors
+// after the first value end the response, Handle logs them with the ErrorLog. See DrainStreams to end streams on
+// shutdown, and EventStream for Server-Sent Events.
+//
+// Example usage:
+//
+//	func (c *Controller) Export(_ context.Context, _ any, _ gwu.HandleOpts) (gwu.Stream[Poem], int, error) {
+//		return func(ctx context.Context, send func(Poem) error) error {
+//			return c.store.Each(ctx, func(p Poem) error { return send(p) })
+//		}, http.StatusOK, nil
+//	}
+type Stream[T any] func(ctx context.Context, send func(T) error) error
+
+// EventStream is an Out value that writes the values passed to send as Server-Sent Events, each the JSON of the
+// value as data of an event without type, for browsers' EventSource. It works like a Stream otherwise, see Stream.
+// DrainStreams ends it with an event of type shutdown.
+//
+// Example usage:
+//
+//	func (c *Controller) Live(_ context.Context, _ any, _ gwu.HandleOpts) (gwu.EventStream[Edit], int, error) {
+//		return func(ctx context.Context, send func(Edit) error) error {
+//			return c.edits.Each(ctx, func(e Edit) error { return send(e) })
+//		}, http.StatusOK, nil
+//	}
+type EventStream[T any] func(ctx context.Context, send func(T) error) error
+
+// streamer is implemented by every Stream and EventStream.
+type streamer interface {
+	stream(w http.ResponseWriter, r *http.Request, opts HandleOpts, code int)
+	elem() reflect.Type
+	contentType() string
+}
+
+var streamerType = reflect.TypeFor[streamer]()
+
+// isStream reports whether Out is a Stream or EventStream.
+func isStream[Out any]() bool {
+	return reflect.TypeFor[Out]().Implements(streamerType)
+}
+
+// streamFormat is the framing of the values of a stream.
+type streamFormat struct {
+	contentType string
+	// frame writes the encoded value, with the newline of the JSONEncoder, as frame. The first frame starts the
+	// response.
+	frame func(w io.Writer, encoded []byte) error
+	// shutdown is the terminal frame of a stream ended by DrainStreams.
+	shutdown string
+}
+
+// ndjson is the streamFormat of Stream.
+var ndjson = streamFormat{
+	contentType: ContentTypeNDJSON,
+	frame: func(w io.Writer, encoded []byte) error {
+		_, err := w.Write(encoded)
+		return err
+	},
+	shutdown: `{"event":"shutdown"}` + "\n",
+}
+
+// sse is the streamFormat of EventStream, see the HTML Standard section 9.2.
+var sse = streamFormat{
+	contentType: ContentTypeEventStream,
+	frame: func(w io.Writer, encoded []byte) error {
+		// The JSON of a codec may be indented, every line is a data field then.
+		for _, line := range bytes.Split(bytes.TrimRight(encoded, "\r\n"), []byte("\n")) {
+			if _, err := io.WriteString(w, "data: "); err != nil {
+				return err
+			}
+
+			if _, err := w.Write(bytes.TrimSuffix(line, []byte("\r"))); err != nil {
+				return err
+			}
+
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+
+		_, err := io.WriteString(w, "\n")
+		return err
+	},
+	shutdown: "event: shutdown\ndata: {}\n\n",
+}
+
+func (s Stream[T]) elem() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (s Stream[T]) contentType() string {
+	return ndjson.contentType
+}
+
+func (s Stream[T]) stream(w http.ResponseWriter, r *http.Request, opts HandleOpts, code int) {
+	if s == nil {
+		w.WriteHeader(code)
+		return
+	}
+
+	serveStream(w, r, opts, code, s, ndjson)
+}
+
+func (s EventStream[T]) elem() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (s EventStream[T]) contentType() string {
+	return sse.contentType
+}
+
+func (s EventStream[T]) stream(w http.ResponseWriter, r *http.Request, opts HandleOpts, code int) {
+	if s == nil {
+		w.WriteHeader(code)
+		return
+	}
+
+	serveStream(w, r, opts, code, s, sse)
+}
+
+// serveStream writes the values the producer sends in the format, see Stream.
+func serveStream[T any](
+	w http.ResponseWriter, r *http.Request, opts HandleOpts, code int,
+	produce func(ctx context.Context, send func(T) error) error, format streamFormat,
+) {
+	ctx, cancelCause := context.WithCancelCause(r.Context())
+	cancel := func() { cancelCause(nil) }
+	defer cancel()
+
+	streams := streamsOf(r)
+	entry, ok := streams.enter(func() { cancelCause(ErrShuttingDown) })
+	if !ok {
+		opts.rejectDraining(w, r)
+		return
+	}
+
+	defer streams.leave(entry)
+
+	rc := http.NewResponseController(w)
+	timeout := opts.streamWriteTimeout()
+	if timeout > 0 {
+		// The deadline outlives the request on a kept-alive connection, reset it for the next request.
+		defer func() { _ = rc.SetWriteDeadline(time.Time{}) }()
+	}
+
+	b := getJSONBuffer()
+	defer putJSONBuffer(b)
+
+	enc := opts.JSONCodec().NewEncoder(&b.buf)
+	started := false
+	start := func() {
+		started = true
+		w.Header().Set("Content-Type", format.contentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if format.contentType == ContentTypeEventStream {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+
+		w.WriteHeader(code)
+	}
+
+	var sendErr error
+	// failed is set if encoding or writing a value failed, the response is broken then.
+	failed := false
+	send := func(v T) error {
+		if sendErr != nil {
+			return ErrStreamClosed
+		}
+
+		if err := ctx.Err(); err != nil {
+			sendErr = err
+			return err
+		}
+
+		b.buf.Reset()
+		if err := enc.Encode(v); err != nil {
+			opts.logEncodeFailure(err)
+			sendErr = errors.Join(ErrEncodeResponse, err)
+			failed = true
+			cancel()
+			return sendErr
+		}
+
+		if !started {
+			start()
+		}
+
+		if timeout > 0 {
+			_ = rc.SetWriteDeadline(time.Now().Add(timeout))
+		}
+
+		err := format.frame(w, b.buf.Bytes())
+		if err == nil {
+			err = rc.Flush()
+			if errors.Is(err, http.ErrNotSupported) {
+				err = nil
+			}
+		}
+
+		if err != nil {
+			sendErr, failed = err, true
+			cancel()
+		}
+
+		return err
+	}
+
+	err := produce(ctx, send)
+	switch {
+	case context.Cause(ctx) == ErrShuttingDown && !failed:
+		if !started {
+			start()
+		}
+
+		if timeout > 0 {
+			_ = rc.SetWriteDeadline(time.Now().Add(timeout))
+		}
+
+		if _, err := io.WriteString(w, format.shutdown); err == nil {
+			_ = rc.Flush()
+		}
+
+		opts.Log.Debug("stream ended by shutdown", "method", r.Method, "path", FullPath(r))
+	case context.Cause(ctx) == ErrShuttingDown:
+	case err == nil && !started:
+		start()
+	case err != nil && !started:
+		code := http.StatusInternalServerError
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			code = statusErr.Status
+		}
+
+		if code >= http.StatusInternalServerError {
+			opts.logFailure("stream failed", "method", r.Method, "path", FullPath(r), "status", code, "error", err)
+		}
+
+		opts.writeError(w, r, err, code)
+	case err != nil && sendErr == nil:
+		opts.logFailure("stream failed", "method", r.Method, "path", FullPath(r), "error", err)
+	}
+}
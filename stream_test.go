@@ -0,0 +1,214 @@
+package gwu_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// streamOf returns an Exec responding with the Stream.
+func streamOf[T any](s gwu.Stream[T]) gwu.Exec[any, gwu.Stream[T]] {
+	return func(context.Context, any, gwu.HandleOpts) (gwu.Stream[T], int, error) {
+		return s, http.StatusOK, nil
+	}
+}
+
+func TestStream(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), streamOf(func(_ context.Context, send func(smallPoem) error) error {
+		for i := int64(1); i <= 3; i++ {
+			if err := send(smallPoem{ID: i, Title: "Ode"}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/poems", nil))
+
+	want := `{"id":1,"title":"Ode"}` + "\n" + `{"id":2,"title":"Ode"}` + "\n" + `{"id":3,"title":"Ode"}` + "\n"
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != gwu.ContentTypeNDJSON ||
+		rec.Body.String() != want || !rec.Flushed {
+		t.Errorf("%d %s %q, flushed %v, want the flushed lines", rec.Code, rec.Header().Get("Content-Type"), rec.Body,
+			rec.Flushed)
+	}
+}
+
+// TestStreamErrorBeforeFirstValue responds with the error like an Exec's error.
+func TestStreamErrorBeforeFirstValue(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), streamOf(func(context.Context, func(int) error) error {
+		return gwu.WithStatus(http.StatusConflict, errors.New("export running"))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/poems", nil))
+	gwutest.AssertError(t, rec, http.StatusConflict, "export running")
+}
+
+// slowClient requests the path over a connection with a small receive buffer, and reads the response one byte
+// every 50ms until the returned function is called.
+func slowClient(t *testing.T, srv *httptest.Server, path string) (stop func()) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = conn.(*net.TCPConn).SetReadBuffer(4 << 10)
+	if _, err := io.WriteString(conn, "GET "+path+" HTTP/1.1\r\nHost: gwu.test\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		b := make([]byte, 1)
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			if _, err := conn.Read(b); err != nil {
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		conn.Close()
+		<-stopped
+	}
+}
+
+// assertNoLeak fails the test if the goroutines do not return to the baseline within a second.
+func assertNoLeak(t *testing.T, baseline int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			t.Fatalf("%d goroutines, want at most %d:\n%s", runtime.NumGoroutine(), baseline,
+				buf[:runtime.Stack(buf, true)])
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestStreamSlowClient blocks the producer on a client reading one byte every 50ms, until a write exceeds the
+// WriteTimeout. send then fails within the timeout, cancels the context, and nothing outlives the stream.
+func TestStreamSlowClient(t *testing.T) {
+	const timeout = 300 * time.Millisecond
+	value := strings.Repeat("a", 16<<10)
+
+	type result struct {
+		err, next, ctxErr error
+		blocked           time.Duration
+	}
+
+	results := make(chan result, 1)
+	produce := func(ctx context.Context, send func(string) error) error {
+		for {
+			start := time.Now()
+			if err := send(value); err != nil {
+				results <- result{err: err, next: send(value), ctxErr: ctx.Err(), blocked: time.Since(start)}
+				return err
+			}
+		}
+	}
+
+	rt := gwu.NewRouter(gwu.Log(gwutest.Logger()), gwu.WriteTimeout(timeout))
+	gwu.Get(rt, "/ndjson", gwu.Empty(), streamOf(produce))
+	gwu.Get(rt, "/sse", gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.EventStream[string], int,
+		error) {
+		return produce, http.StatusOK, nil
+	})
+
+	srv := httptest.NewServer(rt)
+	defer srv.Close()
+
+	for _, path := range []string{"/ndjson", "/sse"} {
+		baseline := runtime.NumGoroutine()
+		stop := slowClient(t, srv, path)
+
+		select {
+		case res := <-results:
+			var netErr net.Error
+			if !errors.As(res.err, &netErr) || !netErr.Timeout() {
+				t.Errorf("%s: send error %v, want a timeout", path, res.err)
+			}
+
+			if res.blocked > timeout+time.Second {
+				t.Errorf("%s: send blocked for %s, want about the write timeout %s", path, res.blocked, timeout)
+			}
+
+			if !errors.Is(res.next, gwu.ErrStreamClosed) || !errors.Is(res.ctxErr, context.Canceled) {
+				t.Errorf("%s: next send %v, context %v, want ErrStreamClosed and a cancelled context", path, res.next,
+					res.ctxErr)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("%s: the producer was not cancelled", path)
+		}
+
+		stop()
+		srv.CloseClientConnections()
+		assertNoLeak(t, baseline)
+	}
+}
+
+// TestStreamDisconnect cancels the producer when the client goes away, also without a write timeout.
+func TestStreamDisconnect(t *testing.T) {
+	done := make(chan error, 1)
+	h := gwu.Handle(gwu.Empty(), streamOf(func(ctx context.Context, send func(int) error) error {
+		err := tick(ctx, send)
+		done <- ctx.Err()
+		return err
+	}), gwu.WriteTimeout(-1))
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	baseline := runtime.NumGoroutine()
+	tr := &http.Transport{}
+	resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bufio.NewReader(resp.Body).ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+	tr.CloseIdleConnections()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("context %v, want it cancelled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the producer was not cancelled")
+	}
+
+	assertNoLeak(t, baseline)
+}
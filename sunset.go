@@ -0,0 +1,83 @@
+package gwu
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrGone is the error of requests to a route after its sunset, see GoneAfterSunset.
+// Is safe to display to the client.
+var ErrGone = errors.New("this endpoint has been removed")
+
+// SunsetObserver is an Observer counting the requests to deprecated routes, see Sunset. Observe sets it like any
+// Observer.
+type SunsetObserver interface {
+	Observer
+	// Deprecated reports a request to the deprecated route.
+	Deprecated(route string)
+}
+
+// Sunset deprecates the handler's route in favor of the successor. Every response carries the Deprecation header, the
+// Sunset header with the date the route will be removed, a Link header to the successor with the relation type
+// "successor-version", and the notice as Warning header with code 299. An empty successor or notice omits the
+// header. Sunset sets the headers like StaticHeaders.
+//
+// Every request is logged at info level with the caller's user agent, and counted with the Observer if it is a
+// SunsetObserver, so you know who still calls the route. Combine Sunset with GoneAfterSunset to remove the route at
+// the sunset date.
+//
+// Example usage:
+//
+//	gwu.Get(rt, "/poems/author/{author}", gwu.PathVal("author"), ctrl.ByAuthor, gwu.Sunset(
+//		time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC), "/poems?author={author}",
+//		"use /poems?author={author}, this endpoint is removed on 2025-06-30",
+//	))
+func Sunset(date time.Time, successor, notice string) HandleOptsFunc {
+	h := http.Header{"Deprecation": {"true"}, "Sunset": {date.UTC().Format(http.TimeFormat)}}
+	if successor != "" {
+		h.Set("Link", "<"+successor+`>; rel="successor-version"`)
+	}
+
+	if notice != "" {
+		h.Set("Warning", `299 - "`+strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(notice)+`"`)
+	}
+
+	gone := ErrGone
+	if successor != "" {
+		gone = fmt.Errorf("%w, use %s", ErrGone, successor)
+	}
+
+	hook := func(r *http.Request, opts HandleOpts) error {
+		opts.Log.Info("deprecated route called", "user_agent", r.UserAgent(), "sunset", date)
+		if obs, ok := opts.observer.(SunsetObserver); ok {
+			route := ""
+			if opts.route != nil {
+				route = opts.route.String()
+			}
+
+			obs.Deprecated(route)
+		}
+
+		if opts.goneAfterSunset && !opts.Clock().Now().Before(date) {
+			return WithStatus(http.StatusGone, gone)
+		}
+
+		return nil
+	}
+
+	return func(opt *HandleOpts) {
+		StaticHeaders(h)(opt)
+		Before(hook)(opt)
+	}
+}
+
+// GoneAfterSunset responds to requests to a route deprecated with Sunset with ErrGone and http.StatusGone from the
+// sunset date on, the error names the successor. Until then, GoneAfterSunset has no effect.
+func GoneAfterSunset() HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.goneAfterSunset = true
+	}
+}
@@ -0,0 +1,125 @@
+package gwu_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// sunsetGauge is a SunsetObserver counting the requests to deprecated routes.
+type sunsetGauge struct {
+	*gauge
+	deprecated map[string]int
+}
+
+func (g *sunsetGauge) Deprecated(route string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.deprecated[route]++
+}
+
+var sunsetDate = time.Date(2027, 3, 31, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+// byAuthor serves the deprecated route with the options and returns the response.
+func byAuthor(t *testing.T, opts ...gwu.HandleOptsFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rt := gwu.NewRouter()
+	gwu.Get(rt, "/poems/author/{author}", gwu.PathVal("author"), noContent[string], opts...)
+
+	r := httptest.NewRequest(http.MethodGet, "/poems/author/keats", nil)
+	r.Header.Set("User-Agent", "poem-cli/1.2")
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, r)
+
+	return rec
+}
+
+func TestSunset(t *testing.T) {
+	log := gwutest.Logger()
+	g := &sunsetGauge{gauge: &gauge{routes: make(map[string]bool)}, deprecated: make(map[string]int)}
+	clock := gwu.NewManualClock(sunsetDate.Add(-time.Hour))
+
+	for i := 0; i < 2; i++ {
+		rec := byAuthor(t, gwu.Log(log), gwu.Observe(g), gwu.WithClock(clock), gwu.Sunset(sunsetDate,
+			"/poems?author=keats", `use /poems?author=keats, "by author" is removed on 2027-03-31`))
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status %d, want 204", rec.Code)
+		}
+
+		for key, want := range map[string][]string{
+			"Deprecation": {"true"},
+			"Sunset":      {"Wed, 31 Mar 2027 10:00:00 GMT"},
+			"Link":        {`</poems?author=keats>; rel="successor-version"`},
+			"Warning":     {`299 - "use /poems?author=keats, \"by author\" is removed on 2027-03-31"`},
+		} {
+			if got := rec.Header().Values(key); !slices.Equal(got, want) {
+				t.Errorf("%s %q, want %q", key, got, want)
+			}
+		}
+	}
+
+	log.AssertLogged(t, slog.LevelInfo, "deprecated route called", "user_agent", "poem-cli/1.2", "sunset", sunsetDate)
+	if got := g.deprecated["GET /poems/author/{author}"]; got != 2 || len(g.deprecated) != 1 {
+		t.Errorf("counted %v, want 2 requests to the route", g.deprecated)
+	}
+}
+
+// TestSunsetWithoutSuccessor omits the Link and Warning headers, and works with an Observer that does not count.
+func TestSunsetWithoutSuccessor(t *testing.T) {
+	rec := byAuthor(t, gwu.Log(gwutest.Logger()), gwu.Observe(&gauge{routes: make(map[string]bool)}),
+		gwu.Sunset(sunsetDate, "", ""))
+
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Deprecation") != "true" ||
+		rec.Header().Get("Sunset") != "Wed, 31 Mar 2027 10:00:00 GMT" || rec.Header().Get("Link") != "" ||
+		rec.Header().Get("Warning") != "" {
+		t.Errorf("%d with headers %v, want 204 with only Deprecation and Sunset", rec.Code, rec.Header())
+	}
+}
+
+func TestGoneAfterSunset(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       time.Time
+		gone      bool
+		successor string
+		status    int
+		msg       string
+	}{
+		{"before", sunsetDate.Add(-time.Second), true, "/poems?author=keats", http.StatusNoContent, ""},
+		{"at the date", sunsetDate, true, "/poems?author=keats", http.StatusGone,
+			"this endpoint has been removed, use /poems?author=keats"},
+		{"after", sunsetDate.Add(24 * time.Hour), true, "/poems?author=keats", http.StatusGone,
+			"this endpoint has been removed, use /poems?author=keats"},
+		{"without successor", sunsetDate, true, "", http.StatusGone, gwu.ErrGone.Error()},
+		{"without GoneAfterSunset", sunsetDate.Add(24 * time.Hour), false, "/poems?author=keats",
+			http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		opts := []gwu.HandleOptsFunc{gwu.Log(gwutest.Logger()), gwu.WithClock(gwu.NewManualClock(tt.now)),
+			gwu.Sunset(sunsetDate, tt.successor, "")}
+		if tt.gone {
+			opts = append(opts, gwu.GoneAfterSunset())
+		}
+
+		rec := byAuthor(t, opts...)
+		if tt.status == http.StatusGone {
+			gwutest.AssertError(t, rec, tt.status, tt.msg)
+		} else if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.status)
+		}
+
+		// The headers are set on the 410 too.
+		if rec.Header().Get("Deprecation") != "true" {
+			t.Errorf("%s: headers %v, want the Deprecation header", tt.name, rec.Header())
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package gwu
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// tenantOptions are the options TenantOpts may override per request.
+var tenantOptions = []string{"BodyReadTimeout", "Before", "Errors", "MaxRequestBytes", "WithJSONCodec"}
+
+// TenantOpts resolves the tenant of every request and applies the tenant's options of tenants on top of the route's
+// options, like for stricter limits or another error format for some tenants. The tenant is added to the logger,
+// HandleOpts.Tenant returns it, e.g. to label metrics in an After hook, and a TenantObserver receives it.
+//
+// Only limits, the error format, and the encoder can be overridden per request: BodyReadTimeout, Before, like for
+// RateLimit, Errors, MaxRequestBytes, and WithJSONCodec. Handle panics if the options of a tenant set other options
+// or invalid values. Tenants without options are handled with the route's options. If resolve returns no tenant or
+// panics, the request is handled with the route's options, and the failure is logged at debug level. TenantOpts never
+// fails a request.
+//
+// Example usage:
+//
+//	api := rt.Group("/api", gwu.TenantOpts(func(r *http.Request) string {
+//		return r.Header.Get("X-Tenant")
+//	}, map[string][]gwu.HandleOptsFunc{
+//		"free": {gwu.MaxRequestBytes(64 << 10), gwu.Before(freeLimit)},
+//	}))
+func TenantOpts(resolve func(r *http.Request) string, tenants map[string][]HandleOptsFunc) HandleOptsFunc {
+	names := make([]string, 0, len(tenants))
+	for tenant := range tenants {
+		names = append(names, tenant)
+	}
+
+	slices.Sort(names)
+
+	var errs []error
+	for _, tenant := range names {
+		if err := tenantErr(tenants[tenant]); err != nil {
+			errs = append(errs, fmt.Errorf("TenantOpts: tenant %q: %w", tenant, err))
+		}
+	}
+
+	return func(opt *HandleOpts) {
+		opt.errs = append(opt.errs, errs...)
+		opt.tenantFn = resolve
+		opt.tenants = tenants
+	}
+}
+
+// Tenant returns the tenant of the request resolved by TenantOpts, or "" if there is none.
+func (o HandleOpts) Tenant() string {
+	return o.req.tenant
+}
+
+// forTenant returns the request's HandleOpts with the options of its tenant, see TenantOpts.
+func (o HandleOpts) forTenant(r *http.Request) HandleOpts {
+	tenant, err := resolveTenant(o.tenantFn, r)
+	if err != nil {
+		o.Log.Debug("tenant not resolved", "method", r.Method, "path", FullPath(r), "error", err)
+		return o
+	}
+
+	t := o
+	for _, fn := range o.tenants[tenant] {
+		fn(&t)
+	}
+
+	t.req.tenant = tenant
+	t.Log = withAttrs(o.Log, "tenant", tenant)
+
+	return t
+}
+
+// resolveTenant calls resolve and returns an error if it returns no tenant or panics.
+func resolveTenant(resolve func(r *http.Request) string, r *http.Request) (tenant string, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("resolve panicked: %v", v)
+		}
+	}()
+
+	if tenant = resolve(r); tenant == "" {
+		return "", errors.New("no tenant")
+	}
+
+	return tenant, nil
+}
+
+// tenantErr returns an error if the tenant options set options TenantOpts may not override, or invalid values.
+func tenantErr(optFns []HandleOptsFunc) error {
+	var overrides HandleOpts
+	for _, fn := range optFns {
+		fn(&overrides)
+	}
+
+	var names []string
+	for _, name := range overrides.optNames() {
+		if !slices.Contains(tenantOptions, name) {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) > 0 {
+		return fmt.Errorf("options %s cannot be overridden per tenant", strings.Join(names, ", "))
+	}
+
+	return errors.Join(overrides.errs...)
+}
@@ -0,0 +1,133 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+// tenantHeader resolves the tenant of a request from the X-Tenant header.
+func tenantHeader(r *http.Request) string {
+	return r.Header.Get("X-Tenant")
+}
+
+func discardBody(context.Context, []byte, gwu.HandleOpts) (gwu.NoBody, int, error) {
+	return gwu.NoBody{}, http.StatusNoContent, nil
+}
+
+func TestTenantOptsBodyLimits(t *testing.T) {
+	h := gwu.Handle(gwu.RawBody(), discardBody, gwu.MaxRequestBytes(1024), gwu.TenantOpts(tenantHeader,
+		map[string][]gwu.HandleOptsFunc{
+			"free": {gwu.MaxRequestBytes(16)},
+			"pro":  {gwu.MaxRequestBytes(4096)},
+		}))
+
+	tests := []struct {
+		tenant string
+		size   int
+		code   int
+	}{
+		{"free", 16, http.StatusNoContent},
+		{"free", 17, http.StatusRequestEntityTooLarge},
+		{"pro", 2048, http.StatusNoContent},
+		{"pro", 4097, http.StatusRequestEntityTooLarge},
+		{"", 1024, http.StatusNoContent},
+		{"", 1025, http.StatusRequestEntityTooLarge},
+		{"other", 1025, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", tt.size)))
+		r.Header.Set("X-Tenant", tt.tenant)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != tt.code {
+			t.Errorf("tenant %q, %d bytes: status %d, want %d", tt.tenant, tt.size, rec.Code, tt.code)
+		}
+	}
+}
+
+func TestTenantOptsRejectsOptions(t *testing.T) {
+	defer func() {
+		v := recover()
+		if v == nil || !strings.Contains(v.(error).Error(), `tenant "free": options Log cannot be overridden`) {
+			t.Errorf("panic %v, want the disallowed Log option of tenant free", v)
+		}
+	}()
+
+	gwu.Handle(gwu.Empty(), getSmallPoem, gwu.TenantOpts(tenantHeader, map[string][]gwu.HandleOptsFunc{
+		"free": {gwu.Log(gwu.NoopLogger()), gwu.MaxRequestBytes(16)},
+	}))
+}
+
+func TestTenantOptsResolvePanics(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(_ context.Context, _ any, opts gwu.HandleOpts) (string, int, error) {
+		return opts.Tenant(), http.StatusOK, nil
+	}, gwu.TenantOpts(func(*http.Request) string { panic("no header") }, nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "\"\"\n" {
+		t.Errorf("response %d %q, want 200 without tenant", rec.Code, rec.Body)
+	}
+}
+
+// tenantGauge is a TenantObserver counting the in-flight requests per tenant.
+type tenantGauge struct {
+	mu      sync.Mutex
+	route   map[string]int
+	tenants map[string]int
+}
+
+func (g *tenantGauge) InFlight(route string, delta int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.route[route] += delta
+}
+
+func (g *tenantGauge) Shed(string) {}
+
+func (g *tenantGauge) TenantInFlight(route, tenant string, delta int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.tenants[route+" "+tenant] += delta
+}
+
+func (g *tenantGauge) TenantShed(string, string) {}
+
+func TestTenantObserver(t *testing.T) {
+	g := &tenantGauge{route: make(map[string]int), tenants: make(map[string]int)}
+	rt := gwu.NewRouter(gwu.Observe(g), gwu.TenantOpts(tenantHeader, nil))
+
+	var seen map[string]int
+	gwu.HandleRoute(rt, "GET /poems", gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.NoBody, int, error) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		seen = map[string]int{}
+		for k, v := range g.tenants {
+			seen[k] = v
+		}
+
+		return gwu.NoBody{}, http.StatusNoContent, nil
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/poems", nil)
+	r.Header.Set("X-Tenant", "free")
+	rt.ServeHTTP(httptest.NewRecorder(), r)
+
+	if seen["GET /poems free"] != 1 {
+		t.Errorf("in flight during the request: %v, want GET /poems free: 1", seen)
+	}
+
+	if g.tenants["GET /poems free"] != 0 || len(g.route) != 0 {
+		t.Errorf("in flight after the request: tenants %v, routes %v, want 0 and none", g.tenants, g.route)
+	}
+}
@@ -0,0 +1,108 @@
+package gwu
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// UnixSeconds is the layout of TimestampWindow for timestamps in seconds since the Unix epoch, like 1700000000.
+const UnixSeconds = "unix"
+
+var (
+	// ErrMissingTimestamp is the error of requests without the timestamp header of TimestampWindow.
+	// Is safe to display to the client.
+	ErrMissingTimestamp = errors.New("missing request timestamp")
+	// ErrInvalidTimestamp is the error of requests with a timestamp TimestampWindow cannot parse.
+	// Is safe to display to the client.
+	ErrInvalidTimestamp = errors.New("invalid request timestamp")
+)
+
+// SkewError is the error of requests with a timestamp outside the window of TimestampWindow, it wraps
+// ErrStaleTimestamp. Is safe to display to the client.
+type SkewError struct {
+	// Skew is the timestamp minus the server's time, it is positive for a timestamp in the future.
+	Skew time.Duration
+}
+
+func (e *SkewError) Error() string {
+	skew := e.Skew.Round(time.Second)
+	if skew < 0 {
+		return "request timestamp is " + (-skew).String() + " behind the server time, check the client clock"
+	}
+
+	return "request timestamp is " + skew.String() + " ahead of the server time, check the client clock"
+}
+
+func (e *SkewError) Unwrap() error {
+	return ErrStaleTimestamp
+}
+
+// TimestampWindow CnIn parses the timestamp of the header with the layout, a time.Parse layout like time.RFC3339
+// or UnixSeconds, and rejects timestamps more than maxSkew before or after the handler's Clock. Timestamps exactly
+// maxSkew off are accepted.
+//
+// TimestampWindow responds with http.StatusUnauthorized and ErrMissingTimestamp, ErrInvalidTimestamp, or a
+// SkewError telling the client how far its clock is off. Combine it with the CnIn verifying the signature with
+// Join, the signature must cover the timestamp.
+//
+// Example usage:
+//
+//	in := gwu.Join(gwu.TimestampWindow("X-Signature-Time", time.RFC3339, 5*time.Minute), SignedIn(secret))
+//	gwu.Post(rt, "/partner/orders", in, ctrl.CreateOrder)
+func TimestampWindow(header, layout string, maxSkew time.Duration) CnIn[time.Time] {
+	return func(r *http.Request, opts HandleOpts) (time.Time, error) {
+		v := r.Header.Get(header)
+		if v == "" {
+			return time.Time{}, WithStatus(http.StatusUnauthorized, ErrMissingTimestamp)
+		}
+
+		ts, err := parseTimestamp(v, layout)
+		if err != nil {
+			return time.Time{}, WithStatus(http.StatusUnauthorized, ErrInvalidTimestamp)
+		}
+
+		if skew := ts.Sub(opts.Clock().Now()); skew > maxSkew || skew < -maxSkew {
+			return time.Time{}, WithStatus(http.StatusUnauthorized, &SkewError{Skew: skew})
+		}
+
+		return ts, nil
+	}
+}
+
+// parseTimestamp parses the timestamp with the layout of TimestampWindow.
+func parseTimestamp(v, layout string) (time.Time, error) {
+	if layout != UnixSeconds {
+		return time.Parse(layout, v)
+	}
+
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(sec, 0), nil
+}
+
+// Pair is the input of two CnIns joined with Join.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Join CnIn constructs the input with both CnIns, in order. If the first fails, Join returns its error without
+// running the second, so put cheap checks, like TimestampWindow, before expensive ones, like reading and verifying
+// a signed body.
+func Join[A, B any](first CnIn[A], second CnIn[B]) CnIn[Pair[A, B]] {
+	return func(r *http.Request, opts HandleOpts) (Pair[A, B], error) {
+		var p Pair[A, B]
+		var err error
+		if p.First, err = first(r, opts); err != nil {
+			return p, err
+		}
+
+		p.Second, err = second(r, opts)
+		return p, err
+	}
+}
@@ -0,0 +1,107 @@
+package gwu_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestTimestampWindow(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	unix := func(d time.Duration) string { return strconv.FormatInt(now.Add(d).Unix(), 10) }
+	rfc := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+
+	tests := []struct {
+		name   string
+		layout string
+		value  string
+		skew   time.Duration
+		err    error
+	}{
+		{"now", gwu.UnixSeconds, unix(0), 0, nil},
+		{"at the boundary", gwu.UnixSeconds, unix(-5 * time.Minute), 0, nil},
+		{"future at the boundary", gwu.UnixSeconds, unix(5 * time.Minute), 0, nil},
+		{"just past the boundary", gwu.UnixSeconds, unix(-5*time.Minute - time.Second), -5*time.Minute - time.Second,
+			gwu.ErrStaleTimestamp},
+		{"future", gwu.UnixSeconds, unix(time.Hour), time.Hour, gwu.ErrStaleTimestamp},
+		{"RFC3339 at the boundary", time.RFC3339, rfc(-5 * time.Minute), 0, nil},
+		{"RFC3339 just past the boundary", time.RFC3339, rfc(5*time.Minute + time.Second), 5*time.Minute + time.Second,
+			gwu.ErrStaleTimestamp},
+		{"RFC3339 with offset", time.RFC3339, "2026-10-14T14:04:00+02:00", 0, nil},
+		{"missing", gwu.UnixSeconds, "", 0, gwu.ErrMissingTimestamp},
+		{"invalid", gwu.UnixSeconds, "yesterday", 0, gwu.ErrInvalidTimestamp},
+		{"wrong layout", time.RFC3339, unix(0), 0, gwu.ErrInvalidTimestamp},
+		{"fractional seconds", gwu.UnixSeconds, unix(0) + ".5", 0, gwu.ErrInvalidTimestamp},
+	}
+
+	opts := gwutest.Opts(gwu.WithClock(gwu.NewManualClock(now)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/partner/orders", nil)
+			if tt.value != "" {
+				r.Header.Set("X-Signature-Time", tt.value)
+			}
+
+			ts, err := gwu.TimestampWindow("X-Signature-Time", tt.layout, 5*time.Minute)(r, opts)
+			if tt.err == nil {
+				if err != nil || ts.IsZero() {
+					t.Errorf("%v, %v, want the timestamp", ts, err)
+				}
+
+				return
+			}
+
+			var statusErr *gwu.StatusError
+			if !errors.Is(err, tt.err) || !errors.As(err, &statusErr) || statusErr.Status != http.StatusUnauthorized {
+				t.Fatalf("error %v, want %v with 401", err, tt.err)
+			}
+
+			var skewErr *gwu.SkewError
+			if errors.As(err, &skewErr) != (tt.skew != 0) || skewErr != nil && skewErr.Skew != tt.skew {
+				t.Errorf("error %v, want a skew of %v", err, tt.skew)
+			}
+		})
+	}
+}
+
+func TestSkewError(t *testing.T) {
+	for skew, want := range map[time.Duration]string{
+		-5*time.Minute - 1400*time.Millisecond: "5m1s behind the server time",
+		time.Hour:                              "1h0m0s ahead of the server time",
+	} {
+		want = "request timestamp is " + want + ", check the client clock"
+		if got := (&gwu.SkewError{Skew: skew}).Error(); got != want {
+			t.Errorf("%v: %q, want %q", skew, got, want)
+		}
+	}
+}
+
+// TestJoin runs the second CnIn only if the first succeeds.
+func TestJoin(t *testing.T) {
+	ran := false
+	second := func(*http.Request, gwu.HandleOpts) (string, error) {
+		ran = true
+		return "signed", nil
+	}
+
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	in := gwu.Join(gwu.TimestampWindow("X-Signature-Time", gwu.UnixSeconds, time.Minute), second)
+	opts := gwutest.Opts(gwu.WithClock(clock))
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	if _, err := in(r, opts); !errors.Is(err, gwu.ErrMissingTimestamp) || ran {
+		t.Errorf("error %v, second ran %v, want ErrMissingTimestamp without the second", err, ran)
+	}
+
+	r.Header.Set("X-Signature-Time", strconv.FormatInt(clock.Now().Unix(), 10))
+	p, err := in(r, opts)
+	if err != nil || !p.First.Equal(clock.Now()) || p.Second != "signed" {
+		t.Errorf("%+v, %v, want both inputs", p, err)
+	}
+}
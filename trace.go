@@ -0,0 +1,112 @@
+package gwu
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Trace is the W3C trace context of a request, see https://www.w3.org/TR/trace-context/.
+type Trace struct {
+	// TraceID is the 32 hex character trace-id.
+	TraceID string
+	// SpanID is the 16 hex character parent-id, the span of the caller.
+	SpanID string
+	// Flags are the trace-flags.
+	Flags byte
+	// State is the raw tracestate header, it is empty if the request had none.
+	State string
+}
+
+// Sampled reports whether the caller sampled the trace.
+func (t Trace) Sampled() bool {
+	return t.Flags&1 == 1
+}
+
+// Traceparent returns the traceparent header value for a downstream call, use it to propagate the trace. The value
+// carries the TraceID and Flags with a new span id for the call, the SpanID of the caller is not forwarded. Each call
+// generates a new span id.
+func (t Trace) Traceparent() string {
+	return "00-" + t.TraceID + "-" + newSpanID() + "-" + hex.EncodeToString([]byte{t.Flags})
+}
+
+// newSpanID returns a random, non-zero 16 hex character span id.
+func newSpanID() string {
+	var id [8]byte
+	for id == [8]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+
+	return hex.EncodeToString(id[:])
+}
+
+type traceCtxKey struct{}
+
+// TraceContext parses the W3C traceparent and tracestate headers of each request.
+// Handle stores the Trace in the context passed to the Exec, retrieve it with TraceFrom, and adds the trace_id and
+// span_id to the request's logger. Per the specification, TraceContext ignores malformed headers.
+func TraceContext() HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.traceContext = true
+	}
+}
+
+// ContextWithTrace returns a copy of ctx that carries the given Trace.
+func ContextWithTrace(ctx context.Context, t Trace) context.Context {
+	return context.WithValue(ctx, traceCtxKey{}, t)
+}
+
+// TraceFrom returns the Trace stored in ctx, and whether ctx carries a Trace.
+func TraceFrom(ctx context.Context) (Trace, bool) {
+	t, ok := ctx.Value(traceCtxKey{}).(Trace)
+	return t, ok
+}
+
+// parseTrace parses the trace context headers of r, it reports false if the traceparent is missing or malformed.
+func parseTrace(r *http.Request) (Trace, bool) {
+	v := strings.TrimSpace(r.Header.Get("traceparent"))
+	// version-traceid-parentid-flags, future versions may append fields.
+	if len(v) < 55 || (len(v) > 55 && (v[:2] == "00" || v[55] != '-')) {
+		return Trace{}, false
+	}
+
+	if v[2] != '-' || v[35] != '-' || v[52] != '-' {
+		return Trace{}, false
+	}
+
+	version, traceID, spanID, flags := v[:2], v[3:35], v[36:52], v[53:55]
+	if !isLowerHex(version) || version == "ff" || !isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(flags) {
+		return Trace{}, false
+	}
+
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return Trace{}, false
+	}
+
+	return Trace{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Flags:   unhex(flags[0])<<4 | unhex(flags[1]),
+		State:   strings.Join(r.Header.Values("tracestate"), ","),
+	}, true
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func unhex(c byte) byte {
+	if c >= 'a' {
+		return c - 'a' + 10
+	}
+
+	return c - '0'
+}
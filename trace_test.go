@@ -0,0 +1,115 @@
+package gwu_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+const (
+	traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID  = "00f067aa0ba902b7"
+)
+
+func TestTraceContext(t *testing.T) {
+	valid := "00-" + traceID + "-" + spanID + "-01"
+	tests := []struct {
+		name        string
+		traceparent string
+		tracestate  []string
+		want        *gwu.Trace
+	}{
+		{"valid", valid, []string{"congo=t61rcWkgMzE", "rojo=00f067aa0ba902b7"},
+			&gwu.Trace{TraceID: traceID, SpanID: spanID, Flags: 1, State: "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7"}},
+		{"not sampled", "00-" + traceID + "-" + spanID + "-00", nil,
+			&gwu.Trace{TraceID: traceID, SpanID: spanID}},
+		{"surrounding space", " " + valid + " ", nil, &gwu.Trace{TraceID: traceID, SpanID: spanID, Flags: 1}},
+		{"future version with fields", "cc-" + traceID + "-" + spanID + "-01-what", nil,
+			&gwu.Trace{TraceID: traceID, SpanID: spanID, Flags: 1}},
+		{"absent", "", nil, nil},
+		{"too short", valid[:54], nil, nil},
+		{"version 00 with fields", valid + "-what", nil, nil},
+		{"invalid version", "ff-" + traceID + "-" + spanID + "-01", nil, nil},
+		{"upper case", "00-" + strings.ToUpper(traceID) + "-" + spanID + "-01", nil, nil},
+		{"zero trace id", "00-" + strings.Repeat("0", 32) + "-" + spanID + "-01", nil, nil},
+		{"zero span id", "00-" + traceID + "-" + strings.Repeat("0", 16) + "-01", nil, nil},
+		{"wrong separator", "00_" + traceID + "-" + spanID + "-01", nil, nil},
+		{"not hex", "00-" + traceID + "-" + spanID + "-0g", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *gwu.Trace
+			exec := func(ctx context.Context, _ any, _ gwu.HandleOpts) (gwu.NoBody, int, error) {
+				if tr, ok := gwu.TraceFrom(ctx); ok {
+					got = &tr
+				}
+
+				return gwu.NoBody{}, http.StatusNoContent, nil
+			}
+
+			log := gwutest.Logger()
+			h := gwu.Handle(gwu.Empty(), exec, gwu.Log(log), gwu.TraceContext(), gwu.Before(
+				func(_ *http.Request, opts gwu.HandleOpts) error {
+					opts.Log.Debug("before")
+					return nil
+				}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.traceparent != "" {
+				r.Header.Set("traceparent", tt.traceparent)
+			}
+
+			for _, s := range tt.tracestate {
+				r.Header.Add("tracestate", s)
+			}
+
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			// A malformed traceparent is ignored, the request is handled without trace.
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Fatalf("trace %+v, want %+v", got, tt.want)
+			}
+
+			if tt.want != nil {
+				log.AssertLogged(t, slog.LevelDebug, "before", "trace_id", traceID, "span_id", spanID)
+			} else if log.Contains("before", "trace_id", traceID) {
+				t.Errorf("entries %v, want no trace_id", log.Entries())
+			}
+		})
+	}
+}
+
+// TestTraceparent propagates the trace id and flags with a new span id for every downstream call.
+func TestTraceparent(t *testing.T) {
+	tr := gwu.Trace{TraceID: traceID, SpanID: spanID, Flags: 1}
+	format := regexp.MustCompile(`^00-` + traceID + `-([0-9a-f]{16})-01$`)
+
+	seen := map[string]bool{spanID: true}
+	for range 10 {
+		tp := tr.Traceparent()
+		m := format.FindStringSubmatch(tp)
+		if m == nil {
+			t.Fatalf("traceparent %q, want the trace id, a span id, and the flags", tp)
+		}
+
+		if seen[m[1]] || strings.Trim(m[1], "0") == "" {
+			t.Fatalf("traceparent %q, want a new non-zero span id", tp)
+		}
+
+		seen[m[1]] = true
+	}
+
+	// The flags are formatted as two hex characters.
+	tr.Flags = 0xab
+	if tp := tr.Traceparent(); !strings.HasSuffix(tp, "-ab") {
+		t.Errorf("traceparent %q, want the flags ab", tp)
+	}
+}
@@ -0,0 +1,71 @@
+package gwu
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Upserted is the Out value of an upsert, see Upsert.
+type Upserted[Out any] struct {
+	Out      Out
+	Created  bool
+	Location string
+}
+
+// Upsert returns the output of an idempotent create-or-replace, like a PUT of a resource with a client chosen ID.
+// Handle responds with http.StatusCreated and the Location header if the resource was created, and with
+// http.StatusOK if an existing one was replaced, instead of the status code returned by the Exec. The body is out
+// in both cases. Handle logs a warning if created is true, but location is empty, and responds without Location.
+//
+// For conditional replaces, compare the If-Match header to the current ETag before replacing, and respond to a
+// mismatch with http.StatusPreconditionFailed. If-None-Match: * asks to create only, respond to an existing resource
+// with http.StatusPreconditionFailed as well.
+//
+// Example usage:
+//
+//	func (c *Ctrl) Put(ctx context.Context, poem Poem, opts gwu.HandleOpts) (gwu.Upserted[Poem], int, error) {
+//		r := gwu.RequestFrom(ctx) // with gwu.ExposeRequest
+//		cur, exists := c.repo.Poem(ctx, r.PathValue("id"))
+//		if match := r.Header.Get("If-Match"); match != "" && (!exists || match != cur.ETag()) {
+//			return gwu.Upserted[Poem]{}, 0, gwu.WithStatus(http.StatusPreconditionFailed, ErrPoemChanged)
+//		}
+//
+//		c.repo.Save(ctx, poem)
+//		opts.Header().Set("ETag", poem.ETag())
+//		return gwu.Upsert(poem, !exists, "/poem/"+poem.ID), 0, nil
+//	}
+func Upsert[Out any](out Out, created bool, location string) Upserted[Out] {
+	return Upserted[Out]{Out: out, Created: created, Location: location}
+}
+
+func (u Upserted[Out]) upsert() (v any, created bool, location string) {
+	return u.Out, u.Created, u.Location
+}
+
+func (u Upserted[Out]) elem() reflect.Type {
+	return reflect.TypeFor[Out]()
+}
+
+// upserted is implemented by all Upserted types.
+type upserted interface {
+	upsert() (v any, created bool, location string)
+	elem() reflect.Type
+}
+
+var upsertedType = reflect.TypeFor[upserted]()
+
+// upsertCode sets the Location header of a created resource and returns the output and status code of an upsert.
+func (o HandleOpts) upsertCode(w http.ResponseWriter, r *http.Request, u upserted) (any, int) {
+	v, created, location := u.upsert()
+	if !created {
+		return v, http.StatusOK
+	}
+
+	if location == "" {
+		logWarn(o.Log, "upsert created a resource without location", "method", r.Method, "path", FullPath(r))
+	} else {
+		w.Header().Set("Location", location)
+	}
+
+	return v, http.StatusCreated
+}
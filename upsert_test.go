@@ -0,0 +1,81 @@
+package gwu_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+func TestUpsert(t *testing.T) {
+	log := gwutest.Logger()
+	stored := map[string]bool{"7": true}
+	put := func(_ context.Context, id string, _ gwu.HandleOpts) (gwu.Upserted[smallPoem], int, error) {
+		location := "/poem/" + id
+		if id == "nowhere" {
+			location = ""
+		}
+
+		created := !stored[id]
+		stored[id] = true
+
+		// The status code of the Exec is ignored.
+		return gwu.Upsert(smallPoem{ID: 7, Title: "Ode"}, created, location), http.StatusAccepted, nil
+	}
+
+	rt := gwu.NewRouter(gwu.Log(log))
+	gwu.Put(rt, "/poem/{id}", gwu.PathVal("id"), put)
+
+	tests := []struct {
+		name, id string
+		status   int
+		location string
+	}{
+		{"created", "8", http.StatusCreated, "/poem/8"},
+		{"replaced", "7", http.StatusOK, ""},
+		{"replaced after create", "8", http.StatusOK, ""},
+		{"created without location", "nowhere", http.StatusCreated, ""},
+	}
+
+	for _, tt := range tests {
+		log.Reset()
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/poem/"+tt.id, nil))
+
+		_, hasLocation := rec.Header()["Location"]
+		if rec.Code != tt.status || rec.Header().Get("Location") != tt.location || hasLocation != (tt.location != "") {
+			t.Errorf("%s: %d with Location %q, want %d with %q", tt.name, rec.Code, rec.Header().Get("Location"),
+				tt.status, tt.location)
+		}
+
+		if got := strings.TrimSpace(rec.Body.String()); got != `{"id":7,"title":"Ode"}` {
+			t.Errorf("%s: body %s, want the poem", tt.name, got)
+		}
+
+		if warned := log.Filter(slog.LevelWarn); (len(warned) > 0) != (tt.id == "nowhere") {
+			t.Errorf("%s: logged %v", tt.name, warned)
+		}
+	}
+
+	log.AssertLogged(t, slog.LevelWarn, "upsert created a resource without location", "method", http.MethodPut,
+		"path", "/poem/nowhere")
+}
+
+// TestUpsertError responds with the error of the Exec instead of the upsert.
+func TestUpsertError(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.Upserted[smallPoem], int, error) {
+		return gwu.Upserted[smallPoem]{}, http.StatusPreconditionFailed, errPoemNotFound
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/poem/7", nil))
+	gwutest.AssertError(t, rec, http.StatusPreconditionFailed, errPoemNotFound.Error())
+	if rec.Header().Get("Location") != "" {
+		t.Errorf("Location %q, want none", rec.Header().Get("Location"))
+	}
+}
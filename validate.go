@@ -0,0 +1,189 @@
+package gwu
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// knownMethods are the methods a Router accepts in patterns.
+var knownMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "CONNECT", "TRACE"}
+
+// CollectRouteErrors makes a Router record invalid and conflicting patterns instead of panicking, the Router skips
+// these routes. Retrieve the errors with Router.Err after registering all routes.
+//
+// Set it with NewRouter, the options of groups and routes do not affect it.
+//
+// Example usage:
+//
+//	rt := gwu.NewRouter(gwu.CollectRouteErrors())
+//	registerRoutes(rt)
+//	if err := rt.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+func CollectRouteErrors() HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		opt.collectRouteErrs = true
+	}
+}
+
+// Err returns the errors of all invalid and conflicting patterns registered with the Router, its groups, and its
+// host routers, if the Router was created with CollectRouteErrors.
+func (rt *Router) Err() error {
+	rt.mu.Lock()
+	errs := append([]error(nil), rt.errs...)
+	hosts := rt.hosts
+	rt.mu.Unlock()
+
+	for _, h := range hosts {
+		if err := h.rt.Err(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// fail panics with the error, or records it if the Router collects route errors. The caller must hold rt.mu.
+func (rt *Router) fail(err error) {
+	if !rt.collectErrs {
+		panic(err)
+	}
+
+	rt.errs = append(rt.errs, err)
+}
+
+// checkPattern validates a pattern registered by the user and reports whether it is valid. It reports all problems
+// of the pattern together with the call site of the registration, see fail.
+func (rt *Router) checkPattern(p pattern) bool {
+	errs := validatePattern(p)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	site := callSite()
+	key := p.method + " " + p.host + normalizePath(p.path)
+	if first, ok := rt.sites[key]; ok {
+		errs = append(errs, fmt.Errorf("duplicate of the pattern registered at %s", first))
+	}
+
+	if len(errs) > 0 {
+		rt.fail(fmt.Errorf("gwu: register %q at %s: %w", p.String(), site, errors.Join(errs...)))
+		return false
+	}
+
+	rt.sites[key] = site
+
+	return true
+}
+
+// validatePattern returns the problems of a pattern: an unknown method, a method without a space, a missing leading
+// slash, and malformed wildcards.
+func validatePattern(p pattern) []error {
+	var errs []error
+	if p.method != "" && !slices.Contains(knownMethods, p.method) {
+		errs = append(errs, fmt.Errorf("unknown method %q", p.method))
+	}
+
+	if p.method == "" && slices.Contains(knownMethods, strings.ToUpper(p.host)) {
+		errs = append(errs, fmt.Errorf("method %q must be followed by a space", p.host))
+	}
+
+	if !strings.HasPrefix(p.path, "/") {
+		errs = append(errs, errors.New("path must start with a slash"))
+		return errs
+	}
+
+	segments := strings.Split(p.path[1:], "/")
+	for i, seg := range segments {
+		if err := validateSegment(seg, i == len(segments)-1); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// validateSegment returns the problem of a path segment, if any.
+func validateSegment(seg string, last bool) error {
+	if !strings.ContainsAny(seg, "{}") {
+		return nil
+	}
+
+	if seg[0] != '{' || seg[len(seg)-1] != '}' || strings.Count(seg, "{") != 1 || strings.Count(seg, "}") != 1 {
+		return fmt.Errorf("segment %q: a wildcard must be a whole segment in balanced braces", seg)
+	}
+
+	name := seg[1 : len(seg)-1]
+	if name == "$" {
+		if !last {
+			return errors.New("{$} must be the last segment")
+		}
+
+		return nil
+	}
+
+	name, rest := strings.CutSuffix(name, "...")
+	if rest && !last {
+		return fmt.Errorf("segment %q: a wildcard with ... must be the last segment", seg)
+	}
+
+	if !isIdent(name) {
+		return fmt.Errorf("segment %q: wildcard name %q is no Go identifier", seg, name)
+	}
+
+	return nil
+}
+
+// isIdent reports whether s is a valid Go identifier, as http.ServeMux requires for wildcard names.
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, c := range s {
+		if c != '_' && !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalizePath removes the wildcard names from a path, patterns that only differ in wildcard names are duplicates.
+func normalizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}' && seg != "{$}" {
+			if strings.HasSuffix(seg, "...}") {
+				segments[i] = "{...}"
+			} else {
+				segments[i] = "{}"
+			}
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// gwuPkg is the prefix of the function names of package gwu.
+var gwuPkg = reflect.TypeFor[Router]().PkgPath() + "."
+
+// callSite returns the file and line of the first caller outside of package gwu.
+func callSite() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, gwuPkg) {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package gwu_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+)
+
+func TestRouterMalformedPatterns(t *testing.T) {
+	tests := []struct {
+		name, pattern, want string
+	}{
+		{"unknown method", "FETCH /poems", `unknown method "FETCH"`},
+		{"method without space", "GET/poems", `method "GET" must be followed by a space`},
+		{"no leading slash", "GET poems", "path must start with a slash"},
+		{"unclosed brace", "GET /poems/{id", `segment "{id": a wildcard must be a whole segment in balanced braces`},
+		{"unopened brace", "GET /poems/id}", `segment "id}"`},
+		{"two wildcards", "GET /poems/{a}{b}", `segment "{a}{b}"`},
+		{"partial segment", "GET /poems/x{id}", `segment "x{id}"`},
+		{"end not last", "GET /poems/{$}/lines", "{$} must be the last segment"},
+		{"rest not last", "GET /poems/{rest...}/lines", "a wildcard with ... must be the last segment"},
+		{"no identifier", "GET /poems/{1id}", `wildcard name "1id" is no Go identifier`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := gwu.NewRouter(gwu.CollectRouteErrors())
+			rt.Handle(tt.pattern, textHandler("poems"))
+			err := rt.Err()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error %v, want %q", err, tt.want)
+			}
+
+			if !strings.Contains(err.Error(), "validate_test.go:") {
+				t.Errorf("error %q without the call site", err)
+			}
+		})
+	}
+}
+
+// TestRouterPatternConflicts reports duplicates across groups and conflicts of the mux at the call site of the
+// registration.
+func TestRouterPatternConflicts(t *testing.T) {
+	rt := gwu.NewRouter(gwu.CollectRouteErrors())
+	first := nextLine()
+	rt.Group("/api").Handle("GET /poems/{id}", textHandler("first"))
+	duplicate := nextLine()
+	rt.Group("/api/poems").Handle("GET /{name}", textHandler("duplicate"))
+	rt.Handle("GET /a/{x}/b", textHandler("a"))
+	conflict := nextLine()
+	rt.Handle("GET /a/b/{y}", textHandler("conflict"))
+
+	err := rt.Err()
+	if err == nil {
+		t.Fatal("no error")
+	}
+
+	for _, want := range []string{
+		`register "GET /api/poems/{name}" at ` + duplicate + ": duplicate of the pattern registered at " + first,
+		`register "GET /a/b/{y}" at ` + conflict + ":",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q, want %q", err, want)
+		}
+	}
+}
+
+// nextLine returns the file and line of the line after its call.
+func nextLine() string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", file, line+1)
+}
+
+func TestRouterPatternPanics(t *testing.T) {
+	defer func() {
+		v := recover()
+		if v == nil || !strings.Contains(fmt.Sprint(v), `unknown method "FETCH"`) {
+			t.Errorf("panic %v, want the unknown method", v)
+		}
+	}()
+
+	gwu.NewRouter().Handle("FETCH /poems", textHandler("poems"))
+}
@@ -0,0 +1,142 @@
+package gwu
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Key is the key of a request-scoped value of type T, see NewKey. Keys are compared by identity, so the keys of
+// different packages never collide, even with the same name.
+//
+// The values of a request live in its HandleOpts: the Before hooks, the CnIn, the Exec, and the After hooks of a
+// request share them, like the response headers of HandleOpts.Header. Use them for state that hooks, CnIns, and the
+// Exec exchange about the handling of the request, like an error ID or the tenant a hook resolved. Use the request's
+// context instead for values the code called by the Exec needs, and for values that must outlive the request, like
+// in goroutines the Exec starts. Values are only valid during the request.
+//
+// Example usage:
+//
+//	var tenantKey = gwu.NewKey[Tenant]("tenant")
+//
+//	func resolveTenant(r *http.Request, opts gwu.HandleOpts) error {
+//		tenantKey.Set(opts, tenantOf(r))
+//		return nil
+//	}
+//
+//	// In the CnIn or Exec of a route with gwu.Before(resolveTenant):
+//	tenant, ok := tenantKey.Get(opts)
+type Key[T any] struct {
+	id *keyID
+}
+
+// keyID is the identity of a Key.
+type keyID struct {
+	name string
+}
+
+// NewKey returns a new Key of type T, the name is for debugging only. Create keys once, e.g. as package variables.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{id: &keyID{name: name}}
+}
+
+// String returns the name of the key.
+func (k Key[T]) String() string {
+	if k.id == nil {
+		return ""
+	}
+
+	return k.id.name
+}
+
+// Set sets the value of the key for the request of the HandleOpts. Outside of a request, Set does nothing.
+// Set is not safe for concurrent use, like the request's HandleOpts.
+func (k Key[T]) Set(opts HandleOpts, v T) {
+	vals := opts.req.vals
+	if vals == nil || k.id == nil {
+		return
+	}
+
+	for i := range vals.entries {
+		if vals.entries[i].key == k.id {
+			vals.entries[i].v = v
+			return
+		}
+	}
+
+	vals.entries = append(vals.entries, valueEntry{key: k.id, v: v})
+}
+
+// Get returns the value of the key for the request of the HandleOpts, and whether it is set.
+func (k Key[T]) Get(opts HandleOpts) (T, bool) {
+	if vals := opts.req.vals; vals != nil {
+		for _, e := range vals.entries {
+			if e.key == k.id {
+				return e.v.(T), true
+			}
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// values are the request-scoped values of a request. A request has few values, so a slice is faster than a map.
+type values struct {
+	entries []valueEntry
+	// noBody is set by Skip, Handle writes only the status code of the response.
+	noBody bool
+	// log and errLog are the loggers enriched by EnrichLog, nil if the request's loggers are not enriched.
+	log, errLog Logger
+	// phases measures the phases of the request for a PhaseObserver, it is pooled to not allocate.
+	phases phaseTimer
+	// resp tracks whether the response was written for recoverAbort, it is pooled to not allocate. raw is set when
+	// a Raw took over the response.
+	resp statusWriter
+	raw  bool
+}
+
+// valueEntry is a value and its key.
+type valueEntry struct {
+	key *keyID
+	v   any
+}
+
+// maxPooledValues is the capacity above which values are not returned to the pool, so a request storing many values
+// does not keep their memory.
+const maxPooledValues = 16
+
+// valuesPool recycles the values of requests, so requests that never use them do not allocate.
+var valuesPool = sync.Pool{
+	New: func() any { return new(values) },
+}
+
+// getValues returns empty values for a request.
+func getValues() *values {
+	return valuesPool.Get().(*values)
+}
+
+// putValues clears the values of a finished request and returns them to the pool.
+func putValues(vals *values) {
+	if cap(vals.entries) > maxPooledValues {
+		return
+	}
+
+	clear(vals.entries)
+	vals.entries = vals.entries[:0]
+	vals.noBody = false
+	vals.log, vals.errLog = nil, nil
+	vals.phases = phaseTimer{}
+	vals.resp, vals.raw = statusWriter{}, false
+	valuesPool.Put(vals)
+}
+
+// trackResponse returns w wrapped to track whether the response was written, see responseWritten.
+func (vals *values) trackResponse(w http.ResponseWriter) *statusWriter {
+	vals.resp = statusWriter{ResponseWriter: w}
+	return &vals.resp
+}
+
+// responseWritten reports whether the response tracked by trackResponse was written, or taken over by a Raw.
+func (vals *values) responseWritten() bool {
+	return vals.raw || vals.resp.status != 0
+}
@@ -0,0 +1,84 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+var (
+	tenantKey = gwu.NewKey[string]("tenant")
+	// otherTenantKey has the name of tenantKey, but is a different key.
+	otherTenantKey = gwu.NewKey[string]("tenant")
+	attemptKey     = gwu.NewKey[int]("attempt")
+)
+
+func TestKeySetGet(t *testing.T) {
+	type got struct {
+		Tenant      string `json:"tenant"`
+		Attempt     int    `json:"attempt"`
+		OtherTenant bool   `json:"otherTenant"`
+		ZeroKey     bool   `json:"zeroKey"`
+	}
+
+	before := func(_ *http.Request, opts gwu.HandleOpts) error {
+		tenantKey.Set(opts, "acme")
+		attemptKey.Set(opts, 1)
+		attemptKey.Set(opts, 2)
+
+		return nil
+	}
+
+	exec := func(_ context.Context, _ any, opts gwu.HandleOpts) (got, int, error) {
+		var g got
+		g.Tenant, _ = tenantKey.Get(opts)
+		g.Attempt, _ = attemptKey.Get(opts)
+		_, g.OtherTenant = otherTenantKey.Get(opts)
+		_, g.ZeroKey = gwu.Key[string]{}.Get(opts)
+
+		return g, http.StatusOK, nil
+	}
+
+	rec := httptest.NewRecorder()
+	gwu.Handle(gwu.Empty(), exec, gwu.Before(before)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertJSON(t, json.RawMessage(rec.Body.Bytes()), `{"tenant":"acme","attempt":2,"otherTenant":false,"zeroKey":false}`)
+
+	// The pooled values of a request do not leak into the next one.
+	rec = httptest.NewRecorder()
+	gwu.Handle(gwu.Empty(), exec).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertJSON(t, json.RawMessage(rec.Body.Bytes()), `{"tenant":"","attempt":0,"otherTenant":false,"zeroKey":false}`)
+}
+
+func TestKeyOutsideRequest(t *testing.T) {
+	opts := gwutest.Opts()
+	tenantKey.Set(opts, "acme")
+	if v, ok := tenantKey.Get(opts); ok {
+		t.Errorf("Get = %q, want unset outside of a request", v)
+	}
+}
+
+func TestKeyAllocs(t *testing.T) {
+	skipAllocsWithRace(t)
+	lookup := func(_ context.Context, _ any, opts gwu.HandleOpts) (smallPoem, int, error) {
+		if _, ok := tenantKey.Get(opts); ok {
+			t.Error("tenant set, want unset")
+		}
+
+		return smallPoem{ID: 7, Title: "Ode"}, http.StatusOK, nil
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	untouched := allocsPerRequest(gwu.Handle(gwu.Empty(), getSmallPoem), r)
+	if got := allocsPerRequest(gwu.Handle(gwu.Empty(), lookup), r); got != untouched {
+		t.Errorf("%v allocs per request getting an absent key, want %v like without values", got, untouched)
+	}
+
+	if untouched > maxHandleAllocs {
+		t.Errorf("%v allocs per request without values, want at most %d", untouched, maxHandleAllocs)
+	}
+}
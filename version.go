@@ -0,0 +1,171 @@
+package gwu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"mime"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"strings"
+)
+
+// ErrUnsupportedVersion is the error of responses to requests for an API version Versioned does not serve.
+// Is safe to display to the client.
+var ErrUnsupportedVersion = errors.New("unsupported API version")
+
+// Versioned returns an http.Handler dispatching requests to the handler of the requested API version.
+// The keys of the map are the versions, e.g. "v1" and "v2".
+//
+// Versioned selects the version by the first segment of the request path and strips it from the path, so
+// /v2/poems is served by the "v2" handler as /poems, see FullPath. Without a version in the path, Versioned selects
+// the version by a vendor media type in the Accept header, e.g. `application/vnd.reqlabs.v2+json` selects "v2".
+//
+// If a vendor media type requests an unknown version, Versioned responds with http.StatusNotAcceptable, otherwise with
+// http.StatusNotFound. The JSON body carries ErrUnsupportedVersion and the supported versions.
+//
+// The selected version is stored in the request's context, retrieve it with VersionFrom. Handle adds it to the
+// handler's logger as the api_version attribute.
+//
+// Example usage:
+//
+//	mux.Handle("/", gwu.Versioned(map[string]http.Handler{"v1": v1, "v2": v2}))
+func Versioned(versions map[string]http.Handler) http.Handler {
+	supported := make([]string, 0, len(versions))
+	for v := range versions {
+		supported = append(supported, v)
+	}
+
+	slices.Sort(supported)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seg, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if h, ok := versions[seg]; ok {
+			h.ServeHTTP(w, stripPrefix(r.WithContext(context.WithValue(r.Context(), versionCtxKey{}, seg)), "/"+seg))
+			return
+		}
+
+		code := http.StatusNotFound
+		if v, ok := acceptVersion(r); ok {
+			if h, ok := versions[v]; ok {
+				h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), versionCtxKey{}, v)))
+				return
+			}
+
+			code = http.StatusNotAcceptable
+		}
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(versionsBody{Error: ErrUnsupportedVersion.Error(), Versions: supported})
+	})
+}
+
+// versionsBody is the JSON body of Versioned's error responses.
+type versionsBody struct {
+	Error    string   `json:"error"`
+	Versions []string `json:"versions"`
+}
+
+// acceptVersion returns the version of the first vendor media type in the Accept header, like
+// `application/vnd.reqlabs.v2+json`.
+func acceptVersion(r *http.Request) (string, bool) {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, s := range strings.Split(accept, ",") {
+			mt, _, err := mime.ParseMediaType(s)
+			if err != nil {
+				continue
+			}
+
+			sub, ok := strings.CutPrefix(mt, "application/vnd.")
+			if !ok {
+				continue
+			}
+
+			sub, _, _ = strings.Cut(sub, "+")
+			if i := strings.LastIndexByte(sub, '.'); i >= 0 && i < len(sub)-1 {
+				return sub[i+1:], true
+			}
+		}
+	}
+
+	return "", false
+}
+
+type versionCtxKey struct{}
+
+// VersionFrom returns the API version selected by Versioned for the request of ctx.
+func VersionFrom(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(versionCtxKey{}).(string)
+	return v, ok
+}
+
+// outTransform transforms the output of an Exec for the API version of the request, see VersionedOut.
+type outTransform struct {
+	out   reflect.Type
+	apply func(v any, version string) any
+}
+
+// VersionedOut transforms the Exec's output for the API version selected by Versioned before Handle encodes it, so
+// the versions share one controller but respond with different JSON. The keys of the map are the versions, the
+// output of requests for other versions, or without version, is encoded as is. A transform may return any value,
+// e.g. a struct with the fields of an older version. Out must be the handler's output type, or the output type
+// of its Upserted.
+//
+// A panicking transform is a bug: Handle responds with ErrEncodeResponse and http.StatusInternalServerError and logs
+// the panic with the ErrorLog, or with the handler's logger if there is none.
+//
+// Example usage:
+//
+//	poems := gwu.Handle(gwu.PathVal("id"), ctrl.ByID, gwu.VersionedOut(map[string]func(Poem) any{
+//		"v1": func(p Poem) any { return PoemV1{Poem: p, AuthorName: p.Author.Name} },
+//	}))
+//	mux.Handle("/", gwu.Versioned(map[string]http.Handler{"v1": poems, "v2": poems}))
+func VersionedOut[Out any](transforms map[string]func(Out) any) HandleOptsFunc {
+	transforms = maps.Clone(transforms)
+	t := &outTransform{out: reflect.TypeFor[Out](), apply: func(v any, version string) any {
+		fn, ok := transforms[version]
+		if !ok {
+			return v
+		}
+
+		// A nil interface Out is no Out, pass the zero Out instead.
+		out, _ := v.(Out)
+		return fn(out)
+	}}
+
+	return func(opt *HandleOpts) {
+		opt.outTransform = t
+	}
+}
+
+// checkOutTransform records an invalid option value if the output type of VersionedOut is not Out, or the output
+// type of an Upserted Out.
+func checkOutTransform[Out any](opts *HandleOpts) {
+	out := reflect.TypeFor[Out]()
+	if out.Implements(upsertedType) {
+		out = reflect.Zero(out).Interface().(upserted).elem()
+	}
+
+	if t := opts.outTransform; t != nil && t.out != out {
+		opts.invalid("VersionedOut: %s is not the output type %s", t.out, out)
+	}
+}
+
+// transformOut applies the VersionedOut transform of the request's API version to the output, it reports false if
+// the transform panicked.
+func (o HandleOpts) transformOut(r *http.Request, v any) (out any, ok bool) {
+	version, _ := VersionFrom(r.Context())
+	defer func() {
+		if p := recover(); p != nil {
+			o.reportFailure("output transform panicked", "method", r.Method, "path", FullPath(r), "panic", p,
+				"stack", string(debug.Stack()))
+			ok = false
+		}
+	}()
+
+	return o.outTransform.apply(v, version), true
+}
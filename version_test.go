@@ -0,0 +1,226 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+// TestVersionedOutNilInterface transforms the nil output of an Exec with an interface output type.
+func TestVersionedOutNilInterface(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (fmt.Stringer, int, error) {
+		return nil, http.StatusOK, nil
+	}, gwu.VersionedOut(map[string]func(fmt.Stringer) any{
+		"v1": func(s fmt.Stringer) any {
+			if s == nil {
+				return []string{}
+			}
+
+			return s.String()
+		},
+	}))
+
+	rt := gwu.Versioned(map[string]http.Handler{"v1": h, "v2": h})
+	for path, want := range map[string]string{"/v1/poems": "[]", "/v2/poems": "null"} {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != want {
+			t.Errorf("%s: %d %s, want 200 %s", path, rec.Code, rec.Body, want)
+		}
+	}
+}
+
+// versionEcho responds with the name of the handler, the version in the context, and the path it sees.
+func versionEcho(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, _ := gwu.VersionFrom(r.Context())
+		_, _ = fmt.Fprintf(w, "%s %s %s %s", name, v, r.URL.Path, gwu.FullPath(r))
+	})
+}
+
+func TestVersioned(t *testing.T) {
+	h := gwu.Versioned(map[string]http.Handler{"v2": versionEcho("second"), "v1": versionEcho("first")})
+
+	tests := []struct {
+		name   string
+		path   string
+		accept []string
+		status int
+		body   string
+	}{
+		{"path", "/v1/poems/7", nil, http.StatusOK, "first v1 /poems/7 /v1/poems/7"},
+		{"path root", "/v2/", nil, http.StatusOK, "second v2 / /v2/"},
+		{"path before header", "/v1/poems", []string{"application/vnd.reqlabs.v2+json"}, http.StatusOK,
+			"first v1 /poems /v1/poems"},
+		{"header", "/poems", []string{"application/vnd.reqlabs.v2+json"}, http.StatusOK,
+			"second v2 /poems /poems"},
+		{"header with parameters", "/poems", []string{"text/html, application/vnd.reqlabs.v1+json; q=0.9"},
+			http.StatusOK, "first v1 /poems /poems"},
+		{"second header", "/poems", []string{"text/html", "application/vnd.reqlabs.v1+json"}, http.StatusOK,
+			"first v1 /poems /poems"},
+		{"unknown path version", "/v3/poems", nil, http.StatusNotFound, ""},
+		{"prefix of a version", "/v1poems", nil, http.StatusNotFound, ""},
+		{"no vendor type", "/poems", []string{"application/json"}, http.StatusNotFound, ""},
+		{"unknown header version", "/poems", []string{"application/vnd.reqlabs.v3+json"}, http.StatusNotAcceptable,
+			""},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		for _, a := range tt.accept {
+			r.Header.Add("Accept", a)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.status)
+			continue
+		}
+
+		if tt.status == http.StatusOK {
+			if rec.Body.String() != tt.body {
+				t.Errorf("%s: %q, want %q", tt.name, rec.Body, tt.body)
+			}
+
+			continue
+		}
+
+		// The error lists the supported versions.
+		var body struct {
+			Error    string   `json:"error"`
+			Versions []string `json:"versions"`
+		}
+
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Header().Get("Content-Type") !=
+			gwu.ContentTypeJSON || body.Error != gwu.ErrUnsupportedVersion.Error() ||
+			strings.Join(body.Versions, ",") != "v1,v2" {
+			t.Errorf("%s: %s %s, %v, want the supported versions", tt.name, rec.Header().Get("Content-Type"),
+				rec.Body, err)
+		}
+	}
+}
+
+// TestVersionedLog adds the version to the handler's logger.
+func TestVersionedLog(t *testing.T) {
+	log := gwutest.Logger()
+	h := gwu.Handle(gwu.Empty(), func(ctx context.Context, _ any, _ gwu.HandleOpts) (gwu.NoBody, int, error) {
+		gwu.LoggerFrom(ctx).Info("listing poems")
+		return gwu.NoContent()
+	}, gwu.Log(log))
+
+	r := httptest.NewRequest(http.MethodGet, "/poems", nil)
+	r.Header.Set("Accept", "application/vnd.reqlabs.v2+json")
+	gwu.Versioned(map[string]http.Handler{"v2": h}).ServeHTTP(httptest.NewRecorder(), r)
+	log.AssertLogged(t, slog.LevelInfo, "listing poems", "api_version", "v2")
+
+	if _, ok := gwu.VersionFrom(context.Background()); ok {
+		t.Error("VersionFrom reported a version without Versioned")
+	}
+}
+
+type poemV1 struct {
+	Name string `json:"name"`
+}
+
+type poemV2 struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+// versionedPoemIn decodes the body of the request's version, v1 has the title as name and no author.
+func versionedPoemIn(r *http.Request, opts gwu.HandleOpts) (poemV2, error) {
+	if v, _ := gwu.VersionFrom(r.Context()); v == "v1" {
+		old, err := gwu.JSON[poemV1]()(r, opts)
+		return poemV2{Title: old.Name, Author: "unknown"}, err
+	}
+
+	return gwu.JSON[poemV2]()(r, opts)
+}
+
+// TestVersionedChain serves two versions with one Exec, the CnIn reads the body of the version and VersionedOut
+// writes the output of the version.
+func TestVersionedChain(t *testing.T) {
+	var got []poemV2
+	create := func(_ context.Context, p poemV2, _ gwu.HandleOpts) (poemV2, int, error) {
+		got = append(got, p)
+		return gwu.Created(p)
+	}
+
+	h := gwu.Handle(versionedPoemIn, create, gwu.VersionedOut(map[string]func(poemV2) any{
+		"v1": func(p poemV2) any { return poemV1{Name: p.Title} },
+	}))
+	rt := gwu.Versioned(map[string]http.Handler{"v1": h, "v2": h})
+
+	tests := []struct {
+		path, body, want string
+		in               poemV2
+	}{
+		{"/v1/poems", `{"name": "Ode"}`, `{"name":"Ode"}`, poemV2{Title: "Ode", Author: "unknown"}},
+		{"/v2/poems", `{"title": "Ode", "author": "Keats"}`, `{"title":"Ode","author":"Keats"}`,
+			poemV2{Title: "Ode", Author: "Keats"}},
+	}
+
+	for i, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", gwu.ContentTypeJSON)
+
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, r)
+		if rec.Code != http.StatusCreated || strings.TrimSpace(rec.Body.String()) != tt.want || got[i] != tt.in {
+			t.Errorf("%s: %d %s with input %+v, want %s with %+v", tt.path, rec.Code, rec.Body, got[i], tt.want,
+				tt.in)
+		}
+	}
+}
+
+// TestVersionedOutUpsert transforms the output of an Upserted and keeps its status code and Location.
+func TestVersionedOutUpsert(t *testing.T) {
+	h := gwu.Handle(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (gwu.Upserted[poemV2], int, error) {
+		return gwu.Upsert(poemV2{Title: "Ode"}, true, "/poem/7"), 0, nil
+	}, gwu.VersionedOut(map[string]func(poemV2) any{
+		"v1": func(p poemV2) any { return poemV1{Name: p.Title} },
+	}))
+
+	rec := httptest.NewRecorder()
+	gwu.Versioned(map[string]http.Handler{"v1": h}).ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/v1/poem/7",
+		nil))
+	if rec.Code != http.StatusCreated || rec.Header().Get("Location") != "/poem/7" ||
+		strings.TrimSpace(rec.Body.String()) != `{"name":"Ode"}` {
+		t.Errorf("%d with Location %q: %s, want the created v1 poem", rec.Code, rec.Header().Get("Location"), rec.Body)
+	}
+}
+
+// TestVersionedOutPanic responds with ErrEncodeResponse and logs the panic of a transform.
+func TestVersionedOutPanic(t *testing.T) {
+	log := gwutest.Logger()
+	h := gwu.Handle(gwu.Empty(), getSmallPoem, gwu.Log(log), gwu.VersionedOut(map[string]func(smallPoem) any{
+		"v1": func(smallPoem) any { panic("no v1 poems") },
+	}))
+
+	rec := httptest.NewRecorder()
+	gwu.Versioned(map[string]http.Handler{"v1": h}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/poem/7",
+		nil))
+	gwutest.AssertError(t, rec, http.StatusInternalServerError, gwu.ErrEncodeResponse.Error())
+	log.AssertLogged(t, slog.LevelError, "output transform panicked", "path", "/v1/poem/7", "panic", "no v1 poems")
+}
+
+// TestVersionedOutWrongType rejects a VersionedOut of another type than the output.
+func TestVersionedOutWrongType(t *testing.T) {
+	_, err := gwu.TryHandle(gwu.Empty(), getSmallPoem, gwu.VersionedOut(map[string]func(poemV2) any{
+		"v1": func(p poemV2) any { return p },
+	}))
+
+	if err == nil || !strings.Contains(err.Error(), "VersionedOut: gwu_test.poemV2 is not the output type") {
+		t.Errorf("error %v, want the mismatched output type", err)
+	}
+}
@@ -0,0 +1,288 @@
+package gwu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotWebSocket is the error of responses to requests to a HandleWS handler without a WebSocket handshake.
+	// Is safe to display to the client.
+	ErrNotWebSocket = errors.New("websocket upgrade required")
+	// ErrWebSocketClosed is returned by the send function of HandleWS after the connection closed.
+	ErrWebSocketClosed = errors.New("websocket closed")
+)
+
+// defaultMaxMessage and defaultPing are the defaults of MaxMessageBytes and PingInterval, maxMessageCap is the limit
+// of messages without MaxMessageBytes limit.
+const (
+	defaultMaxMessage = 1 << 20
+	maxMessageCap     = 64 << 20
+	defaultPing       = 30 * time.Second
+)
+
+// MaxMessageBytes limits the messages a HandleWS handler reads to n bytes, defaults to 1 MiB. HandleWS closes the
+// connection with the close code 1009 on larger messages. A negative n raises the limit to 64 MiB, the length of
+// a message is sent by the client, so it is never unlimited.
+func MaxMessageBytes(n int64) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		if n == 0 {
+			opt.invalid("MaxMessageBytes: limit must not be 0, use a negative limit to remove it")
+			return
+		}
+
+		opt.wsMaxMsg = n
+	}
+}
+
+// PingInterval sets the interval a HandleWS handler sends pings in, defaults to 30 seconds. HandleWS closes
+// connections that stay silent for two intervals. A negative d disables the pings and the timeout.
+// The pings are timed on the handler's Clock, the timeout is a read deadline of the connection in real time.
+func PingInterval(d time.Duration) HandleOptsFunc {
+	return func(opt *HandleOpts) {
+		if d == 0 {
+			opt.invalid("PingInterval: interval must not be 0, use a negative interval to disable pings")
+			return
+		}
+
+		opt.wsPing = d
+	}
+}
+
+// maxMessage returns the message limit of MaxMessageBytes.
+func (o HandleOpts) maxMessage() int64 {
+	switch {
+	case o.wsMaxMsg == 0:
+		return defaultMaxMessage
+	case o.wsMaxMsg < 0:
+		return maxMessageCap
+	default:
+		return o.wsMaxMsg
+	}
+}
+
+// pingInterval returns the interval of PingInterval, 0 for no pings.
+func (o HandleOpts) pingInterval() time.Duration {
+	switch {
+	case o.wsPing == 0:
+		return defaultPing
+	case o.wsPing < 0:
+		return 0
+	default:
+		return o.wsPing
+	}
+}
+
+// HandleWS returns an http.Handler that upgrades requests to WebSocket connections and calls onMessage with every
+// JSON-decoded message. The send function writes an Out as JSON text message, it is safe for concurrent use and
+// returns ErrWebSocketClosed after the connection closed.
+//
+// Before the upgrade, accept decides whether to accept the connection, e.g. to authenticate the request. Its error
+// is written to the response with the status code of a StatusError, or http.StatusForbidden. A nil accept accepts
+// all connections. Requests without a WebSocket handshake get ErrNotWebSocket and http.StatusUpgradeRequired.
+//
+// The context passed to onMessage is canceled when the client disconnects. HandleWS closes the connection with
+// the close code 1007 if a message cannot be decoded, and with 1011 and the error as reason if onMessage fails.
+// Like the errors of an Exec, the errors of onMessage must be safe to display to the client.
+// See MaxMessageBytes and PingInterval to configure the connection, and DrainStreams to close it on shutdown.
+//
+// HandleWS panics if the options are invalid or conflict with each other, like Handle.
+//
+// Example usage:
+//
+//	rt.Handle("GET /echo", gwu.HandleWS(nil,
+//		func(ctx context.Context, in Msg, send func(Msg) error, _ gwu.HandleOpts) error {
+//			return send(in)
+//		}))
+func HandleWS[In, Out any](
+	accept func(r *http.Request, opts HandleOpts) error,
+	onMessage func(ctx context.Context, in In, send func(Out) error, opts HandleOpts) error,
+	optFns ...HandleOptsFunc,
+) http.Handler {
+	opts := newHandleOpts(optFns)
+	if err := opts.validate(); err != nil {
+		panic(err)
+	}
+
+	opts.prepare()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o := opts.forRequest(w, r)
+		o.req.vals = getValues()
+		defer putValues(o.req.vals)
+
+		if o.errLog != nil {
+			defer o.logPanic(r)
+		}
+
+		o.setHeaders(w)
+
+		if !isWebSocketUpgrade(r) {
+			w.Header().Set("Upgrade", "websocket")
+			w.Header().Set("Sec-WebSocket-Version", "13")
+			o.writeError(w, r, ErrNotWebSocket, http.StatusUpgradeRequired)
+			return
+		}
+
+		if code, err := o.runBefore(r); err != nil {
+			o.writeError(w, r, err, code)
+			return
+		}
+
+		if streamsOf(r).isDraining() {
+			o.rejectDraining(w, r)
+			return
+		}
+
+		if accept != nil {
+			if err := accept(r, o); err != nil {
+				code := http.StatusForbidden
+				var statusErr *StatusError
+				if errors.As(err, &statusErr) {
+					code = statusErr.Status
+				}
+
+				o.writeError(w, r, err, code)
+				return
+			}
+		}
+
+		conn, err := upgrade(w, r, o.maxMessage())
+		if err != nil {
+			o.logFailure("websocket upgrade failed", "method", r.Method, "path", FullPath(r), "error", err)
+			return
+		}
+
+		serveWS(r, o, conn, onMessage)
+	})
+}
+
+// serveWS reads the messages of the connection and calls onMessage with them until the connection closes.
+func serveWS[In, Out any](
+	r *http.Request,
+	opts HandleOpts,
+	conn *wsConn,
+	onMessage func(ctx context.Context, in In, send func(Out) error, opts HandleOpts) error,
+) {
+	ctx, cancel := context.WithCancel(ContextWithLogger(r.Context(), opts.Log))
+	if opts.req.trace != nil {
+		ctx = ContextWithTrace(ctx, *opts.req.trace)
+	}
+
+	if opts.exposeRequest {
+		ctx = context.WithValue(ctx, requestCtxKey{}, r)
+	}
+
+	ping := opts.pingInterval()
+
+	var timeout time.Duration
+	if ping > 0 {
+		timeout = 2 * ping
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	msgs := make(chan []byte)
+	readErr := make(chan error, 1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer cancel()
+
+		for {
+			msg, err := conn.readMessage(timeout)
+			if err != nil {
+				readErr <- err
+				return
+			}
+
+			select {
+			case msgs <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if ping > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			clock := opts.Clock()
+			for {
+				select {
+				case <-clock.After(ping):
+					if conn.write(wsPing, nil) != nil {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	send := func(out Out) error {
+		b, err := opts.JSONCodec().Marshal(out)
+		if err != nil {
+			return errors.Join(ErrEncodeResponse, err)
+		}
+
+		return conn.write(wsText, b)
+	}
+
+	shutdown := make(chan struct{})
+	streams := streamsOf(r)
+	entry, ok := streams.enter(func() { close(shutdown) })
+	if !ok {
+		conn.close(wsCloseGoingAway, ErrShuttingDown.Error())
+		return
+	}
+
+	defer streams.leave(entry)
+
+	code, reason := wsCloseNormal, ""
+	defer func() {
+		conn.close(code, reason)
+		opts.Log.Debug("websocket closed", "path", FullPath(r), "code", code)
+	}()
+
+	for {
+		select {
+		case msg := <-msgs:
+			var in In
+			if err := opts.JSONCodec().Unmarshal(msg, &in); err != nil {
+				code, reason = wsCloseInvalidData, ErrDecodeRequest.Error()
+				return
+			}
+
+			if err := onMessage(ctx, in, send, opts); err != nil {
+				opts.logFailure("websocket message failed", "path", FullPath(r), "error", err)
+				code, reason = wsCloseInternalError, err.Error()
+				return
+			}
+		case <-shutdown:
+			code, reason = wsCloseGoingAway, ErrShuttingDown.Error()
+			return
+		case err := <-readErr:
+			var closeErr *wsCloseError
+			switch {
+			case errors.As(err, &closeErr):
+				code, reason = closeErr.code, closeErr.reason
+			case isClosedErr(err):
+				code = wsCloseNoStatus
+			default:
+				opts.Log.Debug("websocket read failed", "path", FullPath(r), "error", err)
+				code = wsCloseNoStatus
+			}
+
+			return
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package gwu_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+	"github.com/jensilo/gwu/gwutest"
+)
+
+type wsMsg struct {
+	Text string `json:"text"`
+}
+
+func echoWS(_ context.Context, in wsMsg, send func(wsMsg) error, _ gwu.HandleOpts) error {
+	if in.Text == "fail" {
+		return errors.New("cannot echo fail")
+	}
+
+	return send(in)
+}
+
+func wsServer(t *testing.T, h http.Handler) string {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	return srv.URL
+}
+
+func TestHandleWSEcho(t *testing.T) {
+	c := gwutest.DialWS(t, wsServer(t, gwu.HandleWS(nil, echoWS)), nil)
+
+	for _, text := range []string{"hello", "world"} {
+		c.Send(wsMsg{Text: text})
+
+		var got wsMsg
+		c.Receive(&got)
+		if got.Text != text {
+			t.Errorf("got %q, want %q", got.Text, text)
+		}
+	}
+
+	c.Close()
+}
+
+func TestHandleWSCloseCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		optFns   []gwu.HandleOptsFunc
+		send     func(c *gwutest.WSClient)
+		wantCode int
+	}{
+		{
+			name:     "invalid JSON",
+			send:     func(c *gwutest.WSClient) { c.WriteFrame(true, gwutest.WSText, []byte("{")) },
+			wantCode: 1007,
+		},
+		{
+			name:     "exec error",
+			send:     func(c *gwutest.WSClient) { c.Send(wsMsg{Text: "fail"}) },
+			wantCode: 1011,
+		},
+		{
+			name:     "message over limit",
+			optFns:   []gwu.HandleOptsFunc{gwu.MaxMessageBytes(16)},
+			send:     func(c *gwutest.WSClient) { c.Send(wsMsg{Text: "longer than sixteen bytes"}) },
+			wantCode: 1009,
+		},
+		{
+			name:   "fragments over limit",
+			optFns: []gwu.HandleOptsFunc{gwu.MaxMessageBytes(16)},
+			send: func(c *gwutest.WSClient) {
+				c.WriteFrame(false, gwutest.WSText, []byte(`{"text":"`))
+				c.WriteFrame(true, 0, []byte(`fragmented"}`))
+			},
+			wantCode: 1009,
+		},
+		{
+			name:   "huge frame without limit",
+			optFns: []gwu.HandleOptsFunc{gwu.MaxMessageBytes(-1)},
+			send: func(c *gwutest.WSClient) {
+				// A masked text frame claiming a payload of 2^62 bytes, without the payload.
+				frame := append([]byte{0x81, 0x80 | 127}, binary.BigEndian.AppendUint64(nil, 1<<62)...)
+				c.WriteRaw(append(frame, 1, 2, 3, 4))
+			},
+			wantCode: 1009,
+		},
+		{
+			name:     "unmasked frame",
+			send:     func(c *gwutest.WSClient) { c.WriteRaw([]byte{0x81, 0x01, 'x'}) },
+			wantCode: 1002,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gwutest.DialWS(t, wsServer(t, gwu.HandleWS(nil, echoWS, tt.optFns...)), nil)
+			tt.send(c)
+
+			if code, reason := c.ReadClose(); code != tt.wantCode {
+				t.Errorf("close code = %d (%s), want %d", code, reason, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestHandleWSCancelOnDisconnect(t *testing.T) {
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	h := gwu.HandleWS(nil, func(ctx context.Context, _ wsMsg, _ func(wsMsg) error, _ gwu.HandleOpts) error {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+
+		return nil
+	})
+
+	c := gwutest.DialWS(t, wsServer(t, h), nil)
+	c.Send(wsMsg{Text: "wait"})
+	<-started
+	c.Close()
+
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("context of onMessage not canceled after the client disconnected")
+	}
+}
+
+func TestHandleWSRequiresUpgrade(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gwu.HandleWS(nil, echoWS).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusUpgradeRequired {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUpgradeRequired)
+	}
+}
+
+// TestHandleWSPingClock sends the pings on the handler's Clock.
+func TestHandleWSPingClock(t *testing.T) {
+	clock := gwu.NewManualClock(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	c := gwutest.DialWS(t, wsServer(t, gwu.HandleWS(nil, echoWS, gwu.WithClock(clock),
+		gwu.PingInterval(time.Hour))), nil)
+
+	// No ping before an hour passed on the clock, the echo is the next frame.
+	c.Send(wsMsg{Text: "hello"})
+	if _, opcode, _ := c.ReadFrame(); opcode != gwutest.WSText {
+		t.Fatalf("opcode %#x, want the text frame of the echo", opcode)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+				clock.Advance(10 * time.Minute)
+			}
+		}
+	}()
+
+	if _, opcode, _ := c.ReadFrame(); opcode != gwutest.WSPing {
+		t.Errorf("opcode %#x, want a ping", opcode)
+	}
+
+	c.Close()
+}
@@ -0,0 +1,24 @@
+package gwu
+
+import "net/http"
+
+// Wrap wraps the handler with standard middleware, the first middleware is the outermost, so it sees the request
+// first and the response last. Wrap works with any http.Handler, like a handler of Handle or HandleFunc, and keeps
+// the handler's options in effect, they apply inside the middleware.
+//
+// Middleware that replaces the http.ResponseWriter, like to capture the status and size for an access log, sees the
+// final status code and body of the gwu handler, including error responses: gwu wraps the writer it receives only
+// internally, like for WarnSlow, and always writes through it. Panics are not recovered, they pass the middleware.
+// The replacing writer must implement Unwrap returning the writer it wraps, or Flush and the write deadlines of
+// Stream, LongPoll, and WebSocket handlers reach it only through http.ResponseController's fallbacks and fail.
+//
+// Example usage:
+//
+//	h := gwu.Wrap(gwu.HandleFunc(gwu.Empty(), ctrl.All), accessLog, gzipMiddleware)
+func Wrap(h http.Handler, mw ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+
+	return h
+}
@@ -0,0 +1,108 @@
+package gwu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jensilo/gwu"
+)
+
+// accessWriter captures the status code and size of a response for accessLog.
+type accessWriter struct {
+	http.ResponseWriter
+	status, size int
+}
+
+func (w *accessWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *accessWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+
+	return n, err
+}
+
+func (w *accessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// accessLog is a middleware replacing the writer to log the status code and size of every response.
+func accessLog(log *[]accessWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			aw := &accessWriter{ResponseWriter: w}
+			next.ServeHTTP(aw, r)
+			*log = append(*log, *aw)
+		})
+	}
+}
+
+// poweredBy is a middleware adding a header.
+func poweredBy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Powered-By", "gwu")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestWrapStatusCapture(t *testing.T) {
+	created := func(context.Context, any, gwu.HandleOpts) (smallPoem, int, error) {
+		return smallPoem{ID: 7, Title: "Ode"}, http.StatusCreated, nil
+	}
+
+	notFound := func(context.Context, any, gwu.HandleOpts) (smallPoem, int, error) {
+		return smallPoem{}, http.StatusNotFound, errPoemNotFound
+	}
+
+	tests := []struct {
+		name string
+		h    http.Handler
+		want int
+	}{
+		{"created", gwu.HandleFunc(gwu.Empty(), created), http.StatusCreated},
+		{"error", gwu.HandleFunc(gwu.Empty(), notFound), http.StatusNotFound},
+		// WarnSlow wraps the writer inside the gwu handler.
+		{"wrapped inside", gwu.HandleFunc(gwu.Empty(), created, gwu.WarnSlow(time.Hour)), http.StatusCreated},
+		{"no content", gwu.HandleFunc(gwu.Empty(), func(context.Context, any, gwu.HandleOpts) (any, int, error) {
+			return nil, http.StatusNoContent, nil
+		}), http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []accessWriter
+			rec := httptest.NewRecorder()
+			gwu.Wrap(tt.h, poweredBy, accessLog(&log)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tt.want {
+				t.Errorf("status %d, want %d", rec.Code, tt.want)
+			}
+
+			if got := rec.Header().Get("X-Powered-By"); got != "gwu" {
+				t.Errorf("X-Powered-By %q, want gwu", got)
+			}
+
+			if len(log) != 1 {
+				t.Fatalf("%d access log entries, want 1", len(log))
+			}
+
+			if log[0].status != rec.Code || log[0].size != rec.Body.Len() {
+				t.Errorf("access log status %d size %d, want %d and %d", log[0].status, log[0].size, rec.Code,
+					rec.Body.Len())
+			}
+		})
+	}
+}
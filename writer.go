@@ -0,0 +1,66 @@
+package gwu
+
+import (
+	"io"
+	"net/http"
+)
+
+// statusWriter records the status code and the number of bytes written to the response.
+// If capture is not nil, statusWriter copies the response body to it.
+type statusWriter struct {
+	http.ResponseWriter
+	status  int
+	size    int
+	capture io.Writer
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	if w.capture != nil {
+		w.capture.Write(b[:n])
+	}
+
+	return n, err
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ReadFrom keeps the io.ReaderFrom of the underlying http.ResponseWriter, like its sendfile for files, if the body
+// is not captured.
+func (w *statusWriter) ReadFrom(src io.Reader) (int64, error) {
+	rf, ok := w.ResponseWriter.(io.ReaderFrom)
+	if !ok || w.capture != nil {
+		return io.Copy(struct{ io.Writer }{w}, src)
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := rf.ReadFrom(src)
+	w.size += int(n)
+
+	return n, err
+}
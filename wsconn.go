@@ -0,0 +1,305 @@
+package gwu
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WebSocket opcodes, see RFC 6455 section 5.2.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// WebSocket close codes, see RFC 6455 section 7.4.1.
+const (
+	wsCloseNormal        = 1000
+	wsCloseGoingAway     = 1001
+	wsCloseProtocolError = 1002
+	wsCloseNoStatus      = 1005
+	wsCloseInvalidData   = 1007
+	wsCloseTooBig        = 1009
+	wsCloseInternalError = 1011
+)
+
+// wsGUID is the GUID of the Sec-WebSocket-Accept header, see RFC 6455 section 1.3.
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsCloseError is a failure of the connection, the connection is closed with the code and reason.
+type wsCloseError struct {
+	code   int
+	reason string
+}
+
+func (e *wsCloseError) Error() string {
+	return "websocket: " + e.reason
+}
+
+// isWebSocketUpgrade reports whether the request is a valid WebSocket opening handshake.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		headerContains(r.Header, "Connection", "upgrade") &&
+		headerContains(r.Header, "Upgrade", "websocket") &&
+		r.Header.Get("Sec-WebSocket-Version") == "13" &&
+		r.Header.Get("Sec-WebSocket-Key") != ""
+}
+
+// headerContains reports whether the comma-separated values of the header contain the token, ignoring case.
+func headerContains(h http.Header, key, token string) bool {
+	for _, v := range h.Values(key) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// wsAccept returns the Sec-WebSocket-Accept header value for the key.
+func wsAccept(key string) string {
+	h := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// wsConn is a server-side WebSocket connection. Reads happen on a single goroutine, writes are safe for
+// concurrent use.
+type wsConn struct {
+	conn   net.Conn
+	br     *bufio.Reader
+	maxMsg int64
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// upgrade completes the opening handshake and takes over the connection.
+func upgrade(w http.ResponseWriter, r *http.Request, maxMsg int64) (*wsConn, error) {
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(resp)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &wsConn{conn: conn, br: brw.Reader, maxMsg: maxMsg}, nil
+}
+
+// readMessage returns the next text or binary message, it answers pings and the closing handshake itself.
+// It returns io.EOF after the client closed the connection, and a *wsCloseError if the client violated the
+// protocol or the message limit.
+func (c *wsConn) readMessage(timeout time.Duration) ([]byte, error) {
+	var (
+		msg    []byte
+		opcode = -1
+	)
+
+	for {
+		if timeout > 0 {
+			_ = c.conn.SetReadDeadline(time.Now().Add(timeout))
+		}
+
+		fin, op, payload, err := c.readFrame(int64(len(msg)))
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case wsPing:
+			if err := c.write(wsPong, payload); err != nil {
+				return nil, err
+			}
+
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			code := wsCloseNoStatus
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+
+			c.close(code, "")
+			return nil, io.EOF
+		case wsContinuation:
+			if opcode < 0 {
+				return nil, &wsCloseError{code: wsCloseProtocolError, reason: "unexpected continuation frame"}
+			}
+		case wsText, wsBinary:
+			if opcode >= 0 {
+				return nil, &wsCloseError{code: wsCloseProtocolError, reason: "expected continuation frame"}
+			}
+
+			opcode = op
+		default:
+			return nil, &wsCloseError{code: wsCloseProtocolError, reason: "unknown opcode"}
+		}
+
+		msg = append(msg, payload...)
+		if !fin {
+			continue
+		}
+
+		if opcode == wsText && !utf8.Valid(msg) {
+			return nil, &wsCloseError{code: wsCloseInvalidData, reason: "invalid UTF-8 in text message"}
+		}
+
+		return msg, nil
+	}
+}
+
+// readFrame reads a single frame, n is the length of the message read so far for the message limit.
+func (c *wsConn) readFrame(n int64) (fin bool, opcode int, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin, opcode = head[0]&0x80 != 0, int(head[0]&0x0f)
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, &wsCloseError{code: wsCloseProtocolError, reason: "reserved bits set"}
+	}
+
+	if head[1]&0x80 == 0 {
+		return false, 0, nil, &wsCloseError{code: wsCloseProtocolError, reason: "unmasked client frame"}
+	}
+
+	length := int64(head[1] & 0x7f)
+	switch length {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = int64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		if b[0]&0x80 != 0 {
+			return false, 0, nil, &wsCloseError{code: wsCloseProtocolError, reason: "invalid frame length"}
+		}
+
+		length = int64(binary.BigEndian.Uint64(b[:]))
+	}
+
+	if opcode >= wsClose && (!fin || length > 125) {
+		return false, 0, nil, &wsCloseError{code: wsCloseProtocolError, reason: "invalid control frame"}
+	}
+
+	limit := c.maxMsg
+	if limit <= 0 {
+		limit = maxMessageCap
+	}
+
+	if opcode < wsClose && length > limit-n {
+		return false, 0, nil, &wsCloseError{code: wsCloseTooBig, reason: "message too large"}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// write writes a single unmasked frame.
+func (c *wsConn) write(opcode int, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrWebSocketClosed
+	}
+
+	return c.writeFrame(opcode, payload)
+}
+
+// writeFrame writes a frame, the caller must hold c.mu.
+func (c *wsConn) writeFrame(opcode int, payload []byte) error {
+	frame := make([]byte, 0, 10+len(payload))
+	frame = append(frame, 0x80|byte(opcode))
+
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := c.conn.Write(append(frame, payload...))
+
+	return err
+}
+
+// close sends a close frame with the code and reason, and closes the connection. Only the first call has an effect.
+func (c *wsConn) close(code int, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	c.closed = true
+
+	var payload []byte
+	if code != wsCloseNoStatus {
+		// Control frames are limited to 125 bytes, 2 of them are the code.
+		if len(reason) > 123 {
+			reason = reason[:123]
+			for !utf8.ValidString(reason) {
+				reason = reason[:len(reason)-1]
+			}
+		}
+
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		payload = append(payload, reason...)
+	}
+
+	_ = c.writeFrame(wsClose, payload)
+	c.conn.Close()
+}
+
+// isClosedErr reports whether the read error is of a connection closed by the client or the network.
+func isClosedErr(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) || errors.Is(err, io.ErrUnexpectedEOF)
+}